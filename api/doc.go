@@ -0,0 +1,2 @@
+// ジョブネットの管理用HTTP APIを提供する常駐コマンド
+package main
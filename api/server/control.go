@@ -0,0 +1,181 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/db/query"
+	"github.com/unirita/cuto/master/jobnet"
+)
+
+// リクエストボディの最大サイズ
+const maxBodySize = 10 * 1024 * 1024
+
+// ジョブネット起動要求のリクエストボディ
+type startRequest struct {
+	Jobnet string `json:"jobnet"`
+}
+
+// ジョブネットの起動・リラン・キャンセルのレスポンスボディ
+type controlResult struct {
+	Instance int    `json:"instance"`
+	Message  string `json:"message"`
+}
+
+// BPMN検証のレスポンスボディ
+type validateResult struct {
+	Valid   bool   `json:"valid"`
+	Message string `json:"message"`
+}
+
+// リクエストボディで指定されたジョブネットを起動し、インスタンスIDを返す。
+func (s *Server) startJobnet(w http.ResponseWriter, r *http.Request) {
+	req := new(startRequest)
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxBodySize)).Decode(req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %s", err))
+		return
+	}
+	if !isValidJobnetName(req.Jobnet) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid jobnet name [%s].", req.Jobnet))
+		return
+	}
+
+	id, err := s.launch("-n", req.Jobnet, "-s")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, &controlResult{Instance: id, Message: "Jobnet started."})
+}
+
+// master -rと同様に、ジョブネットインスタンスをリランする。
+func (s *Server) rerunJobnet(w http.ResponseWriter, r *http.Request, nid int) {
+	result, status, err := s.getJobnetResult(nid)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	switch result.Status {
+	case db.NORMAL, db.WARN:
+		writeError(w, http.StatusConflict, fmt.Sprintf("Instance [%d] already ended with no error.", nid))
+		return
+	case db.RUNNING:
+		writeError(w, http.StatusConflict, fmt.Sprintf("Instance [%d] is running.", nid))
+		return
+	}
+
+	id, err := s.launch("-r", strconv.Itoa(nid))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, &controlResult{Instance: id, Message: "Jobnet rerun started."})
+}
+
+// master -kと同様に、実行中のジョブネットインスタンスをキャンセルする。
+func (s *Server) cancelJobnet(w http.ResponseWriter, r *http.Request, nid int) {
+	result, status, err := s.getJobnetResult(nid)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	if result.Status != db.RUNNING {
+		writeError(w, http.StatusConflict, fmt.Sprintf("Instance [%d] is not running.", nid))
+		return
+	}
+
+	output, err := s.command("-k", strconv.Itoa(nid))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("%s: %s", err, strings.TrimSpace(output)))
+		return
+	}
+	writeJSON(w, http.StatusOK, &controlResult{Instance: nid, Message: "Jobnet cancelled."})
+}
+
+func (s *Server) getJobnetResult(nid int) (*db.JobNetworkResult, int, error) {
+	conn, err := db.Open(s.dbFile)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	defer conn.Close()
+
+	result, err := query.GetJobnetwork(conn, nid)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+	return result, http.StatusOK, nil
+}
+
+// BPMN定義を検証し、結果を返す。
+// jobnetパラメータが指定された場合はそのジョブネットのBPMNファイルを、それ以外の場合はリクエストボディをBPMNとして検証する。
+func (s *Server) handleValidate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed.")
+		return
+	}
+
+	name := r.URL.Query().Get("jobnet")
+	var bpmn []byte
+	var err error
+	if name == "" {
+		name = "validate"
+		bpmn, err = ioutil.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	} else if !isValidJobnetName(name) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid jobnet name [%s].", name))
+		return
+	} else {
+		bpmn, err = ioutil.ReadFile(filepath.Join(s.jobnetDir, name+".bpmn"))
+		if os.IsNotExist(err) {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("Jobnet [%s] not found.", name))
+			return
+		}
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := validateBPMN(name, bpmn); err != nil {
+		writeJSON(w, http.StatusOK, &validateResult{Valid: false, Message: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, &validateResult{Valid: true, Message: "BPMN is valid."})
+}
+
+// -sオプション無しのmaster -nと同様に、BPMNを検証する。
+// 拡張ジョブ定義と営業日カレンダーは、ジョブネット名を元にjobnet_dirから読み込む。
+//
+// param : name ジョブネット名。
+//
+// param : bpmn BPMNの内容。
+//
+// return : エラー情報。
+func validateBPMN(name string, bpmn []byte) error {
+	nwk, err := jobnet.NewNetwork(name)
+	if err != nil {
+		return err
+	}
+	defer nwk.Terminate()
+
+	if err := nwk.LoadElements(bytes.NewReader(bpmn)); err != nil {
+		return err
+	}
+	return nwk.Validate()
+}
+
+// ジョブネット名が空でなく、jobnet_dirの外を指していないかを調べる。
+func isValidJobnetName(name string) bool {
+	if name == "" || name == "." || name == ".." {
+		return false
+	}
+	return !strings.ContainsAny(name, `/\`)
+}
@@ -0,0 +1,2 @@
+// apiコマンドが提供する、ジョブネットの管理用HTTP APIのパッケージ。
+package server
@@ -0,0 +1,218 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/db/query"
+	"github.com/unirita/cuto/show/gen"
+	"github.com/unirita/cuto/utctime"
+)
+
+// statusパラメータに指定できるステータス名
+// showコマンドのstatusオプションと同じ値とする。
+var statusNames = map[string]int{
+	"normal":    db.NORMAL,
+	"abnormal":  db.ABNORMAL,
+	"running":   db.RUNNING,
+	"warn":      db.WARN,
+	"cancelled": db.CANCELLED,
+}
+
+// /jobnetsへのリクエストを処理する。
+func (s *Server) handleJobnets(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		s.listJobnets(w, r)
+	case "POST":
+		s.startJobnet(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed.")
+	}
+}
+
+// /jobnets/{nid}と、その配下へのリクエストを処理する。
+func (s *Server) handleJobnet(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/jobnets/"), "/")
+	nid, err := strconv.Atoi(parts[0])
+	if err != nil || nid <= 0 || len(parts) > 2 {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+
+	var sub string
+	if len(parts) == 2 {
+		sub = parts[1]
+	}
+	method := "GET"
+	if sub == "rerun" || sub == "cancel" {
+		method = "POST"
+	}
+	if r.Method != method {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed.")
+		return
+	}
+
+	switch sub {
+	case "":
+		s.getJobnet(w, r, nid)
+	case "jobs":
+		s.getJobs(w, r, nid)
+	case "rerun":
+		s.rerunJobnet(w, r, nid)
+	case "cancel":
+		s.cancelJobnet(w, r, nid)
+	default:
+		writeError(w, http.StatusNotFound, "Not found.")
+	}
+}
+
+// クエリパラメータで絞り込んだジョブネットインスタンスの一覧を返す。
+// パラメータはshowコマンドのオプションと同じく、nid、jobnet、from、to、status、utcとする。
+func (s *Server) listJobnets(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	isUTC := params.Get("utc") == "true" || params.Get("utc") == "1"
+
+	conn, err := db.Open(s.dbFile)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer conn.Close()
+
+	q := query.CreateJobnetworkQuery(conn)
+	if v := params.Get("nid"); v != "" {
+		nid, err := strconv.Atoi(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid nid [%s].", v))
+			return
+		}
+		q.AddAndWhereID(nid)
+	}
+	if v := params.Get("jobnet"); v != "" {
+		q.AddAndWhereJobnetwork(v)
+	}
+	if v := params.Get("from"); v != "" {
+		from, err := parseDate(v, "000000.000", isUTC)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid from [%s].", v))
+			return
+		}
+		q.AddAndWhereMoreThanStartdate(from)
+	}
+	if v := params.Get("to"); v != "" {
+		to, err := parseDate(v, "235959.999", isUTC)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid to [%s].", v))
+			return
+		}
+		q.AddAndWhereLessThanStartdate(to)
+	}
+	if v := params.Get("status"); v != "" {
+		status, ok := statusNames[v]
+		if !ok {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid status [%s].", v))
+			return
+		}
+		q.AddAndWhereStatus(status)
+	}
+	q.AddOrderBy(query.ORDERBY_ASC)
+
+	results, err := q.GetJobnetworkList()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	out := &gen.OutputRoot{Jobnetworks: make([]*gen.OutputJobNet, 0, len(results))}
+	for _, result := range results {
+		out.Jobnetworks = append(out.Jobnetworks, gen.NewOutputJobNet(result, nil, nil, isUTC))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// yyyymmdd形式の日付と時刻の接尾辞を、検索用のUTCのタイムスタンプ文字列に変換する。
+//
+// param : date yyyymmdd形式の日付。
+//
+// param : suffix 日付に付与する時刻（hhmmss.sss形式）。
+//
+// param : isUTC 日付がUTCの場合はtrue。
+//
+// return : タイムスタンプ文字列。
+//
+// return : エラー情報。
+func parseDate(date, suffix string, isUTC bool) (string, error) {
+	parseMethod := utctime.Parse
+	if !isUTC {
+		parseMethod = utctime.ParseLocaltime
+	}
+	if len(date) != len(utctime.Date8Num) {
+		return "", fmt.Errorf("Invalid date format.")
+	}
+	t, err := parseMethod(utctime.NoDelimiter, date+suffix)
+	if err != nil {
+		return "", err
+	}
+	return t.String(), nil
+}
+
+// ジョブネットインスタンスを、ジョブと合わせて返す。
+func (s *Server) getJobnet(w http.ResponseWriter, r *http.Request, nid int) {
+	out, status, err := s.loadJobnet(nid, r.URL.Query().Get("utc"))
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// ジョブネットインスタンスのジョブを返す。
+func (s *Server) getJobs(w http.ResponseWriter, r *http.Request, nid int) {
+	out, status, err := s.loadJobnet(nid, r.URL.Query().Get("utc"))
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	jobs := out.Jobs
+	if jobs == nil {
+		jobs = make([]*gen.OutputJob, 0)
+	}
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+// ジョブネットインスタンスとそのジョブをDBから読み込む。
+//
+// param : nid インスタンスID。
+//
+// param : utc UTCで出力する場合は"true"または"1"。
+//
+// return : ジョブネットインスタンスの情報。
+//
+// return : エラー時に返すHTTPステータスコード。
+//
+// return : エラー情報。
+func (s *Server) loadJobnet(nid int, utc string) (*gen.OutputJobNet, int, error) {
+	conn, err := db.Open(s.dbFile)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	defer conn.Close()
+
+	jobnet, err := query.GetJobnetwork(conn, nid)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+	jobs, err := query.GetJobsOfTargetNetwork(conn, nid, query.ORDERBY_ASC)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	attempts, err := query.GetJobAttemptsOfTargetNetwork(conn, nid)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	isUTC := utc == "true" || utc == "1"
+	return gen.NewOutputJobNet(jobnet, jobs, attempts, isUTC), http.StatusOK, nil
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+
+	"github.com/unirita/cuto/log"
+	"github.com/unirita/cuto/master/config"
+	"github.com/unirita/cuto/master/launcher"
+)
+
+// ジョブネットの管理用APIを提供するHTTPハンドラ
+//
+// ルーティング：
+//
+//	GET  /jobnets              ジョブネットインスタンスの一覧を取得する。
+//	POST /jobnets              ジョブネットを起動する。
+//	GET  /jobnets/{nid}        ジョブネットインスタンスをジョブと合わせて取得する。
+//	GET  /jobnets/{nid}/jobs   ジョブネットインスタンスのジョブを取得する。
+//	POST /jobnets/{nid}/rerun  ジョブネットインスタンスをリランする。
+//	POST /jobnets/{nid}/cancel 実行中のジョブネットインスタンスをキャンセルする。
+//	POST /validate             BPMN定義を検証する。
+type Server struct {
+	dbFile    string
+	jobnetDir string
+	authToken string
+	mux       *http.ServeMux
+
+	launch  func(args ...string) (int, error)    // masterコマンドを起動し、起動したジョブネットのインスタンスIDを返す
+	command func(args ...string) (string, error) // masterコマンドを終了まで実行し、その出力を返す
+}
+
+// config.Loadで読み込んだmaster.iniの設定から、Serverオブジェクトを生成する。
+// ジョブネットの起動・リラン・キャンセルは、masterコマンドにより行う。
+//
+// param : masterPath masterコマンドのパス。
+//
+// param : configPath masterコマンドに指定する設定ファイルのパス。
+//
+// return : Serverオブジェクト。
+func New(masterPath, configPath string) *Server {
+	s := newServer(config.DB.DBFile, config.Dir.JobnetDir, config.API.AuthToken)
+	s.launch = func(args ...string) (int, error) {
+		cmd := exec.Command(masterPath, append(args, "-c", configPath)...)
+		id, _, err := launcher.Launch(cmd)
+		return id, err
+	}
+	s.command = func(args ...string) (string, error) {
+		cmd := exec.Command(masterPath, append(args, "-c", configPath)...)
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+	return s
+}
+
+func newServer(dbFile, jobnetDir, authToken string) *Server {
+	s := new(Server)
+	s.dbFile = dbFile
+	s.jobnetDir = jobnetDir
+	s.authToken = authToken
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("/jobnets", s.handleJobnets)
+	s.mux.HandleFunc("/jobnets/", s.handleJobnet)
+	s.mux.HandleFunc("/validate", s.handleValidate)
+	return s
+}
+
+// リクエストの認証を確認し、ルーティング先のハンドラで処理する。
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Info(fmt.Sprintf("%s %s %s", r.RemoteAddr, r.Method, r.URL.String()))
+	if !s.isAuthorized(r) {
+		writeError(w, http.StatusUnauthorized, "Unauthorized.")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// auth_tokenが設定されている場合に、リクエストのBearerトークンを確認する。
+func (s *Server) isAuthorized(r *http.Request) bool {
+	if s.authToken == "" {
+		return true
+	}
+	const prefix = "Bearer "
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, prefix) {
+		return false
+	}
+	token := auth[len(prefix):]
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.authToken)) == 1
+}
+
+type errorResult struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(b)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, &errorResult{Error: msg})
+}
@@ -0,0 +1,306 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unirita/cuto/show/gen"
+	"github.com/unirita/cuto/testutil"
+)
+
+var testDataDir = filepath.Join(testutil.GetBaseDir(), "api", "server", "_testdata")
+
+// testCommand records arguments of master command calls.
+type testCommand struct {
+	args [][]string
+	err  error
+}
+
+func (c *testCommand) launch(args ...string) (int, error) {
+	c.args = append(c.args, args)
+	return 100, c.err
+}
+
+func (c *testCommand) command(args ...string) (string, error) {
+	c.args = append(c.args, args)
+	return "output", c.err
+}
+
+func newTestServer(token string) (*Server, *testCommand) {
+	s := newServer(filepath.Join(testDataDir, "api_test.sqlite"), filepath.Join(testDataDir, "jobnet"), token)
+	c := new(testCommand)
+	s.launch = c.launch
+	s.command = c.command
+	return s, c
+}
+
+func doRequest(s *Server, method, url, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, url, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestListJobnets(t *testing.T) {
+	s, _ := newTestServer("")
+	rec := doRequest(s, "GET", "/jobnets?status=abnormal&utc=1", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status code => %d, wants %d", rec.Code, http.StatusOK)
+	}
+
+	out := new(gen.OutputRoot)
+	if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+		t.Fatalf("Could not decode response: %s", err)
+	}
+	if len(out.Jobnetworks) != 2 {
+		t.Fatalf("Number of jobnets => %d, wants %d", len(out.Jobnetworks), 2)
+	}
+	if out.Jobnetworks[0].Id != 3 || out.Jobnetworks[1].Id != 8 {
+		t.Errorf("Unexpected jobnets: %d, %d", out.Jobnetworks[0].Id, out.Jobnetworks[1].Id)
+	}
+}
+
+func TestListJobnets_DateRange(t *testing.T) {
+	s, _ := newTestServer("")
+	rec := doRequest(s, "GET", "/jobnets?from=20150319&to=20150319&utc=1", "")
+	out := new(gen.OutputRoot)
+	if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+		t.Fatalf("Could not decode response: %s", err)
+	}
+	if len(out.Jobnetworks) != 2 {
+		t.Errorf("Number of jobnets => %d, wants %d", len(out.Jobnetworks), 2)
+	}
+}
+
+func TestListJobnets_InvalidParameter(t *testing.T) {
+	s, _ := newTestServer("")
+	for _, url := range []string{"/jobnets?status=unknown", "/jobnets?nid=a", "/jobnets?from=2015"} {
+		rec := doRequest(s, "GET", url, "")
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: Status code => %d, wants %d", url, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestGetJobnet(t *testing.T) {
+	s, _ := newTestServer("")
+	rec := doRequest(s, "GET", "/jobnets/1?utc=1", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status code => %d, wants %d", rec.Code, http.StatusOK)
+	}
+
+	out := new(gen.OutputJobNet)
+	if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+		t.Fatalf("Could not decode response: %s", err)
+	}
+	if out.Id != 1 {
+		t.Errorf("Id => %d, wants %d", out.Id, 1)
+	}
+	if len(out.Jobs) != 4 {
+		t.Errorf("Number of jobs => %d, wants %d", len(out.Jobs), 4)
+	}
+}
+
+func TestGetJobs(t *testing.T) {
+	s, _ := newTestServer("")
+	rec := doRequest(s, "GET", "/jobnets/1/jobs", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status code => %d, wants %d", rec.Code, http.StatusOK)
+	}
+
+	var jobs []*gen.OutputJob
+	if err := json.Unmarshal(rec.Body.Bytes(), &jobs); err != nil {
+		t.Fatalf("Could not decode response: %s", err)
+	}
+	if len(jobs) != 4 || jobs[0].JobId != "JOB01" {
+		t.Errorf("Unexpected jobs: %s", rec.Body.String())
+	}
+}
+
+func TestGetJobnet_NotFound(t *testing.T) {
+	s, _ := newTestServer("")
+	for _, url := range []string{"/jobnets/999", "/jobnets/abc", "/jobnets/1/unknown"} {
+		rec := doRequest(s, "GET", url, "")
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: Status code => %d, wants %d", url, rec.Code, http.StatusNotFound)
+		}
+	}
+}
+
+func TestStartJobnet(t *testing.T) {
+	s, c := newTestServer("")
+	rec := doRequest(s, "POST", "/jobnets", `{"jobnet":"test"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Status code => %d, wants %d", rec.Code, http.StatusAccepted)
+	}
+	if len(c.args) != 1 || strings.Join(c.args[0], " ") != "-n test -s" {
+		t.Errorf("Unexpected master arguments: %v", c.args)
+	}
+
+	result := new(controlResult)
+	if err := json.Unmarshal(rec.Body.Bytes(), result); err != nil {
+		t.Fatalf("Could not decode response: %s", err)
+	}
+	if result.Instance != 100 {
+		t.Errorf("Instance => %d, wants %d", result.Instance, 100)
+	}
+}
+
+func TestStartJobnet_InvalidName(t *testing.T) {
+	s, c := newTestServer("")
+	for _, body := range []string{`{"jobnet":""}`, `{"jobnet":"../test"}`, `{"jobnet":"sub\\test"}`, `{`} {
+		rec := doRequest(s, "POST", "/jobnets", body)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: Status code => %d, wants %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+	if len(c.args) != 0 {
+		t.Errorf("Master must not be launched: %v", c.args)
+	}
+}
+
+func TestStartJobnet_LaunchError(t *testing.T) {
+	s, c := newTestServer("")
+	c.err = errors.New("launch error")
+	rec := doRequest(s, "POST", "/jobnets", `{"jobnet":"test"}`)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Status code => %d, wants %d", rec.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestRerunJobnet(t *testing.T) {
+	s, c := newTestServer("")
+	rec := doRequest(s, "POST", "/jobnets/3/rerun", "")
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Status code => %d, wants %d", rec.Code, http.StatusAccepted)
+	}
+	if len(c.args) != 1 || strings.Join(c.args[0], " ") != "-r 3" {
+		t.Errorf("Unexpected master arguments: %v", c.args)
+	}
+}
+
+func TestRerunJobnet_Conflict(t *testing.T) {
+	s, c := newTestServer("")
+	for _, url := range []string{"/jobnets/1/rerun", "/jobnets/2/rerun"} {
+		rec := doRequest(s, "POST", url, "")
+		if rec.Code != http.StatusConflict {
+			t.Errorf("%s: Status code => %d, wants %d", url, rec.Code, http.StatusConflict)
+		}
+	}
+	if len(c.args) != 0 {
+		t.Errorf("Master must not be launched: %v", c.args)
+	}
+}
+
+func TestCancelJobnet(t *testing.T) {
+	s, c := newTestServer("")
+	rec := doRequest(s, "POST", "/jobnets/2/cancel", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status code => %d, wants %d", rec.Code, http.StatusOK)
+	}
+	if len(c.args) != 1 || strings.Join(c.args[0], " ") != "-k 2" {
+		t.Errorf("Unexpected master arguments: %v", c.args)
+	}
+
+	rec = doRequest(s, "POST", "/jobnets/1/cancel", "")
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Status code => %d, wants %d", rec.Code, http.StatusConflict)
+	}
+}
+
+func TestHandleJobnet_MethodNotAllowed(t *testing.T) {
+	s, _ := newTestServer("")
+	cases := []struct{ method, url string }{
+		{"POST", "/jobnets/1"},
+		{"GET", "/jobnets/1/rerun"},
+		{"DELETE", "/jobnets"},
+		{"GET", "/validate"},
+	}
+	for _, c := range cases {
+		rec := doRequest(s, c.method, c.url, "")
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s: Status code => %d, wants %d", c.method, c.url, rec.Code, http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := `<definitions><process>
+<startEvent id="start"/><endEvent id="end"/><serviceTask id="j1" name="job1"/>
+<sequenceFlow sourceRef="start" targetRef="j1"/><sequenceFlow sourceRef="j1" targetRef="end"/>
+</process></definitions>`
+	invalid := `<definitions><process>
+<startEvent id="start"/><endEvent id="end"/><serviceTask id="j1" name="job1"/>
+<sequenceFlow sourceRef="j1" targetRef="end"/>
+</process></definitions>`
+	varError := `<definitions><process>
+<startEvent id="start"/><endEvent id="end"/><serviceTask id="j1" name="job1"/><serviceTask id="j2" name="job2"/>
+<exclusiveGateway id="x1" default="f3"/><exclusiveGateway id="x2"/>
+<sequenceFlow sourceRef="start" targetRef="x1"/>
+<sequenceFlow id="f2" sourceRef="x1" targetRef="j1"><conditionExpression>$MJjob2:RC$ == 0</conditionExpression></sequenceFlow>
+<sequenceFlow id="f3" sourceRef="x1" targetRef="j2"/>
+<sequenceFlow sourceRef="j1" targetRef="x2"/><sequenceFlow sourceRef="j2" targetRef="x2"/><sequenceFlow sourceRef="x2" targetRef="end"/>
+</process></definitions>`
+
+	s, _ := newTestServer("")
+	cases := []struct {
+		url   string
+		body  string
+		valid bool
+	}{
+		{"/validate", valid, true},
+		{"/validate", invalid, false},
+		{"/validate", varError, false},
+		{"/validate", "not xml", false},
+		{"/validate?jobnet=test", "", true},
+	}
+	for _, c := range cases {
+		rec := doRequest(s, "POST", c.url, c.body)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: Status code => %d, wants %d", c.url, rec.Code, http.StatusOK)
+			continue
+		}
+		result := new(validateResult)
+		if err := json.Unmarshal(rec.Body.Bytes(), result); err != nil {
+			t.Fatalf("Could not decode response: %s", err)
+		}
+		if result.Valid != c.valid {
+			t.Errorf("%s: Valid => %v, wants %v (%s)", c.url, result.Valid, c.valid, result.Message)
+		}
+	}
+
+	rec := doRequest(s, "POST", "/validate?jobnet=notexist", "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Status code => %d, wants %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestServeHTTP_Authorization(t *testing.T) {
+	s, _ := newTestServer("secret")
+
+	rec := doRequest(s, "GET", "/jobnets/1", "")
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Status code => %d, wants %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	req, _ := http.NewRequest("GET", "/jobnets/1", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Status code => %d, wants %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	req, _ = http.NewRequest("GET", "/jobnets/1", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Status code => %d, wants %d", rec.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,4 @@
+package main
+
+// apiのバージョン情報
+const Version = "0.9.7.1"
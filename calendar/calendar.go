@@ -0,0 +1,237 @@
+package calendar
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// カレンダー定義ファイルの拡張子
+const Extension = ".cal"
+
+// ジョブネット専用のカレンダーが無い場合に使用するカレンダー名
+const DefaultName = "default"
+
+// カレンダー定義ファイルの日付の書式
+const DateLayout = "2006-01-02"
+
+// 営業日を探索する日数の上限
+const searchDays = 366
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// カレンダー定義ファイルの内容
+type definition struct {
+	Weekend  []string `toml:"weekend"`
+	Holidays []string `toml:"holidays"`
+	Workdays []string `toml:"workdays"`
+}
+
+// 営業日カレンダーを表す構造体
+type Calendar struct {
+	Name     string                // カレンダー名
+	Path     string                // カレンダー定義ファイルのパス。標準カレンダーの場合は空文字列。
+	weekend  map[time.Weekday]bool // 休業日とする曜日
+	holidays map[string]bool       // 休業日とする日付
+	workdays map[string]bool       // 曜日や休業日に関わらず営業日とする日付
+}
+
+// 月曜日から金曜日を営業日とする、標準カレンダーを生成する。
+//
+// return : カレンダー。
+func Standard() *Calendar {
+	c := newCalendar("")
+	c.weekend[time.Saturday] = true
+	c.weekend[time.Sunday] = true
+	return c
+}
+
+func newCalendar(name string) *Calendar {
+	c := new(Calendar)
+	c.Name = name
+	c.weekend = make(map[time.Weekday]bool)
+	c.holidays = make(map[string]bool)
+	c.workdays = make(map[string]bool)
+	return c
+}
+
+// ディレクトリから、指定した名前のカレンダー定義ファイルを読み込む。
+//
+// param : dir カレンダー定義ファイルのディレクトリ。
+//
+// param : name カレンダー名。
+//
+// return : カレンダー。
+//
+// return : エラー情報。
+func Load(dir, name string) (*Calendar, error) {
+	path := filepath.Join(dir, name+Extension)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	c, err := LoadReader(name, f)
+	if err != nil {
+		return nil, fmt.Errorf("Calendar file[%s] is invalid: %s", path, err)
+	}
+	c.Path = path
+	return c, nil
+}
+
+// ジョブネットで使用するカレンダーを読み込む。
+// <ジョブネット名>.cal、default.calの順に探し、どちらも無い場合は標準カレンダーを使用する。
+//
+// param : dir カレンダー定義ファイルのディレクトリ。
+//
+// param : jobnet ジョブネット名。
+//
+// return : カレンダー。
+//
+// return : エラー情報。
+func LoadFor(dir, jobnet string) (*Calendar, error) {
+	for _, name := range []string{jobnet, DefaultName} {
+		c, err := Load(dir, name)
+		if err == nil {
+			return c, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return Standard(), nil
+}
+
+// カレンダー定義を読み込む。
+//
+// 定義は以下のようなtoml形式で記述する。
+//
+//	weekend = ["sat", "sun"]                   # 休業日とする曜日（省略時は土曜日と日曜日）
+//	holidays = ["2016-01-01", "2016-01-11"]    # 休業日とする日付
+//	workdays = ["2016-01-09"]                  # 曜日や休業日に関わらず営業日とする日付
+//
+// param : name カレンダー名。
+//
+// param : reader カレンダー定義の読み込み元。
+//
+// return : カレンダー。
+//
+// return : エラー情報。
+func LoadReader(name string, reader io.Reader) (*Calendar, error) {
+	def := new(definition)
+	meta, err := toml.DecodeReader(reader, def)
+	if err != nil {
+		return nil, err
+	}
+
+	c := newCalendar(name)
+	if !meta.IsDefined("weekend") {
+		def.Weekend = []string{"sat", "sun"}
+	}
+	for _, w := range def.Weekend {
+		wd, ok := weekdayNames[strings.ToLower(w)]
+		if !ok {
+			return nil, fmt.Errorf("Invalid day of week[%s].", w)
+		}
+		c.weekend[wd] = true
+	}
+	if len(c.weekend) == len(weekdayNames) && len(def.Workdays) == 0 {
+		return nil, fmt.Errorf("Calendar has no business day.")
+	}
+
+	if err := parseDates(def.Holidays, c.holidays); err != nil {
+		return nil, err
+	}
+	if err := parseDates(def.Workdays, c.workdays); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// 日付文字列のリストを解析し、datesへ登録する。
+func parseDates(values []string, dates map[string]bool) error {
+	for _, v := range values {
+		d, err := time.Parse(DateLayout, v)
+		if err != nil {
+			return fmt.Errorf("Invalid date[%s].", v)
+		}
+		dates[d.Format(DateLayout)] = true
+	}
+	return nil
+}
+
+// tの日付が営業日であるかを調べる。
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	date := t.Format(DateLayout)
+	if c.workdays[date] {
+		return true
+	}
+	return !c.weekend[t.Weekday()] && !c.holidays[date]
+}
+
+// tの翌日以降で最初の営業日を返す。
+// 1年以内に営業日が無い場合はゼロ値を返す。
+func (c *Calendar) NextBusinessDay(t time.Time) time.Time {
+	return c.searchBusinessDay(t, 1)
+}
+
+// tの前日以前で最後の営業日を返す。
+// 1年以内に営業日が無い場合はゼロ値を返す。
+func (c *Calendar) PrevBusinessDay(t time.Time) time.Time {
+	return c.searchBusinessDay(t, -1)
+}
+
+// tの日付が営業日であればその日付を、それ以外は前日以前で最後の営業日を返す。
+// 1年以内に営業日が無い場合はゼロ値を返す。
+func (c *Calendar) BusinessDate(t time.Time) time.Time {
+	t = truncateDay(t)
+	if c.IsBusinessDay(t) {
+		return t
+	}
+	return c.PrevBusinessDay(t)
+}
+
+// tからstep日ずつ移動して、最初の営業日を探す。
+func (c *Calendar) searchBusinessDay(t time.Time, step int) time.Time {
+	t = truncateDay(t)
+	for i := 0; i < searchDays; i++ {
+		t = t.AddDate(0, 0, step)
+		if c.IsBusinessDay(t) {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// tの日付が月初の営業日であるかを調べる。
+func (c *Calendar) IsFirstBusinessDay(t time.Time) bool {
+	if !c.IsBusinessDay(t) {
+		return false
+	}
+	prev := c.PrevBusinessDay(t)
+	return prev.IsZero() || prev.Month() != t.Month() || prev.Year() != t.Year()
+}
+
+// tの日付が月末の営業日であるかを調べる。
+func (c *Calendar) IsLastBusinessDay(t time.Time) bool {
+	if !c.IsBusinessDay(t) {
+		return false
+	}
+	next := c.NextBusinessDay(t)
+	return next.IsZero() || next.Month() != t.Month() || next.Year() != t.Year()
+}
+
+// 時刻を切り捨て、日付のみとする。
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
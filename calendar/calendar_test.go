@@ -0,0 +1,151 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 12, 0, 0, 0, time.Local)
+}
+
+func loadTestCalendar(t *testing.T, def string) *Calendar {
+	c, err := LoadReader("test", strings.NewReader(def))
+	if err != nil {
+		t.Fatalf("Unexpected error occured: %s", err)
+	}
+	return c
+}
+
+func TestStandard(t *testing.T) {
+	c := Standard()
+	cases := []struct {
+		date   time.Time
+		expect bool
+	}{
+		{date(2016, 1, 1), true},
+		{date(2016, 1, 2), false},
+		{date(2016, 1, 3), false},
+		{date(2016, 1, 4), true},
+	}
+	for _, cs := range cases {
+		if actual := c.IsBusinessDay(cs.date); actual != cs.expect {
+			t.Errorf("IsBusinessDay(%s) => %v, want %v", cs.date.Format(DateLayout), actual, cs.expect)
+		}
+	}
+}
+
+func TestLoadReader(t *testing.T) {
+	c := loadTestCalendar(t, `
+weekend = ["Sun"]
+holidays = ["2016-01-01"]
+workdays = ["2016-01-03"]
+`)
+	cases := []struct {
+		date   time.Time
+		expect bool
+	}{
+		{date(2015, 12, 31), true},
+		{date(2016, 1, 1), false},
+		{date(2016, 1, 2), true},
+		{date(2016, 1, 3), true},
+		{date(2016, 1, 10), false},
+	}
+	for _, cs := range cases {
+		if actual := c.IsBusinessDay(cs.date); actual != cs.expect {
+			t.Errorf("IsBusinessDay(%s) => %v, want %v", cs.date.Format(DateLayout), actual, cs.expect)
+		}
+	}
+}
+
+func TestLoadReader_DefaultWeekend(t *testing.T) {
+	c := loadTestCalendar(t, `holidays = ["2016-01-04"]`)
+	if c.IsBusinessDay(date(2016, 1, 2)) {
+		t.Error("Saturday must not be a business day.")
+	}
+	if c.IsBusinessDay(date(2016, 1, 4)) {
+		t.Error("Holiday must not be a business day.")
+	}
+	if !c.IsBusinessDay(date(2016, 1, 5)) {
+		t.Error("Tuesday must be a business day.")
+	}
+}
+
+func TestLoadReader_Error(t *testing.T) {
+	defs := []string{
+		`weekend = ["xxx"]`,
+		`holidays = ["2016/01/01"]`,
+		`workdays = ["20160101"]`,
+		`weekend = ["sun", "mon", "tue", "wed", "thu", "fri", "sat"]`,
+		`weekend = "sun"`,
+	}
+	for _, def := range defs {
+		if _, err := LoadReader("test", strings.NewReader(def)); err == nil {
+			t.Errorf("Error was not occured with definition[%s].", def)
+		}
+	}
+}
+
+func TestSearchBusinessDay(t *testing.T) {
+	c := loadTestCalendar(t, `holidays = ["2016-01-01", "2016-01-11"]`)
+
+	if d := c.NextBusinessDay(date(2015, 12, 31)); d.Format(DateLayout) != "2016-01-04" {
+		t.Errorf("NextBusinessDay => %s, want %s", d.Format(DateLayout), "2016-01-04")
+	}
+	if d := c.PrevBusinessDay(date(2016, 1, 12)); d.Format(DateLayout) != "2016-01-08" {
+		t.Errorf("PrevBusinessDay => %s, want %s", d.Format(DateLayout), "2016-01-08")
+	}
+	if d := c.BusinessDate(date(2016, 1, 3)); d.Format(DateLayout) != "2015-12-31" {
+		t.Errorf("BusinessDate => %s, want %s", d.Format(DateLayout), "2015-12-31")
+	}
+	if d := c.BusinessDate(date(2016, 1, 4)); d.Format(DateLayout) != "2016-01-04" {
+		t.Errorf("BusinessDate => %s, want %s", d.Format(DateLayout), "2016-01-04")
+	}
+}
+
+func TestFirstAndLastBusinessDay(t *testing.T) {
+	c := loadTestCalendar(t, `holidays = ["2016-01-01", "2016-01-29"]`)
+
+	if !c.IsFirstBusinessDay(date(2016, 1, 4)) {
+		t.Error("2016-01-04 must be the first business day.")
+	}
+	if c.IsFirstBusinessDay(date(2016, 1, 5)) {
+		t.Error("2016-01-05 must not be the first business day.")
+	}
+	if !c.IsLastBusinessDay(date(2016, 1, 28)) {
+		t.Error("2016-01-28 must be the last business day.")
+	}
+	if c.IsLastBusinessDay(date(2016, 1, 29)) {
+		t.Error("Holiday must not be the last business day.")
+	}
+}
+
+func TestLoadFor(t *testing.T) {
+	c, err := LoadFor("_testdata", "test")
+	if err != nil {
+		t.Fatalf("Unexpected error occured: %s", err)
+	}
+	if c.Name != "test" {
+		t.Errorf("Name => %s, want %s", c.Name, "test")
+	}
+
+	c, err = LoadFor("_testdata", "other")
+	if err != nil {
+		t.Fatalf("Unexpected error occured: %s", err)
+	}
+	if c.Name != DefaultName {
+		t.Errorf("Name => %s, want %s", c.Name, DefaultName)
+	}
+	if c.IsBusinessDay(date(2016, 1, 11)) {
+		t.Error("Holiday in default calendar must not be a business day.")
+	}
+
+	c, err = LoadFor("_testdata/noexists", "test")
+	if err != nil {
+		t.Fatalf("Unexpected error occured: %s", err)
+	}
+	if c.Path != "" || !c.IsBusinessDay(date(2016, 1, 11)) {
+		t.Error("Standard calendar must be used when there is no calendar file.")
+	}
+}
@@ -0,0 +1,2 @@
+// 営業日カレンダーを読み込み、営業日の判定を行うパッケージ。
+package calendar
@@ -12,15 +12,27 @@ import (
 
 // USAGE表示用の定義メッセージ
 const USAGE = `Usage :
-    master.exe [-v] [-n Jobnetwork] [-s] [-c ConfigFile] [-r Instance Id]
+    master.exe [-v] [-n Jobnetwork] [-s | -dry-run | -lint [-format text | json]] [-o] [-c ConfigFile] [-r Instance Id [-from Job | -only Job1,Job2] [-skip Job1,Job2] [-force Job1,Job2] [-reason Text]] [-k Instance Id] [-ping Node:Port,...] [-preflight]
 
 Option :
     -v             :   Print master version.
     -n bpmn name   :   Designate a bpmn file name.(Without extensions.)
     -s             :   Execute Jobnetwork.
+    -dry-run       :   Display requests of each Job without executing Jobnetwork. (with -n)
+    -lint          :   Check Jobnetwork definition and display all issues. (with -n)
+    -format json   :   Display issues of -lint in JSON format.
+    -o             :   Display output of Jobs on console in real time.
     -c ConfigFile  :   Designate config file path.
                        If it is omitted, '<Current Directory>/master.ini' will be used.
     -r Instance Id :   To re-run the abnormally terminated Jobnetwork.
+    -from Job      :   Re-run the designated Job and all following Jobs, even if they ended normally. (with -r)
+    -only Job,...  :   Re-run only the designated Jobs, even if they ended normally. (with -r)
+    -skip Job,...  :   Mark the designated Jobs as SKIPPED and do not execute them. (with -r)
+    -force Job,... :   Mark the designated executed Jobs as FORCED END and do not execute them. (with -r)
+    -reason Text   :   Reason to record with -skip or -force.
+    -k Instance Id :   To cancel the running Jobnetwork.
+    -ping Node,... :   Display status of the designated servants. Node is 'host:port' or 'host'.
+    -preflight     :   Check all servants of Jobnetwork are available before execution. (with -s or -r)
 
 Copyright 2015 unirita Inc.
 `
@@ -29,7 +41,8 @@ var stack_msg = []string{"CTM019E", "CTS019E", "CTU003E"}
 
 // showユーティリティのUSAGE表示用の定義メッセージ
 const USAGE_SHOW = `Usage :
-    show.exe [-v] [-jobnet="bpmn file name"] [-From="From date"] [-to="To date"] [-status="normal" | "abnormal" | "running"] [-format="json" | "csv"] [-nid="Instance Id"]
+    show.exe [-v] [-jobnet="bpmn file name"] [-From="From date"] [-to="To date"] [-status="normal" | "abnormal" | "running" | "cancelled"] [-format="json" | "csv"] [-nid="Instance Id"]
+    show.exe -joblog -nid="Instance Id" -jid="Job Id" [-lines="from-to" | -tail="n"]
 
 Option :
     -v                 :   Print master version.
@@ -39,10 +52,15 @@ Option :
     -status=normal     :   Status indicates only something of NORMAL-END.
     -status=abnormal   :   Status indicates only something of ABNORMAL-END.
     -status=running    :   Status indicates only something of RUNNING.
+    -status=cancelled  :   Status indicates only something of CANCELLED.
     -format=json       :   It outputs by the form of JSON.
     -format=csv        :   It outputs by the form of CSV.
 	-utc               :   Consider timezone as UTC.
 	-nid=InstanceId    :   Designate a instance id.
+    -joblog            :   Print the joblog of the job designated by [-nid] and [-jid].
+    -jid=JobId         :   Designate a job id. (with -joblog)
+    -lines=from-to     :   Print only the designated line range of the joblog. (with -joblog)
+    -tail=n            :   Print only the last n lines of the joblog. (with -joblog)
     
 When omitting [-from] and [-to], only Jobnetwork begun today is indicated.
 	
@@ -80,6 +98,31 @@ var msgs = map[string]string{
 	"CTM027W": "JOB [%s] REQUEST FAILED. RETRYING...(%d of %d)",
 	"CTM028W": "JOB [%s] REQUEST FAILED. TRYING TO REQUEST SECONDARY SERVANT[%s].",
 	"CTM029I": "INSTANCE [%d] ALREADY ENDED WITH NO ERROR.",
+	"CTM030I": "GATEWAY [%s] SELECTED SEQUENCEFLOW [%s]. NEXT ELEMENT [%s].",
+	"CTM031I": "JOB [%s] SKIPPED. [%s] IS NOT A RUN DAY [%s].",
+	"CTM032E": "FAILED TO READ CALENDAR FILE. REASON [%s]",
+	"CTM033I": "[%s] CANCEL REQUESTED. INSTANCE [%d]",
+	"CTM034I": "SENT CANCEL SIGNAL TO MASTER. INSTANCE [%d] PID [%d]",
+	"CTM035W": "FAILED TO CANCEL JOB [%s] AT NODE [%s]. INSTANCE [%d] JOBID [%s] REASON [%s]",
+	"CTM036I": "INSTANCE [%d] IS NOT RUNNING.",
+	"CTM037W": "MASTER OF INSTANCE [%d] HAS GONE OR IS NOT RESPONDING. FORCE TO CANCEL.",
+	"CTM038I": "INSTANCE [%d] CANCELLED.",
+	"CTM039W": "JOB [%s] WILL BE RETRIED. INSTANCE [%d] JOBID [%s] RETRY [%d/%d] DELAY [%.1f SEC].",
+	"CTM040I": "JOB [%s] WAITED IN QUEUE OF SERVANT [%s]. INSTANCE [%d] JOBID [%s] POSITION [%d] WAIT [%d SEC].",
+	"CTM041I": "[%s:%s] %s",
+	"CTM042W": "FAILED TO SEND NOTIFICATION BY [%s]. TARGET [%s] INSTANCE [%d] REASON [%s]",
+	"CTM043I": "JOB [%s] IS NOT A TARGET OF RERUN. INSTANCE [%d] JOBID [%s]",
+	"CTM044I": "JOB [%s] MARKED AS [%s] BY [%s]. INSTANCE [%d] JOBID [%s]",
+	"CTM045I": "JOB [%s] IS STILL RUNNING ON SERVANT [%s]. ATTACHED TO IT. INSTANCE [%d] JOBID [%s]",
+	"CTM046W": "CONNECTION TO SERVANT [%s] WAS LOST. RECONNECTING. REASON [%v]",
+	"CTM047W": "SERVANT [%s] DOES NOT SUPPORT MULTIPLEXED CONNECTION. CONNECT PER REQUEST.",
+	"CTM048I": "RECONNECTED TO SERVANT [%s]. RESUMED [%d] REQUESTS.",
+	"CTM049I": "CONNECTED TO SERVANT [%s] VERSION [%s] BY PROTOCOL VERSION [%d].",
+	"CTM050W": "SERVANT [%s] DOES NOT SUPPORT FRAMED PROTOCOL. USE LEGACY PROTOCOL.",
+	"CTM051I": "SERVANT [%s] VERSION [%s] IS UP FOR [%d SEC]. MULTI_PROC [%d] RUNNING [%d] QUEUED [%d] LOAD [%s]",
+	"CTM052I": "JOB [%s] IS RUNNING ON SERVANT [%s]. INSTANCE [%d] JOBID [%s] PID [%d] START [%s]",
+	"CTM053E": "SERVANT [%s] IS NOT AVAILABLE. REASON [%v]",
+	"CTM054W": "SECONDARY SERVANT [%s] IS NOT AVAILABLE. REASON [%v]",
 	"1":       "",
 	"CTS001I": "GOCUTO SERVANT STARTED. PID [%v] VERSION [%s]",
 	"CTS002I": "GOCUTO SERVANT ENDED. RC [%d].",
@@ -104,6 +147,24 @@ var msgs = map[string]string{
 	"CTS021I": "SERVANT CHILD ENDED. RC[%d]",
 	"CTS022E": "UNABLE TO OUTPUT JOBLOG. MSG[%s]",
 	"CTS023E": "COULD NOT INITIALIZE LOGGER. REASON[%s]",
+	"CTS024I": "JOB [%s] CANCELLED. INSTANCE [%d] ID [%s] PID [%d].",
+	"CTS025W": "CANCEL REQUESTED, BUT JOB IS NOT RUNNING. INSTANCE [%d] ID [%s].",
+	"CTS026E": "REJECTED UNAUTHENTICATED MESSAGE FROM [%v]. REASON [%s]",
+	"CTS027I": "TLS ENABLED.",
+	"CTS028E": "JOB [%s] IS NOT ALLOWED TO EXECUTE. INSTANCE [%d] ID [%s] REASON [%s]",
+	"CTS029I": "JOB [%s] IS QUEUED BECAUSE MULTI_PROC IS FULL. INSTANCE [%d] ID [%s] POSITION [%d].",
+	"CTS030I": "JOB [%s] IS DEQUEUED. INSTANCE [%d] ID [%s] WAIT [%d SEC].",
+	"CTS031I": "QUEUED JOB [%s] CANCELLED. INSTANCE [%d] ID [%s].",
+	"CTS032I": "JOBLOG REQUESTED. INSTANCE [%d] ID [%s].",
+	"CTS033W": "COULD NOT WRITE JOB JOURNAL. INSTANCE [%d] ID [%s] REASON [%s]",
+	"CTS034W": "COULD NOT COMPACT JOB JOURNAL. REASON [%s]",
+	"CTS035I": "MASTER ATTACHED TO JOB [%s]. INSTANCE [%d] ID [%s].",
+	"CTS036W": "ATTACH REQUESTED, BUT JOB IS NOT RUNNING. INSTANCE [%d] ID [%s].",
+	"CTS037I": "MULTIPLEXED CONNECTION FROM [%v] STARTED.",
+	"CTS038I": "MULTIPLEXED CONNECTION FROM [%v] ENDED.",
+	"CTS039W": "REJECTED MESSAGE FROM [%v]. REASON [%v]",
+	"CTS040I": "STATUS REQUESTED FROM [%v].",
+	"CTS041I": "RESENT REQUEST WAS ALREADY EXECUTED. INSTANCE [%d] ID [%s].",
 	"2":       "",
 	"CTU001I": "SHOW UTILITY STARTED. VERSION [%v]",
 	"CTU002I": "SHOW UTILITY ENDED. RC [%d].",
@@ -111,6 +172,42 @@ var msgs = map[string]string{
 	"CTU004E": "AN INTERNAL ERROR OCCURRED. - %v",
 	"CTU005W": "FAILED TO JOB INFORMATION NID[%v]. - %v",
 	"CTU006E": "NOT FOUND CONFIG FILE. - %v",
+	"CTU007E": "FAILED TO FETCH JOBLOG. INSTANCE [%d] ID [%s] - %v",
+	"CTU008W": "JOBLOG [%s] IS TOO LARGE. ONLY THE LAST PART IS DISPLAYED.",
+	"3":       "",
+	"CTC001I": "GOCUTO SCHEDULER STARTED. PID [%v] VERSION [%s]",
+	"CTC002I": "GOCUTO SCHEDULER ENDED. RC [%d].",
+	"CTC003E": "INVALID ARGUMENT.",
+	"CTC004E": "FAILED TO READ CONFIG FILE [%s]. REASON [%s]",
+	"CTC005E": "CONFIG PARM IS NOT EXACT FORMAT. REASON [%s]",
+	"CTC006E": "COULD NOT INITIALIZE LOGGER. REASON[%s]",
+	"CTC007E": "FAILED TO READ SCHEDULE FILE [%s]. REASON [%s]",
+	"CTC008E": "JOBNET [%s] OF SCHEDULE [%s] IS INVALID.",
+	"CTC009I": "SCHEDULE [%s] WILL TRIGGER JOBNET [%s] AT [%s].",
+	"CTC010W": "SCHEDULE [%s] WILL NOT TRIGGER JOBNET [%s] ANYMORE.",
+	"CTC011I": "SCHEDULE [%s] LAUNCHED JOBNET [%s]. INSTANCE [%d].",
+	"CTC012W": "SCHEDULE [%s] SKIPPED JOBNET [%s] BECAUSE PREVIOUS RUN IS NOT ENDED.",
+	"CTC013I": "SCHEDULE [%s] QUEUED JOBNET [%s] UNTIL PREVIOUS RUN ENDS.",
+	"CTC014E": "SCHEDULE [%s] FAILED TO LAUNCH JOBNET [%s]. REASON [%s]",
+	"CTC015E": "FAILED TO RECORD TRIGGER OF SCHEDULE [%s]. REASON [%s]",
+	"4":       "",
+	"CTA001I": "GOCUTO API SERVER STARTED. PID [%v] VERSION [%s]",
+	"CTA002I": "GOCUTO API SERVER ENDED. RC [%d].",
+	"CTA003E": "INVALID ARGUMENT.",
+	"CTA004E": "FAILED TO READ CONFIG FILE [%s]. REASON [%s]",
+	"CTA005E": "CONFIG PARM IS NOT EXACT FORMAT. REASON [%s]",
+	"CTA006E": "COULD NOT INITIALIZE LOGGER. REASON[%s]",
+	"CTA007I": "API SERVER LISTENING ON [%s].",
+	"CTA008E": "API SERVER ERROR. REASON [%s]",
+	"5":       "",
+	"CTD001I": "GOCUTO DASHBOARD STARTED. PID [%v] VERSION [%s]",
+	"CTD002I": "GOCUTO DASHBOARD ENDED. RC [%d].",
+	"CTD003E": "INVALID ARGUMENT.",
+	"CTD004E": "FAILED TO READ CONFIG FILE [%s]. REASON [%s]",
+	"CTD005E": "CONFIG PARM IS NOT EXACT FORMAT. REASON [%s]",
+	"CTD006E": "COULD NOT INITIALIZE LOGGER. REASON[%s]",
+	"CTD007I": "DASHBOARD LISTENING ON [%s].",
+	"CTD008E": "DASHBOARD SERVER ERROR. REASON [%s]",
 }
 
 // 標準出力へメッセージコードcodeに対応したメッセージを表示する。
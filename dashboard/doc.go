@@ -0,0 +1,2 @@
+// ジョブネットの実行状況をWebページで提供する常駐コマンド
+package main
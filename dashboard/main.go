@@ -0,0 +1,137 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"github.com/unirita/cuto/console"
+	"github.com/unirita/cuto/dashboard/web"
+	"github.com/unirita/cuto/log"
+	"github.com/unirita/cuto/master/config"
+	"github.com/unirita/cuto/util"
+)
+
+// 実行時引数のオプション
+type arguments struct {
+	versionFlag bool   // バージョン情報表示フラグ
+	configPath  string // 設定ファイルのパス
+}
+
+// dashboardの戻り値
+const (
+	rc_OK    = 0
+	rc_ERROR = 1
+)
+
+const usage = `Usage :
+    dashboard [-v] [-c ConfigFile]
+
+Option :
+    -v            : Print dashboard version.
+    -c ConfigFile : Designate master config file path.
+                    If it is omitted, '<CUTOROOT>/bin/master.ini' will be used.
+
+Copyright 2015 unirita Inc.
+`
+
+func main() {
+	args := fetchArgs()
+	if args == nil {
+		showUsage()
+		os.Exit(rc_ERROR)
+	}
+	os.Exit(realMain(args))
+}
+
+func realMain(args *arguments) int {
+	if args.versionFlag {
+		fmt.Println(Version)
+		return rc_OK
+	}
+
+	if args.configPath == "" {
+		args.configPath = filepath.Join(util.GetRootPath(), "bin", "master.ini")
+	}
+	if err := config.Load(args.configPath); err != nil {
+		console.Display("CTD004E", args.configPath, err)
+		return rc_ERROR
+	}
+	if err := config.DetectError(); err != nil {
+		console.Display("CTD005E", err)
+		return rc_ERROR
+	}
+	if err := config.DetectDashboardError(); err != nil {
+		console.Display("CTD005E", err)
+		return rc_ERROR
+	}
+
+	if err := log.Init(config.Dir.LogDir,
+		"dashboard",
+		"",
+		config.Log.OutputLevel,
+		config.Log.MaxSizeKB,
+		config.Log.MaxGeneration,
+		config.Log.TimeoutSec); err != nil {
+		console.Display("CTD006E", err)
+		return rc_ERROR
+	}
+	defer log.Term()
+
+	rc := rc_OK
+	console.Display("CTD001I", os.Getpid(), Version)
+	defer func() {
+		console.Display("CTD002I", rc)
+	}()
+
+	addr := net.JoinHostPort(config.Dashboard.ListenHost, strconv.Itoa(config.Dashboard.ListenPort))
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		console.Display("CTD008E", err)
+		rc = rc_ERROR
+		return rc
+	}
+
+	stop := make(chan struct{})
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		close(stop)
+		listener.Close()
+	}()
+
+	console.Display("CTD007I", addr)
+	if err := http.Serve(listener, web.New()); err != nil {
+		select {
+		case <-stop:
+		default:
+			console.Display("CTD008E", err)
+			rc = rc_ERROR
+		}
+	}
+	return rc
+}
+
+func fetchArgs() *arguments {
+	args := new(arguments)
+	flag.Usage = showUsage
+	flag.BoolVar(&args.versionFlag, "v", false, "version option")
+	flag.StringVar(&args.configPath, "c", "", "config file option")
+	flag.Parse()
+	if flag.NArg() != 0 {
+		return nil
+	}
+	return args
+}
+
+func showUsage() {
+	console.Display("CTD003E")
+	fmt.Fprint(os.Stderr, usage)
+}
@@ -0,0 +1,2 @@
+// dashboardコマンドが提供する、Webページのパッケージ。
+package web
@@ -0,0 +1,203 @@
+package web
+
+import (
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/master/jobnet/parser"
+)
+
+// グラフのノードの種類
+const (
+	kindStart     = "start"
+	kindEnd       = "end"
+	kindTask      = "task"
+	kindParallel  = "parallel"
+	kindExclusive = "exclusive"
+)
+
+// グラフのレイアウトの大きさ（ピクセル）
+const (
+	graphMargin = 20
+	colWidth    = 170
+	rowHeight   = 70
+	taskWidth   = 130
+	taskHeight  = 40
+)
+
+// SVGで描画するためにレイアウトしたジョブネットのフロー
+type graph struct {
+	Width  int
+	Height int
+	Nodes  []*graphNode
+	Edges  []*graphEdge
+}
+
+// ジョブネットのフローの要素を表すノード
+// X、Yはノードの中心の座標とする。
+type graphNode struct {
+	ID     string
+	Name   string
+	Kind   string
+	Class  string
+	Title  string
+	X      int
+	Y      int
+	col    int
+	row    int
+	outs   []string
+	degree int
+}
+
+// ノード間のシーケンスフローを表す辺
+type graphEdge struct {
+	X1 int
+	Y1 int
+	X2 int
+	Y2 int
+}
+
+// フローの順に、プロセスの要素を左から右へレイアウトする。
+// 各列には、開始イベントからの最長距離が同じノードを配置する。
+// タスクは、同じIDのジョブ実行結果のステータスで色分けする。
+//
+// param : proc ジョブネットのプロセス定義。
+//
+// param : jobs ジョブIDをキーとしたジョブ実行結果。
+//
+// return : レイアウトしたグラフ。
+func buildGraph(proc *parser.Process, jobs map[string]*db.JobResult) *graph {
+	nodes := make(map[string]*graphNode)
+	order := make([]*graphNode, 0)
+	add := func(id, name, kind string) {
+		if _, exists := nodes[id]; exists {
+			return
+		}
+		n := &graphNode{ID: id, Name: name, Kind: kind, Class: kind}
+		nodes[id] = n
+		order = append(order, n)
+	}
+	for _, e := range proc.Start {
+		add(e.ID, "", kindStart)
+	}
+	for _, t := range proc.Task {
+		add(t.ID, t.Name, kindTask)
+	}
+	for _, g := range proc.Gateway {
+		add(g.ID, "", kindParallel)
+	}
+	for _, g := range proc.Exclusive {
+		add(g.ID, "", kindExclusive)
+	}
+	for _, e := range proc.End {
+		add(e.ID, "", kindEnd)
+	}
+
+	flows := make([]parser.SequenceFlow, 0, len(proc.Flow))
+	for _, f := range proc.Flow {
+		from, ok1 := nodes[f.From]
+		to, ok2 := nodes[f.To]
+		if !ok1 || !ok2 {
+			continue
+		}
+		from.outs = append(from.outs, f.To)
+		to.degree++
+		flows = append(flows, f)
+	}
+
+	layoutColumns(order, nodes)
+
+	g := new(graph)
+	rows := make(map[int]int)
+	maxCol, maxRow := 0, 0
+	for _, n := range order {
+		n.row = rows[n.col]
+		rows[n.col]++
+		if n.col > maxCol {
+			maxCol = n.col
+		}
+		if n.row > maxRow {
+			maxRow = n.row
+		}
+		n.X = graphMargin + n.col*colWidth + taskWidth/2
+		n.Y = graphMargin + n.row*rowHeight + taskHeight/2
+		if n.Kind == kindTask {
+			n.Class, n.Title = taskStatus(jobs[n.ID])
+		}
+		g.Nodes = append(g.Nodes, n)
+	}
+	for _, f := range flows {
+		from, to := nodes[f.From], nodes[f.To]
+		g.Edges = append(g.Edges, &graphEdge{
+			X1: from.X + nodeHalfWidth(from),
+			Y1: from.Y,
+			X2: to.X - nodeHalfWidth(to),
+			Y2: to.Y,
+		})
+	}
+	g.Width = 2*graphMargin + maxCol*colWidth + taskWidth
+	g.Height = 2*graphMargin + maxRow*rowHeight + taskHeight
+	return g
+}
+
+// トポロジカルソートにより、開始イベントからの最長距離を各ノードの列に設定する。
+// 循環しているノードや開始イベントから到達できないノードは、最後の列の次に配置する。
+func layoutColumns(order []*graphNode, nodes map[string]*graphNode) {
+	degree := make(map[string]int)
+	queue := make([]*graphNode, 0)
+	for _, n := range order {
+		degree[n.ID] = n.degree
+		if n.degree == 0 && n.Kind == kindStart {
+			queue = append(queue, n)
+		}
+	}
+
+	visited := make(map[string]bool)
+	maxCol := 0
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		visited[n.ID] = true
+		if n.col > maxCol {
+			maxCol = n.col
+		}
+		for _, id := range n.outs {
+			next := nodes[id]
+			if next.col < n.col+1 {
+				next.col = n.col + 1
+			}
+			degree[id]--
+			if degree[id] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	for _, n := range order {
+		if !visited[n.ID] {
+			n.col = maxCol + 1
+		}
+	}
+}
+
+func nodeHalfWidth(n *graphNode) int {
+	switch n.Kind {
+	case kindTask:
+		return taskWidth / 2
+	case kindParallel, kindExclusive:
+		return 20
+	}
+	return 15
+}
+
+// ジョブ実行結果から、タスクのCSSクラスとツールチップの文字列を返す。
+//
+// param : res ジョブ実行結果。実行されていない場合はnil。
+//
+// return : CSSクラス。
+//
+// return : ツールチップの文字列。
+func taskStatus(res *db.JobResult) (string, string) {
+	if res == nil {
+		return "notexecuted", "NOT EXECUTED"
+	}
+	return statusClass(res.Status), statusText(res.Status)
+}
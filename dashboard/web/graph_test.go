@@ -0,0 +1,106 @@
+package web
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/master/jobnet/parser"
+)
+
+const testFlow = `<definitions><process>
+<startEvent id="start"/><endEvent id="end"/>
+<serviceTask id="j1" name="job1"/><serviceTask id="j2" name="job2"/>
+<serviceTask id="j3" name="job3"/><serviceTask id="j4" name="job4"/>
+<parallelGateway id="gw1"/><parallelGateway id="gw2"/>
+<sequenceFlow sourceRef="start" targetRef="j1"/>
+<sequenceFlow sourceRef="j1" targetRef="gw1"/>
+<sequenceFlow sourceRef="gw1" targetRef="j2"/>
+<sequenceFlow sourceRef="gw1" targetRef="j3"/>
+<sequenceFlow sourceRef="j3" targetRef="j4"/>
+<sequenceFlow sourceRef="j2" targetRef="gw2"/>
+<sequenceFlow sourceRef="j4" targetRef="gw2"/>
+<sequenceFlow sourceRef="gw2" targetRef="end"/>
+</process></definitions>`
+
+func findNode(g *graph, id string) *graphNode {
+	for _, n := range g.Nodes {
+		if n.ID == id {
+			return n
+		}
+	}
+	return nil
+}
+
+func TestBuildGraph(t *testing.T) {
+	proc, err := parser.ParseNetwork(strings.NewReader(testFlow))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	jobs := map[string]*db.JobResult{
+		"j1": {JobId: "j1", Status: db.NORMAL},
+		"j2": {JobId: "j2", Status: db.ABNORMAL},
+		"j3": {JobId: "j3", Status: db.RUNNING},
+	}
+	g := buildGraph(proc, jobs)
+
+	if len(g.Nodes) != 8 {
+		t.Fatalf("Number of nodes => %d, wants %d", len(g.Nodes), 8)
+	}
+	if len(g.Edges) != 8 {
+		t.Errorf("Number of edges => %d, wants %d", len(g.Edges), 8)
+	}
+
+	cols := map[string]int{"start": 0, "j1": 1, "gw1": 2, "j2": 3, "j3": 3, "j4": 4, "gw2": 5, "end": 6}
+	for id, col := range cols {
+		n := findNode(g, id)
+		if n == nil {
+			t.Errorf("Node %s is not found.", id)
+			continue
+		}
+		if n.col != col {
+			t.Errorf("Column of %s => %d, wants %d", id, n.col, col)
+		}
+	}
+	if findNode(g, "j2").row == findNode(g, "j3").row {
+		t.Error("Nodes in the same column must be in different rows.")
+	}
+
+	classes := map[string]string{"j1": "normal", "j2": "abnormal", "j3": "running", "j4": "notexecuted", "gw1": "parallel"}
+	for id, class := range classes {
+		if n := findNode(g, id); n.Class != class {
+			t.Errorf("Class of %s => %s, wants %s", id, n.Class, class)
+		}
+	}
+
+	if g.Width != 2*graphMargin+6*colWidth+taskWidth {
+		t.Errorf("Width => %d", g.Width)
+	}
+	if g.Height != 2*graphMargin+rowHeight+taskHeight {
+		t.Errorf("Height => %d", g.Height)
+	}
+}
+
+func TestBuildGraph_Cycle(t *testing.T) {
+	flow := `<definitions><process>
+<startEvent id="start"/><endEvent id="end"/>
+<serviceTask id="j1" name="job1"/><serviceTask id="j2" name="job2"/>
+<sequenceFlow sourceRef="start" targetRef="j1"/>
+<sequenceFlow sourceRef="j1" targetRef="j2"/>
+<sequenceFlow sourceRef="j2" targetRef="j1"/>
+<sequenceFlow sourceRef="j2" targetRef="end"/>
+</process></definitions>`
+	proc, err := parser.ParseNetwork(strings.NewReader(flow))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	g := buildGraph(proc, nil)
+	if len(g.Nodes) != 4 {
+		t.Fatalf("Number of nodes => %d, wants %d", len(g.Nodes), 4)
+	}
+	for _, id := range []string{"j1", "j2", "end"} {
+		if n := findNode(g, id); n.col != 1 {
+			t.Errorf("Column of %s => %d, wants %d", id, n.col, 1)
+		}
+	}
+}
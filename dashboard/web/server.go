@@ -0,0 +1,295 @@
+package web
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/db/query"
+	"github.com/unirita/cuto/log"
+	"github.com/unirita/cuto/master/config"
+	"github.com/unirita/cuto/master/jobnet/parser"
+	"github.com/unirita/cuto/master/remote"
+	"github.com/unirita/cuto/message"
+	"github.com/unirita/cuto/show/gen"
+)
+
+// 一覧ページに表示するインスタンスの最大数
+const maxInstances = 100
+
+// servantへメッセージを送信する関数の型
+type sendFunc func(string, int, string, chan<- string, chan<- string) (string, error)
+
+// ダッシュボードのページを提供するHTTPハンドラ
+//
+// ページ：
+//
+//	/                               インスタンスの一覧。jobnetパラメータで絞り込む。
+//	/instances/{nid}                インスタンスのフローとジョブ。
+//	/instances/{nid}/joblog/{jid}   servantから取得したジョブのジョブログ。
+type Server struct {
+	dbFile       string
+	jobnetDir    string
+	refreshSec   int
+	authUser     string
+	authPassword string
+	send         sendFunc
+	mux          *http.ServeMux
+}
+
+// config.Loadで読み込んだmaster.iniの設定から、Serverオブジェクトを生成する。
+//
+// return : Serverオブジェクト。
+func New() *Server {
+	s := newServer(config.DB.DBFile, config.Dir.JobnetDir, config.Dashboard.RefreshSec)
+	s.authUser = config.Dashboard.AuthUser
+	s.authPassword = config.Dashboard.AuthPassword
+	s.send = remote.SendRequest
+	return s
+}
+
+func newServer(dbFile, jobnetDir string, refreshSec int) *Server {
+	s := new(Server)
+	s.dbFile = dbFile
+	s.jobnetDir = jobnetDir
+	s.refreshSec = refreshSec
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("/", s.handleIndex)
+	s.mux.HandleFunc("/instances/", s.handleInstance)
+	return s
+}
+
+// リクエストの認証を確認し、ページのハンドラで処理する。
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Info(fmt.Sprintf("%s %s %s", r.RemoteAddr, r.Method, r.URL.String()))
+	if !s.isAuthorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="GoCuto Dashboard"`)
+		http.Error(w, "Unauthorized.", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != "GET" && r.Method != "HEAD" {
+		http.Error(w, "Method not allowed.", http.StatusMethodNotAllowed)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// auth_userが設定されている場合に、リクエストのBasic認証を確認する。
+func (s *Server) isAuthorized(r *http.Request) bool {
+	if s.authUser == "" {
+		return true
+	}
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	userOK := subtle.ConstantTimeCompare([]byte(user), []byte(s.authUser)) == 1
+	passOK := subtle.ConstantTimeCompare([]byte(password), []byte(s.authPassword)) == 1
+	return userOK && passOK
+}
+
+type indexPage struct {
+	Title   string
+	Refresh int
+	Jobnet  string
+	Jobnets []*gen.OutputJobNet
+	Limited bool
+}
+
+type instancePage struct {
+	Title      string
+	Refresh    int
+	Jobnet     *gen.OutputJobNet
+	Graph      *graph
+	GraphError string
+}
+
+type joblogPage struct {
+	Title     string
+	Refresh   int
+	NID       int
+	File      string
+	Content   string
+	Truncated bool
+	Error     string
+}
+
+// 最新のインスタンスの一覧を表示する。
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	conn, err := db.Open(s.dbFile)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	defer conn.Close()
+
+	page := &indexPage{Title: "Instances", Jobnet: r.URL.Query().Get("jobnet")}
+	q := query.CreateJobnetworkQuery(conn)
+	if page.Jobnet != "" {
+		q.AddAndWhereJobnetwork(page.Jobnet)
+	}
+	q.AddOrderBy(query.ORDERBY_DESC)
+	results, err := q.GetJobnetworkList()
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	if len(results) > maxInstances {
+		results = results[:maxInstances]
+		page.Limited = true
+	}
+	for _, result := range results {
+		page.Jobnets = append(page.Jobnets, gen.NewOutputJobNet(result, nil, nil, false))
+	}
+	s.render(w, "index", page)
+}
+
+// インスタンスのページ、またはジョブログのページを表示する。
+func (s *Server) handleInstance(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/instances/"), "/")
+	nid, err := strconv.Atoi(parts[0])
+	if err != nil || nid <= 0 {
+		http.NotFound(w, r)
+		return
+	}
+	switch {
+	case len(parts) == 1:
+		s.renderInstance(w, r, nid)
+	case len(parts) == 3 && parts[1] == "joblog" && parts[2] != "":
+		s.renderJoblog(w, r, nid, parts[2])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) renderInstance(w http.ResponseWriter, r *http.Request, nid int) {
+	conn, err := db.Open(s.dbFile)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	defer conn.Close()
+
+	jobnet, err := query.GetJobnetwork(conn, nid)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	jobs, err := query.GetJobsOfTargetNetwork(conn, nid, query.ORDERBY_ASC)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	attempts, err := query.GetJobAttemptsOfTargetNetwork(conn, nid)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	page := &instancePage{Title: fmt.Sprintf("Instance %d", nid)}
+	page.Jobnet = gen.NewOutputJobNet(jobnet, jobs, attempts, false)
+	if jobnet.Status == db.RUNNING {
+		page.Refresh = s.refreshSec
+	}
+
+	// 実行時点のBPMNは保存されていないため、現在の定義からフローを描画する
+	proc, err := parser.ParseNetworkFile(filepath.Join(s.jobnetDir, jobnet.JobnetWork+".bpmn"))
+	if os.IsNotExist(err) {
+		page.GraphError = "BPMN file of the jobnet is not found."
+	} else if err != nil {
+		page.GraphError = fmt.Sprintf("Could not parse BPMN file: %s", err)
+	} else {
+		jobMap := make(map[string]*db.JobResult)
+		for _, job := range jobs {
+			jobMap[job.JobId] = job
+		}
+		page.Graph = buildGraph(proc, jobMap)
+	}
+	s.render(w, "instance", page)
+}
+
+func (s *Server) renderJoblog(w http.ResponseWriter, r *http.Request, nid int, jid string) {
+	conn, err := db.Open(s.dbFile)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	defer conn.Close()
+
+	jobs, err := query.GetJobMapOfTargetNetwork(conn, nid)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	job, ok := jobs[jid]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	page := &joblogPage{Title: fmt.Sprintf("Joblog of %s (Instance %d)", jid, nid), NID: nid}
+	result, err := s.fetchJoblog(job)
+	if err != nil {
+		page.Error = err.Error()
+	} else {
+		page.File = result.File
+		page.Content = result.Content
+		page.Truncated = result.Truncated
+	}
+	s.render(w, "joblog", page)
+}
+
+// ジョブを実行したservantへ、ジョブログを要求する。
+//
+// param : job ジョブ実行結果。
+//
+// return : ジョブログの取得結果。
+//
+// return : エラー情報。
+func (s *Server) fetchJoblog(job *db.JobResult) (*message.JoblogResult, error) {
+	jl := &message.Joblog{NID: job.ID, JID: job.JobId}
+	jlMsg, err := jl.GenerateJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	stCh := make(chan string, 1)
+	defer close(stCh)
+
+	host := strings.SplitN(job.Node, ">", 2)[0]
+	resultMsg, err := s.send(host, job.Port, jlMsg, stCh, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(message.JoblogResult)
+	if err := result.ParseJSON(resultMsg); err != nil {
+		return nil, err
+	}
+	if result.Detail != "" {
+		return nil, fmt.Errorf("%s", result.Detail)
+	}
+	return result, nil
+}
+
+func (s *Server) render(w http.ResponseWriter, name string, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := pages.ExecuteTemplate(w, name, data); err != nil {
+		log.Error(err)
+	}
+}
+
+func (s *Server) serverError(w http.ResponseWriter, err error) {
+	log.Error(err)
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
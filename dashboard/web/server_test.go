@@ -0,0 +1,153 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unirita/cuto/testutil"
+)
+
+var testDataDir = filepath.Join(testutil.GetBaseDir(), "dashboard", "web", "_testdata")
+
+func newTestServer() *Server {
+	return newServer(filepath.Join(testDataDir, "dashboard_test.sqlite"), filepath.Join(testDataDir, "jobnet"), 5)
+}
+
+func doGet(s *Server, url string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", url, nil)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandleIndex(t *testing.T) {
+	s := newTestServer()
+	rec := doGet(s, "/")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status code => %d, wants %d", rec.Code, http.StatusOK)
+	}
+	body := rec.Body.String()
+	for _, nid := range []string{"1", "4", "8"} {
+		if !strings.Contains(body, `href="/instances/`+nid+`"`) {
+			t.Errorf("Link to instance %s is not found.", nid)
+		}
+	}
+}
+
+func TestHandleIndex_Jobnet(t *testing.T) {
+	s := newTestServer()
+	rec := doGet(s, "/?jobnet=testnet1")
+	body := rec.Body.String()
+	if !strings.Contains(body, `href="/instances/1"`) || !strings.Contains(body, `href="/instances/5"`) {
+		t.Error("Instances of testnet1 are not displayed.")
+	}
+	if strings.Contains(body, `href="/instances/2"`) {
+		t.Error("Instance of other jobnet is displayed.")
+	}
+}
+
+func TestRenderInstance(t *testing.T) {
+	s := newTestServer()
+	rec := doGet(s, "/instances/1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status code => %d, wants %d", rec.Code, http.StatusOK)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "<svg") {
+		t.Error("Flow graph is not rendered.")
+	}
+	if !strings.Contains(body, `class="node normal"`) || !strings.Contains(body, `class="node notexecuted"`) {
+		t.Error("Tasks are not colored by job status.")
+	}
+	if !strings.Contains(body, `href="/instances/1/joblog/JOB01"`) {
+		t.Error("Link to joblog is not found.")
+	}
+	if strings.Contains(body, `http-equiv="refresh"`) {
+		t.Error("Ended instance must not be refreshed.")
+	}
+}
+
+func TestRenderInstance_Running(t *testing.T) {
+	s := newTestServer()
+	rec := doGet(s, "/instances/2")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status code => %d, wants %d", rec.Code, http.StatusOK)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `<meta http-equiv="refresh" content="5">`) {
+		t.Error("Running instance must be refreshed.")
+	}
+	if !strings.Contains(body, "BPMN file of the jobnet is not found.") {
+		t.Error("Message for missing BPMN is not displayed.")
+	}
+}
+
+func TestHandleInstance_NotFound(t *testing.T) {
+	s := newTestServer()
+	for _, url := range []string{"/instances/999", "/instances/abc", "/instances/1/unknown", "/instances/1/joblog/NOJOB", "/unknown"} {
+		rec := doGet(s, url)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: Status code => %d, wants %d", url, rec.Code, http.StatusNotFound)
+		}
+	}
+}
+
+func TestRenderJoblog(t *testing.T) {
+	s := newTestServer()
+	var sentHost string
+	var sentPort int
+	s.send = func(host string, port int, msg string, stCh chan<- string, outCh chan<- string) (string, error) {
+		sentHost, sentPort = host, port
+		return `{"type":"joblogresult","version":"1.2.3","nid":1,"jid":"JOB03","file":"1.job3.JOB03.log","content":"<output>\n","truncated":false,"detail":""}`, nil
+	}
+
+	rec := doGet(s, "/instances/1/joblog/JOB03")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status code => %d, wants %d", rec.Code, http.StatusOK)
+	}
+	if sentHost != "dcserver" || sentPort != 2015 {
+		t.Errorf("Request was sent to %s:%d", sentHost, sentPort)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "&lt;output&gt;") {
+		t.Errorf("Joblog content is not escaped: %s", body)
+	}
+	if !strings.Contains(body, "1.job3.JOB03.log") {
+		t.Error("Joblog file name is not displayed.")
+	}
+}
+
+func TestRenderJoblog_Error(t *testing.T) {
+	s := newTestServer()
+	s.send = func(host string, port int, msg string, stCh chan<- string, outCh chan<- string) (string, error) {
+		return "", errors.New("connection refused")
+	}
+
+	rec := doGet(s, "/instances/1/joblog/JOB01")
+	if !strings.Contains(rec.Body.String(), "connection refused") {
+		t.Error("Error message is not displayed.")
+	}
+}
+
+func TestServeHTTP_BasicAuth(t *testing.T) {
+	s := newTestServer()
+	s.authUser = "admin"
+	s.authPassword = "pass"
+
+	rec := doGet(s, "/")
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Status code => %d, wants %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.SetBasicAuth("admin", "pass")
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Status code => %d, wants %d", rec.Code, http.StatusOK)
+	}
+}
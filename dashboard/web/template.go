@@ -0,0 +1,177 @@
+package web
+
+import (
+	"html/template"
+
+	"github.com/unirita/cuto/db"
+)
+
+// 各ページのテンプレート。外部のファイルを必要としないよう、スタイルは全て埋め込む。
+var pages = template.Must(template.New("").Funcs(template.FuncMap{
+	"statusClass": statusClass,
+	"statusText":  statusText,
+}).Parse(pageTemplates))
+
+func statusClass(status int) string {
+	switch status {
+	case db.RUNNING:
+		return "running"
+	case db.NORMAL:
+		return "normal"
+	case db.WARN:
+		return "warn"
+	case db.SKIPPED:
+		return "skipped"
+	case db.FORCED:
+		return "forced"
+	case db.CANCELLED:
+		return "cancelled"
+	case db.ABNORMAL:
+		return "abnormal"
+	}
+	return "notexecuted"
+}
+
+func statusText(status int) string {
+	switch status {
+	case db.RUNNING:
+		return db.ST_RUNNING
+	case db.NORMAL:
+		return db.ST_NORMAL
+	case db.WARN:
+		return db.ST_WARN
+	case db.SKIPPED:
+		return db.ST_SKIPPED
+	case db.FORCED:
+		return db.ST_FORCED
+	case db.CANCELLED:
+		return db.ST_CANCELLED
+	case db.ABNORMAL:
+		return db.ST_ABNORMAL
+	}
+	return "UNKNOWN"
+}
+
+const pageTemplates = `
+{{define "header"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+{{if .Refresh}}<meta http-equiv="refresh" content="{{.Refresh}}">{{end}}
+<title>{{.Title}} - GoCuto Dashboard</title>
+<style>
+body { font-family: sans-serif; margin: 20px; color: #333; }
+a { color: #1a5fb4; text-decoration: none; }
+a:hover { text-decoration: underline; }
+h1 { font-size: 20px; }
+h1 a { color: #333; }
+h2 { font-size: 16px; margin-top: 24px; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; font-size: 13px; text-align: left; }
+th { background: #f0f0f0; }
+td.status { font-weight: bold; }
+td.running, rect.running { background: #fff3c4; fill: #fff3c4; }
+td.normal, rect.normal { background: #d4f4d4; fill: #d4f4d4; }
+td.warn, rect.warn { background: #ffe0b2; fill: #ffe0b2; }
+td.cancelled, rect.cancelled { background: #ddd; fill: #ddd; }
+td.skipped, rect.skipped, td.forced, rect.forced { background: #e1d5f5; fill: #e1d5f5; }
+td.abnormal, rect.abnormal { background: #ffcdd2; fill: #ffcdd2; }
+rect.notexecuted { fill: #fff; }
+svg .node { stroke: #555; stroke-width: 1.5; }
+svg .start, svg .end, svg .parallel, svg .exclusive { fill: #fff; }
+svg .end { stroke-width: 3; }
+svg text { font-size: 12px; text-anchor: middle; dominant-baseline: middle; }
+svg line { stroke: #555; stroke-width: 1.5; }
+pre { background: #f8f8f8; border: 1px solid #ccc; padding: 8px; overflow: auto; }
+.note { color: #777; font-size: 12px; }
+</style>
+</head>
+<body>
+<h1><a href="/">GoCuto Dashboard</a> / {{.Title}}</h1>
+{{end}}
+
+{{define "footer"}}</body>
+</html>
+{{end}}
+
+{{define "index"}}{{template "header" .}}
+<form method="get" action="/">
+Jobnet: <input type="text" name="jobnet" value="{{.Jobnet}}">
+<input type="submit" value="Filter">
+{{if .Jobnet}}<a href="/">Clear</a>{{end}}
+</form>
+<h2>Instances</h2>
+{{if .Jobnets}}
+<table>
+<tr><th>Instance</th><th>Jobnet</th><th>Status</th><th>Start</th><th>End</th><th>Detail</th></tr>
+{{range .Jobnets}}<tr>
+<td><a href="/instances/{{.Id}}">{{.Id}}</a></td>
+<td><a href="/?jobnet={{.Jobnetwork}}">{{.Jobnetwork}}</a></td>
+<td class="status {{statusClass .Status}}">{{statusText .Status}}</td>
+<td>{{.StartDate}}</td>
+<td>{{.EndDate}}</td>
+<td>{{.Detail}}</td>
+</tr>{{end}}
+</table>
+{{if .Limited}}<p class="note">Only the latest {{len .Jobnets}} instances are displayed.</p>{{end}}
+{{else}}<p>No instance found.</p>{{end}}
+{{template "footer"}}{{end}}
+
+{{define "instance"}}{{template "header" .}}
+{{with .Jobnet}}
+<table>
+<tr><th>Instance</th><td>{{.Id}}</td></tr>
+<tr><th>Jobnet</th><td>{{.Jobnetwork}} (<a href="/?jobnet={{.Jobnetwork}}">history</a>)</td></tr>
+<tr><th>Status</th><td class="status {{statusClass .Status}}">{{statusText .Status}}</td></tr>
+<tr><th>Start</th><td>{{.StartDate}}</td></tr>
+<tr><th>End</th><td>{{.EndDate}}</td></tr>
+<tr><th>Detail</th><td>{{.Detail}}</td></tr>
+</table>
+{{end}}
+{{if .Refresh}}<p class="note">This page is refreshed every {{.Refresh}} seconds while the instance is running.</p>{{end}}
+<h2>Flow</h2>
+{{with .Graph}}
+<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}">
+<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto"><path d="M0,0 L10,5 L0,10 z" fill="#555"/></marker></defs>
+{{range .Edges}}<line x1="{{.X1}}" y1="{{.Y1}}" x2="{{.X2}}" y2="{{.Y2}}" marker-end="url(#arrow)"/>
+{{end}}
+{{range .Nodes}}<g>
+{{if eq .Kind "task"}}<rect class="node {{.Class}}" x="{{.X}}" y="{{.Y}}" width="130" height="40" rx="6" transform="translate(-65,-20)"><title>{{.ID}}: {{.Title}}</title></rect>
+<text x="{{.X}}" y="{{.Y}}">{{.Name}}</text>
+{{else if eq .Kind "parallel"}}<path class="node parallel" d="M{{.X}},{{.Y}} m-20,0 l20,-20 l20,20 l-20,20 z"/><text x="{{.X}}" y="{{.Y}}">+</text>
+{{else if eq .Kind "exclusive"}}<path class="node exclusive" d="M{{.X}},{{.Y}} m-20,0 l20,-20 l20,20 l-20,20 z"/><text x="{{.X}}" y="{{.Y}}">×</text>
+{{else}}<circle class="node {{.Kind}}" cx="{{.X}}" cy="{{.Y}}" r="15"/>
+{{end}}</g>
+{{end}}
+</svg>
+{{else}}<p class="note">{{.GraphError}}</p>{{end}}
+<h2>Jobs</h2>
+{{if .Jobnet.Jobs}}
+<table>
+<tr><th>ID</th><th>Name</th><th>Status</th><th>Start</th><th>End</th><th>RC</th><th>Node</th><th>Port</th><th>Detail</th><th>Joblog</th></tr>
+{{$nid := .Jobnet.Id}}
+{{range .Jobnet.Jobs}}<tr>
+<td>{{.JobId}}</td>
+<td>{{.Jobname}}</td>
+<td class="status {{statusClass .Status}}">{{statusText .Status}}</td>
+<td>{{.StartDate}}</td>
+<td>{{.EndDate}}</td>
+<td>{{.Rc}}</td>
+<td>{{.Node}}</td>
+<td>{{.Port}}</td>
+<td>{{.Detail}}</td>
+<td><a href="/instances/{{$nid}}/joblog/{{.JobId}}">joblog</a></td>
+</tr>{{end}}
+</table>
+{{else}}<p>No job executed.</p>{{end}}
+{{template "footer"}}{{end}}
+
+{{define "joblog"}}{{template "header" .}}
+<p><a href="/instances/{{.NID}}">Back to instance {{.NID}}</a></p>
+{{if .Error}}<p>Could not fetch joblog: {{.Error}}</p>
+{{else}}<h2>{{.File}}</h2>
+{{if .Truncated}}<p class="note">The joblog is too large. Only the last part is displayed.</p>{{end}}
+<pre>{{.Content}}</pre>
+{{end}}
+{{template "footer"}}{{end}}
+`
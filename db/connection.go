@@ -43,6 +43,12 @@ func Open(dbfile string) (IConnection, error) {
 	// 外部キーを有効にする。
 	db.Exec("PRAGMA foreign_keys=ON;")
 
+	// 旧バージョンで作成されたDBファイルのテーブル構成を更新する。
+	if err := upgrade(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	// テーブルと構造体のマッピング。
 	dbmap := &gorp.DbMap{
 		Db:      db,
@@ -50,6 +56,8 @@ func Open(dbfile string) (IConnection, error) {
 	}
 	jobNetworkMapping(dbmap)
 	jobMapping(dbmap)
+	jobAttemptMapping(dbmap)
+	scheduleLogMapping(dbmap)
 
 	return Connection{db, dbmap}, nil
 }
@@ -77,10 +85,39 @@ func jobMapping(dbmap *gorp.DbMap) {
 	t.ColMap("Node").Rename("NODE")
 	t.ColMap("Port").Rename("PORT")
 	t.ColMap("Variable").Rename("VARIABLE")
+	t.ColMap("Outputs").Rename("OUTPUTS")
+	t.ColMap("QueuePos").Rename("QUEUEPOS")
+	t.ColMap("QueueSec").Rename("QUEUESEC")
 	t.ColMap("CreateDate").Rename("CREATEDATE")
 	t.ColMap("UpdateDate").Rename("UPDATEDATE")
 }
 
+func jobAttemptMapping(dbmap *gorp.DbMap) {
+	t := dbmap.AddTableWithName(JobAttempt{}, "JOBATTEMPT").SetKeys(false, "ID", "JobId", "Attempt")
+	t.ColMap("JobId").Rename("JOBID")
+	t.ColMap("Attempt").Rename("ATTEMPT")
+	t.ColMap("StartDate").Rename("STARTDATE")
+	t.ColMap("EndDate").Rename("ENDDATE")
+	t.ColMap("Status").Rename("STATUS")
+	t.ColMap("Detail").Rename("DETAIL")
+	t.ColMap("Rc").Rename("RC")
+	t.ColMap("Node").Rename("NODE")
+	t.ColMap("Port").Rename("PORT")
+	t.ColMap("CreateDate").Rename("CREATEDATE")
+}
+
+func scheduleLogMapping(dbmap *gorp.DbMap) {
+	t := dbmap.AddTableWithName(ScheduleLog{}, "SCHEDULELOG").SetKeys(true, "ID")
+	t.ColMap("Schedule").Rename("SCHEDULE")
+	t.ColMap("JobnetWork").Rename("JOBNETWORK")
+	t.ColMap("ScheduledDate").Rename("SCHEDULEDDATE")
+	t.ColMap("TriggerDate").Rename("TRIGGERDATE")
+	t.ColMap("Action").Rename("ACTION")
+	t.ColMap("InstanceID").Rename("INSTANCE")
+	t.ColMap("Detail").Rename("DETAIL")
+	t.ColMap("CreateDate").Rename("CREATEDATE")
+}
+
 // SQLite3とのセッションを切断する。
 func (c Connection) Close() {
 	c.db.Close()
@@ -6,13 +6,19 @@ const (
 	RUNNING = iota
 	NORMAL
 	WARN
-	ABNORMAL = 9
+	SKIPPED   = 3 // 実行せずに完了扱いとした
+	FORCED    = 4 // 異常終了後に完了扱いとした
+	CANCELLED = 8
+	ABNORMAL  = 9
 )
 
 // 実行結果の文字列ステータス
 const (
-	ST_RUNNING  = "RUNNING"
-	ST_NORMAL   = "NORMAL END"
-	ST_WARN     = "WARN END"
-	ST_ABNORMAL = "ABNORMAL END"
+	ST_RUNNING   = "RUNNING"
+	ST_NORMAL    = "NORMAL END"
+	ST_WARN      = "WARN END"
+	ST_SKIPPED   = "SKIPPED"
+	ST_FORCED    = "FORCED END"
+	ST_CANCELLED = "CANCELLED"
+	ST_ABNORMAL  = "ABNORMAL END"
 )
@@ -0,0 +1,38 @@
+package db
+
+// ジョブの試行毎の実行結果
+type JobAttempt struct {
+	ID         int    // ジョブネットワークのインシデントID
+	JobId      string // ジョブID
+	Attempt    int    // 試行回数（1から開始）
+	StartDate  string // ジョブの起動日時
+	EndDate    string // ジョブの終了日時
+	Status     int    // ステータス
+	Detail     string // 詳細メッセージ
+	Rc         int    // リターンコード
+	Node       string // ノード名
+	Port       int    // ポート番号
+	CreateDate string // 作成日時
+}
+
+// ジョブ実行結果から、試行毎の実行結果を生成する。
+//
+// param : job ジョブ実行結果。
+//
+// param : attempt 試行回数。
+//
+// return : JobAttemptポインタ
+func NewJobAttempt(job *JobResult, attempt int) *JobAttempt {
+	return &JobAttempt{
+		ID:        job.ID,
+		JobId:     job.JobId,
+		Attempt:   attempt,
+		StartDate: job.StartDate,
+		EndDate:   job.EndDate,
+		Status:    job.Status,
+		Detail:    job.Detail,
+		Rc:        job.Rc,
+		Node:      job.Node,
+		Port:      job.Port,
+	}
+}
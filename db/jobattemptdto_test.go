@@ -0,0 +1,24 @@
+package db
+
+import "testing"
+
+func TestNewJobAttempt_ジョブ実行結果から初期化できる(t *testing.T) {
+	job := &JobResult{ID: 10, JobId: "job1", StartDate: "2015-12-31 00:00:00.000", EndDate: "2015-12-31 00:01:00.000",
+		Status: ABNORMAL, Detail: "detail", Rc: 12, Node: "node1", Port: 2015}
+	a := NewJobAttempt(job, 2)
+	if a.ID != 10 || a.JobId != "job1" {
+		t.Errorf("ID[%v, %v]が想定と違っています。", a.ID, a.JobId)
+	}
+	if a.Attempt != 2 {
+		t.Errorf("試行回数を[%v]で初期化しましたが、[%v]が返りました。", 2, a.Attempt)
+	}
+	if a.Status != ABNORMAL || a.Rc != 12 || a.Detail != "detail" {
+		t.Errorf("実行結果[%v, %v, %v]が想定と違っています。", a.Status, a.Rc, a.Detail)
+	}
+	if a.Node != "node1" || a.Port != 2015 {
+		t.Errorf("ノード[%v:%v]が想定と違っています。", a.Node, a.Port)
+	}
+	if a.StartDate != job.StartDate || a.EndDate != job.EndDate {
+		t.Errorf("日時[%v - %v]が想定と違っています。", a.StartDate, a.EndDate)
+	}
+}
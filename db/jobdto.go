@@ -3,6 +3,8 @@
 
 package db
 
+import "encoding/json"
+
 // ジョブ実行結果
 type JobResult struct {
 	ID         int    // ジョブネットワークのインシデントID
@@ -16,6 +18,9 @@ type JobResult struct {
 	Node       string // ノード名
 	Port       int    // ポート番号
 	Variable   string // 変数情報
+	Outputs    string // 名前付き出力（JSON形式）
+	QueuePos   int    // servantでの実行待ち順位（待機しなかった場合は0）
+	QueueSec   int    // servantでの実行待ち時間（秒）
 	CreateDate string // 作成日時
 	UpdateDate string // 更新日時
 }
@@ -28,3 +33,29 @@ type JobResult struct {
 func NewJobResult(id int) *JobResult {
 	return &JobResult{ID: id}
 }
+
+// 名前付き出力をJSON形式でセットする。出力が無い場合は空文字列をセットする。
+//
+// param : outputs 名前付き出力。
+func (j *JobResult) SetOutputs(outputs map[string]string) {
+	if len(outputs) == 0 {
+		j.Outputs = ""
+		return
+	}
+	b, _ := json.Marshal(outputs)
+	j.Outputs = string(b)
+}
+
+// JSON形式でセットされた名前付き出力を取得する。出力が無い場合や解析できない場合はnilを返す。
+//
+// return : 名前付き出力。
+func (j *JobResult) GetOutputs() map[string]string {
+	if j.Outputs == "" {
+		return nil
+	}
+	var outputs map[string]string
+	if err := json.Unmarshal([]byte(j.Outputs), &outputs); err != nil {
+		return nil
+	}
+	return outputs
+}
@@ -15,3 +15,27 @@ func TestNewJobResult_初期化できる(t *testing.T) {
 		t.Errorf("ジョブネットIDを[%v]で初期化しましたが、[%v]になってしまった。", jobNetID, jobRes.ID)
 	}
 }
+
+func TestJobResultOutputs_名前付き出力をJSON形式で保持できる(t *testing.T) {
+	jobRes := NewJobResult(1)
+	jobRes.SetOutputs(map[string]string{"count": "10", "file": "a.csv"})
+	if jobRes.Outputs != `{"count":"10","file":"a.csv"}` {
+		t.Errorf("セットした名前付き出力[%s]が想定と違っている。", jobRes.Outputs)
+	}
+
+	outputs := jobRes.GetOutputs()
+	if len(outputs) != 2 || outputs["count"] != "10" || outputs["file"] != "a.csv" {
+		t.Errorf("取得した名前付き出力%vが想定と違っている。", outputs)
+	}
+}
+
+func TestJobResultOutputs_名前付き出力が無い場合(t *testing.T) {
+	jobRes := NewJobResult(1)
+	jobRes.SetOutputs(nil)
+	if jobRes.Outputs != "" {
+		t.Errorf("セットした名前付き出力[%s]が想定と違っている。", jobRes.Outputs)
+	}
+	if jobRes.GetOutputs() != nil {
+		t.Error("名前付き出力が無いのにnil以外が返された。")
+	}
+}
@@ -0,0 +1,40 @@
+package query
+
+import (
+	"github.com/unirita/cuto/db"
+)
+
+// ジョブネットワークのインスタンスIDを指定して、ジョブの試行毎の実行結果を取得する。
+// 結果はジョブID、試行回数の昇順で返す。
+//
+// param - conn 接続済みのDBコネクション。
+//
+// param - nid 検索に使用するジョブネットワークのインスタンスID。
+//
+// return 試行結果レコードのスライスとエラー情報
+func GetJobAttemptsOfTargetNetwork(conn db.IConnection, nid int) ([]*db.JobAttempt, error) {
+	list, err := conn.GetDbMap().Select(db.JobAttempt{},
+		"select * from JOBATTEMPT where ID = ? order by JOBID, ATTEMPT", nid)
+	if err != nil {
+		return nil, err
+	}
+	var results []*db.JobAttempt
+	for _, l := range list {
+		results = append(results, l.(*db.JobAttempt))
+	}
+	return results, nil
+}
+
+// ジョブの試行回数を取得する。
+//
+// param - conn 接続済みのDBコネクション。
+//
+// param - nid ジョブネットワークのインスタンスID。
+//
+// param - jid ジョブID。
+//
+// return 記録済みの試行回数とエラー情報
+func CountJobAttempts(conn db.IConnection, nid int, jid string) (int, error) {
+	num, err := conn.GetDbMap().SelectInt("select count(*) from JOBATTEMPT where ID = ? and JOBID = ?", nid, jid)
+	return int(num), err
+}
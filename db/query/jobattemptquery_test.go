@@ -0,0 +1,39 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestGetJobAttemptsOfTargetNetwork_ジョブネットワークIDを指定して取得(t *testing.T) {
+	results, err := GetJobAttemptsOfTargetNetwork(conn, 3)
+	if err != nil {
+		t.Fatal("エラーが返ってきました。 - ", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("2件見つかるべきところ、%v件が返りました。", len(results))
+	}
+	if results[0].Attempt != 1 || results[1].Attempt != 2 {
+		t.Errorf("不正な試行回数[%v, %v]が返りました。", results[0].Attempt, results[1].Attempt)
+	}
+	if results[0].JobId != "ABENDJOB" {
+		t.Errorf("不正なジョブID[%v]が返りました。", results[0].JobId)
+	}
+}
+
+func TestCountJobAttempts_試行回数を取得(t *testing.T) {
+	num, err := CountJobAttempts(conn, 3, "ABENDJOB")
+	if err != nil {
+		t.Fatal("エラーが返ってきました。 - ", err)
+	}
+	if num != 2 {
+		t.Errorf("2回のはずが、[%v]回が返ってきました。", num)
+	}
+
+	num, err = CountJobAttempts(conn, 999, "ABENDJOB")
+	if err != nil {
+		t.Fatal("エラーが返ってきました。 - ", err)
+	}
+	if num != 0 {
+		t.Errorf("0回のはずが、[%v]回が返ってきました。", num)
+	}
+}
@@ -5,6 +5,7 @@ package query
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/unirita/cuto/db"
 )
@@ -84,8 +85,9 @@ func (j *JobNetResultQuery) AddAndWhereID(id int) {
 }
 
 // 引数に指定したJOBNETWORKと合致する条件を追加。
+// 引数に含まれるシングルクォートはエスケープする。
 func (j *JobNetResultQuery) AddAndWhereJobnetwork(jobnetwork string) {
-	j.sql = fmt.Sprintf(" %v and JOBNETWORK = '%v' ", j.sql, jobnetwork)
+	j.sql = fmt.Sprintf(" %v and JOBNETWORK = '%v' ", j.sql, strings.Replace(jobnetwork, "'", "''", -1))
 }
 
 // 引数に指定したSTARTDATEよりも小さい日付[ STARTDATE < '引数' ]を取得。
@@ -110,6 +110,18 @@ func TestGetJobnetworkList_2件取得(t *testing.T) {
 	}
 }
 
+func TestAddAndWhereJobnetwork_シングルクォートをエスケープする(t *testing.T) {
+	query := CreateJobnetworkQuery(conn)
+	query.AddAndWhereJobnetwork("x' or '1'='1")
+	results, err := query.GetJobnetworkList()
+	if err != nil {
+		t.Fatalf("ジョブ取得時にエラーが返ってきました。 - %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("0件返ってくるべきところ、%v件が返ってきました。", len(results))
+	}
+}
+
 func TestGetJobnetworkList_クエリ不正(t *testing.T) {
 	query := CreateJobnetworkQuery(conn)
 	query.AddAndWhereJobnetwork("ジョブネット1")
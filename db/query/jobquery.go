@@ -65,7 +65,7 @@ func GetJobMapOfTargetNetwork(conn db.IConnection, nid int) (map[string]*db.JobR
 }
 
 func CreateJobQuery(conn db.IConnection) *jobQuery {
-	sql := fmt.Sprintf("select ID,JOBID,JOBNAME,STARTDATE,ENDDATE,STATUS,DETAIL,RC,NODE,PORT,VARIABLE,CREATEDATE,UPDATEDATE from JOB where 0=0 ")
+	sql := fmt.Sprintf("select ID,JOBID,JOBNAME,STARTDATE,ENDDATE,STATUS,DETAIL,RC,NODE,PORT,VARIABLE,OUTPUTS,QUEUEPOS,QUEUESEC,CREATEDATE,UPDATEDATE from JOB where 0=0 ")
 	return &jobQuery{sql, conn}
 }
 
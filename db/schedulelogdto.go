@@ -0,0 +1,39 @@
+package db
+
+// スケジュールによる起動記録
+type ScheduleLog struct {
+	ID            int    // 起動記録ID
+	Schedule      string // スケジュール名
+	JobnetWork    string // ジョブネットワーク名
+	ScheduledDate string // 起動予定日時
+	TriggerDate   string // 起動処理日時
+	Action        string // 起動処理の結果
+	InstanceID    int    // 起動したジョブネットワークのインシデントID
+	Detail        string // 詳細メッセージ
+	CreateDate    string // 作成日時
+}
+
+// スケジュールによる起動処理の結果
+const (
+	SC_LAUNCHED = "LAUNCHED"
+	SC_SKIPPED  = "SKIPPED"
+	SC_QUEUED   = "QUEUED"
+	SC_FAILED   = "FAILED"
+)
+
+// スケジュールによる起動記録のコンストラクタ。
+//
+// param : schedule スケジュール名。
+//
+// param : jobnetName ジョブネットワーク名。
+//
+// param : scheduledDate 起動予定日時。
+//
+// return : ScheduleLogポインタ
+func NewScheduleLog(schedule string, jobnetName string, scheduledDate string) *ScheduleLog {
+	return &ScheduleLog{
+		Schedule:      schedule,
+		JobnetWork:    jobnetName,
+		ScheduledDate: scheduledDate,
+	}
+}
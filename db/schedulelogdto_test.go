@@ -0,0 +1,16 @@
+package db
+
+import "testing"
+
+func TestNewScheduleLog_初期化できる(t *testing.T) {
+	sl := NewScheduleLog("daily", "ABC", "2015-12-31 00:00:00.000")
+	if sl.Schedule != "daily" {
+		t.Errorf("スケジュール名を[%v]で初期化しましたが、[%v]が返りました。", "daily", sl.Schedule)
+	}
+	if sl.JobnetWork != "ABC" {
+		t.Errorf("ジョブネット名を[%v]で初期化しましたが、[%v]が返りました。", "ABC", sl.JobnetWork)
+	}
+	if sl.ScheduledDate != "2015-12-31 00:00:00.000" {
+		t.Errorf("起動予定日時を[%v]で初期化しましたが、[%v]が返りました。", "2015-12-31 00:00:00.000", sl.ScheduledDate)
+	}
+}
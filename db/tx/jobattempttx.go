@@ -0,0 +1,41 @@
+package tx
+
+import (
+	"sync"
+
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/utctime"
+)
+
+// JOBATTEMPTテーブルへINSERTする。
+//
+// param - conn DBコネクション
+//
+// param - attempt JOBATTEMPTレコード構造体ポインタ
+func InsertJobAttempt(conn db.IConnection, attempt *db.JobAttempt, mutex *sync.Mutex) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	var isCommit bool
+
+	tx, err := conn.GetDbMap().Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if !isCommit {
+			tx.Rollback()
+		}
+	}()
+
+	attempt.CreateDate = utctime.Now().String()
+
+	if err = tx.Insert(attempt); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	isCommit = true
+	return nil
+}
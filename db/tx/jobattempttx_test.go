@@ -0,0 +1,33 @@
+package tx
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/unirita/cuto/db"
+)
+
+func TestInsertJobAttempt_試行結果の新規登録処理(t *testing.T) {
+	conn, err := db.Open(db_name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	job := &db.JobResult{ID: 100, JobId: "attemptjob", Status: db.ABNORMAL, Rc: 12, Node: "localhost", Port: 2015}
+	var mutex sync.Mutex
+	if err := InsertJobAttempt(conn, db.NewJobAttempt(job, 1), &mutex); err != nil {
+		t.Fatalf("試行結果の登録に失敗しました。 - %v", err)
+	}
+	if err := InsertJobAttempt(conn, db.NewJobAttempt(job, 1), &mutex); err == nil {
+		t.Error("同じ試行回数の登録が成功しました。")
+	}
+
+	rc, err := conn.GetDbMap().SelectInt("select RC from JOBATTEMPT where ID = ? and JOBID = ? and ATTEMPT = ?", 100, "attemptjob", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc != 12 {
+		t.Errorf("登録されたリターンコード[%d]が想定と違っています。", rc)
+	}
+}
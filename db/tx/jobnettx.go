@@ -93,8 +93,13 @@ func (r *ResultMap) EndJobNetwork(status int, detail string) error {
 	r.JobnetResult.Detail = detail
 
 	for _, jobresult := range r.jobresults {
-		if r.JobnetResult.Status < jobresult.Status {
-			r.JobnetResult.Status = jobresult.Status
+		status := jobresult.Status
+		// 完了扱いとしたジョブがある場合は、警告終了とする。
+		if status == db.SKIPPED || status == db.FORCED {
+			status = db.WARN
+		}
+		if r.JobnetResult.Status < status {
+			r.JobnetResult.Status = status
 		}
 	}
 
@@ -182,6 +187,19 @@ func (r *ResultMap) AddJobResults(jobId string, jobRes *db.JobResult) {
 	r.jobresults[jobId] = jobRes
 }
 
+// 実行中のジョブの実行結果を取得する。(thread safe)
+func (r *ResultMap) GetRunningJobResults() []*db.JobResult {
+	localMutex.Lock()
+	defer localMutex.Unlock()
+	var running []*db.JobResult
+	for _, jobres := range r.jobresults {
+		if jobres.Status == db.RUNNING {
+			running = append(running, jobres)
+		}
+	}
+	return running
+}
+
 // test function
 func NewResultMap() *ResultMap {
 	return &ResultMap{
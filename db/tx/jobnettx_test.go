@@ -27,7 +27,7 @@ func init() {
 	}
 	defer conn.Close()
 
-	conn.GetDbMap().DropTables()
+	conn.GetDbMap().DropTablesIfExists()
 	err = conn.GetDbMap().CreateTables()
 	if err != nil {
 		panic(err.Error())
@@ -233,3 +233,18 @@ func TestEndJobNetwork_接続失敗(t *testing.T) {
 		t.Error("エラーが返るべきところ、成功しました。")
 	}
 }
+
+func TestEndJobNetwork_完了扱いのジョブがある場合は警告終了(t *testing.T) {
+	resMap, err := StartJobNetwork("JNet4", db_name)
+	if err != nil {
+		t.Fatalf("エラーがすべきでないパターンで、エラーが発生しました。: %s", err.Error())
+	}
+	resMap.AddJobResults("JOB001", &db.JobResult{Status: db.NORMAL})
+	resMap.AddJobResults("JOB002", &db.JobResult{Status: db.SKIPPED})
+	resMap.AddJobResults("JOB003", &db.JobResult{Status: db.FORCED})
+
+	resMap.EndJobNetwork(db.NORMAL, "")
+	if resMap.JobnetResult.Status != db.WARN {
+		t.Errorf("ジョブネットワークのステータスが[%v]になるべきところ、[%v]が返りました。", db.WARN, resMap.JobnetResult.Status)
+	}
+}
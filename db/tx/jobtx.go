@@ -4,6 +4,7 @@
 package tx
 
 import (
+	"fmt"
 	"sync"
 
 	"github.com/unirita/cuto/db"
@@ -77,3 +78,28 @@ func UpdateJob(conn db.IConnection, job *db.JobResult, mutex *sync.Mutex) error
 	isCommit = true
 	return nil
 }
+
+// JOBテーブルのジョブを、実行せずに完了扱い（SKIPPED）または強制的に完了扱い（FORCED）へ更新する。
+// 終了日時には現在日時を設定し、開始日時が未設定の場合は同じく現在日時を設定する。
+//
+// param - conn DBコネクション
+//
+// param - job JOBレコード構造体ポインタ
+//
+// param - status 更新後のステータス。db.SKIPPEDまたはdb.FORCEDを指定する。
+//
+// param - detail 詳細メッセージ。
+func MarkJob(conn db.IConnection, job *db.JobResult, status int, detail string, mutex *sync.Mutex) error {
+	if status != db.SKIPPED && status != db.FORCED {
+		return fmt.Errorf("Status[%d] cannot be used to mark job.", status)
+	}
+
+	now := utctime.Now().String()
+	if job.StartDate == "" {
+		job.StartDate = now
+	}
+	job.EndDate = now
+	job.Status = status
+	job.Detail = detail
+	return UpdateJob(conn, job, mutex)
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/db/query"
 	"github.com/unirita/cuto/utctime"
 )
 
@@ -132,3 +133,61 @@ func TestUpdateJob_ジョブの更新失敗(t *testing.T) {
 		t.Error("予定していた失敗が返りませんでした。 - ")
 	}
 }
+
+func TestMarkJob_ジョブを完了扱いに更新(t *testing.T) {
+	conn, err := db.Open(db_name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	jobres := &db.JobResult{
+		ID:      123,
+		JobId:   "JOB003",
+		JobName: "mark.bat",
+		Status:  db.ABNORMAL,
+		Node:    "TestNode03",
+		Port:    9999,
+	}
+	if err := InsertJob(conn, jobres, &mutex); err != nil {
+		t.Fatal("ジョブテーブルへの登録に失敗しました。 - ", err)
+	}
+
+	if err := MarkJob(conn, jobres, db.FORCED, "FORCED by user.", &mutex); err != nil {
+		t.Fatal("ジョブテーブルの更新に失敗しました。 - ", err)
+	}
+	if jobres.StartDate == "" || jobres.EndDate == "" {
+		t.Error("開始日時・終了日時が設定されていません。")
+	}
+
+	jobs, err := query.GetJobMapOfTargetNetwork(conn, 123)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, ok := jobs["JOB003"]
+	if !ok {
+		t.Fatal("更新したジョブが見つかりません。")
+	}
+	if res.Status != db.FORCED {
+		t.Errorf("ステータスが[%v]になるべきところ、[%v]がDBに登録されていました。", db.FORCED, res.Status)
+	}
+	if res.Detail != "FORCED by user." {
+		t.Errorf("詳細メッセージ[%v]がDBに登録されていました。", res.Detail)
+	}
+}
+
+func TestMarkJob_完了扱い以外のステータスはエラー(t *testing.T) {
+	conn, err := db.Open(db_name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	jobres := &db.JobResult{ID: 124, JobId: "JOB004", Status: db.ABNORMAL}
+	if err := MarkJob(conn, jobres, db.NORMAL, "", &mutex); err == nil {
+		t.Error("予定していた失敗が返りませんでした。 - ")
+	}
+	if jobres.Status != db.ABNORMAL {
+		t.Errorf("ステータスが[%v]に変更されました。", jobres.Status)
+	}
+}
@@ -0,0 +1,36 @@
+package tx
+
+import (
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/utctime"
+)
+
+// SCHEDULELOGテーブルへINSERTする。
+//
+// param - conn DBコネクション
+//
+// param - sl SCHEDULELOGレコード構造体ポインタ
+func InsertScheduleLog(conn db.IConnection, sl *db.ScheduleLog) error {
+	var isCommit bool
+
+	tx, err := conn.GetDbMap().Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if !isCommit {
+			tx.Rollback()
+		}
+	}()
+
+	sl.CreateDate = utctime.Now().String()
+
+	if err = tx.Insert(sl); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	isCommit = true
+	return nil
+}
@@ -0,0 +1,49 @@
+package tx
+
+import (
+	"testing"
+
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/utctime"
+)
+
+func TestInsertScheduleLog_起動記録の新規登録処理(t *testing.T) {
+	conn, err := db.Open(db_name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	sl := db.NewScheduleLog("daily", "abcnet", utctime.Now().String())
+	sl.TriggerDate = utctime.Now().String()
+	sl.Action = db.SC_LAUNCHED
+	sl.InstanceID = 10
+	if err := InsertScheduleLog(conn, sl); err != nil {
+		t.Fatalf("起動記録の登録に失敗しました。 - %v", err)
+	}
+	if sl.ID == 0 {
+		t.Error("起動記録IDが採番されていません。")
+	}
+
+	var action string
+	action, err = conn.GetDbMap().SelectStr("select ACTION from SCHEDULELOG where ID = ?", sl.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action != db.SC_LAUNCHED {
+		t.Errorf("登録された起動処理の結果[%s]が想定と違っています。", action)
+	}
+}
+
+func TestInsertScheduleLog_起動記録の新規登録失敗(t *testing.T) {
+	conn, err := db.Open(dummy_db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	sl := db.NewScheduleLog("daily", "abcnet", utctime.Now().String())
+	if err := InsertScheduleLog(conn, sl); err == nil {
+		t.Error("予定していた失敗が返りませんでした。 - ")
+	}
+}
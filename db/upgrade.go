@@ -0,0 +1,119 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// 旧バージョンで作成されたDBファイルに存在しない場合に作成するテーブル。
+var upgradeTables = []string{
+	`CREATE TABLE IF NOT EXISTS "SCHEDULELOG" (
+  "ID" INTEGER PRIMARY KEY  NOT NULL ,
+  "SCHEDULE" TEXT NOT NULL ,
+  "JOBNETWORK" TEXT NOT NULL ,
+  "SCHEDULEDDATE" TEXT NOT NULL ,
+  "TRIGGERDATE" TEXT NOT NULL ,
+  "ACTION" TEXT NOT NULL ,
+  "INSTANCE" INTEGER NOT NULL ,
+  "DETAIL" TEXT ,
+  "CREATEDATE" TEXT NOT NULL
+)`,
+	`CREATE TABLE IF NOT EXISTS "JOBATTEMPT" (
+  "ID" INTEGER NOT NULL,
+  "JOBID" TEXT NOT NULL,
+  "ATTEMPT" INTEGER NOT NULL,
+  "STARTDATE" TEXT NOT NULL,
+  "ENDDATE" TEXT,
+  "STATUS" INTEGER NOT NULL,
+  "DETAIL" TEXT,
+  "RC" INTEGER NOT NULL,
+  "NODE" TEXT NOT NULL DEFAULT localhost,
+  "PORT" INTEGER NOT NULL,
+  "CREATEDATE" TEXT NOT NULL,
+  PRIMARY KEY ("ID", "JOBID", "ATTEMPT"),
+  FOREIGN KEY(ID) REFERENCES JOBNETWORK(ID)
+)`,
+}
+
+// 旧バージョンで作成されたDBファイルのテーブルに、存在しない場合に追加するカラム。
+var upgradeColumns = []struct {
+	table  string // テーブル名
+	column string // カラム名
+	define string // カラムの型と制約
+}{
+	{"JOB", "OUTPUTS", "TEXT NOT NULL DEFAULT ''"},
+	{"JOB", "QUEUEPOS", "INTEGER NOT NULL DEFAULT 0"},
+	{"JOB", "QUEUESEC", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// 旧バージョンで作成されたDBファイルに、不足しているテーブルとカラムを追加する。
+// 既に追加済みの場合や、初期化されていないDBファイルの場合は何もしない。
+//
+// param - db DBオブジェクト。
+//
+// return - エラー情報。
+func upgrade(db *sql.DB) error {
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name='JOBNETWORK'`).Scan(&count); err != nil {
+		return fmt.Errorf("Failed to upgrade db: %s", err)
+	}
+	if count == 0 {
+		return nil
+	}
+
+	for _, query := range upgradeTables {
+		if _, err := db.Exec(query); err != nil {
+			return fmt.Errorf("Failed to upgrade db: %s", err)
+		}
+	}
+	for _, c := range upgradeColumns {
+		exists, err := hasColumn(db, c.table, c.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		query := fmt.Sprintf(`ALTER TABLE "%s" ADD COLUMN "%s" %s`, c.table, c.column, c.define)
+		if _, err := db.Exec(query); err != nil {
+			// 他のプロセスが同時に追加した場合はエラーとしない
+			if exists, _ := hasColumn(db, c.table, c.column); exists {
+				continue
+			}
+			return fmt.Errorf("Failed to add column [%s.%s]: %s", c.table, c.column, err)
+		}
+	}
+	return nil
+}
+
+// テーブルにカラムが存在するかを返す。
+func hasColumn(db *sql.DB, table string, column string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf(`PRAGMA table_info("%s")`, table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return false, err
+	}
+	for rows.Next() {
+		values := make([]interface{}, len(cols))
+		var name string
+		for i := range values {
+			if cols[i] == "name" {
+				values[i] = &name
+			} else {
+				values[i] = new(interface{})
+			}
+		}
+		if err := rows.Scan(values...); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
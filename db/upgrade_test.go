@@ -0,0 +1,98 @@
+package db
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/unirita/cuto/testutil"
+)
+
+// 旧バージョンのテーブル構成のDBファイルを作成する。
+func createOldDBFile(t *testing.T) string {
+	path := filepath.Join(testutil.GetBaseDir(), "db", "_testdata", "upgrade.sqlite")
+	os.Remove(path)
+	db, err := sql.Open(sqlite3_driver, path)
+	if err != nil {
+		t.Fatalf("DBファイルの作成に失敗しました。 - %v", err)
+	}
+	defer db.Close()
+
+	queries := []string{
+		`CREATE TABLE "JOBNETWORK" (
+  "ID" INTEGER PRIMARY KEY  NOT NULL ,
+  "JOBNETWORK" TEXT NOT NULL ,
+  "STARTDATE" TEXT NOT NULL ,
+  "ENDDATE" TEXT ,
+  "STATUS" INTEGER NOT NULL ,
+  "DETAIL" TEXT NOT NULL ,
+  "PID" INTEGER NOT NULL ,
+  "CREATEDATE" TEXT NOT NULL ,
+  "UPDATEDATE" TEXT NOT NULL
+)`,
+		`CREATE TABLE "JOB" (
+  "ID" INTEGER NOT NULL,
+  "JOBID" TEXT NOT NULL,
+  "JOBNAME" TEXT NOT NULL,
+  "STARTDATE" TEXT NOT NULL,
+  "ENDDATE" TEXT,
+  "STATUS" INTEGER NOT NULL,
+  "DETAIL" TEXT,
+  "RC" INTEGER  NOT NULL,
+  "NODE" TEXT NOT NULL DEFAULT localhost,
+  "PORT" INTEGER NOT NULL,
+  "VARIABLE" TEXT,
+  "CREATEDATE" TEXT NOT NULL,
+  "UPDATEDATE" TEXT NOT NULL,
+  PRIMARY KEY ("ID", "JOBID"),
+  FOREIGN KEY(ID) REFERENCES JOBNETWORK(ID)
+)`,
+	}
+	for _, query := range queries {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("テーブルの作成に失敗しました。 - %v", err)
+		}
+	}
+	return path
+}
+
+func TestOpen_旧バージョンのDBファイルに不足しているテーブルを作成する(t *testing.T) {
+	path := createOldDBFile(t)
+	defer os.Remove(path)
+
+	for i := 0; i < 2; i++ {
+		con, err := Open(path)
+		if err != nil {
+			t.Fatalf("%d回目のDBとの接続に失敗しました。 - %v", i+1, err)
+		}
+		for _, table := range []string{"SCHEDULELOG", "JOBATTEMPT"} {
+			if _, err := con.GetDb().Exec(`SELECT COUNT(*) FROM "` + table + `"`); err != nil {
+				t.Errorf("テーブル[%s]が作成されていない。 - %v", table, err)
+			}
+		}
+		con.Close()
+	}
+}
+
+func TestOpen_旧バージョンのDBファイルに不足しているカラムを追加する(t *testing.T) {
+	path := createOldDBFile(t)
+	defer os.Remove(path)
+
+	for i := 0; i < 2; i++ {
+		con, err := Open(path)
+		if err != nil {
+			t.Fatalf("%d回目のDBとの接続に失敗しました。 - %v", i+1, err)
+		}
+		for _, column := range []string{"OUTPUTS", "QUEUEPOS", "QUEUESEC"} {
+			exists, err := hasColumn(con.GetDb(), "JOB", column)
+			if err != nil {
+				t.Fatalf("カラムの確認に失敗しました。 - %v", err)
+			}
+			if !exists {
+				t.Errorf("カラム[%s]が追加されていない。", column)
+			}
+		}
+		con.Close()
+	}
+}
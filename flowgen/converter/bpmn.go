@@ -40,6 +40,7 @@ func (d *Definitions) AppendJob(job *Job, pre string) string {
 }
 
 // AppendGateway appends gateway element to Definitions object.
+// Gateways nested in the paths are appended recursively.
 // This function returns close gateway ID to create connection with next element.
 func (d *Definitions) AppendGateway(gw *Gateway, pre string) string {
 	openGW, closeGW := NewParallelGatewayPair(gw)
@@ -49,28 +50,28 @@ func (d *Definitions) AppendGateway(gw *Gateway, pre string) string {
 
 	// Append inner paths.
 	for _, pathHead := range gw.PathHeads {
-		preInPath := openGW.ID
-		current := pathHead
-		for {
-			d.AppendJob(current, preInPath)
-			preInPath = current.ID()
-			var ok bool
-
-			next := current.Next()
-			if next == nil {
-				break
-			}
-
-			current, ok = next.(*Job)
-			if !ok {
-				panic("Nest of gateway detected.")
-			}
-		}
+		preInPath := d.AppendPath(pathHead, openGW.ID)
 		d.AppendSequenceFlow(NewSequenceFlow(preInPath, closeGW.ID))
 	}
 	return closeGW.ID
 }
 
+// AppendPath appends elements from head to the end of path to Definitions object.
+// This function returns ID of the last element in path to create connection with next element.
+func (d *Definitions) AppendPath(head Element, pre string) string {
+	for current := head; current != nil; current = current.Next() {
+		switch current.(type) {
+		case *Job:
+			pre = d.AppendJob(current.(*Job), pre)
+		case *Gateway:
+			pre = d.AppendGateway(current.(*Gateway), pre)
+		default:
+			panic("Unexpected type detected.")
+		}
+	}
+	return pre
+}
+
 // Process element in BPMN.
 type Process struct {
 	Start    *StartEvent        `xml:"startEvent"`
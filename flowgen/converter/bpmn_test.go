@@ -74,6 +74,32 @@ func TestAppendGateway(t *testing.T) {
 	}
 }
 
+func TestAppendGateway_Nested(t *testing.T) {
+	d := NewDefinitions()
+	inner := NewGateway()
+	inner.AddPathHead(NewJob("test2"))
+	inner.AddPathHead(NewJob("test3"))
+	inner.SetNext(NewJob("test4"))
+	outer := NewGateway()
+	j := NewJob("test1")
+	j.SetNext(inner)
+	outer.AddPathHead(j)
+	outer.AddPathHead(NewJob("test5"))
+	d.AppendGateway(outer, "pre")
+	if len(d.Process.Tasks) != 5 {
+		t.Logf("Number of ServiceTask is %d", len(d.Process.Tasks))
+		t.Fatalf("ServiceTask was not appended enough.")
+	}
+	if len(d.Process.Gateways) != 4 {
+		t.Logf("Number of ParallelGateways is %d", len(d.Process.Gateways))
+		t.Fatalf("ParallelGateways was not appended enough.")
+	}
+	if len(d.Process.Flows) != 11 {
+		t.Logf("Number of SequenceFlow is %d", len(d.Process.Flows))
+		t.Fatalf("SequenceFlow was not appended enough.")
+	}
+}
+
 func TestNewProcess(t *testing.T) {
 	p := NewProcess()
 	if p.Start == nil {
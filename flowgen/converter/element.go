@@ -60,24 +60,24 @@ const gPrefix = "gw"
 var gIndex int = 1
 
 // Gateway is a block which enclosed in bracket in the flow description.
-// Gateway has paths which consists of jobs.
+// Gateway has paths which consists of jobs and nested gateways.
 type Gateway struct {
 	element
 
-	// Head job of paths.
-	PathHeads []*Job
+	// Head element of paths.
+	PathHeads []Element
 }
 
 // Create new Gateway object with no path.
 func NewGateway() *Gateway {
 	g := new(Gateway)
 	g.id = fmt.Sprintf("%s%d", gPrefix, gIndex)
-	g.PathHeads = make([]*Job, 0)
+	g.PathHeads = make([]Element, 0)
 	gIndex++
 	return g
 }
 
 // Add path to Gateway.
-func (g *Gateway) AddPathHead(head *Job) {
+func (g *Gateway) AddPathHead(head Element) {
 	g.PathHeads = append(g.PathHeads, head)
 }
@@ -35,18 +35,7 @@ func ExportFile(filepath string, d *Definitions) error {
 func GenerateDefinitions(head Element) *Definitions {
 	d := NewDefinitions()
 
-	pre := d.Process.Start.ID
-	for current := head; current != nil; current = current.Next() {
-		switch current.(type) {
-		case *Job:
-			pre = d.AppendJob(current.(*Job), pre)
-		case *Gateway:
-			pre = d.AppendGateway(current.(*Gateway), pre)
-		default:
-			panic("Unexpected type detected.")
-		}
-	}
-
+	pre := d.AppendPath(head, d.Process.Start.ID)
 	d.AppendSequenceFlow(NewSequenceFlow(pre, d.Process.End.ID))
 
 	return d
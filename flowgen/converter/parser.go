@@ -1,6 +1,7 @@
 package converter
 
 import (
+	"bytes"
 	"errors"
 	"io/ioutil"
 	"regexp"
@@ -30,20 +31,38 @@ func ParseFile(filepath string) (Element, error) {
 func ParseString(str string) (Element, error) {
 	str = ignoreSpaceChars(str)
 	extracted, gws, err := extractGateways(str)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpGWIndex := 0
+	head, err := parsePath(extracted, gws, &tmpGWIndex)
+	if err != nil {
+		return nil, err
+	}
+	if tmpGWIndex != len(gws) {
+		return nil, errors.New("Gateway parse error.")
+	}
+	return head, nil
+}
 
+// parsePath parses a sequence of elements which are connected with arrows.
+// Gateways in str must be replaced to tmpGW by extractGateways.
+// gwIndex is the index of gws which corresponds to the next tmpGW.
+func parsePath(str string, gws []*Gateway, gwIndex *int) (Element, error) {
 	var head Element = nil
-	elmStrs := strings.Split(extracted, arrow)
+	elmStrs := strings.Split(str, arrow)
 	isFirst := true
 	var current Element
 	var pre Element
-	var tmpGWIndex = 0
+	var err error
 	for _, elmStr := range elmStrs {
 		if elmStr == tmpGW {
-			if tmpGWIndex > len(gws) {
+			if *gwIndex >= len(gws) {
 				return nil, errors.New("Gateway parse error.")
 			}
-			current = gws[tmpGWIndex]
-			tmpGWIndex++
+			current = gws[*gwIndex]
+			*gwIndex++
 		} else {
 			current, err = parseJob(elmStr)
 			if err != nil {
@@ -67,21 +86,46 @@ func ignoreSpaceChars(str string) string {
 	return ptn.ReplaceAllLiteralString(str, "")
 }
 
+// extractGateways replaces outermost gateway blocks in str to tmpGW,
+// and returns the replaced string with parsed gateways.
+// Gateway blocks nested in the outermost ones are parsed recursively by parseGateway.
 func extractGateways(str string) (string, []*Gateway, error) {
-	ptn := regexp.MustCompile(`\[.+?\]`)
 	gws := make([]*Gateway, 0)
 
-	gwStrs := ptn.FindAllString(str, -1)
-	for _, gwStr := range gwStrs {
-		gw, err := parseGateway(gwStr)
-		if err != nil {
-			return str, nil, err
+	var replaced bytes.Buffer
+	depth := 0
+	start := 0
+	for i, c := range str {
+		switch string(c) {
+		case gHead:
+			if depth == 0 {
+				start = i
+			}
+			depth++
+		case gTerm:
+			depth--
+			if depth < 0 {
+				return str, nil, errors.New("Gateway parse error.")
+			}
+			if depth == 0 {
+				gw, err := parseGateway(str[start : i+1])
+				if err != nil {
+					return str, nil, err
+				}
+				gws = append(gws, gw)
+				replaced.WriteString(tmpGW)
+			}
+		default:
+			if depth == 0 {
+				replaced.WriteRune(c)
+			}
 		}
-		gws = append(gws, gw)
+	}
+	if depth != 0 {
+		return str, nil, errors.New("Gateway parse error.")
 	}
 
-	str = ptn.ReplaceAllString(str, tmpGW)
-	return str, gws, nil
+	return replaced.String(), gws, nil
 }
 
 func parseJob(str string) (*Job, error) {
@@ -105,30 +149,24 @@ func validateJobName(str string) error {
 }
 
 func parseGateway(str string) (*Gateway, error) {
-	str = strings.TrimLeft(str, gHead)
-	str = strings.TrimRight(str, gTerm)
+	str = strings.TrimPrefix(str, gHead)
+	str = strings.TrimSuffix(str, gTerm)
+
+	extracted, gws, err := extractGateways(str)
+	if err != nil {
+		return nil, err
+	}
 
 	p := NewGateway()
 
-	pathStrs := strings.Split(str, gDelim)
+	tmpGWIndex := 0
+	pathStrs := strings.Split(extracted, gDelim)
 	for _, pathStr := range pathStrs {
-		jobNames := strings.Split(pathStr, arrow)
-		isFirst := true
-		var preJob *Job
-		for _, jobName := range jobNames {
-			j, err := parseJob(jobName)
-			if err != nil {
-				return nil, err
-			}
-
-			if isFirst {
-				p.AddPathHead(j)
-				isFirst = false
-			} else {
-				preJob.SetNext(j)
-			}
-			preJob = j
+		head, err := parsePath(pathStr, gws, &tmpGWIndex)
+		if err != nil {
+			return nil, err
 		}
+		p.AddPathHead(head)
 	}
 
 	return p, nil
@@ -180,8 +180,8 @@ func TestParseGateway_SinglePath(t *testing.T) {
 	if len(p.PathHeads) != 1 {
 		t.Fatalf("PathHeads size[%d] must be %d.", len(p.PathHeads), 1)
 	}
-	if p.PathHeads[0].Name() != "test1" {
-		t.Errorf("Unexpected job name[%s].", p.PathHeads[0].Name())
+	if p.PathHeads[0].(*Job).Name() != "test1" {
+		t.Errorf("Unexpected job name[%s].", p.PathHeads[0].(*Job).Name())
 	}
 }
 
@@ -198,8 +198,8 @@ func TestParseGateway_SinglePath_MultiJob(t *testing.T) {
 		t.Fatalf("PathHeads size[%d] must be %d.", len(p.PathHeads), 1)
 	}
 
-	head := p.PathHeads[0]
-	if head == nil {
+	head, ok := p.PathHeads[0].(*Job)
+	if !ok {
 		t.Fatalf("Head job is not exist.")
 	}
 	if head.Name() != "test1" {
@@ -243,13 +243,96 @@ func TestParseGateway_MultiPath(t *testing.T) {
 	if len(p.PathHeads) != 3 {
 		t.Fatalf("PathHeads size[%d] must be %d.", len(p.PathHeads), 3)
 	}
-	if p.PathHeads[0].Name() != "test1" {
-		t.Errorf("Unexpected job name[%s].", p.PathHeads[0].Name())
+	if p.PathHeads[0].(*Job).Name() != "test1" {
+		t.Errorf("Unexpected job name[%s].", p.PathHeads[0].(*Job).Name())
+	}
+	if p.PathHeads[1].(*Job).Name() != "test2" {
+		t.Errorf("Unexpected job name[%s].", p.PathHeads[1].(*Job).Name())
+	}
+	if p.PathHeads[2].(*Job).Name() != "test3" {
+		t.Errorf("Unexpected job name[%s].", p.PathHeads[2].(*Job).Name())
+	}
+}
+
+func TestParseString_NestedGateway(t *testing.T) {
+	s := "a->[b->[c,d]->e,f]->g"
+
+	head, err := ParseString(s)
+	if err != nil {
+		t.Fatalf("Unexpected error occured: %s", err)
+	}
+	if head.(*Job).Name() != "a" {
+		t.Errorf("Unexpected head job name[%s]", head.(*Job).Name())
+	}
+
+	outer, ok := head.Next().(*Gateway)
+	if !ok {
+		t.Fatalf("Second element is not gateway element.")
+	}
+	if len(outer.PathHeads) != 2 {
+		t.Fatalf("PathHeads size[%d] must be %d.", len(outer.PathHeads), 2)
+	}
+	if outer.PathHeads[1].(*Job).Name() != "f" {
+		t.Errorf("Unexpected job name[%s].", outer.PathHeads[1].(*Job).Name())
+	}
+
+	b, ok := outer.PathHeads[0].(*Job)
+	if !ok || b.Name() != "b" {
+		t.Fatalf("Head of first path is not job b.")
+	}
+	inner, ok := b.Next().(*Gateway)
+	if !ok {
+		t.Fatalf("Next of job b is not gateway element.")
+	}
+	if len(inner.PathHeads) != 2 {
+		t.Fatalf("PathHeads size[%d] must be %d.", len(inner.PathHeads), 2)
+	}
+	if inner.PathHeads[0].(*Job).Name() != "c" || inner.PathHeads[1].(*Job).Name() != "d" {
+		t.Errorf("Unexpected jobs in nested gateway.")
 	}
-	if p.PathHeads[1].Name() != "test2" {
-		t.Errorf("Unexpected job name[%s].", p.PathHeads[1].Name())
+	if e, ok := inner.Next().(*Job); !ok || e.Name() != "e" {
+		t.Errorf("Next of nested gateway is not job e.")
+	}
+
+	if g, ok := outer.Next().(*Job); !ok || g.Name() != "g" {
+		t.Errorf("Next of outer gateway is not job g.")
+	}
+}
+
+func TestParseString_GatewayHeadOfPath(t *testing.T) {
+	s := "[[a,b]->c,d]"
+
+	head, err := ParseString(s)
+	if err != nil {
+		t.Fatalf("Unexpected error occured: %s", err)
+	}
+	outer, ok := head.(*Gateway)
+	if !ok {
+		t.Fatalf("Head element is not gateway element.")
+	}
+	if _, ok := outer.PathHeads[0].(*Gateway); !ok {
+		t.Errorf("Head of first path is not gateway element.")
+	}
+}
+
+func TestParseString_UnbalancedBracket(t *testing.T) {
+	for _, s := range []string{"a->[b,[c,d]->e", "a->[b,c]]->d", "a->]b,c[->d"} {
+		if _, err := ParseString(s); err == nil {
+			t.Errorf("Error was not detected. Flow[%s]", s)
+		}
+	}
+}
+
+func TestExtractGateways_Nested(t *testing.T) {
+	s := "test1->[test2->[test3,test4],test5]->test6"
+	afterStr, gws, err := extractGateways(s)
+	if err != nil {
+		t.Fatalf("Unexpected error occured: %s", err)
+	}
+	if afterStr != "test1->:gw->test6" {
+		t.Errorf("Gateway expression did not replace correctly. Result[%s]", afterStr)
 	}
-	if p.PathHeads[2].Name() != "test3" {
-		t.Errorf("Unexpected job name[%s].", p.PathHeads[2].Name())
+	if len(gws) != 1 {
+		t.Fatalf("Number of gateway[%d] is not expected value[%d].", len(gws), 1)
 	}
 }
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/unirita/cuto/console"
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/master/jobnet"
+	"github.com/unirita/cuto/util"
+)
+
+// 実行中止シグナル送信後、masterプロセスの終了を待つ時間
+var cancelWaitTime = 60 * time.Second
+
+// masterプロセスの終了を確認する間隔
+const cancelCheckInterval = time.Second
+
+// インスタンスIDがinstanceIDのジョブネットワークの実行を中止する。
+// ジョブネットワークを実行中のmasterへシグナルを送信し、実行中止処理を依頼する。
+// masterが存在しない場合や、masterが終了しない場合は、強制的に実行中止状態とする。
+//
+// param : instanceID ジョブネットワークのインスタンスID。
+//
+// return : エラー情報。
+func cancelNetwork(instanceID int) error {
+	nwkResult, err := getNetworkResult(instanceID)
+	if err != nil {
+		return err
+	}
+	if nwkResult.Status != db.RUNNING {
+		console.Display("CTM036I", instanceID)
+		return nil
+	}
+
+	if util.IsProcessExists(nwkResult.PID) {
+		if err := signalMaster(nwkResult.PID); err != nil {
+			return err
+		}
+		console.Display("CTM034I", instanceID, nwkResult.PID)
+		waitProcessEnd(nwkResult.PID)
+
+		nwkResult, err = getNetworkResult(instanceID)
+		if err != nil {
+			return err
+		}
+		if nwkResult.Status != db.RUNNING {
+			console.Display("CTM038I", instanceID)
+			return nil
+		}
+	}
+
+	console.Display("CTM037W", instanceID)
+	if err := jobnet.ForceCancel(instanceID); err != nil {
+		return err
+	}
+	console.Display("CTM038I", instanceID)
+	return nil
+}
+
+// masterプロセスへ実行中止シグナルを送信する。
+// シグナルを送信できない環境では、masterプロセスを強制終了する。
+func signalMaster(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if err := p.Signal(os.Interrupt); err != nil {
+		return p.Kill()
+	}
+	return nil
+}
+
+// masterプロセスの終了を待つ。一定時間内に終了しない場合はそのまま戻る。
+func waitProcessEnd(pid int) {
+	limit := time.Now().Add(cancelWaitTime)
+	for time.Now().Before(limit) {
+		if !util.IsProcessExists(pid) {
+			return
+		}
+		time.Sleep(cancelCheckInterval)
+	}
+}
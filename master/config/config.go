@@ -4,8 +4,12 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net"
 	"os"
 	"strings"
 
@@ -19,6 +23,10 @@ type config struct {
 	Dir dirSection
 	DB  dbSection
 	Log logSection
+	Sec securitySection  `toml:"security"`
+	API apiSection       `toml:"api"`
+	Dsb dashboardSection `toml:"dashboard"`
+	Ntf notifySection    `toml:"notify"`
 }
 
 // 設定ファイルのjobセクション
@@ -29,6 +37,9 @@ type jobSection struct {
 	ConnectionTimeoutSec int    `toml:"connection_timeout_sec"`
 	TimeTrackingSpanMin  int    `toml:"time_tracking_span_min"`
 	AttemptLimit         int    `toml:"attempt_limit"`
+	StreamOutput         int    `toml:"stream_output"`
+	Multiplex            int    `toml:"multiplex"`
+	FramedProtocol       int    `toml:"framed_protocol"`
 }
 
 // 設定ファイルのdirセクション
@@ -50,12 +61,64 @@ type logSection struct {
 	TimeoutSec    int    `toml:"timeout_sec"`
 }
 
+// 設定ファイルのsecurityセクション
+type securitySection struct {
+	UseTLS      int    `toml:"use_tls"`
+	TLSCAFile   string `toml:"tls_ca_file"`
+	TLSCertFile string `toml:"tls_cert_file"`
+	TLSKeyFile  string `toml:"tls_key_file"`
+	AuthSecret  string `toml:"auth_secret"`
+}
+
+// 設定ファイルのapiセクション
+type apiSection struct {
+	ListenHost string `toml:"listen_host"`
+	ListenPort int    `toml:"listen_port"`
+	AuthToken  string `toml:"auth_token"`
+}
+
+// 設定ファイルのdashboardセクション
+type dashboardSection struct {
+	ListenHost   string `toml:"listen_host"`
+	ListenPort   int    `toml:"listen_port"`
+	AuthUser     string `toml:"auth_user"`
+	AuthPassword string `toml:"auth_password"`
+	RefreshSec   int    `toml:"refresh_sec"`
+}
+
+// 設定ファイルのnotifyセクション
+type notifySection struct {
+	Status        []string `toml:"status"`
+	TimeoutSec    int      `toml:"timeout_sec"`
+	Command       string   `toml:"command"`
+	CommandStatus []string `toml:"command_status"`
+	WebhookURL    string   `toml:"webhook_url"`
+	WebhookStatus []string `toml:"webhook_status"`
+	Syslog        int      `toml:"syslog"`
+	SyslogNetwork string   `toml:"syslog_network"`
+	SyslogAddr    string   `toml:"syslog_addr"`
+	SyslogStatus  []string `toml:"syslog_status"`
+	SMTPServer    string   `toml:"smtp_server"`
+	SMTPUser      string   `toml:"smtp_user"`
+	SMTPPassword  string   `toml:"smtp_password"`
+	MailFrom      string   `toml:"mail_from"`
+	MailTo        []string `toml:"mail_to"`
+	MailStatus    []string `toml:"mail_status"`
+}
+
+// notifyセクションのstatus系キーに指定できる値
+var notifyStatuses = []string{"normal", "warn", "abnormal", "cancelled"}
+
 const tag_CUTOROOT = "<CUTOROOT>"
 
 var Dir = new(dirSection)
 var Job = new(jobSection)
 var DB = new(dbSection)
 var Log = new(logSection)
+var Sec = new(securitySection)
+var API = new(apiSection)
+var Dashboard = new(dashboardSection)
+var Notify = new(notifySection)
 
 // 設定ファイルをロードする。
 //
@@ -73,6 +136,13 @@ func Load(filePath string) error {
 func loadReader(reader io.Reader) error {
 	c := new(config)
 	c.Job.AttemptLimit = 1
+	c.API.ListenHost = "127.0.0.1"
+	c.API.ListenPort = 2016
+	c.Dsb.ListenHost = "127.0.0.1"
+	c.Dsb.ListenPort = 2017
+	c.Dsb.RefreshSec = 5
+	c.Ntf.Status = []string{"abnormal"}
+	c.Ntf.TimeoutSec = 10
 	if _, err := toml.DecodeReader(reader, c); err != nil {
 		return err
 	}
@@ -83,6 +153,10 @@ func loadReader(reader io.Reader) error {
 	Job = &c.Job
 	DB = &c.DB
 	Log = &c.Log
+	Sec = &c.Sec
+	API = &c.API
+	Dashboard = &c.Dsb
+	Notify = &c.Ntf
 	return nil
 }
 
@@ -90,6 +164,10 @@ func replaceCutoroot(c *config) {
 	c.Dir.JobnetDir = strings.Replace(c.Dir.JobnetDir, tag_CUTOROOT, util.GetRootPath(), -1)
 	c.Dir.LogDir = strings.Replace(c.Dir.LogDir, tag_CUTOROOT, util.GetRootPath(), -1)
 	c.DB.DBFile = strings.Replace(c.DB.DBFile, tag_CUTOROOT, util.GetRootPath(), -1)
+	c.Sec.TLSCAFile = strings.Replace(c.Sec.TLSCAFile, tag_CUTOROOT, util.GetRootPath(), -1)
+	c.Sec.TLSCertFile = strings.Replace(c.Sec.TLSCertFile, tag_CUTOROOT, util.GetRootPath(), -1)
+	c.Sec.TLSKeyFile = strings.Replace(c.Sec.TLSKeyFile, tag_CUTOROOT, util.GetRootPath(), -1)
+	c.Ntf.Command = strings.Replace(c.Ntf.Command, tag_CUTOROOT, util.GetRootPath(), -1)
 }
 
 // 設定値のエラー検出を行う。
@@ -111,6 +189,35 @@ func DetectError() error {
 	if Job.AttemptLimit <= 0 {
 		return fmt.Errorf("job.attempt_limit(%d) must not be 0 or less.", Job.AttemptLimit)
 	}
+	if (Sec.TLSCertFile == "") != (Sec.TLSKeyFile == "") {
+		return fmt.Errorf("security.tls_cert_file and security.tls_key_file must be set together.")
+	}
+	if API.ListenPort < 1 || 65535 < API.ListenPort {
+		return fmt.Errorf("api.listen_port(%d) must be within the range 1 and 65535.", API.ListenPort)
+	}
+	if Dashboard.ListenPort < 1 || 65535 < Dashboard.ListenPort {
+		return fmt.Errorf("dashboard.listen_port(%d) must be within the range 1 and 65535.", Dashboard.ListenPort)
+	}
+	if Dashboard.RefreshSec <= 0 {
+		return fmt.Errorf("dashboard.refresh_sec(%d) must not be 0 or less.", Dashboard.RefreshSec)
+	}
+	if Notify.TimeoutSec <= 0 {
+		return fmt.Errorf("notify.timeout_sec(%d) must not be 0 or less.", Notify.TimeoutSec)
+	}
+	for key, statuses := range map[string][]string{
+		"status":         Notify.Status,
+		"command_status": Notify.CommandStatus,
+		"webhook_status": Notify.WebhookStatus,
+		"syslog_status":  Notify.SyslogStatus,
+		"mail_status":    Notify.MailStatus,
+	} {
+		if err := detectNotifyStatusError(key, statuses); err != nil {
+			return err
+		}
+	}
+	if Notify.SMTPServer != "" && (Notify.MailFrom == "" || len(Notify.MailTo) == 0) {
+		return fmt.Errorf("notify.mail_from and notify.mail_to must be set with notify.smtp_server.")
+	}
 	if Log.MaxSizeKB <= 0 {
 		return fmt.Errorf("log.max_size_kb(%d) must not be 0 or less.", Log.MaxSizeKB)
 	}
@@ -120,3 +227,82 @@ func DetectError() error {
 
 	return nil
 }
+
+// Apiコマンドの起動時に、apiセクションの設定値のエラー検出を行う。
+// auth_tokenが空の場合は、ループバックアドレス以外で待ち受けることを許可しない。
+//
+// return : エラー情報
+func DetectAPIError() error {
+	if API.AuthToken == "" && !isLoopbackHost(API.ListenHost) {
+		return fmt.Errorf("api.auth_token must be set to listen on non-loopback address [%s].", API.ListenHost)
+	}
+	return nil
+}
+
+// Dashboardコマンドの起動時に、dashboardセクションの設定値のエラー検出を行う。
+// auth_userが空の場合は、ループバックアドレス以外で待ち受けることを許可しない。
+//
+// return : エラー情報
+func DetectDashboardError() error {
+	if Dashboard.AuthUser == "" && !isLoopbackHost(Dashboard.ListenHost) {
+		return fmt.Errorf("dashboard.auth_user must be set to listen on non-loopback address [%s].", Dashboard.ListenHost)
+	}
+	return nil
+}
+
+// ホスト名がループバックアドレスを表すかを返す。空文字列は全てのアドレスを表すため、falseを返す。
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func detectNotifyStatusError(key string, statuses []string) error {
+	for _, st := range statuses {
+		valid := false
+		for _, v := range notifyStatuses {
+			if st == v {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("notify.%s(%s) must be one of %s.", key, st, strings.Join(notifyStatuses, ", "))
+		}
+	}
+	return nil
+}
+
+// securityセクションの設定値を元に、servantへ接続する際のTLS設定を生成する。
+// use_tlsが0の場合はnilを返す。
+//
+// return : TLS設定。
+//
+// return : エラー情報。
+func TLSConfig() (*tls.Config, error) {
+	if Sec.UseTLS == 0 {
+		return nil, nil
+	}
+
+	tlsConf := new(tls.Config)
+	if Sec.TLSCAFile != "" {
+		pem, err := ioutil.ReadFile(Sec.TLSCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConf.RootCAs = x509.NewCertPool()
+		if !tlsConf.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("No certificate found in tls_ca_file[%s].", Sec.TLSCAFile)
+		}
+	}
+	if Sec.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(Sec.TLSCertFile, Sec.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConf.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConf, nil
+}
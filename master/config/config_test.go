@@ -18,6 +18,22 @@ func generateTestConfig() {
 	Log.OutputLevel = `info`
 	Log.MaxSizeKB = 1
 	Log.MaxGeneration = 1
+	Sec.UseTLS = 0
+	Sec.TLSCAFile = ``
+	Sec.TLSCertFile = ``
+	Sec.TLSKeyFile = ``
+	Sec.AuthSecret = ``
+	API.ListenHost = `127.0.0.1`
+	API.ListenPort = 2016
+	API.AuthToken = ``
+	Dashboard.ListenHost = `127.0.0.1`
+	Dashboard.ListenPort = 2017
+	Dashboard.AuthUser = ``
+	Dashboard.AuthPassword = ``
+	Dashboard.RefreshSec = 5
+	Notify = new(notifySection)
+	Notify.Status = []string{"abnormal"}
+	Notify.TimeoutSec = 10
 }
 
 func TestLoad_存在しないファイルをロードしようとした場合はエラー(t *testing.T) {
@@ -273,3 +289,254 @@ func TestDetectError_ログファイル最大世代数が0以下の場合はエ
 		t.Error("エラーが発生しなかった。")
 	}
 }
+
+func TestDetectError_クライアント証明書と秘密鍵の片方だけが指定された場合はエラー(t *testing.T) {
+	generateTestConfig()
+	Sec.TLSCertFile = `client.crt`
+	if err := DetectError(); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestDetectError_APIのポート番号が範囲外の場合はエラー(t *testing.T) {
+	for _, port := range []int{0, 65536} {
+		generateTestConfig()
+		API.ListenPort = port
+		if err := DetectError(); err == nil {
+			t.Errorf("ポート番号[%d]でエラーが発生しなかった。", port)
+		}
+	}
+}
+
+func TestLoadByReader_apiセクションの設定値を取得できる(t *testing.T) {
+	conf := `
+[job]
+connection_timeout_sec=60
+
+[api]
+listen_host='127.0.0.1'
+auth_token='token'
+`
+
+	if err := loadReader(strings.NewReader(conf)); err != nil {
+		t.Fatalf("想定外のエラーが発生した[%s]", err)
+	}
+	if API.ListenHost != "127.0.0.1" {
+		t.Errorf("listen_hostの値[%s]は想定と違っている。", API.ListenHost)
+	}
+	if API.ListenPort != 2016 {
+		t.Errorf("listen_portのデフォルト値[%d]は想定と違っている。", API.ListenPort)
+	}
+	if API.AuthToken != "token" {
+		t.Errorf("auth_tokenの値[%s]は想定と違っている。", API.AuthToken)
+	}
+}
+
+func TestLoadByReader_apiセクションの待ち受けアドレスのデフォルト値はループバックアドレス(t *testing.T) {
+	conf := `
+[job]
+connection_timeout_sec=60
+`
+
+	if err := loadReader(strings.NewReader(conf)); err != nil {
+		t.Fatalf("想定外のエラーが発生した[%s]", err)
+	}
+	if API.ListenHost != "127.0.0.1" {
+		t.Errorf("listen_hostのデフォルト値[%s]は想定と違っている。", API.ListenHost)
+	}
+	if err := DetectAPIError(); err != nil {
+		t.Errorf("想定外のエラーが発生した[%s]", err)
+	}
+}
+
+func TestDetectAPIError_認証トークンが無い場合はループバックアドレス以外で待ち受けられない(t *testing.T) {
+	generateTestConfig()
+	for _, host := range []string{"", "0.0.0.0", "192.168.0.1", "example.com"} {
+		API.ListenHost = host
+		if err := DetectAPIError(); err == nil {
+			t.Errorf("待ち受けアドレス[%s]でエラーが発生しなかった。", host)
+		}
+	}
+	for _, host := range []string{"localhost", "127.0.0.1", "::1"} {
+		API.ListenHost = host
+		if err := DetectAPIError(); err != nil {
+			t.Errorf("待ち受けアドレス[%s]で想定外のエラーが発生した[%s]", host, err)
+		}
+	}
+
+	API.ListenHost = ""
+	API.AuthToken = "token"
+	if err := DetectAPIError(); err != nil {
+		t.Errorf("認証トークンがある場合に想定外のエラーが発生した[%s]", err)
+	}
+}
+
+func TestDetectError_ダッシュボードの設定値が範囲外の場合はエラー(t *testing.T) {
+	generateTestConfig()
+	Dashboard.ListenPort = 0
+	if err := DetectError(); err == nil {
+		t.Error("ポート番号が0でエラーが発生しなかった。")
+	}
+
+	generateTestConfig()
+	Dashboard.RefreshSec = 0
+	if err := DetectError(); err == nil {
+		t.Error("更新間隔が0でエラーが発生しなかった。")
+	}
+}
+
+func TestDetectDashboardError_認証ユーザが無い場合はループバックアドレス以外で待ち受けられない(t *testing.T) {
+	generateTestConfig()
+	for _, host := range []string{"", "0.0.0.0", "192.168.0.1"} {
+		Dashboard.ListenHost = host
+		if err := DetectDashboardError(); err == nil {
+			t.Errorf("待ち受けアドレス[%s]でエラーが発生しなかった。", host)
+		}
+	}
+	for _, host := range []string{"localhost", "127.0.0.1", "::1"} {
+		Dashboard.ListenHost = host
+		if err := DetectDashboardError(); err != nil {
+			t.Errorf("待ち受けアドレス[%s]で想定外のエラーが発生した[%s]", host, err)
+		}
+	}
+
+	Dashboard.ListenHost = ""
+	Dashboard.AuthUser = "admin"
+	if err := DetectDashboardError(); err != nil {
+		t.Errorf("認証ユーザがある場合に想定外のエラーが発生した[%s]", err)
+	}
+}
+
+func TestLoadByReader_dashboardセクションの設定値を取得できる(t *testing.T) {
+	conf := `
+[job]
+connection_timeout_sec=60
+
+[dashboard]
+listen_port=8080
+auth_user='admin'
+auth_password='pass'
+`
+
+	if err := loadReader(strings.NewReader(conf)); err != nil {
+		t.Fatalf("想定外のエラーが発生した[%s]", err)
+	}
+	if Dashboard.ListenPort != 8080 {
+		t.Errorf("listen_portの値[%d]は想定と違っている。", Dashboard.ListenPort)
+	}
+	if Dashboard.AuthUser != "admin" || Dashboard.AuthPassword != "pass" {
+		t.Errorf("auth_user、auth_passwordの値[%s/%s]は想定と違っている。", Dashboard.AuthUser, Dashboard.AuthPassword)
+	}
+	if Dashboard.RefreshSec != 5 {
+		t.Errorf("refresh_secのデフォルト値[%d]は想定と違っている。", Dashboard.RefreshSec)
+	}
+	if Dashboard.ListenHost != "127.0.0.1" {
+		t.Errorf("listen_hostのデフォルト値[%s]は想定と違っている。", Dashboard.ListenHost)
+	}
+}
+
+func TestLoadByReader_securityセクションの設定値を取得できる(t *testing.T) {
+	conf := `
+[job]
+connection_timeout_sec=60
+
+[security]
+use_tls=1
+tls_ca_file='<CUTOROOT>/ca.crt'
+auth_secret='secret'
+`
+
+	if err := loadReader(strings.NewReader(conf)); err != nil {
+		t.Fatalf("想定外のエラーが発生した[%s]", err)
+	}
+	if Sec.UseTLS != 1 {
+		t.Errorf("use_tlsの値[%d]は想定と違っている。", Sec.UseTLS)
+	}
+	if strings.Contains(Sec.TLSCAFile, "<CUTOROOT>") || !strings.HasSuffix(Sec.TLSCAFile, "ca.crt") {
+		t.Errorf("tls_ca_fileの値[%s]は想定と違っている。", Sec.TLSCAFile)
+	}
+	if Sec.AuthSecret != "secret" {
+		t.Errorf("auth_secretの値[%s]は想定と違っている。", Sec.AuthSecret)
+	}
+}
+
+func TestTLSConfig_TLSを利用しない場合はnilを返す(t *testing.T) {
+	generateTestConfig()
+	tlsConf, err := TLSConfig()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した： %s", err)
+	}
+	if tlsConf != nil {
+		t.Error("TLS設定が生成された。")
+	}
+}
+
+func TestTLSConfig_CA証明書が読み込めない場合はエラー(t *testing.T) {
+	generateTestConfig()
+	Sec.UseTLS = 1
+	Sec.TLSCAFile = `noexistfilepath`
+	if _, err := TLSConfig(); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestDetectError_通知の設定値が不正な場合はエラー(t *testing.T) {
+	generateTestConfig()
+	Notify.TimeoutSec = 0
+	if err := DetectError(); err == nil {
+		t.Error("タイムアウト時間が0でエラーが発生しなかった。")
+	}
+
+	generateTestConfig()
+	Notify.WebhookStatus = []string{"abnormal", "running"}
+	if err := DetectError(); err == nil {
+		t.Error("不正なステータスでエラーが発生しなかった。")
+	}
+
+	generateTestConfig()
+	Notify.SMTPServer = "localhost:25"
+	Notify.MailFrom = "cuto@example.com"
+	if err := DetectError(); err == nil {
+		t.Error("mail_toが未設定でエラーが発生しなかった。")
+	}
+
+	Notify.MailTo = []string{"admin@example.com"}
+	if err := DetectError(); err != nil {
+		t.Errorf("想定外のエラーが発生した[%s]", err)
+	}
+}
+
+func TestLoadByReader_notifyセクションの設定値を取得できる(t *testing.T) {
+	conf := `
+[job]
+connection_timeout_sec=60
+
+[notify]
+webhook_url='http://localhost/hook'
+webhook_status=['abnormal','warn']
+syslog=1
+mail_to=['admin@example.com','ops@example.com']
+`
+
+	if err := loadReader(strings.NewReader(conf)); err != nil {
+		t.Fatalf("想定外のエラーが発生した[%s]", err)
+	}
+	if len(Notify.Status) != 1 || Notify.Status[0] != "abnormal" {
+		t.Errorf("statusのデフォルト値%vは想定と違っている。", Notify.Status)
+	}
+	if Notify.TimeoutSec != 10 {
+		t.Errorf("timeout_secのデフォルト値[%d]は想定と違っている。", Notify.TimeoutSec)
+	}
+	if Notify.WebhookURL != "http://localhost/hook" {
+		t.Errorf("webhook_urlの値[%s]は想定と違っている。", Notify.WebhookURL)
+	}
+	if len(Notify.WebhookStatus) != 2 || Notify.WebhookStatus[1] != "warn" {
+		t.Errorf("webhook_statusの値%vは想定と違っている。", Notify.WebhookStatus)
+	}
+	if Notify.Syslog != 1 {
+		t.Errorf("syslogの値[%d]は想定と違っている。", Notify.Syslog)
+	}
+	if len(Notify.MailTo) != 2 {
+		t.Errorf("mail_toの値%vは想定と違っている。", Notify.MailTo)
+	}
+}
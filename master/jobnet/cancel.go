@@ -0,0 +1,126 @@
+package jobnet
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/unirita/cuto/console"
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/db/tx"
+	"github.com/unirita/cuto/master/config"
+	"github.com/unirita/cuto/master/remote"
+	"github.com/unirita/cuto/message"
+	"github.com/unirita/cuto/utctime"
+)
+
+// 実行中止されたジョブネットワークに記録する詳細メッセージ
+const detailCancel = "Cancelled by request."
+
+// ジョブネットワークの実行中止要求を受け付け、実行中のジョブの中止をservantへ要求する。
+// 以降、新たなジョブは実行されない。
+func (n *Network) Cancel() {
+	n.localMutex.Lock()
+	if !n.cancelled {
+		n.cancelled = true
+		close(n.cancelCh)
+	}
+	running := n.snapshotRunningJobs()
+	n.localMutex.Unlock()
+
+	console.Display("CTM033I", n.Name, n.ID)
+	for _, jobres := range running {
+		send := sendFunc(remote.SendRequest)
+		if j, ok := n.elements[jobres.JobId].(*Job); ok {
+			send = j.sendRequest
+		}
+		if err := sendCancel(send, n.ID, jobres); err != nil {
+			console.Display("CTM035W", jobres.JobName, jobres.Node, n.ID, jobres.JobId, err)
+		}
+	}
+}
+
+// 実行中のジョブについて、中止要求の送信に必要な情報の写しを取得する。
+// ジョブの状態はジョブ側のゴルーチンが更新するため、n.localMutexを取得した状態で呼び出すこと。
+//
+// return : 実行中のジョブ実行結果の写し。
+func (n *Network) snapshotRunningJobs() []*db.JobResult {
+	if n.Result == nil {
+		return nil
+	}
+
+	var running []*db.JobResult
+	for _, jobres := range n.Result.GetRunningJobResults() {
+		snapshot := new(db.JobResult)
+		snapshot.JobId = jobres.JobId
+		snapshot.JobName = jobres.JobName
+		snapshot.Node = jobres.Node
+		snapshot.Port = jobres.Port
+		running = append(running, snapshot)
+	}
+	return running
+}
+
+// ジョブネットワークが実行中止されたかを調べる。
+func (n *Network) IsCancelled() bool {
+	n.localMutex.Lock()
+	defer n.localMutex.Unlock()
+	return n.cancelled
+}
+
+// 実行中のmasterプロセスが存在しないジョブネットワークを強制的に実行中止状態にする。
+// 実行中のまま残っているジョブは、servantへ中止を要求した上で実行中止状態とする。
+//
+// param : nid ジョブネットワークのインスタンスID。
+//
+// return : エラー情報。
+func ForceCancel(nid int) error {
+	result, err := tx.ResumeJobNetwork(nid, config.DB.DBFile)
+	if err != nil {
+		return err
+	}
+
+	var mutex sync.Mutex
+	for _, jobres := range result.GetRunningJobResults() {
+		if err := sendCancel(remote.SendRequest, nid, jobres); err != nil {
+			console.Display("CTM035W", jobres.JobName, jobres.Node, nid, jobres.JobId, err)
+		}
+		jobres.Status = db.CANCELLED
+		jobres.Detail = detailCancel
+		jobres.EndDate = utctime.Now().String()
+		if err := tx.UpdateJob(result.GetConnection(), jobres, &mutex); err != nil {
+			return err
+		}
+	}
+
+	return result.EndJobNetwork(db.CANCELLED, detailCancel)
+}
+
+// ジョブの実行中止要求をservantへ送信する。
+func sendCancel(send sendFunc, nid int, jobres *db.JobResult) error {
+	cnl := new(message.Cancel)
+	cnl.NID = nid
+	cnl.JID = jobres.JobId
+
+	cnlMsg, err := cnl.GenerateJSON()
+	if err != nil {
+		return err
+	}
+
+	stCh := make(chan string, 1)
+	defer close(stCh)
+
+	host, _, _ := explodeNodeString(jobres.Node)
+	resultMsg, err := send(host, jobres.Port, cnlMsg, stCh, nil)
+	if err != nil {
+		return err
+	}
+
+	result := new(message.CancelResult)
+	if err := result.ParseJSON(resultMsg); err != nil {
+		return err
+	}
+	if !result.Cancelled {
+		return fmt.Errorf("%s", result.Detail)
+	}
+	return nil
+}
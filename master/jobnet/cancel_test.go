@@ -0,0 +1,141 @@
+package jobnet
+
+import (
+	"testing"
+
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/master/config"
+	"github.com/unirita/cuto/message"
+)
+
+func testSendRequest_Cancelled(host string, port int, reqMsg string, stCh chan<- string, outCh chan<- string) (string, error) {
+	res := new(message.Response)
+	res.RC = 0
+	res.Stat = db.CANCELLED
+	res.Detail = "JOB was cancelled."
+	res.St = "2015-04-01 12:34:56.789"
+	res.Et = "2015-04-01 12:35:46.123"
+
+	resMsg, _ := res.GenerateJSON()
+	return resMsg, nil
+}
+
+func TestNetworkCancel_実行中のジョブへ中止要求を送信する(t *testing.T) {
+	n := newTestNetwork()
+	j1, _ := NewJob("jobid1", "job1", n)
+	j1.Node = "testnode"
+	j1.Port = 1234
+	n.elements[j1.id] = j1
+	j1.start()
+
+	var sentMsg string
+	j1.sendRequest = func(host string, port int, reqMsg string, stCh chan<- string, outCh chan<- string) (string, error) {
+		sentMsg = reqMsg
+		result := message.CancelResult{NID: n.ID, JID: j1.id, Cancelled: true}
+		return result.GenerateJSON()
+	}
+
+	n.Cancel()
+	if !n.IsCancelled() {
+		t.Error("ジョブネットワークが実行中止状態になっていない。")
+	}
+
+	cnl := new(message.Cancel)
+	if err := cnl.ParseJSON(sentMsg); err != nil {
+		t.Fatalf("中止要求メッセージが送信されなかった: %s", err)
+	}
+	if cnl.JID != j1.id {
+		t.Errorf("中止要求メッセージのJID[%s]が想定と違っている。", cnl.JID)
+	}
+}
+
+func TestJobExecute_実行中止されたネットワークではジョブを実行しない(t *testing.T) {
+	n := newTestNetwork()
+	j1, _ := NewJob("jobid1", "job1", n)
+	isSent := false
+	j1.sendRequest = func(host string, port int, reqMsg string, stCh chan<- string, outCh chan<- string) (string, error) {
+		isSent = true
+		return testSendRequest_Normal(host, port, reqMsg, stCh, outCh)
+	}
+
+	n.Cancel()
+	if _, err := j1.Execute(); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+	if isSent {
+		t.Error("実行中止されたにも関わらず、リクエストが送信された。")
+	}
+}
+
+func TestJobExecute_ジョブが実行中止されたケース(t *testing.T) {
+	config.Job.AttemptLimit = 1
+	n := newTestNetwork()
+	j1, _ := NewJob("jobid1", "job1", n)
+	j2, _ := NewJob("jobid2", "job2", n)
+	j1.Node = "testnode"
+	j1.Port = 1234
+	j1.Next = j2
+
+	j1.sendRequest = testSendRequest_Cancelled
+	next, err := j1.Execute()
+	if err == nil {
+		t.Fatal("エラーが発生しなかった。")
+	}
+	if next != nil {
+		t.Errorf("nilが返される想定に対し、ノード[%s]が返された。", next.ID())
+	}
+
+	jobres, ok := n.Result.GetJobResults(j1.id)
+	if !ok {
+		t.Fatal("ジョブ実行結果がセットされなかった。")
+	}
+	if jobres.Status != db.CANCELLED {
+		t.Errorf("ジョブ実行結果のStatus[%d]は想定と違っている。", jobres.Status)
+	}
+}
+
+func TestNetworkCancel_実行中のジョブと並行して中止要求を送信する(t *testing.T) {
+	config.Job.AttemptLimit = 1
+	n := newTestNetwork()
+	j1, _ := NewJob("jobid1", "job1", n)
+	j1.Node = "testnode"
+	j1.Port = 1234
+	n.elements[j1.id] = j1
+
+	startCh := make(chan struct{})
+	cancelCh := make(chan struct{})
+	j1.sendRequest = func(host string, port int, reqMsg string, stCh chan<- string, outCh chan<- string) (string, error) {
+		cnl := new(message.Cancel)
+		if err := cnl.ParseJSON(reqMsg); err == nil {
+			close(cancelCh)
+			result := message.CancelResult{NID: n.ID, JID: j1.id, Cancelled: true}
+			return result.GenerateJSON()
+		}
+		close(startCh)
+		<-cancelCh
+		return testSendRequest_Cancelled(host, port, reqMsg, stCh, outCh)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := j1.Execute()
+		errCh <- err
+	}()
+
+	<-startCh
+	n.Cancel()
+	if err := <-errCh; err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+
+	jobres, ok := n.Result.GetJobResults(j1.id)
+	if !ok {
+		t.Fatal("ジョブ実行結果がセットされなかった。")
+	}
+	if jobres.Status != db.CANCELLED {
+		t.Errorf("ジョブ実行結果のStatus[%d]は想定と違っている。", jobres.Status)
+	}
+	if running := n.Result.GetRunningJobResults(); len(running) != 0 {
+		t.Errorf("実行中のジョブ数[%d]が想定と違っている。", len(running))
+	}
+}
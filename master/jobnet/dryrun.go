@@ -0,0 +1,193 @@
+package jobnet
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/unirita/cuto/message"
+	"github.com/unirita/cuto/utctime"
+)
+
+// ドライランの出力で、分岐経路ごとに付与するインデント
+const dryRunIndent = "    "
+
+// ドライランの実行状態を保持する構造体
+type dryRunner struct {
+	n *Network
+	w io.Writer
+}
+
+// ジョブネットワークをドライランする。
+// servantへの接続およびDBへの書き込みは行わず、実行順に各ジョブが送信するリクエストの内容をwへ出力する。
+// 並列分岐は分岐経路ごとに、排他分岐は条件に関わらず全ての分岐経路を出力する。
+// 事前にDetectFlowErrorでフローの構造を検証しておく必要がある。
+//
+// param : w 出力先。
+//
+// return : エラー情報。
+func (n *Network) DryRun(w io.Writer) error {
+	now := utctime.Now().String()
+	message.AddSysValue(`JOBNET`, `SD`, now)
+	n.setCalendarValues(now)
+
+	d := &dryRunner{n: n, w: w}
+	fmt.Fprintf(w, "DRY RUN OF JOBNET [%s]. BASE DATE [%s]\n", n.Name, n.baseDate.Format(utctime.Date8Num))
+	_, err := d.walk(n.Start, "", false, false)
+	return err
+}
+
+// エレメントeから実行順にフローをたどり、ジョブのリクエスト内容を出力する。
+// stopAtJoinが真の場合は並列分岐の結合ゲートウェイに、stopAtMergeが真の場合は合流する排他ゲートウェイに到達した時点で、そのゲートウェイを返す。
+//
+// param : e 開始エレメント。
+//
+// param : indent 出力時のインデント。
+//
+// param : stopAtJoin 結合ゲートウェイで終了するかどうか。
+//
+// param : stopAtMerge 排他ゲートウェイで終了するかどうか。
+//
+// return : 到達したゲートウェイ。エンドイベントに到達した場合はnil。
+//
+// return : エラー情報。
+func (d *dryRunner) walk(e Element, indent string, stopAtJoin, stopAtMerge bool) (Element, error) {
+	// 直前に処理した分岐の合流点であるかどうか
+	passed := false
+	for {
+		if e == nil {
+			return nil, fmt.Errorf("Network is terminated before endEvent.")
+		}
+
+		var nexts []Element
+		switch elm := e.(type) {
+		case *Job:
+			d.writeJob(elm, indent)
+			nexts = []Element{elm.Next}
+			if elm.Next == nil {
+				nexts = nil
+			}
+		case *Gateway:
+			if stopAtJoin && !passed && !elm.isSplit() {
+				return elm, nil
+			}
+			if len(elm.Nexts) > 1 {
+				join, err := d.walkParallel(elm, indent)
+				if err != nil {
+					return nil, err
+				}
+				e, passed = join, true
+				continue
+			}
+			nexts = elm.Nexts
+		case *ExclusiveGateway:
+			if stopAtMerge && !passed && !elm.isSplit() {
+				return elm, nil
+			}
+			if len(elm.Nexts) > 1 {
+				merge, err := d.walkExclusive(elm, indent)
+				if err != nil {
+					return nil, err
+				}
+				e, passed = merge, true
+				continue
+			}
+			nexts = elm.Nexts
+		default:
+			return nil, fmt.Errorf("Unknown element[id = %s].", e.ID())
+		}
+
+		passed = false
+		if e == d.n.End {
+			return nil, nil
+		} else if len(nexts) == 0 {
+			return nil, fmt.Errorf("Element[id = %s] cannot terminate network because it is not a endEvent.", e.ID())
+		}
+		e = nexts[0]
+	}
+}
+
+// 並列分岐の各経路をたどり、結合ゲートウェイを返す。
+func (d *dryRunner) walkParallel(g *Gateway, indent string) (Element, error) {
+	var join Element
+	for i, next := range g.Nexts {
+		fmt.Fprintf(d.w, "%sPARALLEL BRANCH [%d/%d] OF GATEWAY [%s]\n", indent, i+1, len(g.Nexts), g.ID())
+		goal, err := d.walk(next, indent+dryRunIndent, true, false)
+		if err != nil {
+			return nil, err
+		}
+		if goal == nil {
+			return nil, fmt.Errorf("Branch of gateway[id = %s] is not joined.", g.ID())
+		}
+		if join == nil {
+			join = goal
+		} else if join != goal {
+			return nil, fmt.Errorf("Branches of gateway[id = %s] are joined at different gateways.", g.ID())
+		}
+	}
+	return join, nil
+}
+
+// 排他分岐の全ての経路をたどり、合流する排他ゲートウェイを返す。
+func (d *dryRunner) walkExclusive(g *ExclusiveGateway, indent string) (Element, error) {
+	var merge Element
+	for i, next := range g.Nexts {
+		cond := "NO CONDITION"
+		if g.isDefault(i) {
+			cond = "DEFAULT"
+		} else if g.Conditions[i] != nil {
+			cond = fmt.Sprintf("IF [%s]", g.Conditions[i])
+		}
+		fmt.Fprintf(d.w, "%sEXCLUSIVE BRANCH [%s] OF GATEWAY [%s] %s\n", indent, g.FlowIDs[i], g.ID(), cond)
+		goal, err := d.walk(next, indent+dryRunIndent, false, true)
+		if err != nil {
+			return nil, err
+		}
+		if goal == nil {
+			return nil, fmt.Errorf("Branch of gateway[id = %s] is not merged.", g.ID())
+		}
+		if merge == nil {
+			merge = goal
+		} else if merge != goal {
+			return nil, fmt.Errorf("Branches of gateway[id = %s] are merged at different gateways.", g.ID())
+		}
+	}
+	return merge, nil
+}
+
+// ジョブが送信するリクエストの内容を出力する。
+func (d *dryRunner) writeJob(j *Job, indent string) {
+	req := j.createRequest()
+	unresolved := req.ExpandMasterVarsPartially()
+	host, _, container := explodeNodeString(j.Node)
+
+	fmt.Fprintf(d.w, "%sJOB [%s] ID [%s]\n", indent, j.Name, j.ID())
+	item := func(name string, value interface{}) {
+		fmt.Fprintf(d.w, "%s%s%-10s : %v\n", indent, dryRunIndent, name, value)
+	}
+	item("NODE", host)
+	if container != "" {
+		item("CONTAINER", container)
+	}
+	item("PORT", j.Port)
+	if j.SecondaryNode != "" {
+		item("SECONDARY", fmt.Sprintf("%s:%d", j.SecondaryNode, j.SecondaryPort))
+	}
+	item("PATH", req.Path)
+	item("PARAM", req.Param)
+	item("ENV", req.Env)
+	item("WORKSPACE", req.Workspace)
+	item("TIMEOUT", fmt.Sprintf("%d SEC", req.Timeout))
+	item("WARN", fmt.Sprintf("RC >= %d, PATTERN [%s]", req.WarnRC, req.WarnStr))
+	item("ERROR", fmt.Sprintf("RC >= %d, PATTERN [%s]", req.ErrRC, req.ErrStr))
+	if j.RetryMax > 0 {
+		item("RETRY", fmt.Sprintf("%d TIMES, DELAY [%d SEC]", j.RetryMax, j.RetryDelaySec))
+	}
+	if !j.isRunDay() {
+		item("RUNDAY", fmt.Sprintf("[%s] IS NOT A RUN DAY [%s]. JOB WILL BE SKIPPED.",
+			j.Instance.baseDate.Format(utctime.Date8Num), j.RunDay))
+	}
+	if len(unresolved) > 0 {
+		item("UNRESOLVED", strings.Join(unresolved, " "))
+	}
+}
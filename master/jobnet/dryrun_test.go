@@ -0,0 +1,150 @@
+package jobnet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const dryRunTestBPMN = `
+<definitions>
+	<process>
+		<startEvent id="start"/>
+		<endEvent id="end"/>
+		<serviceTask id="j1" name="job1"/>
+		<serviceTask id="j2" name="job2"/>
+		<serviceTask id="j3" name="job3"/>
+		<serviceTask id="j4" name="job4"/>
+		<parallelGateway id="gw1"/>
+		<parallelGateway id="gw2"/>
+		<exclusiveGateway id="xgw1" default="flow9"/>
+		<exclusiveGateway id="xgw2"/>
+		<sequenceFlow id="flow1" sourceRef="start" targetRef="j1"/>
+		<sequenceFlow id="flow2" sourceRef="j1" targetRef="gw1"/>
+		<sequenceFlow id="flow3" sourceRef="gw1" targetRef="j2"/>
+		<sequenceFlow id="flow4" sourceRef="gw1" targetRef="j3"/>
+		<sequenceFlow id="flow5" sourceRef="j2" targetRef="gw2"/>
+		<sequenceFlow id="flow6" sourceRef="j3" targetRef="gw2"/>
+		<sequenceFlow id="flow7" sourceRef="gw2" targetRef="xgw1"/>
+		<sequenceFlow id="flow8" sourceRef="xgw1" targetRef="j4">
+			<conditionExpression><![CDATA[$MJjob1:RC$ != 0]]></conditionExpression>
+		</sequenceFlow>
+		<sequenceFlow id="flow9" sourceRef="xgw1" targetRef="xgw2"/>
+		<sequenceFlow id="flow10" sourceRef="j4" targetRef="xgw2"/>
+		<sequenceFlow id="flow11" sourceRef="xgw2" targetRef="end"/>
+	</process>
+</definitions>`
+
+func loadDryRunTestNetwork(t *testing.T) *Network {
+	n, _ := NewNetwork("dryrun")
+	if err := n.LoadElements(strings.NewReader(dryRunTestBPMN)); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if err := n.DetectFlowError(); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	for _, e := range n.elements {
+		if j, ok := e.(*Job); ok {
+			j.SetDefaultEx()
+		}
+	}
+	return n
+}
+
+func TestDryRun_実行順に全てのジョブのリクエスト内容を出力する(t *testing.T) {
+	n := loadDryRunTestNetwork(t)
+
+	buf := new(bytes.Buffer)
+	if err := n.DryRun(buf); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	output := buf.String()
+
+	expects := []string{
+		"DRY RUN OF JOBNET [dryrun].",
+		"JOB [job1] ID [j1]",
+		"PARALLEL BRANCH [1/2] OF GATEWAY [gw1]",
+		"    JOB [job2] ID [j2]",
+		"PARALLEL BRANCH [2/2] OF GATEWAY [gw1]",
+		"    JOB [job3] ID [j3]",
+		"EXCLUSIVE BRANCH [flow8] OF GATEWAY [xgw1] IF [$MJjob1:RC$ != 0]",
+		"    JOB [job4] ID [j4]",
+		"EXCLUSIVE BRANCH [flow9] OF GATEWAY [xgw1] DEFAULT",
+	}
+	pos := 0
+	for _, expect := range expects {
+		i := strings.Index(output[pos:], expect)
+		if i < 0 {
+			t.Fatalf("出力に[%s]が想定の順序で含まれていない。\n%s", expect, output)
+		}
+		pos += i + len(expect)
+	}
+}
+
+func TestDryRun_展開できない変数をそのまま出力する(t *testing.T) {
+	n := loadDryRunTestNetwork(t)
+	j := n.elements["j2"].(*Job)
+	j.Node = "testnode"
+	j.Port = 12345
+	j.Param = "$MSCAL:ISBIZDAY$ $MJdryrunundefined:RC$"
+
+	buf := new(bytes.Buffer)
+	if err := n.DryRun(buf); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	output := buf.String()
+
+	expects := []string{
+		"        NODE       : testnode",
+		"        PORT       : 12345",
+		"        UNRESOLVED : $MJdryrunundefined:RC$",
+	}
+	for _, expect := range expects {
+		if !strings.Contains(output, expect) {
+			t.Errorf("出力に[%s]が含まれていない。\n%s", expect, output)
+		}
+	}
+	if strings.Contains(output, "$MSCAL:ISBIZDAY$") {
+		t.Errorf("展開可能な変数が展開されていない。\n%s", output)
+	}
+}
+
+func TestDryRun_ネストした排他分岐の経路を出力する(t *testing.T) {
+	n, _ := NewNetwork("dryrun")
+	if err := n.setElements(generateNestedExclusiveTestProcess()); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if err := n.DetectFlowError(); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	for _, e := range n.elements {
+		if j, ok := e.(*Job); ok {
+			j.SetDefaultEx()
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := n.DryRun(buf); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	output := buf.String()
+
+	expects := []string{
+		"JOB [job1] ID [task1]",
+		"EXCLUSIVE BRANCH [flow3] OF GATEWAY [xgw1]",
+		"    EXCLUSIVE BRANCH [flow5] OF GATEWAY [xgw2]",
+		"        JOB [job2] ID [task2]",
+		"    EXCLUSIVE BRANCH [flow7] OF GATEWAY [xgw2] DEFAULT",
+		"        JOB [job3] ID [task3]",
+		"EXCLUSIVE BRANCH [flow4] OF GATEWAY [xgw1] DEFAULT",
+		"    JOB [job4] ID [task4]",
+	}
+	pos := 0
+	for _, expect := range expects {
+		i := strings.Index(output[pos:], expect)
+		if i < 0 {
+			t.Fatalf("出力に[%s]が想定の順序で含まれていない。\n%s", expect, output)
+		}
+		pos += i + len(expect)
+	}
+}
@@ -17,4 +17,5 @@ type elementType int
 const (
 	ELM_JOB elementType = iota
 	ELM_GW
+	ELM_XGW
 )
@@ -0,0 +1,169 @@
+package jobnet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/unirita/cuto/console"
+	"github.com/unirita/cuto/message"
+)
+
+// ジョブネット内の条件分岐・合流を表す構造体
+type ExclusiveGateway struct {
+	id          string
+	Nexts       []Element            // 後続エレメント
+	FlowIDs     []string             // 後続エレメントへのsequenceFlowのID
+	Conditions  []*message.Condition // 後続エレメントへ進む条件。条件が無い場合はnil。
+	DefaultFlow string               // どの条件も満たさない場合に進むsequenceFlowのID
+	prevCnt     int                  // 先行エレメントの数
+}
+
+// ExclusiveGateway構造体のコンストラクタ関数
+//
+// param : id ゲートウェイID。
+//
+// param : defaultFlow デフォルトフローのID。
+//
+// return : 排他ゲートウェイ構造体。
+func NewExclusiveGateway(id string, defaultFlow string) *ExclusiveGateway {
+	gateway := new(ExclusiveGateway)
+	gateway.id = id
+	gateway.DefaultFlow = defaultFlow
+	return gateway
+}
+
+// IDを取得する
+func (g *ExclusiveGateway) ID() string {
+	return g.id
+}
+
+// ノードタイプを取得する
+//
+// return : ノードタイプ
+func (g *ExclusiveGateway) Type() elementType {
+	return ELM_XGW
+}
+
+// 後続エレメントの追加を行う。
+func (g *ExclusiveGateway) AddNext(e Element) error {
+	return g.AddBranch(e, "", "")
+}
+
+// 分岐条件付きで後続エレメントの追加を行う。
+//
+// param : e 後続エレメント。
+//
+// param : flowID 後続エレメントへのsequenceFlowのID。
+//
+// param : condition 分岐条件式。条件が無い場合は空文字列。
+//
+// return : エラー情報。
+func (g *ExclusiveGateway) AddBranch(e Element, flowID string, condition string) error {
+	var cond *message.Condition
+	if strings.TrimSpace(condition) != "" {
+		var err error
+		cond, err = message.ParseCondition(condition)
+		if err != nil {
+			return fmt.Errorf("SequenceFlow[id = %s] has invalid condition: %s", flowID, err)
+		}
+	}
+
+	g.Nexts = append(g.Nexts, e)
+	countPrev(e)
+	g.FlowIDs = append(g.FlowIDs, flowID)
+	g.Conditions = append(g.Conditions, cond)
+	return nil
+}
+
+// 後続エレメントの有無を調べる。
+func (g *ExclusiveGateway) HasNext() bool {
+	return len(g.Nexts) > 0
+}
+
+// 先行エレメントの追加を記録する。
+func (g *ExclusiveGateway) addPrev() {
+	g.prevCnt++
+}
+
+// 分岐経路内でネストした分岐ゲートウェイであるかを調べる。
+// 先行エレメントが1つ以下で、後続エレメントが複数ある場合に分岐ゲートウェイとみなす。
+func (g *ExclusiveGateway) isSplit() bool {
+	return g.prevCnt <= 1 && len(g.Nexts) > 1
+}
+
+// i番目の後続エレメントがデフォルトフローであるかを調べる。
+func (g *ExclusiveGateway) isDefault(i int) bool {
+	return g.DefaultFlow != "" && g.FlowIDs[i] == g.DefaultFlow
+}
+
+// 後続のノード数に応じて、ゲートウェイの処理を行う。
+//
+// 後続ノードが存在しない場合：何も行わない。次の実行ノードとしてnilを返す。
+//
+// 後続ノードが1つだけの場合：何も行わない。次の実行ノードとして唯一の後続ノードを返す。
+//
+// 後続ノードが2つ以上の場合：定義順に分岐条件を評価し、最初に条件を満たした後続ノードを返す。どの条件も満たさない場合はデフォルトフローの後続ノードを返す。
+//
+// return : 次の実行ノード
+//
+// return : エラー情報
+func (g *ExclusiveGateway) Execute() (Element, error) {
+	switch len(g.Nexts) {
+	case 0:
+		return nil, nil
+	case 1:
+		return g.Nexts[0], nil
+	}
+
+	defaultIdx := -1
+	for i, cond := range g.Conditions {
+		if g.isDefault(i) {
+			defaultIdx = i
+			continue
+		}
+		if cond == nil {
+			continue
+		}
+
+		ok, err := cond.Evaluate()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to evaluate condition[%s] of sequenceFlow[id = %s]: %s", cond, g.FlowIDs[i], err)
+		}
+		if ok {
+			console.Display("CTM030I", g.id, g.FlowIDs[i], g.Nexts[i].ID())
+			return g.Nexts[i], nil
+		}
+	}
+
+	if defaultIdx < 0 {
+		return nil, fmt.Errorf("ExclusiveGateway[id = %s] has no sequenceFlow to go.", g.id)
+	}
+	console.Display("CTM030I", g.id, g.FlowIDs[defaultIdx], g.Nexts[defaultIdx].ID())
+	return g.Nexts[defaultIdx], nil
+}
+
+// 分岐条件の定義に誤りが無いかを検証する。
+//
+// return : エラー情報
+func (g *ExclusiveGateway) validateConditions() error {
+	hasDefault := false
+	conds := make([]*message.Condition, 0, len(g.Conditions))
+	for i, cond := range g.Conditions {
+		if g.isDefault(i) {
+			hasDefault = true
+			continue
+		}
+		if cond == nil {
+			return fmt.Errorf("SequenceFlow[id = %s] from exclusiveGateway[id = %s] has no condition.", g.FlowIDs[i], g.id)
+		}
+		conds = append(conds, cond)
+	}
+
+	if g.DefaultFlow != "" && !hasDefault {
+		return fmt.Errorf("Default flow[id = %s] of exclusiveGateway[id = %s] does not exist.", g.DefaultFlow, g.id)
+	}
+	if !hasDefault && !message.IsExhaustive(conds) {
+		return fmt.Errorf("ExclusiveGateway[id = %s] must have a default flow or conditions which cover all cases. Ranges of number are checked only for variables whose value is always an integer.", g.id)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package jobnet
+
+import (
+	"testing"
+
+	"github.com/unirita/cuto/message"
+)
+
+// ※補足事項
+// testJob構造体およびその生成関数generateTestJobはpath_test.goで定義されています。
+
+func addTestJobValue(name string, rc int) {
+	res := new(message.Response)
+	res.RC = rc
+	message.AddJobValue(name, res)
+}
+
+func TestNewExclusiveGateway_ゲートウェイのIDとデフォルトフローがセットされる(t *testing.T) {
+	g := NewExclusiveGateway("xgwid1", "flow1")
+	if g.ID() != "xgwid1" {
+		t.Errorf("セットされたID[%s]が想定と違っている。", g.ID())
+	}
+	if g.DefaultFlow != "flow1" {
+		t.Errorf("セットされたデフォルトフロー[%s]が想定と違っている。", g.DefaultFlow)
+	}
+	if g.Type() != ELM_XGW {
+		t.Errorf("取得したノードタイプ[%d]が想定と違っている。", g.Type())
+	}
+}
+
+func TestExclusiveGatewayAddBranch_分岐条件付きで後続エレメントを追加できる(t *testing.T) {
+	g := NewExclusiveGateway("xgwid1", "")
+	j1 := generateTestJob(1)
+	j2 := generateTestJob(2)
+
+	if err := g.AddBranch(j1, "flow1", "$MJjob0:RC$ == 0"); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if err := g.AddNext(j2); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+
+	if len(g.Nexts) != 2 {
+		t.Fatal("後続ジョブの追加に失敗した。")
+	}
+	if g.Nexts[0] != j1 || g.Nexts[1] != j2 {
+		t.Error("追加した後続ジョブが間違っている。")
+	}
+	if g.FlowIDs[0] != "flow1" {
+		t.Errorf("sequenceFlowのID[%s]が想定と違っている。", g.FlowIDs[0])
+	}
+	if g.Conditions[0] == nil || g.Conditions[0].String() != "$MJjob0:RC$ == 0" {
+		t.Errorf("分岐条件[%v]が想定と違っている。", g.Conditions[0])
+	}
+	if g.Conditions[1] != nil {
+		t.Errorf("分岐条件を指定していない後続ジョブに条件[%v]がセットされている。", g.Conditions[1])
+	}
+	if !g.HasNext() {
+		t.Error("後続エレメントがあるのにも関わらず、HasNextがfalseを返した")
+	}
+}
+
+func TestExclusiveGatewayAddBranch_分岐条件が不正な場合はエラー(t *testing.T) {
+	g := NewExclusiveGateway("xgwid1", "")
+	if err := g.AddBranch(generateTestJob(1), "flow1", "$MJjob0:RC$ = 0"); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestExclusiveGatewayExecute_後続ノードが1つのケース(t *testing.T) {
+	g := NewExclusiveGateway("xgwid1", "")
+	j := generateTestJob(1)
+	g.AddNext(j)
+
+	next, err := g.Execute()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if next != j {
+		t.Errorf("想定外の後続ノード[%s]が取得された。", next.ID())
+	}
+}
+
+func TestExclusiveGatewayExecute_条件を満たす後続ノードを返す(t *testing.T) {
+	addTestJobValue("xjob", 8)
+	g := NewExclusiveGateway("xgwid1", "flow3")
+	j1 := generateTestJob(1)
+	j2 := generateTestJob(2)
+	j3 := generateTestJob(3)
+	g.AddBranch(j1, "flow1", "$MJxjob:RC$ == 0")
+	g.AddBranch(j2, "flow2", "$MJxjob:RC$ > 4")
+	g.AddBranch(j3, "flow3", "")
+
+	next, err := g.Execute()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if next != j2 {
+		t.Errorf("想定外の後続ノード[%s]が取得された。", next.ID())
+	}
+	if j1.isExecuted || j2.isExecuted || j3.isExecuted {
+		t.Error("ゲートウェイの処理で後続ジョブが実行された。")
+	}
+}
+
+func TestExclusiveGatewayExecute_どの条件も満たさない場合はデフォルトフローの後続ノードを返す(t *testing.T) {
+	addTestJobValue("xjob", 1)
+	g := NewExclusiveGateway("xgwid1", "flow1")
+	j1 := generateTestJob(1)
+	j2 := generateTestJob(2)
+	g.AddBranch(j1, "flow1", "")
+	g.AddBranch(j2, "flow2", "$MJxjob:RC$ == 0")
+
+	next, err := g.Execute()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if next != j1 {
+		t.Errorf("想定外の後続ノード[%s]が取得された。", next.ID())
+	}
+}
+
+func TestExclusiveGatewayExecute_進む後続ノードが無い場合はエラー(t *testing.T) {
+	addTestJobValue("xjob", 1)
+	g := NewExclusiveGateway("xgwid1", "")
+	g.AddBranch(generateTestJob(1), "flow1", "$MJxjob:RC$ == 0")
+	g.AddBranch(generateTestJob(2), "flow2", "$MJxjob:RC$ == 2")
+
+	next, err := g.Execute()
+	if err == nil {
+		t.Fatal("エラーが発生しなかった。")
+	}
+	if next != nil {
+		t.Errorf("想定外の後続ノード[%s]が取得された。", next.ID())
+	}
+}
+
+func TestExclusiveGatewayExecute_条件の評価に失敗した場合はエラー(t *testing.T) {
+	g := NewExclusiveGateway("xgwid1", "flow2")
+	g.AddBranch(generateTestJob(1), "flow1", "$MJnoexecjob:RC$ == 0")
+	g.AddBranch(generateTestJob(2), "flow2", "")
+
+	if _, err := g.Execute(); err == nil {
+		t.Fatal("エラーが発生しなかった。")
+	}
+}
+
+func TestExclusiveGatewayValidateConditions_分岐条件の定義を検証できる(t *testing.T) {
+	g := NewExclusiveGateway("xgwid1", "flow2")
+	g.AddBranch(generateTestJob(1), "flow1", "$MJxjob:RC$ == 0")
+	g.AddBranch(generateTestJob(2), "flow2", "")
+	if err := g.validateConditions(); err != nil {
+		t.Errorf("デフォルトフローがある場合に想定外のエラーが発生した: %s", err)
+	}
+
+	g = NewExclusiveGateway("xgwid1", "")
+	g.AddBranch(generateTestJob(1), "flow1", "$MJxjob:RC$ == 0")
+	g.AddBranch(generateTestJob(2), "flow2", "$MJxjob:RC$ != 0")
+	if err := g.validateConditions(); err != nil {
+		t.Errorf("条件が網羅されている場合に想定外のエラーが発生した: %s", err)
+	}
+
+	g = NewExclusiveGateway("xgwid1", "")
+	g.AddBranch(generateTestJob(1), "flow1", "$MJxjob:RC$ == 0")
+	g.AddBranch(generateTestJob(2), "flow2", "$MJxjob:RC$ == 1")
+	if err := g.validateConditions(); err == nil {
+		t.Error("条件が網羅されていないにも関わらず、エラーが発生しなかった。")
+	}
+
+	g = NewExclusiveGateway("xgwid1", "flow2")
+	g.AddBranch(generateTestJob(1), "flow1", "")
+	g.AddBranch(generateTestJob(2), "flow2", "")
+	if err := g.validateConditions(); err == nil {
+		t.Error("デフォルトフロー以外に条件が無いにも関わらず、エラーが発生しなかった。")
+	}
+
+	g = NewExclusiveGateway("xgwid1", "flow9")
+	g.AddBranch(generateTestJob(1), "flow1", "$MJxjob:RC$ == 0")
+	g.AddBranch(generateTestJob(2), "flow2", "$MJxjob:RC$ != 0")
+	if err := g.validateConditions(); err == nil {
+		t.Error("存在しないデフォルトフローが指定されているにも関わらず、エラーが発生しなかった。")
+	}
+}
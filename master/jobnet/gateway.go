@@ -4,8 +4,9 @@ import "fmt"
 
 // ジョブネット内のフロー分岐・集約を表す構造体
 type Gateway struct {
-	id    string
-	Nexts []Element
+	id      string
+	Nexts   []Element
+	prevCnt int // 先行エレメントの数
 }
 
 // Gateway構造体のコンストラクタ関数
@@ -30,6 +31,7 @@ func (g *Gateway) Type() elementType {
 // 後続エレメントの追加を行う。
 func (g *Gateway) AddNext(e Element) error {
 	g.Nexts = append(g.Nexts, e)
+	countPrev(e)
 	return nil
 }
 
@@ -38,6 +40,27 @@ func (g *Gateway) HasNext() bool {
 	return len(g.Nexts) > 0
 }
 
+// 先行エレメントの追加を記録する。
+func (g *Gateway) addPrev() {
+	g.prevCnt++
+}
+
+// 分岐経路内でネストした分岐ゲートウェイであるかを調べる。
+// 先行エレメントが1つ以下で、後続エレメントが複数ある場合に分岐ゲートウェイとみなす。
+func (g *Gateway) isSplit() bool {
+	return g.prevCnt <= 1 && len(g.Nexts) > 1
+}
+
+// 後続エレメントがゲートウェイである場合に、先行エレメントの追加を記録する。
+func countPrev(e Element) {
+	switch g := e.(type) {
+	case *Gateway:
+		g.addPrev()
+	case *ExclusiveGateway:
+		g.addPrev()
+	}
+}
+
 // 後続のノード数に応じて、ゲートウェイの処理を行う。
 //
 // 後続ノードが存在しない場合：何も行わない。次の実行ノードとしてnilを返す。
@@ -45,6 +68,7 @@ func (g *Gateway) HasNext() bool {
 // 後続ノードが1つだけの場合：何も行わない。次の実行ノードとして唯一の後続ノードを返す。
 //
 // 後続ノードが2つ以上の場合：各後続ノードを先頭としたPath構造体を生成し、並列実行する。次の実行ノードとして結合ゲートウェイを返す。
+// 分岐経路内でさらに分岐する場合は、Path構造体がネストした分岐・結合を処理する。
 //
 // return : 次の実行ノード
 //
@@ -181,3 +181,70 @@ func TestGatewayExecute_経路間で終着ノードが異なる場合はエラ
 		t.Errorf("想定外の後続ノード[%s]が取得された。", next.ID())
 	}
 }
+
+func TestGatewayExecute_ネストした分岐を実行できる(t *testing.T) {
+	g1 := NewGateway("gwid1")
+	g2 := NewGateway("gwid2")
+	g3 := NewGateway("gwid3")
+	g4 := NewGateway("gwid4")
+	j1 := generateTestJob(1)
+	j2 := generateTestJob(2)
+	j3 := generateTestJob(3)
+	j4 := generateTestJob(4)
+	j5 := generateTestJob(5)
+	g1.AddNext(j1)
+	g1.AddNext(j5)
+	j1.AddNext(g2)
+	g2.AddNext(j2)
+	g2.AddNext(j3)
+	j2.AddNext(g3)
+	j3.AddNext(g3)
+	g3.AddNext(j4)
+	j4.AddNext(g4)
+	j5.AddNext(g4)
+
+	next, err := g1.Execute()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if next != g4 {
+		t.Errorf("想定外の後続ノード[%s]が取得された。", next.ID())
+	}
+	for _, j := range []*testJob{j1, j2, j3, j4, j5} {
+		if !j.isExecuted {
+			t.Errorf("分岐経路中のジョブ%sが実行されなかった。", j.Name)
+		}
+	}
+}
+
+func TestGatewayExecute_ネストした分岐中でジョブが異常終了したらエラー(t *testing.T) {
+	g1 := NewGateway("gwid1")
+	g2 := NewGateway("gwid2")
+	g3 := NewGateway("gwid3")
+	g4 := NewGateway("gwid4")
+	j1 := generateTestJob(1)
+	j2 := generateTestJob(2)
+	j3 := generateTestJob(3)
+	j4 := generateTestJob(4)
+	g1.AddNext(g2)
+	g1.AddNext(j4)
+	g2.AddNext(j1)
+	g2.AddNext(j2)
+	j1.AddNext(g3)
+	j2.AddNext(g3)
+	g3.AddNext(j3)
+	j3.AddNext(g4)
+	j4.AddNext(g4)
+
+	j2.hasError = true
+	next, err := g1.Execute()
+	if err == nil {
+		t.Fatalf("エラーが発生しなかった。")
+	}
+	if next != nil {
+		t.Errorf("想定外の後続ノード[%s]が取得された。", next.ID())
+	}
+	if j3.isExecuted {
+		t.Errorf("異常終了したネスト分岐の後続ジョブj3が実行された。")
+	}
+}
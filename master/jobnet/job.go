@@ -14,34 +14,47 @@ import (
 	"github.com/unirita/cuto/db/tx"
 	"github.com/unirita/cuto/log"
 	"github.com/unirita/cuto/master/config"
+	"github.com/unirita/cuto/master/jobnet/parser"
+	"github.com/unirita/cuto/master/notify"
 	"github.com/unirita/cuto/master/remote"
 	"github.com/unirita/cuto/message"
+	"github.com/unirita/cuto/utctime"
 	"github.com/unirita/cuto/util"
 )
 
-type sendFunc func(string, int, string, chan<- string) (string, error)
+type sendFunc func(string, int, string, chan<- string, chan<- string) (string, error)
 
 // ジョブを表す構造体
 type Job struct {
-	id            string   // ジョブID
-	Name          string   // ジョブ名
-	Node          string   // ノード
-	Port          int      // ポート番号
-	FilePath      string   // ジョブファイル
-	Param         string   // ジョブ引き渡しパラメータ
-	Env           string   // ジョブ実行に渡す環境変数
-	Workspace     string   // ジョブ実行時の作業フォルダ
-	WrnRC         int      // 警告終了と判断する戻り値の下限値
-	WrnPtn        string   // 警告終了と判断するジョブの出力メッセージ
-	ErrRC         int      // 異常終了と判断する戻り値の下限値
-	ErrPtn        string   // 異常終了と判断するジョブの出力メッセージ
-	Timeout       int      // ジョブ実行時間のタイムアウト
-	SecondaryNode string   // セカンダリサーバントのノード
-	SecondaryPort int      // セカンダリサーバントのポート番号
-	Next          Element  // 次ノード
-	Instance      *Network // ネットワーク情報構造体のポインタ
-	sendRequest   sendFunc // リクエスト送信メソッド
-	IsRerunJob    bool     // リランジョブであるかどうか
+	id            string        // ジョブID
+	Name          string        // ジョブ名
+	Node          string        // ノード
+	Port          int           // ポート番号
+	FilePath      string        // ジョブファイル
+	Param         string        // ジョブ引き渡しパラメータ
+	Env           string        // ジョブ実行に渡す環境変数
+	Workspace     string        // ジョブ実行時の作業フォルダ
+	WrnRC         int           // 警告終了と判断する戻り値の下限値
+	WrnPtn        string        // 警告終了と判断するジョブの出力メッセージ
+	ErrRC         int           // 異常終了と判断する戻り値の下限値
+	ErrPtn        string        // 異常終了と判断するジョブの出力メッセージ
+	Timeout       int           // ジョブ実行時間のタイムアウト
+	SecondaryNode string        // セカンダリサーバントのノード
+	SecondaryPort int           // セカンダリサーバントのポート番号
+	RunDay        string        // 実行日の指定
+	RetryMax      int           // 異常終了時のリトライ回数
+	RetryDelaySec int           // リトライまでの待ち時間（秒）
+	RetryBackoff  float64       // リトライ毎に待ち時間に掛ける倍率
+	RetryRC       parser.RCList // リトライ対象とするリターンコード
+	RetryPtn      string        // リトライ対象とする詳細メッセージ
+	Next          Element       // 次ノード
+	Instance      *Network      // ネットワーク情報構造体のポインタ
+	sendRequest   sendFunc      // リクエスト送信メソッド
+	IsRerunJob    bool          // リランジョブであるかどうか
+	forceRerun    bool          // リラン時に実行結果に関わらず再実行するかどうか
+	excluded      bool          // リラン時に再実行の対象外であるかどうか
+	attempt       int           // 試行回数
+	retried       int           // 今回の実行で行ったリトライ回数
 }
 
 // Job構造体のコンストラクタ関数。
@@ -89,6 +102,7 @@ func (j *Job) AddNext(e Element) error {
 		return fmt.Errorf("ServiceTask cannot connect with over 1 element.")
 	}
 	j.Next = e
+	countPrev(e)
 
 	return nil
 }
@@ -122,9 +136,22 @@ func (j *Job) SetDefaultEx() {
 //
 // return : エラー情報。
 func (j *Job) Execute() (Element, error) {
-	if j.IsRerunJob {
+	if j.Instance.IsCancelled() {
+		return nil, fmt.Errorf("Job ID [%s] was not executed because network was cancelled.", j.id)
+	}
+
+	if j.excluded {
+		j.skipRerun()
+		return j.Next, nil
+	}
+
+	var res *message.Response
+	var err error
+	if j.IsRerunJob && j.forceRerun {
+		j.archiveLastAttempt()
+	} else if j.IsRerunJob {
 		jobres, _ := j.Instance.Result.GetJobResults(j.id)
-		if jobres.Status == db.NORMAL || jobres.Status == db.WARN {
+		if isCompleted(jobres.Status) {
 			j.resumeJobValue()
 			return j.Next, nil
 		} else {
@@ -137,7 +164,10 @@ func (j *Job) Execute() (Element, error) {
 
 			switch result.Stat {
 			case db.RUNNING:
-				return nil, fmt.Errorf("Job ID [%s] still running.", j.id)
+				// servantで実行中のジョブは再実行せず、再接続して終了を待つ
+				if res, err = j.attach(); err != nil {
+					return nil, j.abnormalEnd(err)
+				}
 			case db.NORMAL:
 				fallthrough
 			case db.WARN:
@@ -147,13 +177,34 @@ func (j *Job) Execute() (Element, error) {
 				j.changeStatusRunning()
 			}
 		}
+	} else if !j.isRunDay() {
+		j.skip()
+		return j.Next, nil
+	}
+	j.initAttempt()
+	if res == nil {
+		if res, err = j.executeRequest(); err != nil {
+			return nil, j.abnormalEnd(err)
+		}
 	}
-	res, err := j.executeRequest()
-	if err != nil {
-		return nil, j.abnormalEnd(err)
+	for j.isRetryable(res) {
+		j.endAttempt(res)
+		j.recordAttempt()
+		if !j.waitRetry() {
+			return nil, fmt.Errorf("Job ID [%s] was not retried because network was cancelled.", j.id)
+		}
+		j.restart()
+		if res, err = j.executeRequest(); err != nil {
+			return nil, j.abnormalEnd(err)
+		}
 	}
+	defer j.recordAttempt()
 	defer j.end(res)
 
+	if res.Stat == db.CANCELLED {
+		return nil, fmt.Errorf("Job ID [%s] was cancelled.", j.id)
+	}
+
 	if isAbnormalEnd(res) {
 		joblogFile := res.JoblogFile
 		if len(joblogFile) == 0 {
@@ -167,7 +218,7 @@ func (j *Job) Execute() (Element, error) {
 }
 
 func (j *Job) executeRequest() (*message.Response, error) {
-	if !j.IsRerunJob {
+	if !j.IsRerunJob && j.retried == 0 {
 		j.start()
 	}
 	console.Display("CTM023I", j.Name, j.Node, j.Instance.ID, j.id)
@@ -211,7 +262,50 @@ func (j *Job) requestAndWaitResult() (string, error) {
 	defer close(stCh)
 	go j.waitAndSetResultStartDate(stCh)
 
-	return j.sendRequestWithRetry(reqMsg, stCh)
+	var outCh chan string
+	if req.Stream {
+		outCh = make(chan string, 10)
+		outEndCh := make(chan struct{})
+		go j.writeOutput(outCh, outEndCh)
+		defer func() {
+			close(outCh)
+			<-outEndCh
+		}()
+	}
+
+	return j.sendRequestWithRetry(reqMsg, stCh, outCh)
+}
+
+// servantで実行中のジョブに再接続し、ジョブの終了を待って実行結果を受け取る。
+func (j *Job) attach() (*message.Response, error) {
+	console.Display("CTM045I", j.Name, j.Node, j.Instance.ID, j.id)
+
+	a := new(message.Attach)
+	a.NID = j.Instance.ID
+	a.JID = j.ID()
+	attachMsg, err := a.GenerateJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	timerEndCh := make(chan struct{}, 1)
+	go j.startTimer(timerEndCh)
+	defer close(timerEndCh)
+
+	stCh := make(chan string, 1)
+	defer close(stCh)
+
+	host, _, _ := explodeNodeString(j.Node)
+	resMsg, err := j.sendRequest(host, j.Port, attachMsg, stCh, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(message.Response)
+	if err := res.ParseJSON(resMsg); err != nil {
+		return nil, err
+	}
+	return res, nil
 }
 
 func (j *Job) requestLatestJobResult() (*message.JobResult, error) {
@@ -240,7 +334,7 @@ func (j *Job) requestLatestJobResult() (*message.JobResult, error) {
 
 // ジョブ実行リクエストを送信する。
 // 送信失敗時には必要な回数だけリトライを行う。
-func (j *Job) sendRequestWithRetry(reqMsg string, stCh chan<- string) (string, error) {
+func (j *Job) sendRequestWithRetry(reqMsg string, stCh chan<- string, outCh chan<- string) (string, error) {
 	limit := config.Job.AttemptLimit
 	var resMsg string
 	var err error
@@ -250,7 +344,7 @@ func (j *Job) sendRequestWithRetry(reqMsg string, stCh chan<- string) (string, e
 		}
 
 		host, _, _ := explodeNodeString(j.Node)
-		resMsg, err = j.sendRequest(host, j.Port, reqMsg, stCh)
+		resMsg, err = j.sendRequest(host, j.Port, reqMsg, stCh, outCh)
 		if !j.isNecessaryToRetry(err) {
 			break
 		}
@@ -272,7 +366,7 @@ func (j *Job) sendResultCheckRequestWithRetry(chkMsg string) (string, error) {
 		}
 
 		host, _, _ := explodeNodeString(j.Node)
-		resultMsg, err = j.sendRequest(host, j.Port, chkMsg, stCh)
+		resultMsg, err = j.sendRequest(host, j.Port, chkMsg, stCh, nil)
 		if err == nil {
 			break
 		}
@@ -296,6 +390,15 @@ func (j *Job) isNecessaryToRetry(err error) bool {
 	return false
 }
 
+// ステータスが、リラン時に再実行不要な完了状態であればtrueを返す。
+func isCompleted(status int) bool {
+	switch status {
+	case db.NORMAL, db.WARN, db.SKIPPED, db.FORCED:
+		return true
+	}
+	return false
+}
+
 // responseメッセージrのステータスを参照し、ジョブが異常終了している場合はtrueを返す。
 // それ以外はfalseを返す。
 func isAbnormalEnd(r *message.Response) bool {
@@ -305,6 +408,48 @@ func isAbnormalEnd(r *message.Response) bool {
 	return false
 }
 
+// ジョブネットワークの基準日がジョブの実行日であるかを判定する。
+func (j *Job) isRunDay() bool {
+	if j.RunDay == parser.RUNDAY_ANY || j.Instance.Calendar == nil {
+		return true
+	}
+
+	isBizDay := j.Instance.Calendar.IsBusinessDay(j.Instance.baseDate)
+	if j.RunDay == parser.RUNDAY_HOLIDAY {
+		return !isBizDay
+	}
+	return isBizDay
+}
+
+// ジョブを実行せずに完了扱い（SKIPPED）とする。
+func (j *Job) skip() {
+	now := utctime.Now().String()
+	detail := fmt.Sprintf("Run day is %s.", j.RunDay)
+
+	jobres := db.NewJobResult(int(j.Instance.ID))
+	jobres.JobId = j.ID()
+	jobres.JobName = j.Name
+	jobres.Node = j.Node
+	jobres.Port = j.Port
+	jobres.StartDate = now
+	jobres.EndDate = now
+	jobres.Status = db.SKIPPED
+	jobres.Detail = detail
+
+	j.Instance.Result.AddJobResults(j.id, jobres)
+	tx.InsertJob(j.Instance.Result.GetConnection(), jobres, &j.Instance.localMutex)
+
+	res := new(message.Response)
+	res.JID = j.id
+	res.Stat = db.SKIPPED
+	res.St = now
+	res.Et = now
+	res.Detail = detail
+	message.AddJobValue(j.Name, res)
+
+	console.Display("CTM031I", j.Name, j.Instance.baseDate.Format(utctime.Date8Num), j.RunDay)
+}
+
 // ジョブの開始処理を行う。
 func (j *Job) start() {
 	jobres := db.NewJobResult(int(j.Instance.ID))
@@ -329,8 +474,10 @@ func (j *Job) useSecondaryNode() {
 		return
 	}
 
+	j.Instance.localMutex.Lock()
 	jobres.Node = j.SecondaryNode
 	jobres.Port = j.SecondaryPort
+	j.Instance.localMutex.Unlock()
 	tx.UpdateJob(j.Instance.Result.GetConnection(), jobres, &j.Instance.localMutex)
 
 	j.SecondaryNode = ""
@@ -355,21 +502,37 @@ func (j *Job) waitAndSetResultStartDate(stCh <-chan string) {
 	tx.UpdateJob(j.Instance.Result.GetConnection(), jobres, &j.Instance.localMutex)
 }
 
-// ジョブの終了メッセージから、ジョブ状態の更新を行う。
+// ジョブの終了メッセージから、ジョブ状態の更新と終了の通知を行う。
 func (j *Job) end(res *message.Response) {
+	if jobres := j.endAttempt(res); jobres != nil {
+		notify.Send(notify.NewJobEvent(j.Instance.Name, jobres))
+	}
+}
+
+// ジョブの終了メッセージから、ジョブ状態の更新を行う。
+// リトライする試行の結果はジョブの最終的な結果ではないため、終了の通知は行わない。
+//
+// return : 更新したジョブ実行結果。実行結果が登録されていない場合はnil。
+func (j *Job) endAttempt(res *message.Response) *db.JobResult {
 	var jobres *db.JobResult
 	var exist bool
 
 	if jobres, exist = j.Instance.Result.GetJobResults(j.id); !exist {
 		log.Error(fmt.Errorf("Job result[id = %s] is unregisted.", j.id))
-		return
+		return nil
 	}
 	jobres.StartDate = res.St
 	jobres.EndDate = res.Et
-	jobres.Status = res.Stat
+	j.setResultStatus(jobres, res.Stat)
 	jobres.Rc = res.RC
 	jobres.Detail = res.Detail
 	jobres.Variable = res.Var
+	jobres.SetOutputs(res.Outputs)
+	jobres.QueuePos = res.QueuePos
+	jobres.QueueSec = res.QueueSec
+	if res.QueuePos > 0 {
+		console.Display("CTM040I", j.Name, j.Node, j.Instance.ID, j.id, res.QueuePos, res.QueueSec)
+	}
 
 	message.AddJobValue(j.Name, res)
 	tx.UpdateJob(j.Instance.Result.GetConnection(), jobres, &j.Instance.localMutex)
@@ -380,6 +543,8 @@ func (j *Job) end(res *message.Response) {
 		st = db.ST_NORMAL
 	case db.WARN:
 		st = db.ST_WARN
+	case db.CANCELLED:
+		st = db.ST_CANCELLED
 	default:
 		st = db.ST_ABNORMAL
 	}
@@ -388,6 +553,7 @@ func (j *Job) end(res *message.Response) {
 	} else {
 		console.Display("CTM025W", j.Name, j.Node, j.Instance.ID, j.id, st, jobres.Detail)
 	}
+	return jobres
 }
 
 // サーバントへ送受信失敗した場合の異常終了処理
@@ -396,11 +562,12 @@ func (j *Job) abnormalEnd(err error) error {
 	if !exist {
 		return fmt.Errorf("Job result[id = %s] is unregisted.", j.id)
 	}
-	jobres.Status = db.ABNORMAL
+	j.setResultStatus(jobres, db.ABNORMAL)
 	jobres.Detail = err.Error()
 	tx.UpdateJob(j.Instance.Result.GetConnection(), jobres, &j.Instance.localMutex)
 
 	console.Display("CTM025W", j.Name, j.Node, j.Instance.ID, j.id, jobres.Status, jobres.Detail)
+	notify.Send(notify.NewJobEvent(j.Instance.Name, jobres))
 	return err
 }
 
@@ -413,6 +580,7 @@ func (j *Job) resumeJobValue() {
 	res.St = jobres.StartDate
 	res.Et = jobres.EndDate
 	res.Var = jobres.Variable
+	res.Outputs = jobres.GetOutputs()
 	message.AddJobValue(j.Name, res)
 }
 
@@ -423,12 +591,13 @@ func (j *Job) updateNormalEndResult(result *message.JobResult) {
 		return
 	}
 
-	jobres.Status = result.Stat
+	j.setResultStatus(jobres, result.Stat)
 	jobres.Rc = result.RC
 	jobres.StartDate = result.St
 	jobres.EndDate = result.Et
 	jobres.Detail = ""
 	jobres.Variable = result.Var
+	jobres.SetOutputs(result.Outputs)
 	tx.UpdateJob(j.Instance.Result.GetConnection(), jobres, &j.Instance.localMutex)
 
 	j.resumeJobValue()
@@ -441,10 +610,22 @@ func (j *Job) changeStatusRunning() {
 		return
 	}
 
-	jobres.Status = db.RUNNING
+	j.setResultStatus(jobres, db.RUNNING)
 	tx.UpdateJob(j.Instance.Result.GetConnection(), jobres, &j.Instance.localMutex)
 }
 
+// ジョブ実行結果の状態を変更する。
+// 実行中のジョブの状態はNetwork.Cancelからも参照されるため、ジョブネットワークのミューテックスを取得した上で変更する。
+//
+// param : jobres 変更するジョブ実行結果。
+//
+// param : status 変更後の状態。
+func (j *Job) setResultStatus(jobres *db.JobResult, status int) {
+	j.Instance.localMutex.Lock()
+	defer j.Instance.localMutex.Unlock()
+	jobres.Status = status
+}
+
 func (j *Job) startTimer(endCh chan struct{}) {
 	span := config.Job.TimeTrackingSpanMin
 	if span == 0 {
@@ -499,6 +680,7 @@ func (j *Job) createRequest() *message.Request {
 	req.ErrRC = j.ErrRC
 	req.ErrStr = j.ErrPtn
 	req.Timeout = j.Timeout
+	req.Stream = j.isStreaming()
 
 	_, cntHost, cntName := explodeNodeString(j.Node)
 	if cntName != "" {
@@ -3,12 +3,15 @@ package jobnet
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/unirita/cuto/calendar"
 	"github.com/unirita/cuto/db"
 	"github.com/unirita/cuto/db/tx"
 	"github.com/unirita/cuto/master/config"
+	"github.com/unirita/cuto/master/jobnet/parser"
 	"github.com/unirita/cuto/message"
 )
 
@@ -25,7 +28,7 @@ func newTestNetwork() *Network {
 	return n
 }
 
-func testSendRequest_Normal(host string, port int, reqMsg string, stCh chan<- string) (string, error) {
+func testSendRequest_Normal(host string, port int, reqMsg string, stCh chan<- string, outCh chan<- string) (string, error) {
 	req := new(message.Request)
 	req.ParseJSON("reqMsg")
 
@@ -36,6 +39,7 @@ func testSendRequest_Normal(host string, port int, reqMsg string, stCh chan<- st
 	res.Stat = 1
 	res.Detail = ""
 	res.Var = "testvar"
+	res.Outputs = map[string]string{"file": "a.csv"}
 	res.St = "2015-04-01 12:34:56.789"
 	res.Et = "2015-04-01 12:35:46.123"
 
@@ -43,7 +47,7 @@ func testSendRequest_Normal(host string, port int, reqMsg string, stCh chan<- st
 	return resMsg, nil
 }
 
-func testSendRequest_Abnormal(host string, port int, reqMsg string, stCh chan<- string) (string, error) {
+func testSendRequest_Abnormal(host string, port int, reqMsg string, stCh chan<- string, outCh chan<- string) (string, error) {
 	req := new(message.Request)
 	req.ParseJSON("reqMsg")
 
@@ -61,21 +65,21 @@ func testSendRequest_Abnormal(host string, port int, reqMsg string, stCh chan<-
 	return resMsg, nil
 }
 
-func testSendRequest_Error(host string, port int, reqMsg string, stCh chan<- string) (string, error) {
+func testSendRequest_Error(host string, port int, reqMsg string, stCh chan<- string, outCh chan<- string) (string, error) {
 	return "", fmt.Errorf("senderror")
 }
 
-func testSendRequest_ErrorAfterSt(host string, port int, reqMsg string, stCh chan<- string) (string, error) {
+func testSendRequest_ErrorAfterSt(host string, port int, reqMsg string, stCh chan<- string, outCh chan<- string) (string, error) {
 	stCh <- "2015-04-01 12:34:56.789"
 	time.Sleep(time.Millisecond * 50)
 	return "", fmt.Errorf("senderror")
 }
 
-func testSendRequest_NotJSON(host string, port int, reqMsg string, stCh chan<- string) (string, error) {
+func testSendRequest_NotJSON(host string, port int, reqMsg string, stCh chan<- string, outCh chan<- string) (string, error) {
 	return "notjson", nil
 }
 
-func testSendRequest_Rerun_AllreadyNormalEnd(host string, port int, reqMsg string, stCh chan<- string) (string, error) {
+func testSendRequest_Rerun_AllreadyNormalEnd(host string, port int, reqMsg string, stCh chan<- string, outCh chan<- string) (string, error) {
 	res := new(message.JobResult)
 	res.Stat = db.NORMAL
 	resMsg, _ := res.GenerateJSON()
@@ -225,6 +229,109 @@ func TestJobExecute_レスポンスにエラーが無いケース(t *testing.T)
 	if jobres.Variable != "testvar" {
 		t.Errorf("ジョブ実行結果のVariable[%s]は想定と違っている。", jobres.Variable)
 	}
+	if jobres.Outputs != `{"file":"a.csv"}` {
+		t.Errorf("ジョブ実行結果のOutputs[%s]は想定と違っている。", jobres.Outputs)
+	}
+	if out, _ := message.ExpandStringVars("$MJjob1:OUT:file$", 'M', 'J'); out != "a.csv" {
+		t.Errorf("名前付き出力の変数展開結果[%s]が想定と違っている。", out)
+	}
+}
+
+func TestJobExecute_servantでの待ち順位と待ち時間を記録する(t *testing.T) {
+	config.Job.AttemptLimit = 1
+	n := newTestNetwork()
+	j1, _ := NewJob("jobid1", "job1", n)
+	j1.sendRequest = func(host string, port int, reqMsg string, stCh chan<- string, outCh chan<- string) (string, error) {
+		res := new(message.Response)
+		res.Stat = db.NORMAL
+		res.St = "2015-04-01 12:34:56.789"
+		res.Et = "2015-04-01 12:35:46.123"
+		res.QueuePos = 3
+		res.QueueSec = 25
+		resMsg, _ := res.GenerateJSON()
+		return resMsg, nil
+	}
+	if _, err := j1.Execute(); err != nil {
+		t.Fatalf("想定外のエラーが発生: %s", err)
+	}
+
+	jobres, ok := n.Result.GetJobResults(j1.id)
+	if !ok {
+		t.Fatal("ジョブ実行結果がセットされなかった。")
+	}
+	if jobres.QueuePos != 3 {
+		t.Errorf("ジョブ実行結果のQueuePos[%d]は想定と違っている。", jobres.QueuePos)
+	}
+	if jobres.QueueSec != 25 {
+		t.Errorf("ジョブ実行結果のQueueSec[%d]は想定と違っている。", jobres.QueueSec)
+	}
+}
+
+func TestJobExecute_実行日でない場合はジョブを実行せずにSKIPPEDとする(t *testing.T) {
+	config.Job.AttemptLimit = 1
+	n := newTestNetwork()
+	n.Calendar = calendar.Standard()
+	n.baseDate = time.Date(2016, 1, 2, 12, 0, 0, 0, time.Local)
+	j1, _ := NewJob("jobid1", "skipjob1", n)
+	j2, _ := NewJob("jobid2", "skipjob2", n)
+	j1.RunDay = parser.RUNDAY_BIZDAY
+	j1.Next = j2
+
+	isSent := false
+	j1.sendRequest = func(host string, port int, reqMsg string, stCh chan<- string, outCh chan<- string) (string, error) {
+		isSent = true
+		return testSendRequest_Normal(host, port, reqMsg, stCh, outCh)
+	}
+	next, err := j1.Execute()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生: %s", err)
+	}
+	if next != j2 {
+		t.Errorf("次に実行されるのとは違うノード[%s]が返された。", next.ID())
+	}
+	if isSent {
+		t.Error("実行日でないにも関わらず、リクエストが送信された。")
+	}
+
+	jobres, ok := n.Result.GetJobResults(j1.id)
+	if !ok {
+		t.Fatal("ジョブ実行結果がセットされなかった。")
+	}
+	if jobres.Status != db.SKIPPED {
+		t.Errorf("ジョブ実行結果のStatus[%d]は想定と違っている。", jobres.Status)
+	}
+	if jobres.Detail == "" {
+		t.Error("ジョブ実行結果のDetailにスキップした旨がセットされていない。")
+	}
+
+	rc, err := message.ExpandStringVars("$MJskipjob1:RC$", 'M', 'J')
+	if err != nil {
+		t.Fatalf("スキップしたジョブのジョブネットワーク変数が展開できない: %s", err)
+	}
+	if rc != "0" {
+		t.Errorf("スキップしたジョブのRC[%s]は想定と違っている。", rc)
+	}
+}
+
+func TestJobExecute_実行日の場合はジョブを実行する(t *testing.T) {
+	config.Job.AttemptLimit = 1
+	n := newTestNetwork()
+	n.Calendar = calendar.Standard()
+	n.baseDate = time.Date(2016, 1, 2, 12, 0, 0, 0, time.Local)
+	j1, _ := NewJob("jobid1", "job1", n)
+	j1.RunDay = parser.RUNDAY_HOLIDAY
+
+	isSent := false
+	j1.sendRequest = func(host string, port int, reqMsg string, stCh chan<- string, outCh chan<- string) (string, error) {
+		isSent = true
+		return testSendRequest_Normal(host, port, reqMsg, stCh, outCh)
+	}
+	if _, err := j1.Execute(); err != nil {
+		t.Fatalf("想定外のエラーが発生: %s", err)
+	}
+	if !isSent {
+		t.Error("実行日であるにも関わらず、リクエストが送信されなかった。")
+	}
 }
 
 func TestJobExecute_使用できない変数を使用したケース(t *testing.T) {
@@ -515,6 +622,60 @@ func TestJobExecute_リラン実行_リモートの実行結果が既に正常
 	}
 }
 
+func TestJobExecute_リラン実行_リモートで実行中のジョブに再接続して終了を待つ(t *testing.T) {
+	config.Job.AttemptLimit = 1
+	n := newTestNetwork()
+	n.ID = 1
+
+	j1, _ := NewJob("jobid1", "job1", n)
+	j2, _ := NewJob("jobid1", "job1", n)
+	var attached bool
+	j1.sendRequest = func(host string, port int, reqMsg string, stCh chan<- string, outCh chan<- string) (string, error) {
+		if strings.Contains(reqMsg, `"type":"attach"`) {
+			attached = true
+			res := new(message.Response)
+			res.NID = 1
+			res.JID = "jobid1"
+			res.Stat = db.NORMAL
+			res.RC = 0
+			res.Var = "attachvar"
+			res.St = "2015-04-01 12:34:56.789"
+			res.Et = "2015-04-01 12:35:46.123"
+			return res.GenerateJSON()
+		}
+		if strings.Contains(reqMsg, `"type":"request"`) {
+			t.Error("実行中のジョブが再実行された。")
+		}
+		res := new(message.JobResult)
+		res.Stat = db.RUNNING
+		return res.GenerateJSON()
+	}
+	j1.IsRerunJob = true
+	j1.Node = "testnode"
+	j1.Port = 1234
+	j1.Next = j2
+
+	n.Result.AddJobResults(j1.id, &db.JobResult{Status: db.RUNNING, Node: "testnode", Port: 1234})
+
+	elm, err := j1.Execute()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した： %s", err)
+	}
+	if !attached {
+		t.Error("実行中のジョブに再接続していない。")
+	}
+	if elm != j2 {
+		t.Error("次のジョブが返されていない。")
+	}
+	jobres, _ := n.Result.GetJobResults(j1.id)
+	if jobres.Status != db.NORMAL {
+		t.Errorf("ジョブ実行結果のStatus[%d]は想定と違っている。", jobres.Status)
+	}
+	if jobres.Variable != "attachvar" {
+		t.Errorf("ジョブ実行結果のVariable[%s]は想定と違っている。", jobres.Variable)
+	}
+}
+
 func TestCreateJoblogFileName(t *testing.T) {
 	n := newTestNetwork()
 	j, _ := NewJob("jobid1", "job1", n)
@@ -0,0 +1,287 @@
+package jobnet
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/unirita/cuto/master/config"
+	"github.com/unirita/cuto/master/jobnet/parser"
+	"github.com/unirita/cuto/message"
+)
+
+// 検査結果の重要度
+const (
+	LINT_ERROR   = "error"
+	LINT_WARNING = "warning"
+)
+
+// ジョブネットワーク定義の検査で検出した問題を表す構造体
+type LintIssue struct {
+	Level   string `json:"level"`   // 重要度
+	Rule    string `json:"rule"`    // 検査項目
+	ID      string `json:"id"`      // エレメントID
+	Name    string `json:"name"`    // ジョブ名
+	Message string `json:"message"` // 問題の内容
+}
+
+// 検出した問題の文字列表現を返す。
+func (i *LintIssue) String() string {
+	return fmt.Sprintf("%-7s [%s] ID [%s] NAME [%s] %s", strings.ToUpper(i.Level), i.Rule, i.ID, i.Name, i.Message)
+}
+
+// masterで展開されるシステム変数の一覧
+var lintSysVars = map[string]bool{
+	"JOBNET:ID":       true,
+	"JOBNET:SD":       true,
+	"CAL:DATE":        true,
+	"CAL:BIZDATE":     true,
+	"CAL:PREVBIZDATE": true,
+	"CAL:NEXTBIZDATE": true,
+	"CAL:ISBIZDAY":    true,
+	"CAL:FIRSTBIZDAY": true,
+	"CAL:LASTBIZDAY":  true,
+}
+
+var lintVarExp = regexp.MustCompile(`\$M[SEJ].+?\$`)
+var lintHostExp = regexp.MustCompile(`^[0-9A-Za-z._:\[\]-]+$`)
+
+// 検査の実行状態を保持する構造体
+type linter struct {
+	n      *Network
+	graph  *flowGraph
+	issues []*LintIssue
+}
+
+// ジョブネットワーク定義を検査し、検出した全ての問題を返す。
+// DetectFlowErrorと異なり最初の問題で中断せず、フロー構造、拡張ジョブ定義、ポート番号、ノード名、変数参照、タイムアウトを検査する。
+// 検査の過程で拡張ジョブ定義をネットワーク内のジョブへセットする。
+//
+// param : jobEx 拡張ジョブ定義のパース結果。nilの場合は拡張ジョブ定義の過不足を検査しない。
+//
+// return : 検出した問題の一覧。
+func (n *Network) Lint(jobEx map[string]*parser.JobEx) []*LintIssue {
+	l := &linter{n: n, graph: n.newFlowGraph()}
+	l.lintFlow()
+	if jobEx != nil {
+		l.lintJobEx(jobEx)
+	}
+	n.setJobEx(jobEx)
+
+	for _, e := range n.sortedElements() {
+		if j, ok := e.(*Job); ok {
+			l.lintJob(j)
+		}
+		for _, src := range variableSources(e) {
+			l.lintVars(e, src.item, src.value, true)
+		}
+	}
+	return l.issues
+}
+
+func (l *linter) add(level, rule string, e Element, name string, format string, a ...interface{}) {
+	issue := &LintIssue{Level: level, Rule: rule, Name: name, Message: fmt.Sprintf(format, a...)}
+	if e != nil {
+		issue.ID = e.ID()
+		if j, ok := e.(*Job); ok {
+			issue.Name = j.Name
+		}
+	}
+	l.issues = append(l.issues, issue)
+}
+
+// フロー構造を検査する。
+func (l *linter) lintFlow() {
+	n := l.n
+	if n.Start == nil {
+		l.add(LINT_ERROR, "flow", nil, "", "There is no element which connects with startEvent.")
+	}
+	if n.End == nil {
+		l.add(LINT_ERROR, "flow", nil, "", "There is no element which connects with endEvent.")
+	}
+	if n.Start == nil || n.End == nil {
+		return
+	}
+
+	reachable := map[Element]bool{n.Start: true}
+	queue := []Element{n.Start}
+	for len(queue) > 0 {
+		e := queue[0]
+		queue = queue[1:]
+		for _, next := range nextElements(e) {
+			if !reachable[next] {
+				reachable[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	found := false
+	for _, e := range n.sortedElements() {
+		if !reachable[e] {
+			l.add(LINT_ERROR, "isolated", e, "", "Element is not reachable from startEvent.")
+			continue
+		}
+		if e == n.End && e.HasNext() {
+			l.add(LINT_ERROR, "flow", e, "", "Element which connects with endEvent cannot connect with another element.")
+			found = true
+		} else if e != n.End && !e.HasNext() {
+			l.add(LINT_ERROR, "flow", e, "", "Element cannot terminate network because it is not a endEvent.")
+			found = true
+		}
+	}
+
+	// 分岐・結合の対応はフローが終端まで繋がっている場合のみ検査する。
+	if !found {
+		novisit := make(map[string]Element)
+		if err := n.scanFlow(n.Start, novisit); err != nil {
+			l.add(LINT_ERROR, "flow", nil, "", "%s", err)
+		}
+	}
+}
+
+// 拡張ジョブ定義の過不足と値を検査する。
+func (l *linter) lintJobEx(jobEx map[string]*parser.JobEx) {
+	names := make(map[string]bool)
+	for _, e := range l.n.sortedElements() {
+		j, ok := e.(*Job)
+		if !ok {
+			continue
+		}
+		names[j.Name] = true
+		je, ok := jobEx[j.Name]
+		if !ok {
+			l.add(LINT_WARNING, "jobex-missing", j, "", "Job is not defined in jobex csv. Default values are used.")
+			continue
+		}
+		for _, invalid := range je.Invalids {
+			l.add(LINT_ERROR, "jobex-value", j, "", "Jobex line[%d] has invalid %s", je.Line, invalid)
+		}
+	}
+
+	var unknowns []string
+	for name := range jobEx {
+		if !names[name] {
+			unknowns = append(unknowns, name)
+		}
+	}
+	sort.Strings(unknowns)
+	for _, name := range unknowns {
+		l.add(LINT_WARNING, "jobex-unknown", nil, name, "Jobex line[%d] does not match any job.", jobEx[name].Line)
+	}
+}
+
+// ジョブの実行設定を検査する。
+func (l *linter) lintJob(j *Job) {
+	if msg := lintNode(j.Node); msg != "" {
+		l.add(LINT_ERROR, "node", j, "", "%s", msg)
+	}
+	if !isValidPort(j.Port) {
+		l.add(LINT_ERROR, "port", j, "", "Port[%d] is out of range 1-65535.", j.Port)
+	}
+
+	if j.SecondaryNode != "" {
+		if msg := lintNode(j.SecondaryNode); msg != "" {
+			l.add(LINT_ERROR, "secondary", j, "", "Secondary %s", msg)
+		}
+		if !isValidPort(j.SecondaryPort) {
+			l.add(LINT_ERROR, "secondary", j, "", "Secondary port[%d] is out of range 1-65535.", j.SecondaryPort)
+		}
+		if j.SecondaryNode == j.Node && j.SecondaryPort == j.Port {
+			l.add(LINT_WARNING, "secondary", j, "", "Secondary servant is the same as primary servant.")
+		}
+	} else if j.SecondaryPort != 0 {
+		l.add(LINT_WARNING, "secondary", j, "", "Secondary port[%d] is designated without secondary node.", j.SecondaryPort)
+	}
+
+	if def := config.Job.DefaultTimeoutMin * 60; def > 0 && j.Timeout > def {
+		l.add(LINT_WARNING, "timeout", j, "", "Timeout[%d min] exceeds default timeout[%d min].", j.Timeout/60, def/60)
+	}
+
+	l.lintVars(j, "path", j.FilePath, false)
+	l.lintVars(j, "workspace", j.Workspace, false)
+}
+
+// 文字列src内で参照しているmasterの変数を検査する。
+// expandableが偽の項目では、master環境変数以外の変数は展開されない。
+func (l *linter) lintVars(e Element, item string, src string, expandable bool) {
+	for _, key := range lintVarExp.FindAllString(src, -1) {
+		v := message.NewVariable(key)
+		if v == nil || v.Kind == 'E' {
+			continue
+		}
+		if !expandable {
+			l.add(LINT_ERROR, "variable", e, "", "Variable[%s] in %s is not expanded. Only $ME...$ is available.", key, item)
+			continue
+		}
+
+		if v.Kind == 'S' {
+			if !lintSysVars[v.Name+":"+v.Tag] {
+				l.add(LINT_ERROR, "variable", e, "", "Variable[%s] in %s is not defined.", key, item)
+			}
+			continue
+		}
+
+		if err := l.graph.checkJobVar(e, key); err != nil {
+			l.add(LINT_ERROR, "variable", e, "", "%s: %s", item, err)
+		}
+	}
+}
+
+// ノード名の書式を検査し、問題がある場合はその内容を返す。
+func lintNode(node string) string {
+	host, _, container := explodeNodeString(node)
+	if !lintHostExp.MatchString(host) {
+		return fmt.Sprintf("node[%s] has invalid host name.", node)
+	}
+	if strings.Contains(node, ">") && container == "" {
+		return fmt.Sprintf("node[%s] has empty container name.", node)
+	}
+	return ""
+}
+
+func isValidPort(port int) bool {
+	return port >= 1 && port <= 65535
+}
+
+// ネットワーク内のエレメントをID順に並べて返す。
+func (n *Network) sortedElements() []Element {
+	ids := make([]string, 0, len(n.elements))
+	for id := range n.elements {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	elements := make([]Element, len(ids))
+	for i, id := range ids {
+		elements[i] = n.elements[id]
+	}
+	return elements
+}
+
+// 各エレメントの先行エレメントの一覧を返す。
+func (n *Network) prevElements() map[Element][]Element {
+	prevs := make(map[Element][]Element)
+	for _, e := range n.sortedElements() {
+		for _, next := range nextElements(e) {
+			prevs[next] = append(prevs[next], e)
+		}
+	}
+	return prevs
+}
+
+// エレメントの後続エレメントの一覧を返す。
+func nextElements(e Element) []Element {
+	switch elm := e.(type) {
+	case *Job:
+		if elm.Next != nil {
+			return []Element{elm.Next}
+		}
+	case *Gateway:
+		return elm.Nexts
+	case *ExclusiveGateway:
+		return elm.Nexts
+	}
+	return nil
+}
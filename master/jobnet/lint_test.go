@@ -0,0 +1,153 @@
+package jobnet
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/unirita/cuto/master/jobnet/parser"
+)
+
+const lintTestBPMN = `
+<definitions>
+	<process>
+		<startEvent id="start"/>
+		<endEvent id="end"/>
+		<serviceTask id="j1" name="job1"/>
+		<serviceTask id="j2" name="job2"/>
+		<serviceTask id="j3" name="job3"/>
+		<serviceTask id="j4" name="job4"/>
+		<serviceTask id="j5" name="job5"/>
+		<parallelGateway id="gw1"/>
+		<parallelGateway id="gw2"/>
+		<sequenceFlow sourceRef="start" targetRef="j1"/>
+		<sequenceFlow sourceRef="j1" targetRef="gw1"/>
+		<sequenceFlow sourceRef="gw1" targetRef="j2"/>
+		<sequenceFlow sourceRef="gw1" targetRef="j3"/>
+		<sequenceFlow sourceRef="j2" targetRef="gw2"/>
+		<sequenceFlow sourceRef="j3" targetRef="gw2"/>
+		<sequenceFlow sourceRef="gw2" targetRef="j4"/>
+		<sequenceFlow sourceRef="j4" targetRef="end"/>
+	</process>
+</definitions>`
+
+const lintTestJobEx = `
+ジョブ名,ノード名,ポート番号,実行ファイル,パラメータ,環境変数,作業フォルダ,警告コード,警告出力,異常コード,異常出力,タイムアウト,セカンダリノード,セカンダリポート
+job1,node1,abc,,,,,,,,,,,
+job2,node2,70000,,$MJjob1:RC$ $MJjob3:RC$ $MSJOBNET:XX$,,,,,,,,bad host,0
+job3,node3,2015,,,A=$MJjob1:OUT$,,,,,,999,,
+job4,node4,2015,$MJjob1:RC$.sh,$MJjob2:RC$ $MJjob3:RC$ $MJjob1:XX$ $MSCAL:DATE$,,,,,,,,,
+nojob,node9,2015,,,,,,,,,,,`
+
+func lintTestNetwork(t *testing.T) []*LintIssue {
+	loadTestConfig()
+	n, _ := NewNetwork("lint")
+	if err := n.LoadElements(strings.NewReader(lintTestBPMN)); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	jobEx, err := parser.ParseJobEx(strings.NewReader(lintTestJobEx))
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	return n.Lint(jobEx)
+}
+
+func findLintIssue(issues []*LintIssue, rule string, id string, name string, msg string) *LintIssue {
+	for _, issue := range issues {
+		if issue.Rule == rule && issue.ID == id && issue.Name == name && strings.Contains(issue.Message, msg) {
+			return issue
+		}
+	}
+	return nil
+}
+
+func TestLint_全ての問題を検出する(t *testing.T) {
+	issues := lintTestNetwork(t)
+
+	expects := []struct {
+		level string
+		rule  string
+		id    string
+		name  string
+		msg   string
+	}{
+		{LINT_ERROR, "isolated", "j5", "job5", "not reachable"},
+		{LINT_WARNING, "jobex-missing", "j5", "job5", "not defined in jobex"},
+		{LINT_WARNING, "jobex-unknown", "", "nojob", "Jobex line[6]"},
+		{LINT_ERROR, "jobex-value", "j1", "job1", "port[abc]"},
+		{LINT_ERROR, "port", "j2", "job2", "Port[70000]"},
+		{LINT_ERROR, "secondary", "j2", "job2", "invalid host name"},
+		{LINT_ERROR, "secondary", "j2", "job2", "Secondary port[0]"},
+		{LINT_ERROR, "variable", "j2", "job2", "job[job3] which runs in parallel branch of gateway[gw1]"},
+		{LINT_ERROR, "variable", "j2", "job2", "$MSJOBNET:XX$"},
+		{LINT_WARNING, "timeout", "j3", "job3", "Timeout[999 min]"},
+		{LINT_ERROR, "variable", "j4", "job4", "in path is not expanded"},
+		{LINT_ERROR, "variable", "j4", "job4", "undefined tag[XX]"},
+	}
+	for _, e := range expects {
+		issue := findLintIssue(issues, e.rule, e.id, e.name, e.msg)
+		if issue == nil {
+			t.Errorf("問題[%s %s %s]が検出されていない。", e.rule, e.id, e.msg)
+			continue
+		}
+		if issue.Level != e.level {
+			t.Errorf("問題[%s %s]の重要度[%s]が想定と違っている。", e.rule, e.id, issue.Level)
+		}
+	}
+	if len(issues) != len(expects) {
+		for _, issue := range issues {
+			t.Log(issue)
+		}
+		t.Errorf("検出した問題の数[%d]が想定と違っている。", len(issues))
+	}
+}
+
+func TestLint_上流のジョブを参照する変数は問題としない(t *testing.T) {
+	issues := lintTestNetwork(t)
+
+	for _, issue := range issues {
+		if issue.Rule != "variable" {
+			continue
+		}
+		if strings.Contains(issue.Message, "param: Variable[$MJjob1:RC$]") ||
+			strings.Contains(issue.Message, "$MJjob1:OUT$") ||
+			strings.Contains(issue.Message, "$MJjob2:RC$") ||
+			strings.Contains(issue.Message, "$MSCAL:DATE$") ||
+			(issue.ID == "j4" && strings.Contains(issue.Message, "$MJjob3:RC$")) {
+			t.Errorf("問題ではない変数参照が検出された: %s", issue)
+		}
+	}
+}
+
+func TestLint_フローが終端しないエレメントを全て検出する(t *testing.T) {
+	bpmn := `
+<definitions>
+	<process>
+		<startEvent id="start"/>
+		<endEvent id="end"/>
+		<serviceTask id="j1" name="job1"/>
+		<serviceTask id="j2" name="job2"/>
+		<serviceTask id="j3" name="job3"/>
+		<parallelGateway id="gw1"/>
+		<sequenceFlow sourceRef="start" targetRef="j1"/>
+		<sequenceFlow sourceRef="j1" targetRef="gw1"/>
+		<sequenceFlow sourceRef="gw1" targetRef="j2"/>
+		<sequenceFlow sourceRef="gw1" targetRef="j3"/>
+		<sequenceFlow sourceRef="j3" targetRef="end"/>
+	</process>
+</definitions>`
+	loadTestConfig()
+	n, _ := NewNetwork("lint")
+	if err := n.LoadElements(strings.NewReader(bpmn)); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	issues := n.Lint(nil)
+
+	if findLintIssue(issues, "flow", "j2", "job2", "cannot terminate network") == nil {
+		t.Error("終端しないエレメントが検出されていない。")
+	}
+	for _, issue := range issues {
+		if strings.HasPrefix(issue.Rule, "jobex") {
+			t.Errorf("拡張ジョブ定義を指定しない場合に検出されない問題が検出された: %s", issue)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package jobnet
+
+import (
+	"fmt"
+
+	"github.com/unirita/cuto/console"
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/db/tx"
+)
+
+// 完了扱いとするジョブの指定が正しいかを検査する。
+//
+// return : エラー情報。
+func (n *Network) checkMarkJobs() error {
+	if len(n.SkipJobs) == 0 && len(n.ForceJobs) == 0 {
+		return nil
+	}
+	if n.RerunFrom != "" || len(n.RerunOnly) > 0 {
+		return fmt.Errorf("Cannot designate jobs to skip or force with rerun-from or rerun-only jobs.")
+	}
+	for _, name := range append(append([]string{}, n.SkipJobs...), n.ForceJobs...) {
+		if len(n.findJobsByName(name)) == 0 {
+			return fmt.Errorf("Job[%s] is not found in jobnet[%s].", name, n.Name)
+		}
+	}
+	return nil
+}
+
+// SkipJobs・ForceJobsで指定されたジョブを完了扱いとし、DBへ記録する。
+// SkipJobsのジョブは実行済みかどうかに関わらずSKIPPEDとし、ForceJobsのジョブは実行済みのもののみFORCEDとする。
+// 詳細メッセージには、操作者名と理由を記録する。
+//
+// return : エラー情報。
+func (n *Network) markJobs() error {
+	for _, name := range n.ForceJobs {
+		for _, j := range n.findJobsByName(name) {
+			if _, exists := n.Result.GetJobResults(j.id); !exists {
+				return fmt.Errorf("Job[%s] cannot be forced to complete because it has not been executed.", name)
+			}
+		}
+	}
+
+	for _, name := range n.SkipJobs {
+		for _, j := range n.findJobsByName(name) {
+			if err := j.mark(db.SKIPPED, db.ST_SKIPPED, n.MarkOperator, n.MarkReason); err != nil {
+				return err
+			}
+		}
+	}
+	for _, name := range n.ForceJobs {
+		for _, j := range n.findJobsByName(name) {
+			if err := j.mark(db.FORCED, db.ST_FORCED, n.MarkOperator, n.MarkReason); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ジョブを完了扱いとする。
+// 実行結果が存在する場合は、その結果を試行毎の実行結果として退避してから更新する。
+func (j *Job) mark(status int, st string, operator string, reason string) error {
+	conn := j.Instance.Result.GetConnection()
+	jobres, exists := j.Instance.Result.GetJobResults(j.id)
+	if exists {
+		j.archiveResult(jobres)
+	} else {
+		jobres = db.NewJobResult(j.Instance.ID)
+		jobres.JobId = j.id
+		jobres.JobName = j.Name
+		jobres.Node = j.Node
+		jobres.Port = j.Port
+		if err := tx.InsertJob(conn, jobres, &j.Instance.localMutex); err != nil {
+			return err
+		}
+		j.Instance.Result.AddJobResults(j.id, jobres)
+	}
+
+	detail := fmt.Sprintf("%s by %s.", st, operator)
+	if reason != "" {
+		detail = fmt.Sprintf("%s by %s: %s", st, operator, reason)
+	}
+	if err := tx.MarkJob(conn, jobres, status, detail, &j.Instance.localMutex); err != nil {
+		return err
+	}
+	console.Display("CTM044I", j.Name, st, operator, j.Instance.ID, j.id)
+	return nil
+}
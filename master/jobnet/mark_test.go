@@ -0,0 +1,121 @@
+package jobnet
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/db/query"
+	"github.com/unirita/cuto/db/tx"
+)
+
+func TestCheckMarkJobs_存在しないジョブ名が指定された場合はエラー(t *testing.T) {
+	n := loadRerunTestNetwork(t)
+	n.SkipJobs = []string{"job1"}
+	n.ForceJobs = []string{"nojob"}
+	if err := n.checkMarkJobs(); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestCheckMarkJobs_強制再実行の指定と同時に指定された場合はエラー(t *testing.T) {
+	n := loadRerunTestNetwork(t)
+	n.SkipJobs = []string{"job1"}
+	n.RerunFrom = "job2"
+	if err := n.checkMarkJobs(); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestMarkJobs_指定ジョブを完了扱いとして記録する(t *testing.T) {
+	n := newTestNetwork()
+	n.ID = 1
+	if err := n.LoadElements(strings.NewReader(rerunTestBPMN)); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	n.SkipJobs = []string{"job3"}
+	n.ForceJobs = []string{"job2"}
+	n.MarkOperator = "operator"
+	n.MarkReason = "fixed manually"
+
+	conn := n.Result.GetConnection()
+	defer conn.GetDb().Exec("delete from JOB where ID = ? and JOBID in ('j2', 'j3')", n.ID)
+	defer conn.GetDb().Exec("delete from JOBATTEMPT where ID = ? and JOBID in ('j2', 'j3')", n.ID)
+
+	prev := db.NewJobResult(n.ID)
+	prev.JobId = "j2"
+	prev.JobName = "job2"
+	prev.Status = db.ABNORMAL
+	prev.Detail = "error"
+	if err := tx.InsertJob(conn, prev, &n.localMutex); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	n.Result.AddJobResults(prev.JobId, prev)
+
+	if err := n.markJobs(); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+
+	jobs, err := query.GetJobMapOfTargetNetwork(conn, n.ID)
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if j2 := jobs["j2"]; j2 == nil || j2.Status != db.FORCED || j2.Detail != "FORCED END by operator: fixed manually" {
+		t.Errorf("強制完了したジョブの実行結果%vが想定と違っている。", j2)
+	}
+	if j3 := jobs["j3"]; j3 == nil || j3.Status != db.SKIPPED || j3.Detail != "SKIPPED by operator: fixed manually" {
+		t.Errorf("スキップしたジョブの実行結果%vが想定と違っている。", j3)
+	}
+
+	attempts, err := query.GetJobAttemptsOfTargetNetwork(conn, n.ID)
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	found := false
+	for _, a := range attempts {
+		if a.JobId == "j2" {
+			found = true
+			if a.Status != db.ABNORMAL || a.Detail != "error" {
+				t.Errorf("退避した実行結果[Status = %d, Detail = %s]が想定と違っている。", a.Status, a.Detail)
+			}
+		}
+	}
+	if !found {
+		t.Error("強制完了前の実行結果が退避されていない。")
+	}
+}
+
+func TestMarkJobs_未実行のジョブは強制完了できない(t *testing.T) {
+	n := newTestNetwork()
+	n.ID = 1
+	if err := n.LoadElements(strings.NewReader(rerunTestBPMN)); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	n.ForceJobs = []string{"job4"}
+
+	if err := n.markJobs(); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestJobExecute_リラン実行_完了扱いのジョブは実行しない(t *testing.T) {
+	for _, status := range []int{db.SKIPPED, db.FORCED} {
+		n := newTestNetwork()
+		n.ID = 1
+		j1, _ := NewJob("jobid1", "job1", n)
+		j2, _ := NewJob("jobid2", "job2", n)
+		j1.IsRerunJob = true
+		j1.Next = j2
+		j1.sendRequest = testSendRequest_Error
+
+		n.Result.AddJobResults(j1.id, &db.JobResult{JobId: j1.id, Status: status})
+
+		elm, err := j1.Execute()
+		if err != nil {
+			t.Errorf("ステータス[%d]で想定外のエラーが発生した： %s", status, err)
+		}
+		if elm != j2 {
+			t.Errorf("ステータス[%d]で次のジョブが返されていない。", status)
+		}
+	}
+}
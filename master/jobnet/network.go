@@ -8,31 +8,47 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"sync"
+	"time"
 
+	"github.com/unirita/cuto/calendar"
 	"github.com/unirita/cuto/console"
 	"github.com/unirita/cuto/db"
 	"github.com/unirita/cuto/db/tx"
 	"github.com/unirita/cuto/log"
 	"github.com/unirita/cuto/master/config"
 	"github.com/unirita/cuto/master/jobnet/parser"
+	"github.com/unirita/cuto/master/notify"
 	"github.com/unirita/cuto/message"
+	"github.com/unirita/cuto/utctime"
 	"github.com/unirita/cuto/util"
 )
 
 // ジョブネット全体を表す構造体
 type Network struct {
-	ID         int                // ジョブネットワークID。
-	Name       string             // ジョブネットワーク名。
-	Start      Element            // スタートイベントのノード。
-	End        Element            // エンドイベントのノード。
-	MasterPath string             // ジョブネットワークファイルパス。
-	JobExPath  string             // 拡張ジョブ定義ファイルパス。
-	elements   map[string]Element // ジョブネットワークの構成要素Map。
-	Result     *tx.ResultMap      // 実行結果情報。
-	globalLock *util.LockHandle   // マスタ間ロックハンドル
-	localMutex sync.Mutex         // ゴルーチン間のミューテックス
+	ID            int                // ジョブネットワークID。
+	Name          string             // ジョブネットワーク名。
+	Start         Element            // スタートイベントのノード。
+	End           Element            // エンドイベントのノード。
+	MasterPath    string             // ジョブネットワークファイルパス。
+	JobExPath     string             // 拡張ジョブ定義ファイルパス。
+	Calendar      *calendar.Calendar // 営業日カレンダー。
+	elements      map[string]Element // ジョブネットワークの構成要素Map。
+	Result        *tx.ResultMap      // 実行結果情報。
+	globalLock    *util.LockHandle   // マスタ間ロックハンドル
+	localMutex    sync.Mutex         // ゴルーチン間のミューテックス
+	baseDate      time.Time          // 営業日判定の基準日（ジョブネットワークの開始日）
+	cancelled     bool               // 実行中止要求を受けたかどうか
+	cancelCh      chan struct{}      // 実行中止要求を通知するチャネル
+	DisplayOutput bool               // ジョブの出力をコンソールへ表示するかどうか。
+	RerunFrom     string             // リラン時に強制再実行を開始するジョブ名。
+	RerunOnly     []string           // リラン時に再実行するジョブ名のリスト。
+	SkipJobs      []string           // リラン時に実行せず完了扱いとするジョブ名のリスト。
+	ForceJobs     []string           // リラン時に強制的に完了扱いとするジョブ名のリスト。
+	MarkOperator  string             // ジョブを完了扱いとした操作者名。
+	MarkReason    string             // ジョブを完了扱いとした理由。
 }
 
 // cuto masterが使用するミューテックス名。
@@ -47,6 +63,7 @@ func NewNetwork(name string) (*Network, error) {
 	nwk := new(Network)
 	nwk.Name = name
 	nwk.elements = make(map[string]Element)
+	nwk.cancelCh = make(chan struct{})
 	filePrefix := filepath.Join(config.Dir.JobnetDir, name)
 	nwk.MasterPath = filePrefix + ".bpmn"
 	nwk.JobExPath = filePrefix + ".csv"
@@ -123,6 +140,13 @@ func (n *Network) setElements(proc *parser.Process) error {
 		n.elements[g.ID] = NewGateway(g.ID)
 	}
 
+	for _, g := range proc.Exclusive {
+		if _, exists := n.elements[g.ID]; exists {
+			return fmt.Errorf("Element[id = %s] duplicated.", g.ID)
+		}
+		n.elements[g.ID] = NewExclusiveGateway(g.ID, g.Default)
+	}
+
 	sid := proc.Start[0].ID
 	eid := proc.End[0].ID
 
@@ -161,7 +185,11 @@ func (n *Network) setElements(proc *parser.Process) error {
 			if !ok {
 				return fmt.Errorf("There is a sequenceFlow which refers imaginary element[id = %s].", f.To)
 			}
-			if err := from.AddNext(to); err != nil {
+			if g, ok := from.(*ExclusiveGateway); ok {
+				if err := g.AddBranch(to, f.ID, f.Condition); err != nil {
+					return err
+				}
+			} else if err := from.AddNext(to); err != nil {
 				return err
 			}
 		}
@@ -183,6 +211,20 @@ func (n *Network) LoadJobEx() error {
 	return nil
 }
 
+// カレンダーファイルをロードし、ネットワークへ営業日カレンダーをセットする。
+// ジョブネットワーク名のカレンダーファイル、デフォルトカレンダーファイルの順に探し、どちらも存在しない場合は月曜～金曜を営業日とする。
+//
+// return : エラー情報。
+func (n *Network) LoadCalendar() error {
+	cal, err := calendar.LoadFor(config.Dir.JobnetDir, n.Name)
+	if err != nil {
+		return err
+	}
+	n.Calendar = cal
+
+	return nil
+}
+
 // ネットワーク内のジョブへ拡張ジョブ定義のパース結果をセットする。
 func (n *Network) setJobEx(m map[string]*parser.JobEx) {
 	for _, e := range n.elements {
@@ -203,6 +245,12 @@ func (n *Network) setJobEx(m map[string]*parser.JobEx) {
 				j.Timeout = je.TimeoutMin * 60
 				j.SecondaryNode = je.SecondaryNode
 				j.SecondaryPort = je.SecondaryPort
+				j.RunDay = je.RunDay
+				j.RetryMax = je.RetryMax
+				j.RetryDelaySec = je.RetryDelaySec
+				j.RetryBackoff = je.RetryBackoff
+				j.RetryRC = je.RetryRC
+				j.RetryPtn = je.RetryPtn
 			}
 			j.SetDefaultEx()
 		default:
@@ -211,6 +259,72 @@ func (n *Network) setJobEx(m map[string]*parser.JobEx) {
 	}
 }
 
+// ネットワーク内のジョブが要求を送信するservantのアドレスを、"ホスト名:ポート番号"の形式で返す。
+// 事前にLoadJobExで拡張ジョブ定義を読み込んでおく必要がある。
+//
+// return : プライマリservantのアドレス。
+//
+// return : セカンダリservantのアドレス。プライマリservantのアドレスと重複するものは含まない。
+func (n *Network) ServantAddresses() ([]string, []string) {
+	primaries := make(map[string]bool)
+	secondaries := make(map[string]bool)
+	for _, e := range n.elements {
+		j, ok := e.(*Job)
+		if !ok {
+			continue
+		}
+		host, _, _ := explodeNodeString(j.Node)
+		primaries[fmt.Sprintf("%s:%d", host, j.Port)] = true
+		if j.SecondaryNode != "" {
+			host, _, _ := explodeNodeString(j.SecondaryNode)
+			secondaries[fmt.Sprintf("%s:%d", host, j.SecondaryPort)] = true
+		}
+	}
+	for addr := range primaries {
+		delete(secondaries, addr)
+	}
+	return sortedKeys(primaries), sortedKeys(secondaries)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ロードしたネットワーク定義を、ジョブネットワークの実行前と同じ手順で検証する。
+// 実行フローを検査した上で営業日カレンダーと拡張ジョブ定義を読み込み、変数参照を検査する。
+// エラーを検出した場合は、その内容をコンソールへ出力する。
+//
+// return : エラー情報。
+func (n *Network) Validate() error {
+	if err := n.DetectFlowError(); err != nil {
+		console.Display("CTM011E", n.MasterPath, err)
+		return err
+	}
+
+	if err := n.LoadCalendar(); err != nil {
+		console.Display("CTM032E", err)
+		return err
+	}
+
+	if err := n.LoadJobEx(); err != nil {
+		console.Display("CTM004E", n.JobExPath)
+		log.Error(err)
+		return err
+	}
+
+	if err := n.DetectVariableError(); err != nil {
+		console.Display("CTM011E", n.MasterPath, err)
+		return err
+	}
+
+	return nil
+}
+
 // 実行フローのエラー検出を行う。
 //
 // return : エラー情報。
@@ -260,27 +374,75 @@ func (n *Network) scanFlow(e Element, novisit map[string]Element) error {
 		if len(g.Nexts) == 1 {
 			return n.scanFlow(g.Nexts[0], novisit)
 		} else {
-			var jct Element = nil
-			for _, branch := range g.Nexts {
-				bind, err := n.scanFlowParallel(branch, novisit)
-				if err != nil {
-					return err
-				}
-
-				if jct == nil {
-					jct = bind
-				} else if jct != bind {
-					return fmt.Errorf("Cannot nest branches.")
-				}
+			jct, err := n.scanFlowSplit(g, novisit)
+			if err != nil {
+				return err
 			}
 
 			return n.scanFlow(jct, novisit)
 		}
+	case *ExclusiveGateway:
+		g := e.(*ExclusiveGateway)
+
+		if len(g.Nexts) == 1 {
+			return n.scanFlow(g.Nexts[0], novisit)
+		}
+		jct, err := n.scanFlowExclusive(g, novisit)
+		if err != nil {
+			return err
+		}
+		return n.scanFlow(jct, novisit)
 	default:
 		return fmt.Errorf("Irregal element was detected.")
 	}
 }
 
+// 並列ゲートウェイの各分岐経路を検査し、分岐経路を結合するゲートウェイを返す。
+func (n *Network) scanFlowSplit(g *Gateway, novisit map[string]Element) (Element, error) {
+	var jct Element = nil
+	for _, branch := range g.Nexts {
+		bind, err := n.scanFlowParallel(branch, novisit)
+		if err != nil {
+			return nil, err
+		}
+
+		if bind == g {
+			return nil, fmt.Errorf("Branch of gateway[id = %s] loops back.", g.ID())
+		}
+		if jct == nil {
+			jct = bind
+		} else if jct != bind {
+			return nil, fmt.Errorf("Branch is combined by more than one gateway.")
+		}
+	}
+
+	return jct, nil
+}
+
+// 分岐経路内でネストした並列ゲートウェイの分岐・結合を検査し、結合ゲートウェイの後続エレメントを返す。
+// 結合ゲートウェイがさらに分岐する場合は、その分岐・結合も検査する。
+func (n *Network) scanFlowNestedSplit(g *Gateway, novisit map[string]Element) (Element, error) {
+	for {
+		jct, err := n.scanFlowSplit(g, novisit)
+		if err != nil {
+			return nil, err
+		}
+		delete(novisit, jct.ID())
+
+		var ok bool
+		g, ok = jct.(*Gateway)
+		if !ok {
+			return nil, fmt.Errorf("Irregal element was detected.")
+		}
+		switch len(g.Nexts) {
+		case 0:
+			return nil, fmt.Errorf("EndEvent cannot connect with branch.")
+		case 1:
+			return g.Nexts[0], nil
+		}
+	}
+}
+
 func (n *Network) scanFlowParallel(e Element, novisit map[string]Element) (Element, error) {
 	delete(novisit, e.ID())
 	switch e.(type) {
@@ -291,12 +453,123 @@ func (n *Network) scanFlowParallel(e Element, novisit map[string]Element) (Eleme
 		}
 		return n.scanFlowParallel(j.Next, novisit)
 	case *Gateway:
-		return e, nil
+		g := e.(*Gateway)
+		if !g.isSplit() {
+			return e, nil
+		}
+
+		next, err := n.scanFlowNestedSplit(g, novisit)
+		if err != nil {
+			return nil, err
+		}
+		return n.scanFlowParallel(next, novisit)
+	case *ExclusiveGateway:
+		g := e.(*ExclusiveGateway)
+		switch len(g.Nexts) {
+		case 0:
+			return nil, fmt.Errorf("EndEvent cannot connect with branch.")
+		case 1:
+			return n.scanFlowParallel(g.Nexts[0], novisit)
+		}
+
+		jct, err := n.scanFlowExclusive(g, novisit)
+		if err != nil {
+			return nil, err
+		}
+		return n.scanFlowParallel(jct, novisit)
 	default:
 		return nil, fmt.Errorf("Irregal element was detected.")
 	}
 }
 
+// 排他ゲートウェイの各分岐経路を検査し、分岐経路を合流させる排他ゲートウェイを返す。
+func (n *Network) scanFlowExclusive(g *ExclusiveGateway, novisit map[string]Element) (Element, error) {
+	if err := g.validateConditions(); err != nil {
+		return nil, err
+	}
+
+	var jct Element = nil
+	for _, branch := range g.Nexts {
+		e := branch
+		passed := make(map[Element]bool)
+	scan:
+		for {
+			switch e.(type) {
+			case *Job:
+				if passed[e] {
+					return nil, fmt.Errorf("Branch of exclusiveGateway[id = %s] loops back.", g.ID())
+				}
+				passed[e] = true
+				delete(novisit, e.ID())
+				j := e.(*Job)
+				if j.Next == nil {
+					return nil, fmt.Errorf("EndEvent cannot connect with branch.")
+				}
+				e = j.Next
+			case *ExclusiveGateway:
+				if e == g {
+					return nil, fmt.Errorf("Branch of exclusiveGateway[id = %s] loops back.", g.ID())
+				}
+				xg := e.(*ExclusiveGateway)
+				if !xg.isSplit() {
+					break scan
+				}
+				delete(novisit, xg.ID())
+				next, err := n.scanFlowNestedExclusive(xg, novisit)
+				if err != nil {
+					return nil, err
+				}
+				e = next
+			case *Gateway:
+				pg := e.(*Gateway)
+				if !pg.isSplit() {
+					return nil, fmt.Errorf("Branch of exclusiveGateway[id = %s] is combined by gateway[id = %s].", g.ID(), pg.ID())
+				}
+				delete(novisit, pg.ID())
+				next, err := n.scanFlowNestedSplit(pg, novisit)
+				if err != nil {
+					return nil, err
+				}
+				e = next
+			default:
+				return nil, fmt.Errorf("Irregal element was detected.")
+			}
+		}
+
+		if jct == nil {
+			jct = e
+		} else if jct != e {
+			return nil, fmt.Errorf("Branch is combined by more than one gateway.")
+		}
+	}
+
+	return jct, nil
+}
+
+// 分岐経路内でネストした排他ゲートウェイの分岐・合流を検査し、合流ゲートウェイの後続エレメントを返す。
+// 合流ゲートウェイがさらに分岐する場合は、その分岐・合流も検査する。
+func (n *Network) scanFlowNestedExclusive(g *ExclusiveGateway, novisit map[string]Element) (Element, error) {
+	for {
+		jct, err := n.scanFlowExclusive(g, novisit)
+		if err != nil {
+			return nil, err
+		}
+		delete(novisit, jct.ID())
+
+		var ok bool
+		g, ok = jct.(*ExclusiveGateway)
+		if !ok {
+			return nil, fmt.Errorf("Irregal element was detected.")
+		}
+		switch len(g.Nexts) {
+		case 0:
+			return nil, fmt.Errorf("EndEvent cannot connect with branch.")
+		case 1:
+			return g.Nexts[0], nil
+		}
+	}
+}
+
 // ネットワークを実行する。
 //
 // return : エラー情報。
@@ -322,6 +595,14 @@ func (n *Network) Rerun() error {
 	if n.Start == nil {
 		return fmt.Errorf("Start element of network is nil.")
 	}
+	if err := n.setForceRerunJob(); err != nil {
+		console.Display("CTM019E", err)
+		return err
+	}
+	if err := n.checkMarkJobs(); err != nil {
+		console.Display("CTM019E", err)
+		return err
+	}
 
 	err := n.resume()
 	if err != nil {
@@ -334,6 +615,11 @@ func (n *Network) Rerun() error {
 		return fmt.Errorf("JOBNETWORK [%d] still running.", n.ID)
 	}
 
+	if err := n.markJobs(); err != nil {
+		console.Display("CTM019E", err)
+		return err
+	}
+
 	console.Display("CTM012I", n.Name, n.ID)
 	n.setIsRerunJob()
 
@@ -392,6 +678,7 @@ func (n *Network) start() error {
 	n.ID = n.Result.JobnetResult.ID
 	message.AddSysValue(`JOBNET`, `ID`, strconv.Itoa(n.ID))
 	message.AddSysValue(`JOBNET`, `SD`, n.Result.JobnetResult.StartDate)
+	n.setCalendarValues(n.Result.JobnetResult.StartDate)
 
 	return nil
 }
@@ -419,17 +706,63 @@ func (n *Network) resume() error {
 
 	message.AddSysValue(`JOBNET`, `ID`, strconv.Itoa(n.ID))
 	message.AddSysValue(`JOBNET`, `SD`, n.Result.JobnetResult.StartDate)
+	n.setCalendarValues(n.Result.JobnetResult.StartDate)
 
 	return nil
 }
 
+// ジョブネットワークの開始日startDateを基準日として、カレンダー変数の値をセットする。
+func (n *Network) setCalendarValues(startDate string) {
+	if n.Calendar == nil {
+		n.Calendar = calendar.Standard()
+	}
+
+	sd, err := time.ParseInLocation(utctime.Default, startDate, time.UTC)
+	if err != nil {
+		log.Error(fmt.Errorf("Cannot parse start date[%s] of jobnet.", startDate))
+		sd = time.Now()
+	}
+	n.baseDate = sd.Local()
+
+	bizDate := n.Calendar.BusinessDate(n.baseDate)
+	message.AddSysValue(`CAL`, `DATE`, formatCalendarDate(n.baseDate))
+	message.AddSysValue(`CAL`, `BIZDATE`, formatCalendarDate(bizDate))
+	message.AddSysValue(`CAL`, `PREVBIZDATE`, formatCalendarDate(n.Calendar.PrevBusinessDay(bizDate)))
+	message.AddSysValue(`CAL`, `NEXTBIZDATE`, formatCalendarDate(n.Calendar.NextBusinessDay(n.baseDate)))
+	message.AddSysValue(`CAL`, `ISBIZDAY`, formatCalendarFlag(n.Calendar.IsBusinessDay(n.baseDate)))
+	message.AddSysValue(`CAL`, `FIRSTBIZDAY`, formatCalendarFlag(n.Calendar.IsFirstBusinessDay(n.baseDate)))
+	message.AddSysValue(`CAL`, `LASTBIZDAY`, formatCalendarFlag(n.Calendar.IsLastBusinessDay(n.baseDate)))
+}
+
+// カレンダー変数の日付をYYYYMMDD形式へ変換する。該当日が無い場合は空文字列を返す。
+func formatCalendarDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(utctime.Date8Num)
+}
+
+// カレンダー変数の真偽値を1または0へ変換する。
+func formatCalendarFlag(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
 // ジョブネットワークの終了処理
 func (n *Network) end(err error) error {
-	if err != nil {
+	if err != nil && n.IsCancelled() {
+		n.Result.EndJobNetwork(db.CANCELLED, detailCancel)
+	} else if err != nil {
 		n.Result.EndJobNetwork(db.ABNORMAL, err.Error())
 	} else {
 		n.Result.EndJobNetwork(db.NORMAL, "")
 	}
+	if n.Result.JobnetResult != nil {
+		notify.Send(notify.NewJobnetEvent(n.Result.JobnetResult))
+	}
+	notify.Wait()
 	return err
 }
 
@@ -5,10 +5,13 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/unirita/cuto/master/config"
 	"github.com/unirita/cuto/master/jobnet/parser"
+	"github.com/unirita/cuto/message"
 	"github.com/unirita/cuto/testutil"
+	"github.com/unirita/cuto/utctime"
 )
 
 // ※補足事項
@@ -523,6 +526,63 @@ func TestLoadJobEx_拡張ジョブファイルが存在しない場合にエラ
 	}
 }
 
+func TestLoadCalendar_カレンダーファイルをロードできる(t *testing.T) {
+	config.Dir.JobnetDir = filepath.Join(testutil.GetBaseDir(), "master", "jobnet", "_testdata")
+	defer loadTestConfig()
+
+	n, _ := NewNetwork("calnet")
+	if err := n.LoadCalendar(); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if n.Calendar.Name != "calnet" {
+		t.Errorf("ロードしたカレンダー[%s]が想定と違っている。", n.Calendar.Name)
+	}
+}
+
+func TestLoadCalendar_カレンダーファイルが存在しない場合は標準カレンダーを使用する(t *testing.T) {
+	loadTestConfig()
+
+	n, _ := NewNetwork("noexistscalendar")
+	if err := n.LoadCalendar(); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if n.Calendar == nil || n.Calendar.Path != "" {
+		t.Error("標準カレンダーがセットされていない。")
+	}
+}
+
+func TestSetCalendarValues_カレンダー変数をセットできる(t *testing.T) {
+	config.Dir.JobnetDir = filepath.Join(testutil.GetBaseDir(), "master", "jobnet", "_testdata")
+	defer loadTestConfig()
+
+	n, _ := NewNetwork("calnet")
+	if err := n.LoadCalendar(); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	sd := time.Date(2016, 1, 2, 12, 0, 0, 0, time.Local).UTC().Format(utctime.Default)
+	n.setCalendarValues(sd)
+
+	expects := map[string]string{
+		"$MSCAL:DATE$":        "20160102",
+		"$MSCAL:BIZDATE$":     "20151231",
+		"$MSCAL:PREVBIZDATE$": "20151230",
+		"$MSCAL:NEXTBIZDATE$": "20160105",
+		"$MSCAL:ISBIZDAY$":    "0",
+		"$MSCAL:FIRSTBIZDAY$": "0",
+		"$MSCAL:LASTBIZDAY$":  "0",
+	}
+	for key, expect := range expects {
+		actual, err := message.ExpandStringVars(key, 'M', 'S')
+		if err != nil {
+			t.Errorf("変数[%s]の展開で想定外のエラーが発生した: %s", key, err)
+			continue
+		}
+		if actual != expect {
+			t.Errorf("変数[%s]の値[%s]が想定と違っている。", key, actual)
+		}
+	}
+}
+
 func TestSetJobEx_拡張ジョブ情報をセットできる(t *testing.T) {
 	proc := &parser.Process{
 		Start:   make([]parser.StartEvent, 1),
@@ -548,6 +608,7 @@ func TestSetJobEx_拡張ジョブ情報をセットできる(t *testing.T) {
 	je1.TimeoutMin = 60
 	je1.SecondaryNode = "secondary"
 	je1.SecondaryPort = 2
+	je1.RunDay = parser.RUNDAY_BIZDAY
 
 	je2 := new(parser.JobEx)
 	je2.Node = "node2"
@@ -605,6 +666,9 @@ func TestSetJobEx_拡張ジョブ情報をセットできる(t *testing.T) {
 	if task1.SecondaryPort != 2 {
 		t.Errorf("ポート番号[%d]は想定と違っている", task1.SecondaryPort)
 	}
+	if task1.RunDay != parser.RUNDAY_BIZDAY {
+		t.Errorf("実行日[%s]は想定と違っている", task1.RunDay)
+	}
 
 	task2 := nwk.elements["task2"].(*Job)
 	if task2.Node != "node2" {
@@ -945,7 +1009,7 @@ func TestDetectFlowError_分岐を結合せずに終了した場合はエラー(
 	}
 }
 
-func TestDetectFlowError_分岐がネストした場合はエラー(t *testing.T) {
+func TestDetectFlowError_ネストした分岐が結合されずに外側の分岐と結合した場合はエラー(t *testing.T) {
 	proc := &parser.Process{
 		Start:   make([]parser.StartEvent, 1),
 		End:     make([]parser.EndEvent, 1),
@@ -981,6 +1045,85 @@ func TestDetectFlowError_分岐がネストした場合はエラー(t *testing.T
 	}
 }
 
+func generateNestedTestProcess() *parser.Process {
+	proc := &parser.Process{
+		Start:   make([]parser.StartEvent, 1),
+		End:     make([]parser.EndEvent, 1),
+		Task:    make([]parser.ServiceTask, 6),
+		Gateway: make([]parser.ParallelGateway, 4),
+		Flow:    make([]parser.SequenceFlow, 13),
+	}
+	proc.Start[0] = parser.StartEvent{ID: "start"}
+	proc.End[0] = parser.EndEvent{ID: "end"}
+	proc.Task[0] = parser.ServiceTask{ID: "task1", Name: "job1"}
+	proc.Task[1] = parser.ServiceTask{ID: "task2", Name: "job2"}
+	proc.Task[2] = parser.ServiceTask{ID: "task3", Name: "job3"}
+	proc.Task[3] = parser.ServiceTask{ID: "task4", Name: "job4"}
+	proc.Task[4] = parser.ServiceTask{ID: "task5", Name: "job5"}
+	proc.Task[5] = parser.ServiceTask{ID: "task6", Name: "job6"}
+	proc.Gateway[0] = parser.ParallelGateway{ID: "gw1"}
+	proc.Gateway[1] = parser.ParallelGateway{ID: "gw2"}
+	proc.Gateway[2] = parser.ParallelGateway{ID: "gw3"}
+	proc.Gateway[3] = parser.ParallelGateway{ID: "gw4"}
+	// start -> task1 -> [task2 -> [task3, task4] -> task5, task6] -> end
+	proc.Flow[0] = parser.SequenceFlow{From: "start", To: "task1"}
+	proc.Flow[1] = parser.SequenceFlow{From: "task1", To: "gw1"}
+	proc.Flow[2] = parser.SequenceFlow{From: "gw1", To: "task2"}
+	proc.Flow[3] = parser.SequenceFlow{From: "gw1", To: "task6"}
+	proc.Flow[4] = parser.SequenceFlow{From: "task2", To: "gw2"}
+	proc.Flow[5] = parser.SequenceFlow{From: "gw2", To: "task3"}
+	proc.Flow[6] = parser.SequenceFlow{From: "gw2", To: "task4"}
+	proc.Flow[7] = parser.SequenceFlow{From: "task3", To: "gw3"}
+	proc.Flow[8] = parser.SequenceFlow{From: "task4", To: "gw3"}
+	proc.Flow[9] = parser.SequenceFlow{From: "gw3", To: "task5"}
+	proc.Flow[10] = parser.SequenceFlow{From: "task5", To: "gw4"}
+	proc.Flow[11] = parser.SequenceFlow{From: "task6", To: "gw4"}
+	proc.Flow[12] = parser.SequenceFlow{From: "gw4", To: "end"}
+	return proc
+}
+
+func TestDetectFlowError_ネストした分岐を許容する(t *testing.T) {
+	nwk, _ := NewNetwork("test")
+	nwk.setElements(generateNestedTestProcess())
+	err := nwk.DetectFlowError()
+	if err != nil {
+		t.Fatalf("想定外のエラーが検出された: %s", err)
+	}
+}
+
+func TestDetectFlowError_ネストした分岐の結合ゲートウェイが再分岐する場合を許容する(t *testing.T) {
+	proc := generateNestedTestProcess()
+	// gw3で結合した後、task5とtask7へ再分岐し、gw5で結合する。
+	proc.Task = append(proc.Task, parser.ServiceTask{ID: "task7", Name: "job7"})
+	proc.Gateway = append(proc.Gateway, parser.ParallelGateway{ID: "gw5"})
+	proc.Flow[10] = parser.SequenceFlow{From: "task5", To: "gw5"}
+	proc.Flow = append(proc.Flow,
+		parser.SequenceFlow{From: "gw3", To: "task7"},
+		parser.SequenceFlow{From: "task7", To: "gw5"},
+		parser.SequenceFlow{From: "gw5", To: "gw4"})
+
+	nwk, _ := NewNetwork("test")
+	nwk.setElements(proc)
+	err := nwk.DetectFlowError()
+	if err != nil {
+		t.Fatalf("想定外のエラーが検出された: %s", err)
+	}
+}
+
+func TestDetectFlowError_ネストした分岐が別々のゲートウェイで結合する場合はエラー(t *testing.T) {
+	proc := generateNestedTestProcess()
+	proc.Gateway = append(proc.Gateway, parser.ParallelGateway{ID: "gw5"})
+	proc.Flow[8] = parser.SequenceFlow{From: "task4", To: "gw5"}
+	proc.Flow = append(proc.Flow, parser.SequenceFlow{From: "gw5", To: "task5"})
+
+	nwk, _ := NewNetwork("test")
+	nwk.setElements(proc)
+	err := nwk.DetectFlowError()
+	if err == nil {
+		t.Fatalf("エラーが検出されていない。")
+	}
+}
+
 func TestDetectFlowError_定義外の型の要素が発見されたらエラー_分岐外(t *testing.T) {
 	proc := &parser.Process{
 		Start: make([]parser.StartEvent, 1),
@@ -1048,6 +1191,219 @@ func TestDetectFlowError_定義外の型の要素が発見されたらエラー_
 	}
 }
 
+func generateExclusiveTestProcess() *parser.Process {
+	proc := &parser.Process{
+		Start:     make([]parser.StartEvent, 1),
+		End:       make([]parser.EndEvent, 1),
+		Task:      make([]parser.ServiceTask, 3),
+		Exclusive: make([]parser.ExclusiveGateway, 2),
+		Flow:      make([]parser.SequenceFlow, 7),
+	}
+	proc.Start[0] = parser.StartEvent{ID: "start"}
+	proc.End[0] = parser.EndEvent{ID: "end"}
+	proc.Task[0] = parser.ServiceTask{ID: "task1", Name: "job1"}
+	proc.Task[1] = parser.ServiceTask{ID: "task2", Name: "job2"}
+	proc.Task[2] = parser.ServiceTask{ID: "task3", Name: "job3"}
+	proc.Exclusive[0] = parser.ExclusiveGateway{ID: "xgw1", Default: "flow4"}
+	proc.Exclusive[1] = parser.ExclusiveGateway{ID: "xgw2"}
+	proc.Flow[0] = parser.SequenceFlow{ID: "flow1", From: "start", To: "task1"}
+	proc.Flow[1] = parser.SequenceFlow{ID: "flow2", From: "task1", To: "xgw1"}
+	proc.Flow[2] = parser.SequenceFlow{ID: "flow3", From: "xgw1", To: "task2", Condition: "$MJjob1:RC$ != 0"}
+	proc.Flow[3] = parser.SequenceFlow{ID: "flow4", From: "xgw1", To: "xgw2"}
+	proc.Flow[4] = parser.SequenceFlow{ID: "flow5", From: "task2", To: "xgw2"}
+	proc.Flow[5] = parser.SequenceFlow{ID: "flow6", From: "xgw2", To: "task3"}
+	proc.Flow[6] = parser.SequenceFlow{ID: "flow7", From: "task3", To: "end"}
+	return proc
+}
+
+func TestSetElements_ExclusiveGatewayの分岐条件を設定できる(t *testing.T) {
+	nwk, _ := NewNetwork("test")
+	err := nwk.setElements(generateExclusiveTestProcess())
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+
+	xgw1, ok := nwk.elements["xgw1"].(*ExclusiveGateway)
+	if !ok {
+		t.Fatal("xgw1がExclusiveGateway型になっていない。")
+	}
+	if xgw1.DefaultFlow != "flow4" {
+		t.Errorf("xgw1のデフォルトフロー[%s]が想定と違っている。", xgw1.DefaultFlow)
+	}
+	if len(xgw1.Nexts) != 2 {
+		t.Fatal("xgw1の後続要素数が2つになるはずが、違っている。")
+	}
+	if xgw1.Nexts[0] != nwk.elements["task2"] || xgw1.Nexts[1] != nwk.elements["xgw2"] {
+		t.Error("xgw1の後続要素が想定と違っている。")
+	}
+	if xgw1.Conditions[0] == nil || xgw1.Conditions[0].String() != "$MJjob1:RC$ != 0" {
+		t.Errorf("xgw1の1つめの分岐条件[%v]が想定と違っている。", xgw1.Conditions[0])
+	}
+	if xgw1.FlowIDs[1] != "flow4" {
+		t.Errorf("xgw1の2つめのsequenceFlowのID[%s]が想定と違っている。", xgw1.FlowIDs[1])
+	}
+}
+
+func TestSetElements_ID重複時はエラーを吐く_ExclusiveGatewayとの重複(t *testing.T) {
+	proc := generateExclusiveTestProcess()
+	proc.Exclusive[1].ID = "task1"
+
+	nwk, _ := NewNetwork("test")
+	if err := nwk.setElements(proc); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestSetElements_分岐条件が不正な場合はエラーを吐く(t *testing.T) {
+	proc := generateExclusiveTestProcess()
+	proc.Flow[2].Condition = "$MJjob1:RC$"
+
+	nwk, _ := NewNetwork("test")
+	if err := nwk.setElements(proc); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestDetectFlowError_排他ゲートウェイを含む正常なフローではエラーを検出しない(t *testing.T) {
+	nwk, _ := NewNetwork("test")
+	nwk.setElements(generateExclusiveTestProcess())
+	err := nwk.DetectFlowError()
+	if err != nil {
+		t.Fatalf("想定外のエラーが検出された: %s", err)
+	}
+}
+
+func TestDetectFlowError_並列分岐内の排他ゲートウェイを許容する(t *testing.T) {
+	proc := &parser.Process{
+		Start:     make([]parser.StartEvent, 1),
+		End:       make([]parser.EndEvent, 1),
+		Task:      make([]parser.ServiceTask, 3),
+		Gateway:   make([]parser.ParallelGateway, 2),
+		Exclusive: make([]parser.ExclusiveGateway, 2),
+		Flow:      make([]parser.SequenceFlow, 10),
+	}
+	proc.Start[0] = parser.StartEvent{ID: "start"}
+	proc.End[0] = parser.EndEvent{ID: "end"}
+	proc.Task[0] = parser.ServiceTask{ID: "task1", Name: "job1"}
+	proc.Task[1] = parser.ServiceTask{ID: "task2", Name: "job2"}
+	proc.Task[2] = parser.ServiceTask{ID: "task3", Name: "job3"}
+	proc.Gateway[0] = parser.ParallelGateway{ID: "gw1"}
+	proc.Gateway[1] = parser.ParallelGateway{ID: "gw2"}
+	proc.Exclusive[0] = parser.ExclusiveGateway{ID: "xgw1"}
+	proc.Exclusive[1] = parser.ExclusiveGateway{ID: "xgw2"}
+	proc.Flow[0] = parser.SequenceFlow{ID: "flow1", From: "start", To: "gw1"}
+	proc.Flow[1] = parser.SequenceFlow{ID: "flow2", From: "gw1", To: "task1"}
+	proc.Flow[2] = parser.SequenceFlow{ID: "flow3", From: "gw1", To: "xgw1"}
+	proc.Flow[3] = parser.SequenceFlow{ID: "flow4", From: "xgw1", To: "task2", Condition: "$MSJOBNET:ID$ < 100"}
+	proc.Flow[4] = parser.SequenceFlow{ID: "flow5", From: "xgw1", To: "task3", Condition: "$MSJOBNET:ID$ >= 100"}
+	proc.Flow[5] = parser.SequenceFlow{ID: "flow6", From: "task2", To: "xgw2"}
+	proc.Flow[6] = parser.SequenceFlow{ID: "flow7", From: "task3", To: "xgw2"}
+	proc.Flow[7] = parser.SequenceFlow{ID: "flow8", From: "xgw2", To: "gw2"}
+	proc.Flow[8] = parser.SequenceFlow{ID: "flow9", From: "task1", To: "gw2"}
+	proc.Flow[9] = parser.SequenceFlow{ID: "flow10", From: "gw2", To: "end"}
+
+	nwk, _ := NewNetwork("test")
+	if err := nwk.setElements(proc); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	err := nwk.DetectFlowError()
+	if err != nil {
+		t.Fatalf("想定外のエラーが検出された: %s", err)
+	}
+}
+
+func TestDetectFlowError_デフォルトフローが無く条件が網羅されていない場合はエラー(t *testing.T) {
+	proc := generateExclusiveTestProcess()
+	proc.Exclusive[0].Default = ""
+
+	nwk, _ := NewNetwork("test")
+	nwk.setElements(proc)
+	err := nwk.DetectFlowError()
+	if err == nil {
+		t.Fatalf("エラーが検出されていない。")
+	}
+}
+
+func TestDetectFlowError_デフォルトフロー以外に分岐条件が無い場合はエラー(t *testing.T) {
+	proc := generateExclusiveTestProcess()
+	proc.Flow[2].Condition = ""
+
+	nwk, _ := NewNetwork("test")
+	nwk.setElements(proc)
+	err := nwk.DetectFlowError()
+	if err == nil {
+		t.Fatalf("エラーが検出されていない。")
+	}
+}
+
+func TestDetectFlowError_排他分岐を結合せずに終了した場合はエラー(t *testing.T) {
+	proc := generateExclusiveTestProcess()
+	proc.Flow[4].To = "end"
+
+	nwk, _ := NewNetwork("test")
+	nwk.setElements(proc)
+	err := nwk.DetectFlowError()
+	if err == nil {
+		t.Fatalf("エラーが検出されていない。")
+	}
+}
+
+// 排他分岐の経路内で、さらに排他分岐するフローを生成する。
+func generateNestedExclusiveTestProcess() *parser.Process {
+	proc := &parser.Process{
+		Start:     make([]parser.StartEvent, 1),
+		End:       make([]parser.EndEvent, 1),
+		Task:      make([]parser.ServiceTask, 4),
+		Exclusive: make([]parser.ExclusiveGateway, 4),
+		Flow:      make([]parser.SequenceFlow, 11),
+	}
+	proc.Start[0] = parser.StartEvent{ID: "start"}
+	proc.End[0] = parser.EndEvent{ID: "end"}
+	proc.Task[0] = parser.ServiceTask{ID: "task1", Name: "job1"}
+	proc.Task[1] = parser.ServiceTask{ID: "task2", Name: "job2"}
+	proc.Task[2] = parser.ServiceTask{ID: "task3", Name: "job3"}
+	proc.Task[3] = parser.ServiceTask{ID: "task4", Name: "job4"}
+	proc.Exclusive[0] = parser.ExclusiveGateway{ID: "xgw1", Default: "flow4"}
+	proc.Exclusive[1] = parser.ExclusiveGateway{ID: "xgw2", Default: "flow7"}
+	proc.Exclusive[2] = parser.ExclusiveGateway{ID: "xgw3"}
+	proc.Exclusive[3] = parser.ExclusiveGateway{ID: "xgw4"}
+	proc.Flow[0] = parser.SequenceFlow{ID: "flow1", From: "start", To: "task1"}
+	proc.Flow[1] = parser.SequenceFlow{ID: "flow2", From: "task1", To: "xgw1"}
+	proc.Flow[2] = parser.SequenceFlow{ID: "flow3", From: "xgw1", To: "xgw2", Condition: "$MJjob1:RC$ != 0"}
+	proc.Flow[3] = parser.SequenceFlow{ID: "flow4", From: "xgw1", To: "task4"}
+	proc.Flow[4] = parser.SequenceFlow{ID: "flow5", From: "xgw2", To: "task2", Condition: "$MJjob1:RC$ == 1"}
+	proc.Flow[5] = parser.SequenceFlow{ID: "flow6", From: "task2", To: "xgw3"}
+	proc.Flow[6] = parser.SequenceFlow{ID: "flow7", From: "xgw2", To: "task3"}
+	proc.Flow[7] = parser.SequenceFlow{ID: "flow8", From: "task3", To: "xgw3"}
+	proc.Flow[8] = parser.SequenceFlow{ID: "flow9", From: "xgw3", To: "xgw4"}
+	proc.Flow[9] = parser.SequenceFlow{ID: "flow10", From: "task4", To: "xgw4"}
+	proc.Flow[10] = parser.SequenceFlow{ID: "flow11", From: "xgw4", To: "end"}
+	return proc
+}
+
+func TestDetectFlowError_ネストした排他分岐を許容する(t *testing.T) {
+	nwk, _ := NewNetwork("test")
+	if err := nwk.setElements(generateNestedExclusiveTestProcess()); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	err := nwk.DetectFlowError()
+	if err != nil {
+		t.Fatalf("想定外のエラーが検出された: %s", err)
+	}
+}
+
+func TestDetectFlowError_ネストした排他分岐が合流せずに外側の分岐と合流した場合はエラー(t *testing.T) {
+	proc := generateNestedExclusiveTestProcess()
+	proc.Flow[7].To = "xgw4"
+
+	nwk, _ := NewNetwork("test")
+	nwk.setElements(proc)
+	err := nwk.DetectFlowError()
+	if err == nil {
+		t.Fatalf("エラーが検出されていない。")
+	}
+}
+
 func TestNetworkRun_ネットワークを実行できる(t *testing.T) {
 	loadTestConfig()
 
@@ -1091,6 +1447,50 @@ func TestNetworkRun_ネットワークを実行できる(t *testing.T) {
 	}
 }
 
+func TestNetworkRun_排他ゲートウェイで条件を満たす経路のみ実行する(t *testing.T) {
+	loadTestConfig()
+	addTestJobValue("job1", 1)
+
+	n, _ := NewNetwork("test")
+	j1 := generateTestJob(1)
+	j2 := generateTestJob(2)
+	j3 := generateTestJob(3)
+	j4 := generateTestJob(4)
+	g1 := NewExclusiveGateway("xgwid1", "flow2")
+	g2 := NewExclusiveGateway("xgwid2", "")
+	n.elements[j1.ID()] = j1
+	n.elements[j2.ID()] = j2
+	n.elements[j3.ID()] = j3
+	n.elements[j4.ID()] = j4
+	n.elements[g1.ID()] = g1
+	n.elements[g2.ID()] = g2
+
+	n.Start = j1
+	j1.AddNext(g1)
+	g1.AddBranch(j2, "flow1", "$MJjob1:RC$ != 0")
+	g1.AddBranch(j3, "flow2", "")
+	j2.AddNext(g2)
+	j3.AddNext(g2)
+	g2.AddNext(j4)
+	n.End = j4
+
+	if err := n.Run(); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if !j1.isExecuted {
+		t.Errorf("j1が実行されていない。")
+	}
+	if !j2.isExecuted {
+		t.Errorf("条件を満たす経路のj2が実行されていない。")
+	}
+	if j3.isExecuted {
+		t.Errorf("条件を満たさない経路のj3が実行された。")
+	}
+	if !j4.isExecuted {
+		t.Errorf("j4が実行されていない。")
+	}
+}
+
 func TestNetworkRun_開始要素がnilの場合はエラー(t *testing.T) {
 	loadTestConfig()
 
@@ -1153,3 +1553,37 @@ func TestNetworkRun_ジョブネットワークがendEvent以外で終端した
 		t.Error("エラーが発生しなかった。")
 	}
 }
+
+func TestServantAddresses_ジョブが使用するservantのアドレスを重複なく取得できる(t *testing.T) {
+	proc := &parser.Process{
+		Start:   make([]parser.StartEvent, 1),
+		End:     make([]parser.EndEvent, 1),
+		Task:    make([]parser.ServiceTask, 3),
+		Gateway: make([]parser.ParallelGateway, 0),
+		Flow:    make([]parser.SequenceFlow, 0),
+	}
+	proc.Task[0] = parser.ServiceTask{ID: "task1", Name: "job1"}
+	proc.Task[1] = parser.ServiceTask{ID: "task2", Name: "job2"}
+	proc.Task[2] = parser.ServiceTask{ID: "task3", Name: "job3"}
+
+	nwk, _ := NewNetwork("test")
+	if err := nwk.setElements(proc); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	task1 := nwk.elements["task1"].(*Job)
+	task1.Node, task1.Port = "node2", 2015
+	task1.SecondaryNode, task1.SecondaryPort = "node1", 2015
+	task2 := nwk.elements["task2"].(*Job)
+	task2.Node, task2.Port = "node1>cuto", 2015
+	task2.SecondaryNode, task2.SecondaryPort = "node3", 2016
+	task3 := nwk.elements["task3"].(*Job)
+	task3.Node, task3.Port = "node2", 2015
+
+	primaries, secondaries := nwk.ServantAddresses()
+	if strings.Join(primaries, ",") != "node1:2015,node2:2015" {
+		t.Errorf("プライマリservantのアドレス%vが想定と違っている。", primaries)
+	}
+	if strings.Join(secondaries, ",") != "node3:2016" {
+		t.Errorf("セカンダリservantのアドレス%vが想定と違っている。", secondaries)
+	}
+}
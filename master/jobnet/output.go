@@ -0,0 +1,52 @@
+package jobnet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/unirita/cuto/console"
+	"github.com/unirita/cuto/log"
+	"github.com/unirita/cuto/master/config"
+)
+
+// ジョブの出力を書き込むディレクトリ名（log_dir配下）
+const outputDirName = "joboutput"
+
+// ジョブの出力をservantから受け取るかどうかを調べる。
+func (j *Job) isStreaming() bool {
+	return config.Job.StreamOutput != 0 || j.Instance.DisplayOutput
+}
+
+// ジョブの出力を書き込むファイルのパスを取得する。
+// リトライ時は同じファイルへ追記する。
+func (j *Job) outputFilePath() string {
+	name := fmt.Sprintf("%v.%v.%v.log", j.Instance.ID, j.Name, j.id)
+	return filepath.Join(config.Dir.LogDir, outputDirName, name)
+}
+
+// servantから受け取ったジョブの出力を、ジョブ毎の出力ファイルへ書き込む。
+// ジョブネットワークでコンソール表示が指定されている場合は、コンソールにも表示する。
+// チャネルがクローズされたらoutEndChをクローズして終了する。
+func (j *Job) writeOutput(outCh <-chan string, outEndCh chan<- struct{}) {
+	defer close(outEndCh)
+
+	path := j.outputFilePath()
+	var file *os.File
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		log.Error(err)
+	} else if file, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666); err != nil {
+		log.Error(err)
+	} else {
+		defer file.Close()
+	}
+
+	for line := range outCh {
+		if file != nil {
+			file.WriteString(line + "\n")
+		}
+		if j.Instance.DisplayOutput {
+			console.Display("CTM041I", j.Name, j.id, line)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package jobnet
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/master/config"
+	"github.com/unirita/cuto/message"
+)
+
+func TestIsStreaming_設定またはコンソール表示指定で出力を受け取る(t *testing.T) {
+	config.Job.StreamOutput = 0
+	n := newTestNetwork()
+	j, _ := NewJob("jobid1", "job1", n)
+	if j.isStreaming() {
+		t.Error("出力を受け取る指定が無いにも関わらず、trueが返された。")
+	}
+
+	n.DisplayOutput = true
+	if !j.isStreaming() {
+		t.Error("コンソール表示が指定されているにも関わらず、falseが返された。")
+	}
+
+	n.DisplayOutput = false
+	config.Job.StreamOutput = 1
+	defer func() { config.Job.StreamOutput = 0 }()
+	if !j.isStreaming() {
+		t.Error("stream_outputが指定されているにも関わらず、falseが返された。")
+	}
+}
+
+func TestJobExecute_ジョブの出力をファイルへ書き込む(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cuto_output")
+	if err != nil {
+		t.Fatalf("テスト用のディレクトリ作成に失敗した: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	logDir := config.Dir.LogDir
+	config.Dir.LogDir = dir
+	config.Job.StreamOutput = 1
+	config.Job.AttemptLimit = 1
+	defer func() {
+		config.Dir.LogDir = logDir
+		config.Job.StreamOutput = 0
+	}()
+
+	n := newTestNetwork()
+	j, _ := NewJob("jobid1", "job1", n)
+	j.sendRequest = func(host string, port int, reqMsg string, stCh chan<- string, outCh chan<- string) (string, error) {
+		req := new(message.Request)
+		req.ParseJSON(reqMsg)
+		if !req.Stream {
+			t.Error("リクエストで出力の送信が要求されていない。")
+		}
+		outCh <- "output1"
+		outCh <- "output2"
+
+		res := new(message.Response)
+		res.Stat = db.NORMAL
+		res.St = "2015-04-01 12:34:56.789"
+		res.Et = "2015-04-01 12:35:46.123"
+		resMsg, _ := res.GenerateJSON()
+		return resMsg, nil
+	}
+	if _, err := j.Execute(); err != nil {
+		t.Fatalf("想定外のエラーが発生: %s", err)
+	}
+
+	output, err := ioutil.ReadFile(j.outputFilePath())
+	if err != nil {
+		t.Fatalf("ジョブの出力ファイルが読み込めない: %s", err)
+	}
+	if string(output) != "output1\noutput2\n" {
+		t.Errorf("ジョブの出力ファイルの内容[%s]が想定と違っている。", string(output))
+	}
+}
@@ -5,34 +5,53 @@ package parser
 
 import (
 	"encoding/csv"
+	"fmt"
 	"io"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/unirita/cuto/log"
 )
 
 // 拡張ジョブ情報
 type JobEx struct {
-	Node          string // ノード名
-	Port          int    // ポート番号
-	FilePath      string // ジョブファイルパス
-	Param         string // 実行時引数
-	Env           string // 実行時環境変数
-	Workspace     string // 作業フォルダ
-	WrnRC         int    // 警告終了判断に使用するリターンコードの下限値
-	WrnPtn        string // 警告終了と判断する出力文字列
-	ErrRC         int    // 異常終了判断に使用するリターンコードの下限値
-	ErrPtn        string // 異常終了と判断する出力文字列
-	TimeoutMin    int    // タイムアウト（分）
-	SecondaryNode string // ノード名
-	SecondaryPort int    // ポート番号
+	Node          string   // ノード名
+	Port          int      // ポート番号
+	FilePath      string   // ジョブファイルパス
+	Param         string   // 実行時引数
+	Env           string   // 実行時環境変数
+	Workspace     string   // 作業フォルダ
+	WrnRC         int      // 警告終了判断に使用するリターンコードの下限値
+	WrnPtn        string   // 警告終了と判断する出力文字列
+	ErrRC         int      // 異常終了判断に使用するリターンコードの下限値
+	ErrPtn        string   // 異常終了と判断する出力文字列
+	TimeoutMin    int      // タイムアウト（分）
+	SecondaryNode string   // ノード名
+	SecondaryPort int      // ポート番号
+	RunDay        string   // 実行日の指定
+	RetryMax      int      // 異常終了時のリトライ回数
+	RetryDelaySec int      // リトライまでの待ち時間（秒）
+	RetryBackoff  float64  // リトライ毎に待ち時間に掛ける倍率
+	RetryRC       RCList   // リトライ対象とするリターンコード
+	RetryPtn      string   // リトライ対象とする詳細メッセージ
+	Line          int      // 定義されているCSVの行番号
+	Invalids      []string // 数値として解釈できず、ゼロ値とした項目の説明
 }
 
+// 実行日の指定
+const (
+	RUNDAY_ANY     = ""        // 毎日実行する
+	RUNDAY_BIZDAY  = "bizday"  // 営業日のみ実行する
+	RUNDAY_HOLIDAY = "holiday" // 非営業日のみ実行する
+)
+
 // CSVファイルの項目数
 const (
 	noSecondary   = 12
 	withSecondary = 14
+	withRunDay    = 15
+	withRetry     = 20
 )
 
 // 項目のインデックス
@@ -51,6 +70,12 @@ const (
 	tmoutIdx
 	secNodeIdx
 	secPortIdx
+	runDayIdx
+	retryMaxIdx
+	retryDelayIdx
+	retryBackoffIdx
+	retryRCIdx
+	retryPtnIdx
 )
 
 // JobEx構造体のオブジェクトを生成しする。
@@ -59,6 +84,7 @@ const (
 func NewJobEx() *JobEx {
 	je := new(JobEx)
 	je.TimeoutMin = -1
+	je.RetryBackoff = 1
 	return je
 }
 
@@ -109,7 +135,7 @@ func ParseJobEx(reader io.Reader) (map[string]*JobEx, error) {
 			continue
 		}
 
-		if len(record) != noSecondary && len(record) != withSecondary {
+		if len(record) != noSecondary && len(record) != withSecondary && len(record) != withRunDay && len(record) != withRetry {
 			log.Info("Jobex line[%d] was ignored: Irregal column count[%d].", i, len(record))
 			continue
 		}
@@ -121,9 +147,12 @@ func ParseJobEx(reader io.Reader) (map[string]*JobEx, error) {
 		}
 
 		je := NewJobEx()
+		je.Line = i
 		je.Node = record[nodeIdx]
 		if port, err := strconv.Atoi(record[portIdx]); err == nil {
 			je.Port = port
+		} else {
+			je.addInvalid("port", record[portIdx])
 		}
 		je.FilePath = record[pathIdx]
 		je.Param = record[paramIdx]
@@ -131,20 +160,43 @@ func ParseJobEx(reader io.Reader) (map[string]*JobEx, error) {
 		je.Workspace = record[workIdx]
 		if wrc, err := strconv.Atoi(record[wrcIdx]); err == nil {
 			je.WrnRC = wrc
+		} else {
+			je.addInvalid("warning rc", record[wrcIdx])
 		}
 		je.WrnPtn = record[wptIdx]
 		if erc, err := strconv.Atoi(record[ercIdx]); err == nil {
 			je.ErrRC = erc
+		} else {
+			je.addInvalid("error rc", record[ercIdx])
 		}
 		je.ErrPtn = record[eptIdx]
 		if tmout, err := strconv.Atoi(record[tmoutIdx]); err == nil {
 			je.TimeoutMin = tmout
+		} else {
+			je.addInvalid("timeout", record[tmoutIdx])
 		}
 
 		if len(record) >= withSecondary {
 			je.SecondaryNode = record[secNodeIdx]
 			if port, err := strconv.Atoi(record[secPortIdx]); err == nil {
 				je.SecondaryPort = port
+			} else {
+				je.addInvalid("secondary port", record[secPortIdx])
+			}
+		}
+
+		if len(record) >= withRunDay {
+			switch runDay := strings.ToLower(record[runDayIdx]); runDay {
+			case RUNDAY_ANY, RUNDAY_BIZDAY, RUNDAY_HOLIDAY:
+				je.RunDay = runDay
+			default:
+				return nil, fmt.Errorf("Jobex line[%d] has invalid run day[%s].", i, record[runDayIdx])
+			}
+		}
+
+		if len(record) >= withRetry {
+			if err := parseRetry(je, record); err != nil {
+				return nil, fmt.Errorf("Jobex line[%d] has invalid retry policy: %s", i, err)
 			}
 		}
 
@@ -153,3 +205,36 @@ func ParseJobEx(reader io.Reader) (map[string]*JobEx, error) {
 
 	return jobExMap, nil
 }
+
+// 数値として解釈できなかった項目を記録する。空のカラムは記録しない。
+func (je *JobEx) addInvalid(column string, value string) {
+	if value == "" {
+		return
+	}
+	je.Invalids = append(je.Invalids, fmt.Sprintf("%s[%s] is not a number.", column, value))
+}
+
+// リトライ設定のカラムをパースする。
+func parseRetry(je *JobEx, record []string) error {
+	var err error
+	if v := strings.TrimSpace(record[retryMaxIdx]); v != "" {
+		if je.RetryMax, err = strconv.Atoi(v); err != nil || je.RetryMax < 0 {
+			return fmt.Errorf("Retry count[%s] must be a number greater than or equal to 0.", v)
+		}
+	}
+	if v := strings.TrimSpace(record[retryDelayIdx]); v != "" {
+		if je.RetryDelaySec, err = strconv.Atoi(v); err != nil || je.RetryDelaySec < 0 {
+			return fmt.Errorf("Retry delay[%s] must be a number greater than or equal to 0.", v)
+		}
+	}
+	if v := strings.TrimSpace(record[retryBackoffIdx]); v != "" {
+		if je.RetryBackoff, err = strconv.ParseFloat(v, 64); err != nil || je.RetryBackoff < 1 {
+			return fmt.Errorf("Retry backoff[%s] must be a number greater than or equal to 1.", v)
+		}
+	}
+	if je.RetryRC, err = ParseRCList(record[retryRCIdx]); err != nil {
+		return err
+	}
+	je.RetryPtn = record[retryPtnIdx]
+	return nil
+}
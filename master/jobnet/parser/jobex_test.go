@@ -133,6 +133,31 @@ testjob1,,,,,,,,,,,`
 	}
 }
 
+func TestParseJobEx_数値として解釈できない項目を記録する(t *testing.T) {
+	csv := `
+ジョブ名,ノード名,ポート番号,実行ファイル,パラメータ,環境変数,作業フォルダ,警告コード,警告出力,異常コード,異常出力,タイムアウト
+testjob1,node1,abc,,,,,,,,,
+testjob2,node2,2015,,,,,x,,,,y`
+
+	r := strings.NewReader(csv)
+	jeMap, err := ParseJobEx(r)
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+
+	j1 := jeMap["testjob1"]
+	if j1.Line != 2 {
+		t.Errorf("testjob1の行番号[%d]が間違っています。", j1.Line)
+	}
+	if len(j1.Invalids) != 1 || j1.Invalids[0] != "port[abc] is not a number." {
+		t.Errorf("testjob1の解釈できない項目%vが間違っています。", j1.Invalids)
+	}
+	j2 := jeMap["testjob2"]
+	if len(j2.Invalids) != 2 {
+		t.Errorf("testjob2の解釈できない項目%vが間違っています。", j2.Invalids)
+	}
+}
+
 func TestParseJobEx_ジョブ名が無い行はエラーとして無視する(t *testing.T) {
 	csv := `
 ジョブ名,ノード名,ポート番号,実行ファイル,パラメータ,環境変数,作業フォルダ,警告コード,警告出力,異常コード,異常出力,タイムアウト
@@ -194,3 +219,91 @@ testjob2,12.345.67.89,5678,C:\work\test2.bat,testparam2,testenv2,C:\work2,20,war
 		t.Errorf("testjob2のセカンダリポート番号のパース結果[%d]が間違っています。", j2.SecondaryPort)
 	}
 }
+
+func TestParseJobEx_実行日の指定をパースできる(t *testing.T) {
+	csv := `
+ジョブ名,ノード名,ポート番号,実行ファイル,パラメータ,環境変数,作業フォルダ,警告コード,警告出力,異常コード,異常出力,タイムアウト,セカンダリ実行ノード,セカンダリポート番号,実行日
+testjob1,123.45.67.89,1234,C:\work\test1.bat,testparam1,testenv1,C:\work1,10,warn1,11,err1,3600,,,BIZDAY
+testjob2,12.345.67.89,5678,C:\work\test2.bat,testparam2,testenv2,C:\work2,20,warn2,21,err2,3600,,,holiday
+testjob3,12.345.67.89,5678,C:\work\test3.bat,testparam3,testenv3,C:\work3,20,warn3,21,err3,3600,,,`
+
+	r := strings.NewReader(csv)
+	jeMap, err := ParseJobEx(r)
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+
+	if len(jeMap) != 3 {
+		t.Fatalf("パース結果が3件になるはずが、%d件になった。", len(jeMap))
+	}
+	if jeMap["testjob1"].RunDay != RUNDAY_BIZDAY {
+		t.Errorf("testjob1の実行日のパース結果[%s]が間違っています。", jeMap["testjob1"].RunDay)
+	}
+	if jeMap["testjob2"].RunDay != RUNDAY_HOLIDAY {
+		t.Errorf("testjob2の実行日のパース結果[%s]が間違っています。", jeMap["testjob2"].RunDay)
+	}
+	if jeMap["testjob3"].RunDay != RUNDAY_ANY {
+		t.Errorf("testjob3の実行日のパース結果[%s]が間違っています。", jeMap["testjob3"].RunDay)
+	}
+}
+
+func TestParseJobEx_実行日の指定が不正な場合はエラー(t *testing.T) {
+	csv := `
+ジョブ名,ノード名,ポート番号,実行ファイル,パラメータ,環境変数,作業フォルダ,警告コード,警告出力,異常コード,異常出力,タイムアウト,セカンダリ実行ノード,セカンダリポート番号,実行日
+testjob1,123.45.67.89,1234,C:\work\test1.bat,testparam1,testenv1,C:\work1,10,warn1,11,err1,3600,,,weekday`
+
+	r := strings.NewReader(csv)
+	if _, err := ParseJobEx(r); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestParseJobEx_リトライ設定をパースできる(t *testing.T) {
+	csv := `
+ジョブ名,ノード名,ポート番号,実行ファイル,パラメータ,環境変数,作業フォルダ,警告コード,警告出力,異常コード,異常出力,タイムアウト,セカンダリ実行ノード,セカンダリポート番号,実行日,リトライ回数,リトライ間隔,リトライ倍率,リトライRC,リトライ詳細
+testjob1,123.45.67.89,1234,C:\work\test1.bat,testparam1,testenv1,C:\work1,10,warn1,11,err1,3600,,,,3,10,2.5,"11,20-30",Error Pattern
+testjob2,12.345.67.89,5678,C:\work\test2.bat,testparam2,testenv2,C:\work2,20,warn2,21,err2,3600,,,,,,,,`
+
+	r := strings.NewReader(csv)
+	jeMap, err := ParseJobEx(r)
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+
+	je := jeMap["testjob1"]
+	if je.RetryMax != 3 {
+		t.Errorf("リトライ回数のパース結果[%d]が間違っています。", je.RetryMax)
+	}
+	if je.RetryDelaySec != 10 {
+		t.Errorf("リトライ間隔のパース結果[%d]が間違っています。", je.RetryDelaySec)
+	}
+	if je.RetryBackoff != 2.5 {
+		t.Errorf("リトライ倍率のパース結果[%f]が間違っています。", je.RetryBackoff)
+	}
+	if !je.RetryRC.Contains(11) || !je.RetryRC.Contains(25) || je.RetryRC.Contains(12) {
+		t.Errorf("リトライRCのパース結果[%v]が間違っています。", je.RetryRC)
+	}
+	if je.RetryPtn != "Error Pattern" {
+		t.Errorf("リトライ詳細のパース結果[%s]が間違っています。", je.RetryPtn)
+	}
+
+	je = jeMap["testjob2"]
+	if je.RetryMax != 0 || je.RetryDelaySec != 0 || je.RetryBackoff != 1 || je.RetryRC != nil || je.RetryPtn != "" {
+		t.Errorf("空のリトライ設定のパース結果[%v]が間違っています。", je)
+	}
+}
+
+func TestParseJobEx_リトライ設定が不正な場合はエラー(t *testing.T) {
+	header := "ジョブ名,ノード名,ポート番号,実行ファイル,パラメータ,環境変数,作業フォルダ,警告コード,警告出力,異常コード,異常出力,タイムアウト,セカンダリ実行ノード,セカンダリポート番号,実行日,リトライ回数,リトライ間隔,リトライ倍率,リトライRC,リトライ詳細\n"
+	lines := []string{
+		`testjob1,,,,,,,,,,,,,,,-1,,,,`,
+		`testjob1,,,,,,,,,,,,,,,1,abc,,,`,
+		`testjob1,,,,,,,,,,,,,,,1,1,0.5,,`,
+		`testjob1,,,,,,,,,,,,,,,1,1,2,x,`,
+	}
+	for _, line := range lines {
+		if _, err := ParseJobEx(strings.NewReader(header + line)); err == nil {
+			t.Errorf("行[%s]でエラーが発生しなかった。", line)
+		}
+	}
+}
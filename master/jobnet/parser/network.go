@@ -18,11 +18,12 @@ type Definitions struct {
 
 // ネットワーク定義BPMNのprocess要素。
 type Process struct {
-	Start   []StartEvent      `xml:"startEvent"`
-	End     []EndEvent        `xml:"endEvent"`
-	Task    []ServiceTask     `xml:"serviceTask"`
-	Gateway []ParallelGateway `xml:"parallelGateway"`
-	Flow    []SequenceFlow    `xml:"sequenceFlow"`
+	Start     []StartEvent       `xml:"startEvent"`
+	End       []EndEvent         `xml:"endEvent"`
+	Task      []ServiceTask      `xml:"serviceTask"`
+	Gateway   []ParallelGateway  `xml:"parallelGateway"`
+	Exclusive []ExclusiveGateway `xml:"exclusiveGateway"`
+	Flow      []SequenceFlow     `xml:"sequenceFlow"`
 }
 
 // ネットワーク定義BPMNのstartEvent要素。
@@ -46,10 +47,18 @@ type ParallelGateway struct {
 	ID string `xml:"id,attr"`
 }
 
+// ネットワーク定義BPMNのexclusiveGateway要素。
+type ExclusiveGateway struct {
+	ID      string `xml:"id,attr"`
+	Default string `xml:"default,attr"` // デフォルトフローのID
+}
+
 // ネットワーク定義BPMNのsequenceFlow要素。
 type SequenceFlow struct {
-	From string `xml:"sourceRef,attr"`
-	To   string `xml:"targetRef,attr"`
+	ID        string `xml:"id,attr"`
+	From      string `xml:"sourceRef,attr"`
+	To        string `xml:"targetRef,attr"`
+	Condition string `xml:"conditionExpression"` // 分岐条件式
 }
 
 // ネットワーク定義をファイルから読み込み、パース結果を返す
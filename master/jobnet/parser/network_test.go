@@ -95,6 +95,63 @@ func TestParseNetwork_ネットワーク定義XMLをパースできる(t *testin
 	}
 }
 
+func TestParseNetwork_排他ゲートウェイと分岐条件をパースできる(t *testing.T) {
+	xml := `
+<?xml version="1.0" encoding="UTF-8"?>
+<definitions>
+  <process id="sample" name="Sample" isExecutable="true">
+    <startEvent id="startevent1" name="Start"></startEvent>
+    <endEvent id="endevent1" name="End"></endEvent>
+    <serviceTask id="servicetask1" name="JOB1"></serviceTask>
+    <serviceTask id="servicetask2" name="JOB2"></serviceTask>
+    <exclusiveGateway id="exclusivegateway1" name="Exclusive Gateway" default="flow4"></exclusiveGateway>
+    <exclusiveGateway id="exclusivegateway2" name="Exclusive Gateway"></exclusiveGateway>
+    <sequenceFlow id="flow1" sourceRef="startevent1" targetRef="servicetask1"></sequenceFlow>
+    <sequenceFlow id="flow2" sourceRef="servicetask1" targetRef="exclusivegateway1"></sequenceFlow>
+    <sequenceFlow id="flow3" sourceRef="exclusivegateway1" targetRef="servicetask2">
+      <conditionExpression xsi:type="tFormalExpression"><![CDATA[$MJJOB1:RC$ != 0]]></conditionExpression>
+    </sequenceFlow>
+    <sequenceFlow id="flow4" sourceRef="exclusivegateway1" targetRef="exclusivegateway2"></sequenceFlow>
+    <sequenceFlow id="flow5" sourceRef="servicetask2" targetRef="exclusivegateway2"></sequenceFlow>
+    <sequenceFlow id="flow6" sourceRef="exclusivegateway2" targetRef="endevent1"></sequenceFlow>
+  </process>
+</definitions>`
+
+	r := strings.NewReader(xml)
+	proc, err := ParseNetwork(r)
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生: %s", err)
+	}
+
+	xg := proc.Exclusive
+	if len(xg) != 2 {
+		t.Fatalf("exclusiveGatewayが%d個にも関わらず、%d個取得された", 2, len(xg))
+	}
+	if xg[0].ID != "exclusivegateway1" {
+		t.Errorf("1つめのexclusiveGatewayのidは%sのはずが、%sが取得された", "exclusivegateway1", xg[0].ID)
+	}
+	if xg[0].Default != "flow4" {
+		t.Errorf("1つめのexclusiveGatewayのdefaultは%sのはずが、%sが取得された", "flow4", xg[0].Default)
+	}
+	if xg[1].Default != "" {
+		t.Errorf("2つめのexclusiveGatewayのdefaultは空のはずが、%sが取得された", xg[1].Default)
+	}
+
+	sf := proc.Flow
+	if len(sf) != 6 {
+		t.Fatalf("sequenceFlowが%d個にも関わらず、%d個取得された", 6, len(sf))
+	}
+	if sf[2].ID != "flow3" {
+		t.Errorf("3つめのsequenceFlowのidは%sのはずが、%sが取得された", "flow3", sf[2].ID)
+	}
+	if strings.TrimSpace(sf[2].Condition) != "$MJJOB1:RC$ != 0" {
+		t.Errorf("3つめのsequenceFlowの分岐条件[%s]が想定と違っている", sf[2].Condition)
+	}
+	if sf[3].Condition != "" {
+		t.Errorf("4つめのsequenceFlowの分岐条件は空のはずが、%sが取得された", sf[3].Condition)
+	}
+}
+
 func TestParseNetwork_必要最低限の要素があればエラーを吐かない(t *testing.T) {
 	xml := `
 <?xml version="1.0" encoding="UTF-8"?>
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// リターンコードの範囲
+type RCRange struct {
+	Min int // 下限値
+	Max int // 上限値
+}
+
+// リターンコードの範囲のリスト
+type RCList []RCRange
+
+// "1,3-5"形式のリターンコード指定をパースする。
+//
+// param : s リターンコード指定文字列。
+//
+// return : リターンコードの範囲のリスト。空文字列の場合はnil。
+//
+// return : エラー情報。
+func ParseRCList(s string) (RCList, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	var list RCList
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		var r RCRange
+		var err error
+		if i := strings.Index(part, "-"); i > 0 {
+			if r.Min, err = strconv.Atoi(strings.TrimSpace(part[:i])); err != nil {
+				return nil, fmt.Errorf("Invalid return code[%s].", part)
+			}
+			if r.Max, err = strconv.Atoi(strings.TrimSpace(part[i+1:])); err != nil {
+				return nil, fmt.Errorf("Invalid return code[%s].", part)
+			}
+		} else {
+			if r.Min, err = strconv.Atoi(part); err != nil {
+				return nil, fmt.Errorf("Invalid return code[%s].", part)
+			}
+			r.Max = r.Min
+		}
+		if r.Min > r.Max {
+			return nil, fmt.Errorf("Invalid return code range[%s].", part)
+		}
+		list = append(list, r)
+	}
+	return list, nil
+}
+
+// リターンコードがリストに含まれるかを調べる。
+//
+// param : rc リターンコード。
+//
+// return : 含まれる場合はtrue。
+func (l RCList) Contains(rc int) bool {
+	for _, r := range l {
+		if r.Min <= rc && rc <= r.Max {
+			return true
+		}
+	}
+	return false
+}
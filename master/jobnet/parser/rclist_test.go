@@ -0,0 +1,43 @@
+package parser
+
+import "testing"
+
+func TestParseRCList_リターンコード指定をパースできる(t *testing.T) {
+	list, err := ParseRCList(" 1, 3-5 ,8")
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if len(list) != 3 {
+		t.Fatalf("パース結果が3件になるはずが、%d件になった。", len(list))
+	}
+
+	cases := []struct {
+		rc     int
+		expect bool
+	}{
+		{0, false}, {1, true}, {2, false}, {3, true}, {5, true}, {6, false}, {8, true},
+	}
+	for _, c := range cases {
+		if actual := list.Contains(c.rc); actual != c.expect {
+			t.Errorf("RC[%d]の判定結果[%v]が想定と違っている。", c.rc, actual)
+		}
+	}
+}
+
+func TestParseRCList_空文字列の場合はnilを返す(t *testing.T) {
+	list, err := ParseRCList("")
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if list != nil {
+		t.Errorf("nilが返るはずが、%vが返った。", list)
+	}
+}
+
+func TestParseRCList_不正な指定はエラー(t *testing.T) {
+	for _, s := range []string{"a", "1-", "5-3", "1,,2", "-1-3"} {
+		if _, err := ParseRCList(s); err == nil {
+			t.Errorf("指定[%s]でエラーが発生しなかった。", s)
+		}
+	}
+}
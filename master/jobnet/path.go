@@ -20,11 +20,13 @@ func NewPath(head Element) *Path {
 }
 
 // 並列実行経路を実行する。
+// 経路内でネストした分岐ゲートウェイは、その結合ゲートウェイまで実行してから経路の実行を継続する。
 // 実行完了後、doneチャンネルに空のstruct{}リテラルを入力する。
 //
 // param : done 通知用チャネル。
 func (p *Path) Run(done chan<- struct{}) {
 	current := p.Head
+	passJoin := false
 
 	for {
 		if current == nil {
@@ -32,9 +34,17 @@ func (p *Path) Run(done chan<- struct{}) {
 			break
 		}
 
-		if current.Type() == ELM_GW {
-			p.Goal = current
-			break
+		if current.Type() == ELM_GW && !passJoin {
+			if g, ok := current.(*Gateway); !ok || !g.isSplit() {
+				p.Goal = current
+				break
+			}
+		}
+
+		// ネストした分岐の結合ゲートウェイを通過する場合、結合ゲートウェイがさらに分岐するのであれば、その結合ゲートウェイも通過する。
+		passJoin = false
+		if g, ok := current.(*Gateway); ok && len(g.Nexts) > 1 {
+			passJoin = true
 		}
 
 		next, err := current.Execute()
@@ -146,3 +146,38 @@ func TestPathRun_経路の途中でジョブが異常終了したらエラー(t
 		t.Errorf("異常終了ジョブの後続ジョブjob3が実行された。")
 	}
 }
+
+func TestPathRun_経路内でネストした分岐を結合ゲートウェイの先まで実行する(t *testing.T) {
+	j1 := generateTestJob(1)
+	j2 := generateTestJob(2)
+	j3 := generateTestJob(3)
+	j4 := generateTestJob(4)
+	g1 := NewGateway("gwid1")
+	g2 := NewGateway("gwid2")
+	g3 := NewGateway("gwid3")
+	g4 := NewGateway("gwid4")
+
+	j1.AddNext(g1)
+	g1.AddNext(j2)
+	g1.AddNext(j3)
+	j2.AddNext(g2)
+	j3.AddNext(g2)
+	g2.AddNext(j4)
+	j4.AddNext(g3)
+	g4.AddNext(g3)
+	p := NewPath(j1)
+
+	done := make(chan struct{}, 1)
+	p.Run(done)
+	if p.Err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", p.Err)
+	}
+	if p.Goal != g3 {
+		t.Fatalf("経路の終着ノード[%v]が想定と違っている。", p.Goal)
+	}
+	for _, j := range []*testJob{j1, j2, j3, j4} {
+		if !j.isExecuted {
+			t.Errorf("%sが実行されなかった。", j.Name)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package jobnet
+
+import (
+	"fmt"
+
+	"github.com/unirita/cuto/console"
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/db/query"
+	"github.com/unirita/cuto/db/tx"
+	"github.com/unirita/cuto/log"
+)
+
+// リラン時に再実行するジョブを、RerunFrom・RerunOnlyの指定に従って設定する。
+// RerunFromが指定された場合は、指定ジョブとその後続の全ジョブを実行結果に関わらず再実行する。
+// RerunOnlyが指定された場合は、指定ジョブのみを再実行し、それ以外のジョブは実行しない。
+//
+// return : エラー情報。
+func (n *Network) setForceRerunJob() error {
+	if n.RerunFrom != "" && len(n.RerunOnly) > 0 {
+		return fmt.Errorf("Cannot designate both rerun-from job and rerun-only jobs.")
+	}
+
+	if n.RerunFrom != "" {
+		starts := n.findJobsByName(n.RerunFrom)
+		if len(starts) == 0 {
+			return fmt.Errorf("Job[%s] is not found in jobnet[%s].", n.RerunFrom, n.Name)
+		}
+		visited := make(map[string]bool)
+		for _, j := range starts {
+			markForceRerun(j, visited)
+		}
+		return nil
+	}
+
+	if len(n.RerunOnly) > 0 {
+		targets := make(map[string]bool)
+		for _, name := range n.RerunOnly {
+			jobs := n.findJobsByName(name)
+			if len(jobs) == 0 {
+				return fmt.Errorf("Job[%s] is not found in jobnet[%s].", name, n.Name)
+			}
+			for _, j := range jobs {
+				targets[j.id] = true
+			}
+		}
+		for _, e := range n.elements {
+			if j, ok := e.(*Job); ok {
+				j.forceRerun = targets[j.id]
+				j.excluded = !targets[j.id]
+			}
+		}
+	}
+	return nil
+}
+
+// ジョブ名に一致するジョブを全て取得する。
+func (n *Network) findJobsByName(name string) []*Job {
+	var jobs []*Job
+	for _, e := range n.elements {
+		if j, ok := e.(*Job); ok && j.Name == name {
+			jobs = append(jobs, j)
+		}
+	}
+	return jobs
+}
+
+// エレメントeとその後続の全ジョブを、強制再実行の対象とする。
+func markForceRerun(e Element, visited map[string]bool) {
+	if e == nil || visited[e.ID()] {
+		return
+	}
+	visited[e.ID()] = true
+
+	switch elm := e.(type) {
+	case *Job:
+		elm.forceRerun = true
+		markForceRerun(elm.Next, visited)
+	case *Gateway:
+		for _, next := range elm.Nexts {
+			markForceRerun(next, visited)
+		}
+	case *ExclusiveGateway:
+		for _, next := range elm.Nexts {
+			markForceRerun(next, visited)
+		}
+	}
+}
+
+// 再実行の対象外となったジョブを実行せずに次へ進める。
+// 前回正常終了・警告終了していた場合は、その実行結果をジョブ変数として引き継ぐ。
+func (j *Job) skipRerun() {
+	if jobres, exists := j.Instance.Result.GetJobResults(j.id); exists {
+		if isCompleted(jobres.Status) {
+			j.resumeJobValue()
+		}
+	}
+	console.Display("CTM043I", j.Name, j.Instance.ID, j.id)
+}
+
+// 強制再実行の前に、前回の実行結果を試行毎の実行結果として退避し、ジョブの状態を実行中へ戻す。
+func (j *Job) archiveLastAttempt() {
+	jobres, exist := j.Instance.Result.GetJobResults(j.id)
+	if !exist {
+		log.Error(fmt.Errorf("Job result[id = %s] is unregisted.", j.id))
+		return
+	}
+	j.archiveResult(jobres)
+
+	j.Instance.localMutex.Lock()
+	jobres.Node = j.Node
+	jobres.Port = j.Port
+	jobres.Status = db.RUNNING
+	j.Instance.localMutex.Unlock()
+	jobres.StartDate = ""
+	jobres.EndDate = ""
+	jobres.Detail = ""
+	jobres.Rc = 0
+	tx.UpdateJob(j.Instance.Result.GetConnection(), jobres, &j.Instance.localMutex)
+}
+
+// ジョブの実行結果を、試行毎の実行結果の1回目として退避する。
+// 試行毎の実行結果が既に記録済みの場合は、最後の試行として記録されているため退避しない。
+func (j *Job) archiveResult(jobres *db.JobResult) {
+	conn := j.Instance.Result.GetConnection()
+	count, err := query.CountJobAttempts(conn, j.Instance.ID, j.id)
+	if err != nil {
+		log.Error(fmt.Errorf("Cannot count attempts of job[id = %s]: %s", j.id, err))
+		return
+	}
+	if count > 0 {
+		return
+	}
+	attempt := db.NewJobAttempt(jobres, 1)
+	if err := tx.InsertJobAttempt(conn, attempt, &j.Instance.localMutex); err != nil {
+		log.Error(fmt.Errorf("Cannot record attempt[%d] of job[id = %s]: %s", 1, j.id, err))
+	}
+}
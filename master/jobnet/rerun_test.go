@@ -0,0 +1,189 @@
+package jobnet
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/db/query"
+	"github.com/unirita/cuto/db/tx"
+	"github.com/unirita/cuto/master/config"
+)
+
+const rerunTestBPMN = `
+<definitions>
+	<process>
+		<startEvent id="start"/>
+		<endEvent id="end"/>
+		<serviceTask id="j1" name="job1"/>
+		<serviceTask id="j2" name="job2"/>
+		<serviceTask id="j3" name="job3"/>
+		<serviceTask id="j4" name="job4"/>
+		<parallelGateway id="gw1"/>
+		<parallelGateway id="gw2"/>
+		<sequenceFlow sourceRef="start" targetRef="j1"/>
+		<sequenceFlow sourceRef="j1" targetRef="gw1"/>
+		<sequenceFlow sourceRef="gw1" targetRef="j2"/>
+		<sequenceFlow sourceRef="gw1" targetRef="j3"/>
+		<sequenceFlow sourceRef="j2" targetRef="gw2"/>
+		<sequenceFlow sourceRef="j3" targetRef="gw2"/>
+		<sequenceFlow sourceRef="gw2" targetRef="j4"/>
+		<sequenceFlow sourceRef="j4" targetRef="end"/>
+	</process>
+</definitions>`
+
+func loadRerunTestNetwork(t *testing.T) *Network {
+	n, _ := NewNetwork("test")
+	if err := n.LoadElements(strings.NewReader(rerunTestBPMN)); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	return n
+}
+
+func TestSetForceRerunJob_指定ジョブと後続ジョブを強制再実行の対象とする(t *testing.T) {
+	n := loadRerunTestNetwork(t)
+	n.RerunFrom = "job2"
+	if err := n.setForceRerunJob(); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+
+	expected := map[string]bool{"j1": false, "j2": true, "j3": false, "j4": true}
+	for id, force := range expected {
+		j := n.elements[id].(*Job)
+		if j.forceRerun != force {
+			t.Errorf("ジョブ[%s]の強制再実行フラグ[%v]が想定と違っている。", id, j.forceRerun)
+		}
+		if j.excluded {
+			t.Errorf("ジョブ[%s]が再実行の対象外となっている。", id)
+		}
+	}
+}
+
+func TestSetForceRerunJob_指定ジョブのみを再実行の対象とする(t *testing.T) {
+	n := loadRerunTestNetwork(t)
+	n.RerunOnly = []string{"job1", "job3"}
+	if err := n.setForceRerunJob(); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+
+	expected := map[string]bool{"j1": true, "j2": false, "j3": true, "j4": false}
+	for id, target := range expected {
+		j := n.elements[id].(*Job)
+		if j.forceRerun != target {
+			t.Errorf("ジョブ[%s]の強制再実行フラグ[%v]が想定と違っている。", id, j.forceRerun)
+		}
+		if j.excluded == target {
+			t.Errorf("ジョブ[%s]の対象外フラグ[%v]が想定と違っている。", id, j.excluded)
+		}
+	}
+}
+
+func TestSetForceRerunJob_存在しないジョブ名が指定された場合はエラー(t *testing.T) {
+	n := loadRerunTestNetwork(t)
+	n.RerunFrom = "nojob"
+	if err := n.setForceRerunJob(); err == nil {
+		t.Error("-fromの指定でエラーが発生しなかった。")
+	}
+
+	n = loadRerunTestNetwork(t)
+	n.RerunOnly = []string{"job1", "nojob"}
+	if err := n.setForceRerunJob(); err == nil {
+		t.Error("-onlyの指定でエラーが発生しなかった。")
+	}
+}
+
+func TestSetForceRerunJob_両方指定された場合はエラー(t *testing.T) {
+	n := loadRerunTestNetwork(t)
+	n.RerunFrom = "job1"
+	n.RerunOnly = []string{"job2"}
+	if err := n.setForceRerunJob(); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestJobExecute_強制再実行時は前回の実行結果を試行結果として残す(t *testing.T) {
+	config.Job.AttemptLimit = 1
+	n := newTestNetwork()
+	n.ID = 1
+	j1, _ := NewJob("rerunjob1", "rerunjob1", n)
+	j2, _ := NewJob("rerunjob2", "rerunjob2", n)
+	j1.Next = j2
+	j1.IsRerunJob = true
+	j1.forceRerun = true
+	j1.Node = "testnode"
+	j1.Port = 1234
+	j1.sendRequest = testSendRequest_Abnormal
+
+	conn := n.Result.GetConnection()
+	defer conn.GetDb().Exec("delete from JOB where ID = ? and JOBID = ?", n.ID, j1.id)
+	defer conn.GetDb().Exec("delete from JOBATTEMPT where ID = ? and JOBID = ?", n.ID, j1.id)
+
+	prev := db.NewJobResult(n.ID)
+	prev.JobId = j1.id
+	prev.JobName = j1.Name
+	prev.Status = db.NORMAL
+	prev.Rc = 0
+	prev.StartDate = "2015-03-01 10:00:00.000"
+	prev.EndDate = "2015-03-01 10:01:00.000"
+	prev.Node = "prevnode"
+	prev.Port = 2345
+	if err := tx.InsertJob(conn, prev, &n.localMutex); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	n.Result.AddJobResults(j1.id, prev)
+
+	if _, err := j1.Execute(); err == nil {
+		t.Fatal("異常終了したにも関わらずエラーが発生しなかった。")
+	}
+
+	jobres, _ := n.Result.GetJobResults(j1.id)
+	if jobres.Status != db.ABNORMAL || jobres.Node != "testnode" {
+		t.Errorf("ジョブ実行結果[Status = %d, Node = %s]が想定と違っている。", jobres.Status, jobres.Node)
+	}
+
+	var attempts []*db.JobAttempt
+	all, err := query.GetJobAttemptsOfTargetNetwork(conn, n.ID)
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	for _, a := range all {
+		if a.JobId == j1.id {
+			attempts = append(attempts, a)
+		}
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("試行結果の記録件数[%d]が想定と違っている。", len(attempts))
+	}
+	if attempts[0].Attempt != 1 || attempts[0].Status != db.NORMAL || attempts[0].Node != "prevnode" {
+		t.Errorf("前回の試行結果[Attempt = %d, Status = %d, Node = %s]が想定と違っている。",
+			attempts[0].Attempt, attempts[0].Status, attempts[0].Node)
+	}
+	if attempts[1].Attempt != 2 || attempts[1].Status != db.ABNORMAL {
+		t.Errorf("今回の試行結果[Attempt = %d, Status = %d]が想定と違っている。", attempts[1].Attempt, attempts[1].Status)
+	}
+}
+
+func TestJobExecute_再実行の対象外のジョブは実行しない(t *testing.T) {
+	n := newTestNetwork()
+	n.ID = 2002
+	j1, _ := NewJob("rerunjob1", "rerunjob1", n)
+	j2, _ := NewJob("rerunjob2", "rerunjob2", n)
+	j1.Next = j2
+	j1.IsRerunJob = true
+	j1.excluded = true
+	j1.sendRequest = testSendRequest_Error
+
+	n.Result.AddJobResults(j1.id, &db.JobResult{JobId: j1.id, Status: db.ABNORMAL})
+
+	next, err := j1.Execute()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if next != j2 {
+		t.Error("後続ジョブが返されなかった。")
+	}
+	jobres, _ := n.Result.GetJobResults(j1.id)
+	if jobres.Status != db.ABNORMAL {
+		t.Errorf("対象外のジョブの実行結果[%d]が変更された。", jobres.Status)
+	}
+}
@@ -0,0 +1,113 @@
+package jobnet
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/unirita/cuto/console"
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/db/query"
+	"github.com/unirita/cuto/db/tx"
+	"github.com/unirita/cuto/log"
+	"github.com/unirita/cuto/message"
+)
+
+// リトライ設定を持つジョブの試行回数を初期化する。
+// リラン時は記録済みの試行回数の続きから数える。強制再実行するジョブも同様とする。
+func (j *Job) initAttempt() {
+	j.attempt = 1
+	j.retried = 0
+	if (j.RetryMax <= 0 && !j.forceRerun) || !j.IsRerunJob {
+		return
+	}
+
+	count, err := query.CountJobAttempts(j.Instance.Result.GetConnection(), j.Instance.ID, j.id)
+	if err != nil {
+		log.Error(fmt.Errorf("Cannot count attempts of job[id = %s]: %s", j.id, err))
+		return
+	}
+	j.attempt = count + 1
+}
+
+// 異常終了したジョブがリトライ対象であるかを判定する。
+//
+// param : res ジョブ実行結果のresponseメッセージ。
+//
+// return : リトライ対象の場合はtrue。
+func (j *Job) isRetryable(res *message.Response) bool {
+	if res.Stat != db.ABNORMAL || j.retried >= j.RetryMax {
+		return false
+	}
+	if j.Instance.IsCancelled() {
+		return false
+	}
+	if len(j.RetryRC) > 0 && !j.RetryRC.Contains(res.RC) {
+		return false
+	}
+	if j.RetryPtn != "" && !strings.Contains(res.Detail, j.RetryPtn) {
+		return false
+	}
+	return true
+}
+
+// 次のリトライまでの待ち時間を取得する。
+// 待ち時間はリトライ毎にバックオフ倍率を掛けて延長する。
+func (j *Job) retryDelay() time.Duration {
+	backoff := j.RetryBackoff
+	if backoff < 1 {
+		backoff = 1
+	}
+	sec := float64(j.RetryDelaySec) * math.Pow(backoff, float64(j.retried))
+	return time.Duration(sec * float64(time.Second))
+}
+
+// リトライまで待機する。
+// 待機中にジョブネットワークが実行中止された場合はfalseを返す。
+func (j *Job) waitRetry() bool {
+	delay := j.retryDelay()
+	console.Display("CTM039W", j.Name, j.Instance.ID, j.id, j.retried+1, j.RetryMax, delay.Seconds())
+
+	select {
+	case <-time.After(delay):
+		return true
+	case <-j.Instance.cancelCh:
+		return false
+	}
+}
+
+// ジョブの実行結果を試行毎の実行結果として記録する。
+// リトライ設定を持たず、強制再実行もされないジョブについては何もしない。
+func (j *Job) recordAttempt() {
+	if j.RetryMax <= 0 && !j.forceRerun {
+		return
+	}
+
+	jobres, exist := j.Instance.Result.GetJobResults(j.id)
+	if !exist {
+		log.Error(fmt.Errorf("Job result[id = %s] is unregisted.", j.id))
+		return
+	}
+	attempt := db.NewJobAttempt(jobres, j.attempt)
+	if err := tx.InsertJobAttempt(j.Instance.Result.GetConnection(), attempt, &j.Instance.localMutex); err != nil {
+		log.Error(fmt.Errorf("Cannot record attempt[%d] of job[id = %s]: %s", j.attempt, j.id, err))
+	}
+}
+
+// リトライのため、ジョブの状態を実行中へ戻す。
+func (j *Job) restart() {
+	j.retried++
+	j.attempt++
+
+	jobres, exist := j.Instance.Result.GetJobResults(j.id)
+	if !exist {
+		log.Error(fmt.Errorf("Job result[id = %s] is unregisted.", j.id))
+		return
+	}
+	j.setResultStatus(jobres, db.RUNNING)
+	jobres.StartDate = ""
+	jobres.EndDate = ""
+	jobres.Detail = ""
+	tx.UpdateJob(j.Instance.Result.GetConnection(), jobres, &j.Instance.localMutex)
+}
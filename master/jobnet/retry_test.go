@@ -0,0 +1,175 @@
+package jobnet
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/db/query"
+	"github.com/unirita/cuto/master/config"
+	"github.com/unirita/cuto/master/jobnet/parser"
+	"github.com/unirita/cuto/master/notify"
+	"github.com/unirita/cuto/message"
+)
+
+// count回目までは異常終了、それ以降は正常終了のレスポンスを返す送信関数を生成する。
+func generateRetrySendRequest(count int, sent *int) sendFunc {
+	return func(host string, port int, reqMsg string, stCh chan<- string, outCh chan<- string) (string, error) {
+		*sent++
+		if *sent > count {
+			return testSendRequest_Normal(host, port, reqMsg, stCh, outCh)
+		}
+		return testSendRequest_Abnormal(host, port, reqMsg, stCh, outCh)
+	}
+}
+
+func TestJobExecute_異常終了したジョブをリトライする(t *testing.T) {
+	config.Job.AttemptLimit = 1
+	n := newTestNetwork()
+	n.ID = 1
+	j1, _ := NewJob("retryjob1", "retryjob1", n)
+	j2, _ := NewJob("retryjob2", "retryjob2", n)
+	j1.Next = j2
+	j1.RetryMax = 3
+
+	sent := 0
+	j1.sendRequest = generateRetrySendRequest(2, &sent)
+	next, err := j1.Execute()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if next != j2 {
+		t.Error("後続ジョブが返されなかった。")
+	}
+	if sent != 3 {
+		t.Errorf("リクエストの送信回数[%d]が想定と違っている。", sent)
+	}
+
+	jobres, _ := n.Result.GetJobResults(j1.id)
+	if jobres.Status != db.NORMAL {
+		t.Errorf("ジョブ実行結果のStatus[%d]は想定と違っている。", jobres.Status)
+	}
+
+	conn := n.Result.GetConnection()
+	defer conn.GetDb().Exec("delete from JOB where ID = ? and JOBID = ?", n.ID, j1.id)
+	defer conn.GetDb().Exec("delete from JOBATTEMPT where ID = ? and JOBID = ?", n.ID, j1.id)
+	attempts, err := query.GetJobAttemptsOfTargetNetwork(conn, n.ID)
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if len(attempts) != 3 {
+		t.Fatalf("試行結果の記録件数[%d]が想定と違っている。", len(attempts))
+	}
+	if attempts[0].Status != db.ABNORMAL || attempts[2].Status != db.NORMAL {
+		t.Errorf("試行結果のステータス[%d, %d]が想定と違っている。", attempts[0].Status, attempts[2].Status)
+	}
+}
+
+func TestJobExecute_リトライ回数を超えた場合は異常終了する(t *testing.T) {
+	config.Job.AttemptLimit = 1
+	n := newTestNetwork()
+	j1, _ := NewJob("retryjob1", "retryjob1", n)
+	j1.RetryMax = 2
+
+	sent := 0
+	j1.sendRequest = generateRetrySendRequest(5, &sent)
+	if _, err := j1.Execute(); err == nil {
+		t.Fatal("エラーが発生しなかった。")
+	}
+	if sent != 3 {
+		t.Errorf("リクエストの送信回数[%d]が想定と違っている。", sent)
+	}
+}
+
+func TestJobExecute_リトライする試行の異常終了は通知しない(t *testing.T) {
+	config.Job.AttemptLimit = 1
+	var count int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+	}))
+	defer s.Close()
+	defaultNotify := *config.Notify
+	defer func() { *config.Notify = defaultNotify }()
+	config.Notify.WebhookURL = s.URL
+	config.Notify.Status = []string{"abnormal"}
+	config.Notify.TimeoutSec = 1
+
+	n := newTestNetwork()
+	j1, _ := NewJob("retryjob1", "retryjob1", n)
+	j1.RetryMax = 2
+
+	sent := 0
+	j1.sendRequest = generateRetrySendRequest(5, &sent)
+	if _, err := j1.Execute(); err == nil {
+		t.Fatal("エラーが発生しなかった。")
+	}
+	notify.Wait()
+	if sent != 3 {
+		t.Errorf("リクエストの送信回数[%d]が想定と違っている。", sent)
+	}
+	if c := atomic.LoadInt32(&count); c != 1 {
+		t.Errorf("異常終了の通知回数[%d]が想定と違っている。", c)
+	}
+}
+
+func TestJobIsRetryable_リトライ対象を判定できる(t *testing.T) {
+	n := newTestNetwork()
+	j, _ := NewJob("retryjob1", "retryjob1", n)
+	j.RetryMax = 1
+	j.RetryRC, _ = parser.ParseRCList("1,10-12")
+	j.RetryPtn = "testerror"
+
+	cases := []struct {
+		stat   int
+		rc     int
+		detail string
+		expect bool
+	}{
+		{db.ABNORMAL, 1, "testerror", true},
+		{db.ABNORMAL, 11, "testerror occured", true},
+		{db.ABNORMAL, 2, "testerror", false},
+		{db.ABNORMAL, 1, "othererror", false},
+		{db.WARN, 1, "testerror", false},
+		{db.CANCELLED, 1, "testerror", false},
+	}
+	for _, c := range cases {
+		res := &message.Response{Stat: c.stat, RC: c.rc, Detail: c.detail}
+		if actual := j.isRetryable(res); actual != c.expect {
+			t.Errorf("Stat[%d] RC[%d] Detail[%s]の判定結果[%v]が想定と違っている。", c.stat, c.rc, c.detail, actual)
+		}
+	}
+
+	j.retried = 1
+	if j.isRetryable(&message.Response{Stat: db.ABNORMAL, RC: 1, Detail: "testerror"}) {
+		t.Error("リトライ回数を超えているにも関わらず、リトライ対象と判定された。")
+	}
+}
+
+func TestJobRetryDelay_バックオフ倍率で待ち時間を延長する(t *testing.T) {
+	j := new(Job)
+	j.RetryDelaySec = 10
+	j.RetryBackoff = 2
+
+	expects := []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second}
+	for i, expect := range expects {
+		j.retried = i
+		if actual := j.retryDelay(); actual != expect {
+			t.Errorf("%d回目のリトライの待ち時間[%v]が想定と違っている。", i+1, actual)
+		}
+	}
+}
+
+func TestJobWaitRetry_実行中止された場合は待機を中断する(t *testing.T) {
+	n := newTestNetwork()
+	j, _ := NewJob("retryjob1", "retryjob1", n)
+	j.RetryMax = 1
+	j.RetryDelaySec = 60
+
+	go n.Cancel()
+	if j.waitRetry() {
+		t.Error("実行中止されたにも関わらず、trueが返された。")
+	}
+}
@@ -0,0 +1,168 @@
+package jobnet
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/unirita/cuto/message"
+)
+
+// ジョブネットワーク変数の参照を検出する正規表現
+var jobVarExp = regexp.MustCompile(`\$MJ.+?\$`)
+
+// ジョブネットワーク変数のタグの一覧
+var jobVarTags = map[string]bool{"ID": true, "RC": true, "SD": true, "ED": true, "OUT": true}
+
+// フロー上のエレメント間の前後関係を解析する構造体
+type flowGraph struct {
+	prevs     map[Element][]Element
+	jobs      map[string]*Job
+	ancestors map[Element]map[Element]bool
+}
+
+// ネットワークのエレメント間の前後関係を解析するflowGraphを生成する。
+func (n *Network) newFlowGraph() *flowGraph {
+	g := new(flowGraph)
+	g.prevs = n.prevElements()
+	g.jobs = make(map[string]*Job)
+	g.ancestors = make(map[Element]map[Element]bool)
+	for _, e := range n.elements {
+		if j, ok := e.(*Job); ok {
+			g.jobs[j.Name] = j
+		}
+	}
+	return g
+}
+
+// エレメントeより前に実行される可能性のある全てのエレメントの集合を返す。
+func (g *flowGraph) ancestorsOf(e Element) map[Element]bool {
+	if anc, ok := g.ancestors[e]; ok {
+		return anc
+	}
+
+	anc := make(map[Element]bool)
+	queue := []Element{e}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, prev := range g.prevs[cur] {
+			if !anc[prev] {
+				anc[prev] = true
+				queue = append(queue, prev)
+			}
+		}
+	}
+	g.ancestors[e] = anc
+	return anc
+}
+
+// エレメントeで参照しているジョブネットワーク変数keyが、実行時に展開できるかを検査する。
+// 参照先のジョブが存在しない場合、eより後に実行される場合、eと並列に実行される場合、別の分岐経路にある場合はエラーとする。
+//
+// param : e 変数を参照しているエレメント。
+//
+// param : key 変数名。
+//
+// return : エラー情報。
+func (g *flowGraph) checkJobVar(e Element, key string) error {
+	v := message.NewVariable(key)
+	if v == nil {
+		return nil
+	}
+	if !jobVarTags[v.Tag] {
+		return fmt.Errorf("Variable[%s] has undefined tag[%s].", key, v.Tag)
+	}
+	if v.Key != "" && v.Tag != "OUT" {
+		return fmt.Errorf("Variable[%s] cannot have output key with tag[%s].", key, v.Tag)
+	}
+
+	j, ok := g.jobs[v.Name]
+	if !ok {
+		return fmt.Errorf("Variable[%s] refers job[%s] which is not defined.", key, v.Name)
+	}
+	if e == Element(j) {
+		return fmt.Errorf("Variable[%s] refers job[%s] itself.", key, v.Name)
+	}
+
+	anc := g.ancestorsOf(e)
+	if anc[j] {
+		return nil
+	}
+	jobAnc := g.ancestorsOf(j)
+	if jobAnc[e] {
+		return fmt.Errorf("Variable[%s] refers job[%s] which is executed later.", key, v.Name)
+	}
+	// 共通の先行エレメントのうち最も後に実行されるものが並列分岐ゲートウェイであれば、並列に実行される。
+	for _, a := range g.lowestCommonAncestors(anc, jobAnc) {
+		if gw, ok := a.(*Gateway); ok {
+			return fmt.Errorf("Variable[%s] refers job[%s] which runs in parallel branch of gateway[%s].", key, v.Name, gw.ID())
+		}
+	}
+	return fmt.Errorf("Variable[%s] refers job[%s] which is in another branch and never executed before.", key, v.Name)
+}
+
+// 2つの先行エレメントの集合に共通するエレメントのうち、他の共通エレメントの先行エレメントでないものを返す。
+func (g *flowGraph) lowestCommonAncestors(anc1, anc2 map[Element]bool) []Element {
+	common := make(map[Element]bool)
+	for a := range anc1 {
+		if anc2[a] {
+			common[a] = true
+		}
+	}
+
+	var lowest []Element
+	for a := range common {
+		isLowest := true
+		for b := range common {
+			if a != b && g.ancestorsOf(b)[a] {
+				isLowest = false
+				break
+			}
+		}
+		if isLowest {
+			lowest = append(lowest, a)
+		}
+	}
+	return lowest
+}
+
+// ジョブのパラメータ・環境変数および排他ゲートウェイの分岐条件で参照しているジョブネットワーク変数を検査する。
+// 参照先のジョブが実行時に必ず実行済みとならない場合はエラーを返す。
+// 事前にLoadJobExで拡張ジョブ定義を読み込んでおく必要がある。
+//
+// return : エラー情報。
+func (n *Network) DetectVariableError() error {
+	g := n.newFlowGraph()
+	for _, e := range n.sortedElements() {
+		for _, src := range variableSources(e) {
+			for _, key := range jobVarExp.FindAllString(src.value, -1) {
+				if err := g.checkJobVar(e, key); err != nil {
+					return fmt.Errorf("Element[id = %s] %s: %s", e.ID(), src.item, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ジョブネットワーク変数を展開する項目
+type variableSource struct {
+	item  string // 項目名
+	value string // 項目の値
+}
+
+// エレメント内でジョブネットワーク変数を展開する項目の一覧を返す。
+func variableSources(e Element) []variableSource {
+	var sources []variableSource
+	switch elm := e.(type) {
+	case *Job:
+		sources = append(sources, variableSource{"param", elm.Param}, variableSource{"env", elm.Env})
+	case *ExclusiveGateway:
+		for i, cond := range elm.Conditions {
+			if cond != nil {
+				sources = append(sources, variableSource{"condition of flow[" + elm.FlowIDs[i] + "]", cond.Left + " " + cond.Right})
+			}
+		}
+	}
+	return sources
+}
@@ -0,0 +1,130 @@
+package jobnet
+
+import (
+	"strings"
+	"testing"
+)
+
+const varRefTestBPMN = `
+<definitions>
+	<process>
+		<startEvent id="start"/>
+		<endEvent id="end"/>
+		<serviceTask id="j1" name="job1"/>
+		<serviceTask id="j2" name="job2"/>
+		<serviceTask id="j3" name="job3"/>
+		<serviceTask id="j4" name="job4"/>
+		<serviceTask id="j5" name="job5"/>
+		<serviceTask id="j6" name="job6"/>
+		<parallelGateway id="gw1"/>
+		<parallelGateway id="gw2"/>
+		<exclusiveGateway id="xgw1" default="flow9"/>
+		<exclusiveGateway id="xgw2"/>
+		<sequenceFlow id="flow1" sourceRef="start" targetRef="j1"/>
+		<sequenceFlow id="flow2" sourceRef="j1" targetRef="gw1"/>
+		<sequenceFlow id="flow3" sourceRef="gw1" targetRef="j2"/>
+		<sequenceFlow id="flow4" sourceRef="gw1" targetRef="j3"/>
+		<sequenceFlow id="flow5" sourceRef="j2" targetRef="gw2"/>
+		<sequenceFlow id="flow6" sourceRef="j3" targetRef="gw2"/>
+		<sequenceFlow id="flow7" sourceRef="gw2" targetRef="xgw1"/>
+		<sequenceFlow id="flow8" sourceRef="xgw1" targetRef="j4">
+			<conditionExpression><![CDATA[$MJjob%s:RC$ != 0]]></conditionExpression>
+		</sequenceFlow>
+		<sequenceFlow id="flow9" sourceRef="xgw1" targetRef="j5"/>
+		<sequenceFlow id="flow10" sourceRef="j4" targetRef="xgw2"/>
+		<sequenceFlow id="flow11" sourceRef="j5" targetRef="xgw2"/>
+		<sequenceFlow id="flow12" sourceRef="xgw2" targetRef="j6"/>
+		<sequenceFlow id="flow13" sourceRef="j6" targetRef="end"/>
+	</process>
+</definitions>`
+
+func loadVarRefTestNetwork(t *testing.T, condJob string) *Network {
+	n, _ := NewNetwork("varref")
+	bpmn := strings.Replace(varRefTestBPMN, "%s", condJob, 1)
+	if err := n.LoadElements(strings.NewReader(bpmn)); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	return n
+}
+
+func TestDetectVariableError_参照先のジョブとの位置関係を検査できる(t *testing.T) {
+	cases := []struct {
+		id     string
+		param  string
+		expect string
+	}{
+		{"j2", "$MJjob1:RC$", ""},
+		{"j6", "$MJjob4:OUT$ $MJjob5:RC$", ""},
+		{"j2", "$MJjob3:OUT$", "runs in parallel branch of gateway[gw1]"},
+		{"j1", "$MJjob6:RC$", "executed later"},
+		{"j5", "$MJjob4:RC$", "in another branch"},
+		{"j1", "$MJnojob:RC$", "not defined"},
+		{"j1", "$MJjob1:RC$", "itself"},
+		{"j6", "$MJjob4:XX$", "undefined tag[XX]"},
+		{"j6", "$MJjob1:OUT:file$", ""},
+		{"j6", "$MJjob1:RC:file$", "cannot have output key"},
+	}
+	for i, c := range cases {
+		n := loadVarRefTestNetwork(t, "1")
+		n.elements[c.id].(*Job).Param = c.param
+
+		err := n.DetectVariableError()
+		if c.expect == "" {
+			if err != nil {
+				t.Errorf("ケース[%d]で想定外のエラーが発生した: %s", i, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("ケース[%d]でエラーが発生しなかった。", i)
+		} else if !strings.Contains(err.Error(), c.expect) || !strings.Contains(err.Error(), "Element[id = "+c.id+"] param") {
+			t.Errorf("ケース[%d]のエラーメッセージ[%s]が想定と違っている。", i, err)
+		}
+	}
+}
+
+func TestDetectVariableError_環境変数の参照を検査できる(t *testing.T) {
+	n := loadVarRefTestNetwork(t, "1")
+	n.elements["j3"].(*Job).Env = "A=$MJjob2:OUT$"
+
+	err := n.DetectVariableError()
+	if err == nil {
+		t.Fatal("エラーが発生しなかった。")
+	}
+	if !strings.Contains(err.Error(), "Element[id = j3] env") {
+		t.Errorf("エラーメッセージ[%s]が想定と違っている。", err)
+	}
+}
+
+func TestDetectVariableError_分岐条件の参照を検査できる(t *testing.T) {
+	n := loadVarRefTestNetwork(t, "3")
+	if err := n.DetectVariableError(); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+
+	n = loadVarRefTestNetwork(t, "6")
+	err := n.DetectVariableError()
+	if err == nil {
+		t.Fatal("エラーが発生しなかった。")
+	}
+	if !strings.Contains(err.Error(), "Element[id = xgw1] condition of flow[flow8]") {
+		t.Errorf("エラーメッセージ[%s]が想定と違っている。", err)
+	}
+}
+
+func TestValidate_実行前と同じ手順で定義を検証できる(t *testing.T) {
+	n := loadVarRefTestNetwork(t, "1")
+	if err := n.Validate(); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if n.Calendar == nil {
+		t.Error("営業日カレンダーがセットされていない。")
+	}
+}
+
+func TestValidate_変数参照の誤りを検出できる(t *testing.T) {
+	n := loadVarRefTestNetwork(t, "6")
+	if err := n.Validate(); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
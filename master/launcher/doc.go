@@ -0,0 +1,2 @@
+// masterコマンドを起動し、起動したジョブネットのインスタンスIDを取得するパッケージ
+package launcher
@@ -0,0 +1,83 @@
+package launcher
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var instancePattern = regexp.MustCompile(`STARTED\. INSTANCE \[(\d+)\]`)
+
+// masterコマンドを起動し、起動したジョブネットのインスタンスIDが出力されるまで待つ。
+//
+// param : cmd masterコマンド。
+//
+// return : インスタンスID。
+//
+// return : masterプロセスの終了時にクローズされるチャネル。
+//
+// return : エラー情報。
+func Launch(cmd *exec.Cmd) (int, <-chan struct{}, error) {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return 0, nil, err
+	}
+
+	idCh := make(chan int, 1)
+	done := make(chan struct{})
+	output := new(bytes.Buffer)
+	go func() {
+		scanInstanceID(stdout, idCh, output)
+		cmd.Wait()
+		close(done)
+	}()
+
+	select {
+	case id := <-idCh:
+		return id, done, nil
+	case <-done:
+		select {
+		case id := <-idCh:
+			return id, done, nil
+		default:
+		}
+		return 0, nil, fmt.Errorf("Master ended without starting jobnet: %s", strings.TrimSpace(output.String()))
+	}
+}
+
+// masterの出力を終了まで読み込み、インスタンスIDを見つけた時点でidChへ送信する。
+// インスタンスIDより前の行はoutputへ書き出す。
+// masterが出力の書き込みで停止しないよう、行の長さに制限を設けずに読み込む。
+func scanInstanceID(reader io.Reader, idCh chan<- int, output io.Writer) {
+	found := false
+	r := bufio.NewReader(reader)
+	for {
+		line, err := r.ReadString('\n')
+		if !found && len(line) > 0 {
+			line = strings.TrimRight(line, "\r\n")
+			if m := instancePattern.FindStringSubmatch(line); m != nil {
+				if id, err := strconv.Atoi(m[1]); err == nil {
+					idCh <- id
+					found = true
+				}
+			}
+			if !found {
+				fmt.Fprintln(output, line)
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	// 読み込みに失敗した場合も、masterが停止しないよう残りを読み捨てる
+	io.Copy(ioutil.Discard, reader)
+}
@@ -0,0 +1,75 @@
+package launcher
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestScanInstanceID(t *testing.T) {
+	out := `CTM001I GOCUTO MASTER STARTED. PID [100] VERSION [0.9.7.1]
+CTM012I [testnet] STARTED. INSTANCE [123]
+CTM013I [testnet] ENDED. INSTANCE [123] STATUS [NORMAL]
+`
+	idCh := make(chan int, 1)
+	output := new(bytes.Buffer)
+	scanInstanceID(strings.NewReader(out), idCh, output)
+
+	select {
+	case id := <-idCh:
+		if id != 123 {
+			t.Errorf("id => %d, want %d", id, 123)
+		}
+	default:
+		t.Fatal("Instance ID was not found.")
+	}
+	if strings.Contains(output.String(), "CTM013I") {
+		t.Errorf("Output after instance ID must not be kept: %s", output.String())
+	}
+}
+
+func TestScanInstanceID_NotStarted(t *testing.T) {
+	out := `CTM001I GOCUTO MASTER STARTED. PID [100] VERSION [0.9.7.1]
+CTM010E FAILED TO READ BPMN FILE [testnet.bpmn].
+`
+	idCh := make(chan int, 1)
+	output := new(bytes.Buffer)
+	scanInstanceID(strings.NewReader(out), idCh, output)
+
+	select {
+	case id := <-idCh:
+		t.Errorf("Unexpected instance ID[%d] received.", id)
+	default:
+	}
+	if !strings.Contains(output.String(), "CTM010E") {
+		t.Errorf("Output does not contain error message: %s", output.String())
+	}
+}
+
+func TestScanInstanceID_LongLine(t *testing.T) {
+	long := strings.Repeat("x", 100*1024)
+	out := long + `
+CTM012I [testnet] STARTED. INSTANCE [123]
+` + long + `
+CTM013I [testnet] ENDED. INSTANCE [123] STATUS [NORMAL]
+`
+	reader := strings.NewReader(out)
+	idCh := make(chan int, 1)
+	output := new(bytes.Buffer)
+	scanInstanceID(reader, idCh, output)
+
+	select {
+	case id := <-idCh:
+		if id != 123 {
+			t.Errorf("id => %d, want %d", id, 123)
+		}
+	default:
+		t.Fatal("Instance ID was not found.")
+	}
+	if reader.Len() != 0 {
+		t.Errorf("Output was not read to the end. %d bytes remain.", reader.Len())
+	}
+	if !strings.Contains(output.String(), long) {
+		t.Error("Long line before instance ID was not kept.")
+	}
+}
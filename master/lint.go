@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/unirita/cuto/master/jobnet"
+	"github.com/unirita/cuto/master/jobnet/parser"
+)
+
+// 検査結果の出力形式
+const (
+	lintFormatText = "text"
+	lintFormatJSON = "json"
+)
+
+// JSON形式で出力する検査結果
+type lintReport struct {
+	Jobnet   string              `json:"jobnet"`
+	Errors   int                 `json:"errors"`
+	Warnings int                 `json:"warnings"`
+	Issues   []*jobnet.LintIssue `json:"issues"`
+}
+
+// ジョブネットワーク定義を検査し、検出した問題をformatの形式でwへ出力する。
+// 定義ファイルを読み込めない場合も、その内容を問題の1つとして出力する。
+//
+// param : name ジョブネットワーク名。
+//
+// param : format 出力形式。
+//
+// param : w 出力先。
+//
+// return : 重要度がerrorの問題を検出した場合はrc_ERROR、それ以外はrc_OK。
+func lintNetwork(name string, format string, w io.Writer) int {
+	report := &lintReport{Jobnet: name, Issues: make([]*jobnet.LintIssue, 0)}
+	report.Issues = append(report.Issues, loadAndLint(name)...)
+	for _, issue := range report.Issues {
+		if issue.Level == jobnet.LINT_ERROR {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+
+	if format == lintFormatJSON {
+		b, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Fprintln(w, string(b))
+	} else {
+		for _, issue := range report.Issues {
+			fmt.Fprintln(w, issue)
+		}
+		fmt.Fprintf(w, "JOBNET [%s] ERRORS [%d] WARNINGS [%d]\n", name, report.Errors, report.Warnings)
+	}
+
+	if report.Errors > 0 {
+		return rc_ERROR
+	}
+	return rc_OK
+}
+
+// ジョブネットワーク定義を読み込んで検査する。
+func loadAndLint(name string) []*jobnet.LintIssue {
+	fileIssue := func(rule string, err error) []*jobnet.LintIssue {
+		return []*jobnet.LintIssue{{Level: jobnet.LINT_ERROR, Rule: rule, Message: err.Error()}}
+	}
+
+	nwk, err := jobnet.NewNetwork(name)
+	if err != nil {
+		return fileIssue("bpmn", err)
+	}
+	defer nwk.Terminate()
+
+	file, err := os.Open(nwk.MasterPath)
+	if err != nil {
+		return fileIssue("bpmn", err)
+	}
+	defer file.Close()
+	if err := nwk.LoadElements(file); err != nil {
+		return fileIssue("bpmn", fmt.Errorf("[%s] %s", nwk.MasterPath, err))
+	}
+
+	var issues []*jobnet.LintIssue
+	if err := nwk.LoadCalendar(); err != nil {
+		issues = append(issues, fileIssue("calendar", err)...)
+	}
+	jobEx, err := parser.ParseJobExFile(nwk.JobExPath)
+	if err != nil {
+		issues = append(issues, fileIssue("jobex", fmt.Errorf("[%s] %s", nwk.JobExPath, err))...)
+	}
+	return append(issues, nwk.Lint(jobEx)...)
+}
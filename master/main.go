@@ -7,6 +7,10 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"os/user"
+	"strings"
+	"syscall"
 
 	"github.com/unirita/cuto/console"
 	"github.com/unirita/cuto/db"
@@ -19,11 +23,23 @@ import (
 
 // 実行時引数のオプション
 type arguments struct {
-	versionFlag   bool   // バージョン情報表示フラグ
-	networkName   string // ジョブネットワーク名
-	startFlag     bool   // 実行フラグ
-	rerunInstance int    // リランを行うインスタンスID
-	configPath    string // 設定ファイルのパス
+	versionFlag    bool   // バージョン情報表示フラグ
+	networkName    string // ジョブネットワーク名
+	startFlag      bool   // 実行フラグ
+	dryRunFlag     bool   // ドライランフラグ
+	lintFlag       bool   // 定義検査フラグ
+	lintFormat     string // 定義検査結果の出力形式
+	rerunInstance  int    // リランを行うインスタンスID
+	rerunFrom      string // リラン時に強制再実行を開始するジョブ名
+	rerunOnly      string // リラン時に再実行するジョブ名（カンマ区切り）
+	skipJobs       string // リラン時に実行せず完了扱いとするジョブ名（カンマ区切り）
+	forceJobs      string // リラン時に強制的に完了扱いとするジョブ名（カンマ区切り）
+	markReason     string // ジョブを完了扱いとする理由
+	cancelInstance int    // 実行中止を行うインスタンスID
+	pingNodes      string // 状態確認を行うservantのアドレス（カンマ区切り）
+	preflightFlag  bool   // 実行前のservant状態確認フラグ
+	outputFlag     bool   // ジョブ出力表示フラグ
+	configPath     string // 設定ファイルのパス
 }
 
 // masterの戻り値
@@ -54,7 +70,7 @@ func realMain(args *arguments) (rc int) {
 		return
 	}
 
-	if args.networkName == "" && args.rerunInstance == 0 {
+	if args.networkName == "" && args.rerunInstance == 0 && args.cancelInstance == 0 && args.pingNodes == "" {
 		showUsage()
 		rc = rc_ERROR
 		return
@@ -66,6 +82,60 @@ func realMain(args *arguments) (rc int) {
 		return
 	}
 
+	if args.cancelInstance != 0 && (args.networkName != "" || args.rerunInstance != 0) {
+		console.Display("CTM019E", "Cannot use -k option with -n or -r option.")
+		rc = rc_ERROR
+		return
+	}
+
+	if args.pingNodes != "" && (args.networkName != "" || args.rerunInstance != 0 || args.cancelInstance != 0) {
+		console.Display("CTM019E", "Cannot use -ping option with -n, -r or -k option.")
+		rc = rc_ERROR
+		return
+	}
+
+	if args.preflightFlag == flag_ON && args.startFlag == flag_OFF && args.rerunInstance == 0 {
+		console.Display("CTM019E", "Cannot use -preflight option without -s or -r option.")
+		rc = rc_ERROR
+		return
+	}
+
+	if (args.rerunFrom != "" || args.rerunOnly != "") && args.rerunInstance == 0 {
+		console.Display("CTM019E", "Cannot use -from or -only option without -r option.")
+		rc = rc_ERROR
+		return
+	}
+
+	if (args.skipJobs != "" || args.forceJobs != "") && args.rerunInstance == 0 {
+		console.Display("CTM019E", "Cannot use -skip or -force option without -r option.")
+		rc = rc_ERROR
+		return
+	}
+
+	if args.rerunFrom != "" && args.rerunOnly != "" {
+		console.Display("CTM019E", "Cannot use both -from and -only option.")
+		rc = rc_ERROR
+		return
+	}
+
+	if args.dryRunFlag == flag_ON && args.networkName == "" {
+		console.Display("CTM019E", "Cannot use -dry-run option without -n option.")
+		rc = rc_ERROR
+		return
+	}
+
+	if args.lintFlag == flag_ON && args.networkName == "" {
+		console.Display("CTM019E", "Cannot use -lint option without -n option.")
+		rc = rc_ERROR
+		return
+	}
+
+	if args.lintFormat != "" && args.lintFormat != lintFormatText && args.lintFormat != lintFormatJSON {
+		console.Display("CTM019E", fmt.Sprintf("Invalid format [%s].", args.lintFormat))
+		rc = rc_ERROR
+		return
+	}
+
 	if args.configPath == "" {
 		args.configPath = defaultConfig
 	}
@@ -85,6 +155,12 @@ func realMain(args *arguments) (rc int) {
 		return
 	}
 
+	// 検査結果をそのまま機械処理できるよう、ログ出力の初期化およびコンソールメッセージの表示より前に検査する。
+	if args.lintFlag == flag_ON {
+		rc = lintNetwork(args.networkName, args.lintFormat, os.Stdout)
+		return
+	}
+
 	if err := log.Init(config.Dir.LogDir,
 		"master",
 		"",
@@ -103,6 +179,19 @@ func realMain(args *arguments) (rc int) {
 		console.Display("CTM002I", rc)
 	}()
 
+	if args.cancelInstance != 0 {
+		if err := cancelNetwork(args.cancelInstance); err != nil {
+			console.Display("CTM019E", err)
+			rc = rc_ERROR
+		}
+		return
+	}
+
+	if args.pingNodes != "" {
+		rc = pingServants(args.pingNodes)
+		return
+	}
+
 	if args.rerunInstance != 0 {
 		nwkResult, err := getNetworkResult(args.rerunInstance)
 		if err != nil {
@@ -111,7 +200,8 @@ func realMain(args *arguments) (rc int) {
 			return
 		}
 
-		if nwkResult.Status == db.NORMAL || nwkResult.Status == db.WARN {
+		isForced := args.rerunFrom != "" || args.rerunOnly != ""
+		if !isForced && (nwkResult.Status == db.NORMAL || nwkResult.Status == db.WARN) {
 			console.Display("CTM029I", args.rerunInstance)
 			return
 		}
@@ -126,25 +216,49 @@ func realMain(args *arguments) (rc int) {
 		return
 	}
 	defer nwk.Terminate()
+	nwk.DisplayOutput = args.outputFlag
+	nwk.RerunFrom = args.rerunFrom
+	nwk.RerunOnly = splitJobNames(args.rerunOnly)
+	nwk.SkipJobs = splitJobNames(args.skipJobs)
+	nwk.ForceJobs = splitJobNames(args.forceJobs)
+	nwk.MarkOperator = getOperatorName()
+	nwk.MarkReason = args.markReason
 
-	if err := nwk.DetectFlowError(); err != nil {
-		console.Display("CTM011E", nwk.MasterPath, err)
+	if err := nwk.Validate(); err != nil {
 		rc = rc_ERROR
 		return
 	}
 
+	if args.dryRunFlag == flag_ON {
+		if err := nwk.DryRun(os.Stdout); err != nil {
+			console.Display("CTM019E", err)
+			rc = rc_ERROR
+		}
+		return
+	}
+
 	if args.startFlag == flag_OFF {
 		console.Display("CTM020I", nwk.MasterPath)
 		return
 	}
 
-	if err := nwk.LoadJobEx(); err != nil {
-		console.Display("CTM004E", nwk.JobExPath)
-		log.Error(err)
-		rc = rc_ERROR
-		return
+	if args.preflightFlag == flag_ON {
+		if err := preflight(nwk); err != nil {
+			console.Display("CTM019E", err)
+			rc = rc_ERROR
+			return
+		}
 	}
 
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+	go func() {
+		for range sigCh {
+			nwk.Cancel()
+		}
+	}()
+
 	var err error
 	if args.rerunInstance == 0 {
 		err = nwk.Run()
@@ -152,7 +266,11 @@ func realMain(args *arguments) (rc int) {
 		nwk.ID = args.rerunInstance
 		err = nwk.Rerun()
 	}
-	if err != nil {
+	if err != nil && nwk.IsCancelled() {
+		console.Display("CTM013I", nwk.Name, nwk.ID, "CANCELLED")
+		rc = rc_ERROR
+		return
+	} else if err != nil {
 		console.Display("CTM013I", nwk.Name, nwk.ID, "ABNORMAL")
 		// ジョブ自体の異常終了では、エラーメッセージが空で返るので、出力しない
 		if len(err.Error()) != 0 {
@@ -172,12 +290,49 @@ func fetchArgs() *arguments {
 	flag.BoolVar(&args.versionFlag, "v", false, "version option")
 	flag.StringVar(&args.networkName, "n", "", "network name option")
 	flag.BoolVar(&args.startFlag, "s", false, "start option")
+	flag.BoolVar(&args.dryRunFlag, "dry-run", false, "dry run option")
+	flag.BoolVar(&args.lintFlag, "lint", false, "lint option")
+	flag.StringVar(&args.lintFormat, "format", "", "lint format option")
 	flag.IntVar(&args.rerunInstance, "r", 0, "rerun option")
+	flag.StringVar(&args.rerunFrom, "from", "", "rerun from option")
+	flag.StringVar(&args.rerunOnly, "only", "", "rerun only option")
+	flag.StringVar(&args.skipJobs, "skip", "", "skip option")
+	flag.StringVar(&args.forceJobs, "force", "", "force option")
+	flag.StringVar(&args.markReason, "reason", "", "reason option")
+	flag.IntVar(&args.cancelInstance, "k", 0, "cancel option")
+	flag.StringVar(&args.pingNodes, "ping", "", "ping option")
+	flag.BoolVar(&args.preflightFlag, "preflight", false, "preflight option")
+	flag.BoolVar(&args.outputFlag, "o", false, "output option")
 	flag.StringVar(&args.configPath, "c", "", "config file option")
 	flag.Parse()
 	return args
 }
 
+// カンマ区切りのジョブ名を分割する。空の要素は除外する。
+func splitJobNames(names string) []string {
+	var result []string
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// ジョブを完了扱いとした操作者として記録する、OSのユーザ名を取得する。
+func getOperatorName() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	if name := os.Getenv("USERNAME"); name != "" {
+		return name
+	}
+	return "unknown"
+}
+
 // バージョンを表示する。
 func showVersion() {
 	fmt.Printf("%s\n", Version)
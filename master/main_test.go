@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"io"
 	"net"
 	"os"
@@ -9,6 +10,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/master/config"
 	"github.com/unirita/cuto/testutil"
 )
 
@@ -286,6 +289,134 @@ func TestRealMain_ネットワーク定義の書式チェックのみを行う
 	}
 }
 
+func TestRealMain_ネットワーク定義の書式チェックのみを行う場合_変数の参照先エラーあり(t *testing.T) {
+	c := testutil.NewStdoutCapturer()
+
+	args := new(arguments)
+	args.networkName = "varerror"
+
+	c.Start()
+	rc := realMain(args)
+	out := c.Stop()
+
+	if rc != rc_ERROR {
+		t.Errorf("想定外のrc[%d]が返された。", rc)
+	}
+	if !strings.Contains(out, "IS NOT EXACT FORMAT") || !strings.Contains(out, "executed later") {
+		t.Error("出力内容が想定と違っている。")
+		t.Logf("出力: %s", out)
+	}
+}
+
+func TestRealMain_ドライランを行う(t *testing.T) {
+	c := testutil.NewStdoutCapturer()
+
+	args := new(arguments)
+	args.networkName = "test"
+	args.dryRunFlag = flag_ON
+
+	c.Start()
+	rc := realMain(args)
+	out := c.Stop()
+
+	if rc != rc_OK {
+		t.Errorf("想定外のrc[%d]が返された。", rc)
+	}
+	if !strings.Contains(out, "DRY RUN OF JOBNET [test]") || !strings.Contains(out, "JOB [") {
+		t.Error("出力内容が想定と違っている。")
+		t.Logf("出力: %s", out)
+	}
+	if strings.Contains(out, "STARTED. INSTANCE") {
+		t.Error("ジョブネットワークが実行されている。")
+		t.Logf("出力: %s", out)
+	}
+}
+
+func TestRealMain_ドライランがネットワーク名なしで指定された場合(t *testing.T) {
+	c := testutil.NewStdoutCapturer()
+
+	args := new(arguments)
+	args.rerunInstance = 1
+	args.dryRunFlag = flag_ON
+
+	c.Start()
+	rc := realMain(args)
+	out := c.Stop()
+
+	if rc != rc_ERROR {
+		t.Errorf("想定外のrc[%d]が返された。", rc)
+	}
+	if !strings.Contains(out, "EXCEPTION") {
+		t.Error("出力内容が想定と違っている。")
+		t.Logf("出力: %s", out)
+	}
+}
+
+func TestRealMain_定義検査を行う_問題なし(t *testing.T) {
+	c := testutil.NewStdoutCapturer()
+
+	args := new(arguments)
+	args.networkName = "test"
+	args.lintFlag = flag_ON
+
+	c.Start()
+	rc := realMain(args)
+	out := c.Stop()
+
+	if rc != rc_OK {
+		t.Errorf("想定外のrc[%d]が返された。", rc)
+	}
+	if !strings.Contains(out, "JOBNET [test] ERRORS [0]") {
+		t.Error("出力内容が想定と違っている。")
+		t.Logf("出力: %s", out)
+	}
+}
+
+func TestRealMain_定義検査を行う_JSON形式で出力(t *testing.T) {
+	c := testutil.NewStdoutCapturer()
+
+	args := new(arguments)
+	args.networkName = "error"
+	args.lintFlag = flag_ON
+	args.lintFormat = "json"
+
+	c.Start()
+	rc := realMain(args)
+	out := c.Stop()
+
+	if rc != rc_ERROR {
+		t.Errorf("想定外のrc[%d]が返された。", rc)
+	}
+	report := new(lintReport)
+	if err := json.Unmarshal([]byte(out), report); err != nil {
+		t.Fatalf("出力をJSONとしてパースできない: %s\n%s", err, out)
+	}
+	if report.Jobnet != "error" || report.Errors == 0 || len(report.Issues) != report.Errors+report.Warnings {
+		t.Errorf("検査結果%+vが想定と違っている。", report)
+	}
+}
+
+func TestRealMain_定義検査の出力形式が不正な場合(t *testing.T) {
+	c := testutil.NewStdoutCapturer()
+
+	args := new(arguments)
+	args.networkName = "test"
+	args.lintFlag = flag_ON
+	args.lintFormat = "xml"
+
+	c.Start()
+	rc := realMain(args)
+	out := c.Stop()
+
+	if rc != rc_ERROR {
+		t.Errorf("想定外のrc[%d]が返された。", rc)
+	}
+	if !strings.Contains(out, "EXCEPTION") {
+		t.Error("出力内容が想定と違っている。")
+		t.Logf("出力: %s", out)
+	}
+}
+
 func TestRealMain_ジョブ実行を行う_正常な実行(t *testing.T) {
 	c := testutil.NewStdoutCapturer()
 
@@ -389,3 +520,292 @@ func TestRealMain_リラン実行_存在しないインスタンスIDの場合(t
 		t.Logf("出力: %s", out)
 	}
 }
+
+func TestRealMain_実行中止とネットワーク名が同時に指定された場合(t *testing.T) {
+	c := testutil.NewStdoutCapturer()
+
+	args := new(arguments)
+	args.networkName = "test"
+	args.cancelInstance = 4
+
+	c.Start()
+	rc := realMain(args)
+	out := c.Stop()
+
+	if rc != rc_ERROR {
+		t.Errorf("想定外のrc[%d]が返された。", rc)
+	}
+	if !strings.Contains(out, "EXCEPTION") {
+		t.Error("出力内容が想定と違っている。")
+		t.Logf("出力: %s", out)
+	}
+}
+
+func TestRealMain_実行中止_実行中でないインスタンスの場合(t *testing.T) {
+	c := testutil.NewStdoutCapturer()
+
+	args := new(arguments)
+	args.cancelInstance = 1
+
+	c.Start()
+	rc := realMain(args)
+	out := c.Stop()
+
+	if rc != rc_OK {
+		t.Errorf("想定外のrc[%d]が返された。", rc)
+	}
+	if !strings.Contains(out, "CTM036I") {
+		t.Errorf("想定されるメッセージ[%s]が出力されていない。", "CTM036I")
+		t.Logf("出力: %s", out)
+	}
+}
+
+func TestRealMain_実行中止_masterが存在しない場合は強制的に実行中止状態とする(t *testing.T) {
+	c := testutil.NewStdoutCapturer()
+
+	args := new(arguments)
+	args.cancelInstance = 4
+
+	c.Start()
+	rc := realMain(args)
+	out := c.Stop()
+
+	if rc != rc_OK {
+		t.Errorf("想定外のrc[%d]が返された。", rc)
+	}
+	if !strings.Contains(out, "CTM037W") || !strings.Contains(out, "CTM038I") {
+		t.Error("出力内容が想定と違っている。")
+		t.Logf("出力: %s", out)
+	}
+
+	nwkResult, err := getNetworkResult(4)
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if nwkResult.Status != db.CANCELLED {
+		t.Errorf("ジョブネットワークのステータス[%d]が想定と違っている。", nwkResult.Status)
+	}
+}
+
+func TestRealMain_リラン対象ジョブの指定がリラン以外で指定された場合(t *testing.T) {
+	c := testutil.NewStdoutCapturer()
+
+	args := new(arguments)
+	args.networkName = "test"
+	args.rerunFrom = "job1"
+
+	c.Start()
+	rc := realMain(args)
+	out := c.Stop()
+
+	if rc != rc_ERROR {
+		t.Errorf("想定外のrc[%d]が返された。", rc)
+	}
+	if !strings.Contains(out, "EXCEPTION") {
+		t.Error("出力内容が想定と違っている。")
+		t.Logf("出力: %s", out)
+	}
+}
+
+func TestRealMain_リラン対象ジョブの指定が両方指定された場合(t *testing.T) {
+	c := testutil.NewStdoutCapturer()
+
+	args := new(arguments)
+	args.rerunInstance = 1
+	args.rerunFrom = "job1"
+	args.rerunOnly = "job2"
+
+	c.Start()
+	rc := realMain(args)
+	out := c.Stop()
+
+	if rc != rc_ERROR {
+		t.Errorf("想定外のrc[%d]が返された。", rc)
+	}
+	if !strings.Contains(out, "EXCEPTION") {
+		t.Error("出力内容が想定と違っている。")
+		t.Logf("出力: %s", out)
+	}
+}
+
+func TestSplitJobNames_カンマ区切りのジョブ名を分割できる(t *testing.T) {
+	names := splitJobNames(" job1,job2 ,,job3")
+	if len(names) != 3 {
+		t.Fatalf("分割後の要素数[%d]が想定と違っている。", len(names))
+	}
+	if names[0] != "job1" || names[1] != "job2" || names[2] != "job3" {
+		t.Errorf("分割結果%vが想定と違っている。", names)
+	}
+	if names := splitJobNames(""); len(names) != 0 {
+		t.Errorf("空文字列の分割結果%vが想定と違っている。", names)
+	}
+}
+
+func TestRealMain_完了扱いとするジョブがリラン以外で指定された場合(t *testing.T) {
+	c := testutil.NewStdoutCapturer()
+
+	args := new(arguments)
+	args.networkName = "test"
+	args.skipJobs = "job1"
+
+	c.Start()
+	rc := realMain(args)
+	out := c.Stop()
+
+	if rc != rc_ERROR {
+		t.Errorf("想定外のrc[%d]が返された。", rc)
+	}
+	if !strings.Contains(out, "EXCEPTION") {
+		t.Error("出力内容が想定と違っている。")
+		t.Logf("出力: %s", out)
+	}
+}
+
+func TestGetOperatorName_操作者名を取得できる(t *testing.T) {
+	if name := getOperatorName(); name == "" {
+		t.Error("操作者名が空になっている。")
+	}
+}
+
+func runTestStatusServant(t *testing.T, listener net.Listener) {
+	conn, cerr := listener.Accept()
+	if cerr != nil {
+		t.Log(cerr)
+		return
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 1024)
+	if _, err := conn.Read(buf); err != nil {
+		t.Log(err)
+		return
+	}
+
+	res := `{"type":"statusresult","version":"2.3.4","uptime":120,"multiproc":20,"queued":1,"load":0.5,`
+	res += `"jobs":[{"nid":1234,"jid":"j1","path":"job1.bat","pid":5678,"st":"2015-04-01 12:34:56.789"}]}`
+	res += "\n"
+
+	if _, err := conn.Write([]byte(res)); err != nil {
+		t.Log(err)
+		return
+	}
+}
+
+func TestRealMain_servantの状態確認を行う(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:15244")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go runTestStatusServant(t, listener)
+
+	c := testutil.NewStdoutCapturer()
+
+	args := new(arguments)
+	args.pingNodes = "localhost:15244"
+
+	c.Start()
+	rc := realMain(args)
+	out := c.Stop()
+
+	if rc != rc_OK {
+		t.Errorf("想定外のrc[%d]が返された。", rc)
+	}
+	if !strings.Contains(out, "CTM051I SERVANT [localhost:15244] VERSION [2.3.4]") {
+		t.Errorf("想定されるメッセージ[%s]が出力されていない。", "CTM051I")
+		t.Logf("出力: %s", out)
+	}
+	if !strings.Contains(out, "CTM052I JOB [job1.bat] IS RUNNING ON SERVANT [localhost:15244]. INSTANCE [1234] JOBID [j1] PID [5678]") {
+		t.Errorf("想定されるメッセージ[%s]が出力されていない。", "CTM052I")
+		t.Logf("出力: %s", out)
+	}
+}
+
+func TestRealMain_servantの状態確認_接続できない場合(t *testing.T) {
+	c := testutil.NewStdoutCapturer()
+
+	args := new(arguments)
+	args.pingNodes = "localhost:15245"
+
+	c.Start()
+	rc := realMain(args)
+	out := c.Stop()
+
+	if rc != rc_ERROR {
+		t.Errorf("想定外のrc[%d]が返された。", rc)
+	}
+	if !strings.Contains(out, "CTM053E") {
+		t.Errorf("想定されるメッセージ[%s]が出力されていない。", "CTM053E")
+		t.Logf("出力: %s", out)
+	}
+}
+
+func TestRealMain_servantの状態確認とネットワーク名が同時に指定された場合(t *testing.T) {
+	c := testutil.NewStdoutCapturer()
+
+	args := new(arguments)
+	args.networkName = "test"
+	args.pingNodes = "localhost:15244"
+
+	c.Start()
+	rc := realMain(args)
+	out := c.Stop()
+
+	if rc != rc_ERROR {
+		t.Errorf("想定外のrc[%d]が返された。", rc)
+	}
+	if !strings.Contains(out, "EXCEPTION") {
+		t.Error("出力内容が想定と違っている。")
+		t.Logf("出力: %s", out)
+	}
+}
+
+func TestRealMain_実行前の状態確認がジョブ実行以外で指定された場合(t *testing.T) {
+	c := testutil.NewStdoutCapturer()
+
+	args := new(arguments)
+	args.networkName = "test"
+	args.preflightFlag = flag_ON
+
+	c.Start()
+	rc := realMain(args)
+	out := c.Stop()
+
+	if rc != rc_ERROR {
+		t.Errorf("想定外のrc[%d]が返された。", rc)
+	}
+	if !strings.Contains(out, "EXCEPTION") {
+		t.Error("出力内容が想定と違っている。")
+		t.Logf("出力: %s", out)
+	}
+}
+
+func TestSplitNodeAddress_servantのアドレスをホスト名とポート番号に分割できる(t *testing.T) {
+	host, port, err := splitNodeAddress("testhost:1234")
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if host != "testhost" || port != 1234 {
+		t.Errorf("分割結果[%s][%d]が想定と違っている。", host, port)
+	}
+}
+
+func TestSplitNodeAddress_ポート番号が省略された場合はデフォルトポートを使用する(t *testing.T) {
+	config.Job.DefaultPort = 2015
+	host, port, err := splitNodeAddress("testhost")
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if host != "testhost" || port != 2015 {
+		t.Errorf("分割結果[%s][%d]が想定と違っている。", host, port)
+	}
+}
+
+func TestFormatLoad_平均負荷を表示用の文字列に変換できる(t *testing.T) {
+	if s := formatLoad(0.5); s != "0.50" {
+		t.Errorf("変換結果[%s]が想定と違っている。", s)
+	}
+	if s := formatLoad(-1); s != "-" {
+		t.Errorf("変換結果[%s]が想定と違っている。", s)
+	}
+}
@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// ローカルのコマンドを実行して通知する。
+// イベントの内容は、環境変数とJSON形式の標準入力でコマンドへ渡す。
+type commandNotifier struct {
+	path string
+}
+
+func (n *commandNotifier) name() string {
+	return "command"
+}
+
+func (n *commandNotifier) notify(e *Event, timeout time.Duration) error {
+	body, err := e.json()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(n.path)
+	cmd.Env = append(os.Environ(),
+		"CUTO_NOTIFY_TYPE="+e.Type,
+		"CUTO_NOTIFY_NID="+strconv.Itoa(e.NID),
+		"CUTO_NOTIFY_JOBNET="+e.Jobnet,
+		"CUTO_NOTIFY_JID="+e.JID,
+		"CUTO_NOTIFY_JOB="+e.Job,
+		"CUTO_NOTIFY_NODE="+e.Node,
+		"CUTO_NOTIFY_STATUS="+e.Status,
+		"CUTO_NOTIFY_RC="+strconv.Itoa(e.RC),
+		"CUTO_NOTIFY_DETAIL="+e.Detail,
+		"CUTO_NOTIFY_STARTDATE="+e.StartDate,
+		"CUTO_NOTIFY_ENDDATE="+e.EndDate)
+	cmd.Stdin = bytes.NewReader(body)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		cmd.Process.Kill()
+		<-done
+		return fmt.Errorf("Command[%s] timed out.", n.path)
+	}
+}
@@ -0,0 +1,2 @@
+// ジョブネットワーク・ジョブの状態変化を外部へ通知するパッケージ
+package notify
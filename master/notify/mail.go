@@ -0,0 +1,105 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPサーバ経由でメールを送信して通知する。
+type mailNotifier struct {
+	server   string
+	user     string
+	password string
+	from     string
+	to       []string
+}
+
+func (n *mailNotifier) name() string {
+	return "mail"
+}
+
+func (n *mailNotifier) notify(e *Event, timeout time.Duration) error {
+	host, _, err := net.SplitHostPort(n.server)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialTimeout("tcp", n.server, timeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if n.user != "" {
+		if err := c.Auth(smtp.PlainAuth("", n.user, n.password, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(n.from); err != nil {
+		return err
+	}
+	for _, to := range n.to {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(n.message(e)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// メールのヘッダと本文を生成する。
+func (n *mailNotifier) message(e *Event) []byte {
+	var subject string
+	if e.Type == TYPE_JOB {
+		subject = fmt.Sprintf("[cuto] Job %s of %s (instance %d) ended %s", e.Job, e.Jobnet, e.NID, e.Status)
+	} else {
+		subject = fmt.Sprintf("[cuto] Jobnet %s (instance %d) ended %s", e.Jobnet, e.NID, e.Status)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", n.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+	fmt.Fprintf(&buf, "Type      : %s\r\n", e.Type)
+	fmt.Fprintf(&buf, "Jobnet    : %s\r\n", e.Jobnet)
+	fmt.Fprintf(&buf, "Instance  : %d\r\n", e.NID)
+	if e.Type == TYPE_JOB {
+		fmt.Fprintf(&buf, "Job       : %s (%s)\r\n", e.Job, e.JID)
+		fmt.Fprintf(&buf, "Node      : %s\r\n", e.Node)
+		fmt.Fprintf(&buf, "RC        : %d\r\n", e.RC)
+	}
+	fmt.Fprintf(&buf, "Status    : %s\r\n", e.Status)
+	fmt.Fprintf(&buf, "Start date: %s\r\n", e.StartDate)
+	fmt.Fprintf(&buf, "End date  : %s\r\n", e.EndDate)
+	fmt.Fprintf(&buf, "Detail    : %s\r\n", e.Detail)
+	return buf.Bytes()
+}
@@ -0,0 +1,196 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/unirita/cuto/console"
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/master/config"
+)
+
+// イベント種別
+const (
+	TYPE_JOBNET = "jobnet"
+	TYPE_JOB    = "job"
+)
+
+// ジョブネットワークまたはジョブの状態変化を表す構造体
+type Event struct {
+	Type      string `json:"type"`
+	NID       int    `json:"nid"`
+	Jobnet    string `json:"jobnet"`
+	JID       string `json:"jid,omitempty"`
+	Job       string `json:"job,omitempty"`
+	Node      string `json:"node,omitempty"`
+	Status    string `json:"status"`
+	RC        int    `json:"rc"`
+	Detail    string `json:"detail"`
+	StartDate string `json:"startdate"`
+	EndDate   string `json:"enddate"`
+}
+
+// 通知手段を表すインターフェース
+type notifier interface {
+	// 通知手段の名称を返す。
+	name() string
+	// イベントを通知する。
+	notify(e *Event, timeout time.Duration) error
+}
+
+// 通知手段と、通知対象とするステータスの組
+type channel struct {
+	notifier notifier
+	statuses []string
+}
+
+var wg sync.WaitGroup
+
+// ジョブネットワークの実行結果からイベントを生成する。
+//
+// param : res ジョブネットワークの実行結果。
+//
+// return : イベント。
+func NewJobnetEvent(res *db.JobNetworkResult) *Event {
+	e := new(Event)
+	e.Type = TYPE_JOBNET
+	e.NID = res.ID
+	e.Jobnet = res.JobnetWork
+	e.Status = StatusName(res.Status)
+	e.Detail = res.Detail
+	e.StartDate = res.StartDate
+	e.EndDate = res.EndDate
+	return e
+}
+
+// ジョブの実行結果からイベントを生成する。
+//
+// param : jobnet ジョブネットワーク名。
+//
+// param : res ジョブの実行結果。
+//
+// return : イベント。
+func NewJobEvent(jobnet string, res *db.JobResult) *Event {
+	e := new(Event)
+	e.Type = TYPE_JOB
+	e.NID = res.ID
+	e.Jobnet = jobnet
+	e.JID = res.JobId
+	e.Job = res.JobName
+	e.Node = res.Node
+	e.Status = StatusName(res.Status)
+	e.RC = res.Rc
+	e.Detail = res.Detail
+	e.StartDate = res.StartDate
+	e.EndDate = res.EndDate
+	return e
+}
+
+// ステータスを、master.iniのnotifyセクションで使用する名称へ変換する。
+//
+// param : status ステータス。
+//
+// return : ステータス名。
+func StatusName(status int) string {
+	switch status {
+	case db.RUNNING:
+		return "running"
+	case db.NORMAL:
+		return "normal"
+	case db.WARN:
+		return "warn"
+	case db.SKIPPED:
+		return "skipped"
+	case db.FORCED:
+		return "forced"
+	case db.CANCELLED:
+		return "cancelled"
+	default:
+		return "abnormal"
+	}
+}
+
+// master.iniのnotifyセクションの設定に従い、イベントを通知する。
+// 通知は非同期で行われるため、完了を待つ場合はWait関数を使用する。
+// 通知に失敗した場合は、コンソールへ警告を出力する。
+//
+// param : e 通知するイベント。
+func Send(e *Event) {
+	timeout := time.Duration(config.Notify.TimeoutSec) * time.Second
+	for _, c := range channels() {
+		if !c.accepts(e.Status) {
+			continue
+		}
+		wg.Add(1)
+		go func(n notifier) {
+			defer wg.Done()
+			if err := n.notify(e, timeout); err != nil {
+				console.Display("CTM042W", n.name(), e.Type, e.NID, err)
+			}
+		}(c.notifier)
+	}
+}
+
+// 送信中の通知が全て完了するまで待機する。
+func Wait() {
+	wg.Wait()
+}
+
+// 設定ファイルで有効になっている通知手段を返す。
+func channels() []*channel {
+	conf := config.Notify
+	var chs []*channel
+	if conf.Command != "" {
+		chs = append(chs, newChannel(&commandNotifier{path: conf.Command}, conf.CommandStatus))
+	}
+	if conf.WebhookURL != "" {
+		chs = append(chs, newChannel(&webhookNotifier{url: conf.WebhookURL}, conf.WebhookStatus))
+	}
+	if conf.Syslog != 0 {
+		chs = append(chs, newChannel(&syslogNotifier{network: conf.SyslogNetwork, addr: conf.SyslogAddr}, conf.SyslogStatus))
+	}
+	if conf.SMTPServer != "" {
+		m := &mailNotifier{
+			server:   conf.SMTPServer,
+			user:     conf.SMTPUser,
+			password: conf.SMTPPassword,
+			from:     conf.MailFrom,
+			to:       conf.MailTo,
+		}
+		chs = append(chs, newChannel(m, conf.MailStatus))
+	}
+	return chs
+}
+
+// 通知手段毎のステータス指定が無い場合は、statusキーの指定を使用する。
+func newChannel(n notifier, statuses []string) *channel {
+	if len(statuses) == 0 {
+		statuses = config.Notify.Status
+	}
+	return &channel{notifier: n, statuses: statuses}
+}
+
+func (c *channel) accepts(status string) bool {
+	for _, s := range c.statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// イベントの内容を1行で表した文字列を返す。
+func (e *Event) summary() string {
+	if e.Type == TYPE_JOB {
+		return fmt.Sprintf("JOB [%s] ENDED WITH STATUS [%s]. JOBNET [%s] INSTANCE [%d] JOBID [%s] NODE [%s] RC [%d] DETAIL [%s]",
+			e.Job, e.Status, e.Jobnet, e.NID, e.JID, e.Node, e.RC, e.Detail)
+	}
+	return fmt.Sprintf("JOBNET [%s] ENDED WITH STATUS [%s]. INSTANCE [%d] DETAIL [%s]",
+		e.Jobnet, e.Status, e.NID, e.Detail)
+}
+
+func (e *Event) json() ([]byte, error) {
+	return json.Marshal(e)
+}
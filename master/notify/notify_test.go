@@ -0,0 +1,248 @@
+package notify
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/master/config"
+)
+
+var defaultNotifyConfig = *config.Notify
+
+func initTestConfig() {
+	*config.Notify = defaultNotifyConfig
+	config.Notify.Status = []string{"abnormal"}
+	config.Notify.TimeoutSec = 1
+}
+
+func createTestEvent(status int) *Event {
+	res := &db.JobResult{
+		ID:        12,
+		JobId:     "JOB01",
+		JobName:   "job1",
+		Node:      "localhost",
+		Status:    status,
+		Rc:        9,
+		Detail:    "detail",
+		StartDate: "2015-04-01 10:00:00.000",
+		EndDate:   "2015-04-01 10:00:05.000",
+	}
+	return NewJobEvent("testnet", res)
+}
+
+// 受信したリクエストボディを保持するWebhookのスタブサーバを起動する。
+func startWebhookServer(status int) (*httptest.Server, chan []byte) {
+	bodies := make(chan []byte, 10)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies <- body
+		w.WriteHeader(status)
+	}))
+	return s, bodies
+}
+
+func TestStatusName_ステータスを名称に変換できる(t *testing.T) {
+	cases := map[int]string{
+		db.RUNNING:   "running",
+		db.NORMAL:    "normal",
+		db.WARN:      "warn",
+		db.CANCELLED: "cancelled",
+		db.ABNORMAL:  "abnormal",
+	}
+	for status, expected := range cases {
+		if name := StatusName(status); name != expected {
+			t.Errorf("ステータス[%d]の名称[%s]が想定と違っている。", status, name)
+		}
+	}
+}
+
+func TestNewJobnetEvent_ジョブネットワークの実行結果からイベントを生成できる(t *testing.T) {
+	res := &db.JobNetworkResult{
+		ID:         5,
+		JobnetWork: "testnet",
+		Status:     db.WARN,
+		Detail:     "detail",
+		StartDate:  "2015-04-01 10:00:00.000",
+		EndDate:    "2015-04-01 10:01:00.000",
+	}
+	e := NewJobnetEvent(res)
+	if e.Type != TYPE_JOBNET {
+		t.Errorf("種別[%s]が想定と違っている。", e.Type)
+	}
+	if e.NID != 5 || e.Jobnet != "testnet" || e.Status != "warn" || e.Detail != "detail" {
+		t.Errorf("イベントの内容%vが想定と違っている。", e)
+	}
+	if e.StartDate != res.StartDate || e.EndDate != res.EndDate {
+		t.Errorf("開始・終了日時[%s - %s]が想定と違っている。", e.StartDate, e.EndDate)
+	}
+}
+
+func TestSend_Webhookへイベントを送信できる(t *testing.T) {
+	initTestConfig()
+	s, bodies := startWebhookServer(http.StatusOK)
+	defer s.Close()
+	config.Notify.WebhookURL = s.URL
+
+	Send(createTestEvent(db.ABNORMAL))
+	Wait()
+
+	select {
+	case body := <-bodies:
+		e := new(Event)
+		if err := json.Unmarshal(body, e); err != nil {
+			t.Fatalf("想定外のエラーが発生した: %s", err)
+		}
+		if e.Type != TYPE_JOB || e.NID != 12 || e.JID != "JOB01" || e.Job != "job1" || e.Jobnet != "testnet" {
+			t.Errorf("送信されたイベント%vが想定と違っている。", e)
+		}
+		if e.Status != "abnormal" || e.RC != 9 || e.Node != "localhost" {
+			t.Errorf("送信されたイベント%vが想定と違っている。", e)
+		}
+	default:
+		t.Fatal("イベントが送信されなかった。")
+	}
+}
+
+func TestSend_対象外のステータスは通知しない(t *testing.T) {
+	initTestConfig()
+	s, bodies := startWebhookServer(http.StatusOK)
+	defer s.Close()
+	config.Notify.WebhookURL = s.URL
+
+	Send(createTestEvent(db.NORMAL))
+	Wait()
+
+	if len(bodies) != 0 {
+		t.Error("対象外のステータスのイベントが送信された。")
+	}
+}
+
+func TestSend_通知手段毎のステータス指定を優先する(t *testing.T) {
+	initTestConfig()
+	s, bodies := startWebhookServer(http.StatusOK)
+	defer s.Close()
+	config.Notify.WebhookURL = s.URL
+	config.Notify.WebhookStatus = []string{"normal", "warn"}
+
+	Send(createTestEvent(db.NORMAL))
+	Send(createTestEvent(db.WARN))
+	Send(createTestEvent(db.ABNORMAL))
+	Wait()
+
+	if len(bodies) != 2 {
+		t.Errorf("送信されたイベントの数[%d]が想定と違っている。", len(bodies))
+	}
+}
+
+func TestWebhookNotify_エラーステータスが返された場合はエラー(t *testing.T) {
+	s, _ := startWebhookServer(http.StatusInternalServerError)
+	defer s.Close()
+
+	n := &webhookNotifier{url: s.URL}
+	if err := n.notify(createTestEvent(db.ABNORMAL), time.Second); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+// 受信したコマンドとメール本文を記録するSMTPのスタブサーバを起動する。
+func startSMTPServer(t *testing.T) (string, chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	received := make(chan string, 1)
+	go func() {
+		defer l.Close()
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var log []string
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP stub")
+		inData := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			log = append(log, line)
+			if inData {
+				if line == "." {
+					inData = false
+					reply("250 OK")
+				}
+				continue
+			}
+			switch {
+			case strings.HasPrefix(line, "EHLO"):
+				reply("250 localhost")
+			case strings.HasPrefix(line, "DATA"):
+				inData = true
+				reply("354 Go ahead")
+			case strings.HasPrefix(line, "QUIT"):
+				reply("221 Bye")
+				received <- strings.Join(log, "\n")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+	return l.Addr().String(), received
+}
+
+func TestMailNotify_SMTPサーバへメールを送信できる(t *testing.T) {
+	addr, received := startSMTPServer(t)
+	n := &mailNotifier{
+		server: addr,
+		from:   "cuto@example.com",
+		to:     []string{"admin@example.com", "ops@example.com"},
+	}
+
+	if err := n.notify(createTestEvent(db.ABNORMAL), time.Second); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+
+	var log string
+	select {
+	case log = <-received:
+	case <-time.After(time.Second):
+		t.Fatal("SMTPサーバがメールを受信しなかった。")
+	}
+	expected := []string{
+		"MAIL FROM:<cuto@example.com>",
+		"RCPT TO:<admin@example.com>",
+		"RCPT TO:<ops@example.com>",
+		"Subject: [cuto] Job job1 of testnet (instance 12) ended abnormal",
+		"Status    : abnormal",
+	}
+	for _, s := range expected {
+		if !strings.Contains(log, s) {
+			t.Errorf("受信内容に[%s]が含まれていない。\n%s", s, log)
+		}
+	}
+}
+
+func TestMailNotify_SMTPサーバへ接続できない場合はエラー(t *testing.T) {
+	l, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := l.Addr().String()
+	l.Close()
+
+	n := &mailNotifier{server: addr, from: "cuto@example.com", to: []string{"admin@example.com"}}
+	if err := n.notify(createTestEvent(db.ABNORMAL), time.Second); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
@@ -0,0 +1,97 @@
+// +build darwin linux
+
+package notify
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unirita/cuto/db"
+)
+
+func TestCommandNotify_環境変数と標準入力でイベントを渡す(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cuto_notify")
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	out := filepath.Join(dir, "out.txt")
+	script := filepath.Join(dir, "notify.sh")
+	body := "#!/bin/sh\necho \"$CUTO_NOTIFY_JOBNET $CUTO_NOTIFY_JID $CUTO_NOTIFY_STATUS\" > " + out + "\ncat >> " + out + "\n"
+	if err := ioutil.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+
+	n := &commandNotifier{path: script}
+	if err := n.notify(createTestEvent(db.ABNORMAL), 5*time.Second); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+
+	result, err := ioutil.ReadFile(out)
+	if err != nil {
+		t.Fatalf("コマンドが実行されなかった: %s", err)
+	}
+	lines := strings.SplitN(string(result), "\n", 2)
+	if lines[0] != "testnet JOB01 abnormal" {
+		t.Errorf("環境変数の値[%s]が想定と違っている。", lines[0])
+	}
+	if !strings.Contains(lines[1], `"jid":"JOB01"`) {
+		t.Errorf("標準入力の内容[%s]が想定と違っている。", lines[1])
+	}
+}
+
+func TestCommandNotify_タイムアウトした場合はエラー(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cuto_notify")
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	script := filepath.Join(dir, "sleep.sh")
+	if err := ioutil.WriteFile(script, []byte("#!/bin/sh\nexec sleep 10\n"), 0755); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+
+	n := &commandNotifier{path: script}
+	start := time.Now()
+	if err := n.notify(createTestEvent(db.ABNORMAL), 100*time.Millisecond); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("タイムアウト後もコマンドの終了を待ち続けた。")
+	}
+}
+
+func TestSyslogNotify_syslogサーバへ出力できる(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	defer conn.Close()
+
+	n := &syslogNotifier{network: "udp", addr: conn.LocalAddr().String()}
+	if err := n.notify(createTestEvent(db.ABNORMAL), time.Second); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+
+	buf := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	size, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("syslogサーバが受信しなかった: %s", err)
+	}
+	msg := string(buf[:size])
+	// LOG_DAEMON(3) * 8 + LOG_ERR(3)
+	if !strings.HasPrefix(msg, "<27>") {
+		t.Errorf("優先度が想定と違っている: %s", msg)
+	}
+	if !strings.Contains(msg, "cuto") || !strings.Contains(msg, "JOB [job1] ENDED WITH STATUS [abnormal].") {
+		t.Errorf("受信内容[%s]が想定と違っている。", msg)
+	}
+}
@@ -0,0 +1,37 @@
+// +build darwin linux
+
+package notify
+
+import (
+	"log/syslog"
+	"time"
+)
+
+// syslogへ出力して通知する。
+// networkが空の場合はローカルのsyslogへ出力する。
+type syslogNotifier struct {
+	network string
+	addr    string
+}
+
+func (n *syslogNotifier) name() string {
+	return "syslog"
+}
+
+func (n *syslogNotifier) notify(e *Event, timeout time.Duration) error {
+	w, err := syslog.Dial(n.network, n.addr, syslog.LOG_INFO|syslog.LOG_DAEMON, "cuto")
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	msg := e.summary()
+	switch e.Status {
+	case "abnormal":
+		return w.Err(msg)
+	case "warn", "cancelled":
+		return w.Warning(msg)
+	default:
+		return w.Info(msg)
+	}
+}
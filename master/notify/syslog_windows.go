@@ -0,0 +1,21 @@
+package notify
+
+import (
+	"fmt"
+	"time"
+)
+
+// syslogへ出力して通知する。
+// Windowsではsyslogが存在しないため、常にエラーとなる。
+type syslogNotifier struct {
+	network string
+	addr    string
+}
+
+func (n *syslogNotifier) name() string {
+	return "syslog"
+}
+
+func (n *syslogNotifier) notify(e *Event, timeout time.Duration) error {
+	return fmt.Errorf("Syslog is not supported on Windows.")
+}
@@ -0,0 +1,39 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// WebhookのURLへイベントをJSON形式でPOSTして通知する。
+type webhookNotifier struct {
+	url string
+}
+
+func (n *webhookNotifier) name() string {
+	return "webhook"
+}
+
+func (n *webhookNotifier) notify(e *Event, timeout time.Duration) error {
+	body, err := e.json()
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: timeout}
+	res, err := client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+
+	if res.StatusCode < 200 || 300 <= res.StatusCode {
+		return fmt.Errorf("Webhook[%s] responded status [%s].", n.url, res.Status)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/unirita/cuto/console"
+	"github.com/unirita/cuto/master/config"
+	"github.com/unirita/cuto/master/jobnet"
+	"github.com/unirita/cuto/master/remote"
+	"github.com/unirita/cuto/message"
+)
+
+// servantへ状態確認要求を送信し、結果を表示する。
+//
+// param : nodes カンマ区切りのservantのアドレス。"ホスト名:ポート番号"の形式で、ポート番号を省略した場合はdefault_portを使用する。
+//
+// return : 全てのservantから状態を取得できた場合はrc_OK、それ以外はrc_ERROR。
+func pingServants(nodes string) int {
+	rc := rc_OK
+	for _, node := range strings.Split(nodes, ",") {
+		node = strings.TrimSpace(node)
+		if node == "" {
+			continue
+		}
+		if err := pingServant(node); err != nil {
+			console.Display("CTM053E", node, err)
+			rc = rc_ERROR
+		}
+	}
+	return rc
+}
+
+// ジョブネットワークの実行前に、ジョブが使用する全てのservantへ状態確認要求を送信する。
+// プライマリservantから状態を取得できない場合はエラーとし、セカンダリservantの場合は警告のみとする。
+//
+// param : nwk 実行するジョブネットワーク。
+//
+// return : エラー情報。
+func preflight(nwk *jobnet.Network) error {
+	primaries, secondaries := nwk.ServantAddresses()
+	var failed []string
+	for _, addr := range primaries {
+		if err := pingServant(addr); err != nil {
+			console.Display("CTM053E", addr, err)
+			failed = append(failed, addr)
+		}
+	}
+	for _, addr := range secondaries {
+		if err := pingServant(addr); err != nil {
+			console.Display("CTM054W", addr, err)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("Servant%v is not available.", failed)
+	}
+	return nil
+}
+
+// servantへ状態確認要求を送信し、servantの状態と実行中のジョブを表示する。
+func pingServant(node string) error {
+	host, port, err := splitNodeAddress(node)
+	if err != nil {
+		return err
+	}
+	result, err := requestStatus(host, port)
+	if err != nil {
+		return err
+	}
+
+	addr := fmt.Sprintf("%s:%d", host, port)
+	console.Display("CTM051I", addr, result.Version, result.Uptime, result.MultiProc, len(result.Jobs), result.Queued, formatLoad(result.Load))
+	for _, j := range result.Jobs {
+		console.Display("CTM052I", j.Path, addr, j.NID, j.JID, j.PID, j.St)
+	}
+	return nil
+}
+
+// "ホスト名:ポート番号"形式のservantのアドレスを、ホスト名とポート番号に分割する。
+// ポート番号が省略されている場合は、default_portを返す。
+func splitNodeAddress(node string) (string, int, error) {
+	host, portStr, err := net.SplitHostPort(node)
+	if err != nil {
+		return node, config.Job.DefaultPort, nil
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return ``, 0, fmt.Errorf("Invalid port number [%s].", portStr)
+	}
+	return host, port, nil
+}
+
+// servantへ状態確認要求を送信し、状態確認結果を返す。
+func requestStatus(host string, port int) (*message.StatusResult, error) {
+	msg, err := message.Status{}.GenerateJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	stCh := make(chan string, 1)
+	resMsg, err := remote.SendRequest(host, port, msg, stCh, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resMsg == "" {
+		// 状態確認要求に対応していないservantは、応答せずに接続を閉じる
+		return nil, fmt.Errorf("Servant does not support status request.")
+	}
+
+	result := new(message.StatusResult)
+	if err := result.ParseJSON(resMsg); err != nil {
+		return nil, fmt.Errorf("Invalid status result [%s]: %s", resMsg, err)
+	}
+	return result, nil
+}
+
+// 平均負荷を表示用の文字列に変換する。取得できなかった場合は"-"とする。
+func formatLoad(load float64) string {
+	if load < 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(load, 'f', 2, 64)
+}
@@ -0,0 +1,150 @@
+package remote
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/unirita/cuto/console"
+	"github.com/unirita/cuto/message"
+)
+
+// 長さ付きフレーム形式に対応していないservantへ接続した場合のエラー
+var errFramedUnsupported = errors.New("Servant does not support framed protocol.")
+
+// 接続開始メッセージの受信に使用するバッファサイズ
+const helloBufSize = 4096
+
+// servant毎に受信した接続開始メッセージ。長さ付きフレーム形式に対応していないservantはnil。
+var servantHellos = struct {
+	sync.Mutex
+	hellos map[string]*message.Hello
+}{hellos: make(map[string]*message.Hello)}
+
+// 接続開始メッセージを交換した上で、長さ付きフレーム形式で要求を送信し、返信を待つ。
+//
+// param : addr servantのアドレス。
+//
+// param : signed 認証コード付きの要求メッセージ。
+//
+// param : stCh ジョブの開始時刻を受け取るチャネル。
+//
+// param : outCh ジョブの出力を行単位で受け取るチャネル。受け取らない場合はnil。
+//
+// param : timeout 接続および返信を待つ時間。
+//
+// return : 返信メッセージ。
+//
+// return : エラー情報。servantが長さ付きフレーム形式に対応していない場合はerrFramedUnsupported。
+func sendFramed(addr string, signed string, stCh chan<- string, outCh chan<- string, timeout time.Duration) (string, error) {
+	servantHellos.Lock()
+	peer, ok := servantHellos.hellos[addr]
+	servantHellos.Unlock()
+	if ok && peer == nil {
+		return ``, errFramedUnsupported
+	}
+
+	conn, err := dial(addr, timeout)
+	if err != nil {
+		return ``, err
+	}
+	defer conn.Close()
+
+	r := bufio.NewReaderSize(conn, helloBufSize)
+	peer, err = handshake(addr, conn, r, timeout)
+	if err != nil {
+		return ``, err
+	}
+	if err := checkSendable(peer, signed); err != nil {
+		return ``, err
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(timeout))
+	if err := message.WriteFramed(conn, signed); err != nil {
+		return ``, err
+	}
+
+	resMsg, err := receiveResponse(func() <-chan *response { return readFramedResponse(r) }, stCh, outCh, timeout)
+	if err != nil {
+		return ``, err
+	}
+	rj := new(message.Reject)
+	if err := rj.ParseJSON(resMsg); err == nil {
+		return ``, fmt.Errorf("Servant[%s] rejected message: %s", addr, rj.Detail)
+	}
+	return resMsg, nil
+}
+
+// servantと接続開始メッセージを交換し、servantから受信したHelloオブジェクトを返す。
+// servantが長さ付きフレーム形式に対応していない場合は、以降そのservantへは従来の形式で送信する。
+func handshake(addr string, conn net.Conn, r *bufio.Reader, timeout time.Duration) (*message.Hello, error) {
+	hello, err := message.NewHello(message.MasterVersion, message.MasterCapabilities).GenerateJSON()
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	defer conn.SetDeadline(time.Time{})
+	if _, err := conn.Write([]byte(hello + MsgEnd)); err != nil {
+		return nil, err
+	}
+
+	line, err := r.ReadSlice('\n')
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return nil, err
+	}
+	peer := new(message.Hello)
+	if err != nil || peer.ParseJSON(string(line)) != nil {
+		rj := new(message.Reject)
+		if err == nil && rj.ParseJSON(string(line)) == nil {
+			return nil, fmt.Errorf("Servant[%s] rejected connection: %s", addr, rj.Detail)
+		}
+		// 従来のservantは接続開始メッセージを解析できず、接続を閉じる
+		servantHellos.Lock()
+		servantHellos.hellos[addr] = nil
+		servantHellos.Unlock()
+		console.Display("CTM050W", addr)
+		return nil, errFramedUnsupported
+	}
+
+	protocol, err := message.NegotiateProtocol(peer)
+	if err != nil {
+		return nil, fmt.Errorf("Could not connect to servant[%s]: %s", addr, err)
+	}
+	servantHellos.Lock()
+	if _, ok := servantHellos.hellos[addr]; !ok {
+		console.Display("CTM049I", addr, peer.Version, protocol)
+	}
+	servantHellos.hellos[addr] = peer
+	servantHellos.Unlock()
+	return peer, nil
+}
+
+// servantが要求メッセージを受信できるかを検査する。
+func checkSendable(peer *message.Hello, msg string) error {
+	msgType, err := message.MessageType(msg)
+	if err != nil {
+		return err
+	}
+	if !peer.Supports(msgType) {
+		return fmt.Errorf("Servant version [%s] does not support message type [%s].", peer.Version, msgType)
+	}
+	if peer.MaxSize > 0 && len(msg) > peer.MaxSize {
+		return fmt.Errorf("Message size [%d] exceeds limit [%d] of servant.", len(msg), peer.MaxSize)
+	}
+	return nil
+}
+
+func readFramedResponse(r io.Reader) <-chan *response {
+	ch := make(chan *response, 1)
+	go func() {
+		res := new(response)
+		res.msg, res.err = message.ReadFramed(r, message.MaxMessageSize)
+		ch <- res
+	}()
+
+	return ch
+}
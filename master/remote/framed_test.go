@@ -0,0 +1,188 @@
+package remote
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/unirita/cuto/master/config"
+	"github.com/unirita/cuto/message"
+)
+
+// 長さ付きフレーム形式に対応したテスト用servantの接続を受け付け、接続開始メッセージに応答する。
+func acceptTestFramed(t *testing.T, listener net.Listener, hello *message.Hello) (net.Conn, *bufio.Reader) {
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Log(err)
+		return nil, nil
+	}
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	if err != nil || !strings.Contains(line, `"type":"hello"`) {
+		t.Errorf("接続開始メッセージ[%s]が想定と違っている。", line)
+		conn.Close()
+		return nil, nil
+	}
+	msg, _ := hello.GenerateJSON()
+	conn.Write([]byte(msg + "\n"))
+	return conn, r
+}
+
+func TestSendRequest_長さ付きフレーム形式で要求を送信できる(t *testing.T) {
+	config.Job.FramedProtocol = 1
+	defer func() { config.Job.FramedProtocol = 0 }()
+	const port = 12353
+	listener := listenTestPort(t, port)
+	defer listener.Close()
+
+	req := `{"type":"request","nid":1,"jid":"job1","param":"` + strings.Repeat("a", 100*1024) + `"}`
+	res := `{"type":"response","nid":1,"jid":"job1","var":"` + strings.Repeat("b", 100*1024) + `"}`
+	msq := make(chan string, 1)
+	go func() {
+		conn, r := acceptTestFramed(t, listener, message.NewHello("2.3.4", message.ServantCapabilities))
+		if conn == nil {
+			return
+		}
+		defer conn.Close()
+		msg, err := message.ReadFramed(r, message.MaxMessageSize)
+		if err != nil {
+			t.Errorf("要求を受信できなかった: %s", err)
+			return
+		}
+		msq <- msg
+		message.WriteFramed(conn, message.HEARTBEAT)
+		message.WriteFramed(conn, message.ST_HEADER+"2015-04-01 12:34:56.789")
+		message.WriteFramed(conn, res)
+	}()
+
+	stCh := make(chan string, 1)
+	defer close(stCh)
+	resMsg, err := SendRequest(testHost, port, req, stCh, nil)
+	if err != nil {
+		t.Fatalf("エラーが発生しました: %s", err)
+	}
+	if msg := <-msq; msg != req {
+		t.Errorf("リスナに届いたメッセージ長[%d]が想定[%d]と違っている。", len(msg), len(req))
+	}
+	if st := <-stCh; st != "2015-04-01 12:34:56.789" {
+		t.Errorf("スタート時刻[%s]が想定と違っている。", st)
+	}
+	if resMsg != res {
+		t.Errorf("返信メッセージ長[%d]が想定[%d]と違っている。", len(resMsg), len(res))
+	}
+}
+
+func TestSendRequest_長さ付きフレーム形式に対応していないservantには従来の形式で送信する(t *testing.T) {
+	config.Job.FramedProtocol = 1
+	defer func() { config.Job.FramedProtocol = 0 }()
+	const port = 12354
+	listener := listenTestPort(t, port)
+	defer listener.Close()
+
+	msq := make(chan string, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		// 従来のservantは接続開始メッセージを解析できずに接続を閉じる
+		bufio.NewScanner(conn).Scan()
+		conn.Close()
+		runTestReceiver(t, listener, msq, 0)
+		// 2回目以降は接続開始メッセージを送信しない
+		runTestReceiver(t, listener, msq, 0)
+	}()
+
+	for i := 0; i < 2; i++ {
+		stCh := make(chan string, 1)
+		res, err := SendRequest(testHost, port, `testrequest`, stCh, nil)
+		close(stCh)
+		if err != nil {
+			t.Fatalf("エラーが発生しました: %s", err)
+		}
+		if msg := <-msq; msg != "testrequest\n" {
+			t.Errorf("リスナに届いたメッセージが間違っています: %s", msg)
+		}
+		if res != `testresponse` {
+			t.Errorf("返信[%s]が想定と違っている。", res)
+		}
+	}
+}
+
+func TestSendRequest_servantが拒否した場合はエラーになる(t *testing.T) {
+	config.Job.FramedProtocol = 1
+	defer func() { config.Job.FramedProtocol = 0 }()
+	const port = 12355
+	listener := listenTestPort(t, port)
+	defer listener.Close()
+
+	go func() {
+		conn, r := acceptTestFramed(t, listener, message.NewHello("2.3.4", message.ServantCapabilities))
+		if conn == nil {
+			return
+		}
+		defer conn.Close()
+		message.ReadFramed(r, message.MaxMessageSize)
+		msg, _ := message.Reject{Detail: "testerror"}.GenerateJSON()
+		message.WriteFramed(conn, msg)
+	}()
+
+	stCh := make(chan string, 1)
+	defer close(stCh)
+	_, err := SendRequest(testHost, port, `{"type":"request","nid":1,"jid":"job1"}`, stCh, nil)
+	if err == nil {
+		t.Fatal("エラーが発生しなかった。")
+	}
+	if !strings.Contains(err.Error(), "rejected message: testerror") {
+		t.Errorf("エラーメッセージ[%s]が想定と違っている。", err)
+	}
+}
+
+func TestSendRequest_servantが対応していない種別のメッセージは送信しない(t *testing.T) {
+	config.Job.FramedProtocol = 1
+	defer func() { config.Job.FramedProtocol = 0 }()
+	const port = 12356
+	listener := listenTestPort(t, port)
+	defer listener.Close()
+
+	msq := make(chan string, 1)
+	endCh := make(chan struct{})
+	go func() {
+		defer close(endCh)
+		hello := message.NewHello("2.3.4", []string{"request"})
+		conn, r := acceptTestFramed(t, listener, hello)
+		if conn == nil {
+			return
+		}
+		defer conn.Close()
+		msg, err := message.ReadFramed(r, message.MaxMessageSize)
+		if err == nil {
+			msq <- msg
+		}
+	}()
+
+	stCh := make(chan string, 1)
+	defer close(stCh)
+	_, err := SendRequest(testHost, port, `{"type":"attach","nid":1,"jid":"job1"}`, stCh, nil)
+	if err == nil {
+		t.Fatal("エラーが発生しなかった。")
+	}
+	if !strings.Contains(err.Error(), "does not support message type [attach]") {
+		t.Errorf("エラーメッセージ[%s]が想定と違っている。", err)
+	}
+	<-endCh
+	if len(msq) != 0 {
+		t.Errorf("対応していない種別のメッセージ[%s]が送信された。", <-msq)
+	}
+}
+
+func TestCheckSendable_servantの最大サイズを超えるメッセージはエラーになる(t *testing.T) {
+	peer := message.NewHello("2.3.4", message.ServantCapabilities)
+	peer.MaxSize = 10
+	msg := fmt.Sprintf(`{"type":"request","param":"%s"}`, strings.Repeat("a", 10))
+	if err := checkSendable(peer, msg); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
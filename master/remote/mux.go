@@ -0,0 +1,352 @@
+package remote
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/unirita/cuto/console"
+	"github.com/unirita/cuto/log"
+	"github.com/unirita/cuto/master/config"
+	"github.com/unirita/cuto/message"
+)
+
+// 多重化接続に対応していないservantへ接続した場合のエラー
+var errMuxUnsupported = errors.New("Servant does not support multiplexed connection.")
+
+// 切断後に再接続を試みる間隔
+const reconnectInterval = 500 * time.Millisecond
+
+// servant毎の多重化接続
+var muxClients = struct {
+	sync.Mutex
+	clients map[string]*muxClient
+}{clients: make(map[string]*muxClient)}
+
+// 1つのservantとの多重化接続
+type muxClient struct {
+	addr   string
+	mutex  sync.Mutex
+	conn   net.Conn           // 接続。切断中はnil。
+	legacy bool               // 多重化接続に対応していないservantであればtrue
+	seq    int64              // 最後に採番した要求の番号
+	calls  map[int64]*muxCall // 返信を待っている要求
+}
+
+// 多重化接続上で返信を待っている要求
+type muxCall struct {
+	req     string         // 認証コードを付与する前の要求メッセージ
+	frame   *message.Frame // 要求フレーム
+	msgType string         // 要求メッセージの種別
+	acked   bool           // servantが要求を受け付けたか
+	ch      chan *response // 返信を受け取るチャネル
+	doneCh  chan struct{}  // 返信の待ち合わせ終了を通知するチャネル
+}
+
+// servantとの多重化接続を取得する。未作成の場合は作成する。接続は要求の送信時に行う。
+func getMuxClient(addr string) *muxClient {
+	muxClients.Lock()
+	defer muxClients.Unlock()
+	c, ok := muxClients.clients[addr]
+	if !ok {
+		c = &muxClient{addr: addr, calls: make(map[int64]*muxCall)}
+		muxClients.clients[addr] = c
+	}
+	return c
+}
+
+// 多重化接続で要求を送信し、返信を待つ。
+// 返信を待つ間に切断された場合は再接続し、未受付の要求は再送、受付済みのジョブ実行要求は再接続要求に切り替えて返信を待つ。
+//
+// param : req 要求メッセージ。送信の度に認証コードを付与する。
+//
+// param : stCh ジョブの開始時刻を受け取るチャネル。
+//
+// param : outCh ジョブの出力を行単位で受け取るチャネル。受け取らない場合はnil。
+//
+// param : timeout 接続および返信を待つ時間。
+//
+// return : 返信メッセージ。
+//
+// return : エラー情報。servantが多重化接続に対応していない場合はerrMuxUnsupported。
+func (c *muxClient) send(req string, stCh chan<- string, outCh chan<- string, timeout time.Duration) (string, error) {
+	signed, err := message.Sign(req, config.Sec.AuthSecret)
+	if err != nil {
+		return ``, err
+	}
+
+	c.mutex.Lock()
+	if c.legacy {
+		c.mutex.Unlock()
+		return ``, errMuxUnsupported
+	}
+	call := c.addCall(req, signed)
+	if _, err := call.frame.GenerateJSON(); err != nil {
+		// 送信できないフレームは、再送を繰り返さないよう送信前にエラーとする
+		c.removeCall(call)
+		c.mutex.Unlock()
+		return ``, err
+	}
+	if c.conn != nil {
+		if err := c.write(c.conn, call.frame, timeout); err != nil {
+			// 受信側で切断を検知し、再接続後に再送する
+			c.conn.Close()
+		}
+	} else if err := c.connect(timeout); err != nil {
+		c.removeCall(call)
+		c.mutex.Unlock()
+		return ``, err
+	}
+	c.mutex.Unlock()
+
+	defer func() {
+		c.mutex.Lock()
+		c.removeCall(call)
+		c.mutex.Unlock()
+	}()
+	return receiveResponse(func() <-chan *response { return call.ch }, stCh, outCh, timeout)
+}
+
+// 要求を採番して登録する。c.mutexをロックして呼び出す。
+func (c *muxClient) addCall(req string, signed string) *muxCall {
+	c.seq++
+	call := new(muxCall)
+	call.req = req
+	call.frame = message.NewRequestFrame(c.seq, signed)
+	call.ch = make(chan *response, 100)
+	call.doneCh = make(chan struct{})
+
+	var header struct {
+		Type string `json:"type"`
+	}
+	json.Unmarshal([]byte(signed), &header)
+	call.msgType = header.Type
+
+	c.calls[call.frame.Seq] = call
+	return call
+}
+
+// 要求の登録を解除する。c.mutexをロックして呼び出す。
+func (c *muxClient) removeCall(call *muxCall) {
+	if _, ok := c.calls[call.frame.Seq]; ok {
+		delete(c.calls, call.frame.Seq)
+		close(call.doneCh)
+	}
+}
+
+// servantへ接続し、返信を待っている要求を送信する。c.mutexをロックして呼び出す。
+func (c *muxClient) connect(timeout time.Duration) error {
+	conn, scanner, err := dialMux(c.addr, timeout)
+	if err == errMuxUnsupported {
+		c.legacy = true
+		console.Display("CTM047W", c.addr)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.conn = conn
+	stopCh := make(chan struct{})
+	go c.readLoop(conn, scanner, stopCh, timeout)
+	go c.pingLoop(conn, stopCh, timeout)
+
+	for _, call := range c.calls {
+		if err := c.write(conn, call.resendFrame(), timeout); err != nil {
+			conn.Close()
+			break
+		}
+	}
+	return nil
+}
+
+// servantへ接続し、多重化接続の開始を要求する。
+func dialMux(addr string, timeout time.Duration) (net.Conn, *bufio.Scanner, error) {
+	conn, err := dial(addr, timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hello, err := message.Mux{Version: message.MasterVersion}.GenerateJSON()
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	if _, err := conn.Write([]byte(hello + MsgEnd)); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, bufSize), message.MaxFrameSize)
+	if !scanner.Scan() {
+		conn.Close()
+		if err := scanner.Err(); err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				return nil, nil, err
+			}
+		}
+		// 従来のservantは開始要求を解析できず、接続を閉じる
+		return nil, nil, errMuxUnsupported
+	}
+	if err := new(message.Mux).ParseJSON(scanner.Text()); err != nil {
+		conn.Close()
+		return nil, nil, errMuxUnsupported
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, scanner, nil
+}
+
+// フレームを送信する。c.mutexをロックして呼び出す。
+func (c *muxClient) write(conn net.Conn, f *message.Frame, timeout time.Duration) error {
+	msg, err := f.GenerateJSON()
+	if err != nil {
+		return err
+	}
+	conn.SetWriteDeadline(time.Now().Add(timeout))
+	_, err = conn.Write([]byte(msg + MsgEnd))
+	return err
+}
+
+// servantからのフレームを受信し、要求毎のチャネルへ振り分ける。
+// 一定時間受信が無い場合や受信に失敗した場合は切断し、返信を待っている要求があれば再接続する。
+func (c *muxClient) readLoop(conn net.Conn, scanner *bufio.Scanner, stopCh chan struct{}, timeout time.Duration) {
+	defer close(stopCh)
+	for {
+		conn.SetReadDeadline(time.Now().Add(timeout))
+		if !scanner.Scan() {
+			break
+		}
+		log.Debug(scanner.Text())
+		f := new(message.Frame)
+		if err := f.ParseJSON(scanner.Text()); err != nil {
+			log.Error(err)
+			continue
+		}
+
+		c.mutex.Lock()
+		call, ok := c.calls[f.Seq]
+		if ok && f.Kind == message.FRAME_ACK {
+			call.acked = true
+		}
+		c.mutex.Unlock()
+		if !ok || f.Kind != message.FRAME_MESSAGE {
+			continue
+		}
+		select {
+		case call.ch <- &response{msg: f.Body}:
+		case <-call.doneCh:
+		}
+	}
+
+	err := scanner.Err()
+	if err == nil {
+		err = errors.New("Connection closed by servant.")
+	}
+	c.disconnect(conn, err, timeout)
+}
+
+// 一定間隔で接続確認のフレームを送信し、接続を維持する。
+func (c *muxClient) pingLoop(conn net.Conn, stopCh <-chan struct{}, timeout time.Duration) {
+	interval := timeout / 3
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			c.mutex.Lock()
+			err := c.write(conn, &message.Frame{Kind: message.FRAME_PING}, timeout)
+			c.mutex.Unlock()
+			if err != nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// 切断を処理する。返信を待っている要求がある場合は、一定時間再接続を試みる。
+// 再接続できなかった場合は、返信を待っている全ての要求をエラーとする。
+func (c *muxClient) disconnect(conn net.Conn, reason error, timeout time.Duration) {
+	c.mutex.Lock()
+	conn.Close()
+	if c.conn != conn && c.conn != nil {
+		// 既に再接続済み
+		c.mutex.Unlock()
+		return
+	}
+	c.conn = nil
+	pending := len(c.calls)
+	c.mutex.Unlock()
+	if pending == 0 {
+		// 次の要求の送信時に接続する
+		return
+	}
+	console.Display("CTM046W", c.addr, reason)
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(reconnectInterval)
+
+		c.mutex.Lock()
+		if c.conn != nil || len(c.calls) == 0 {
+			c.mutex.Unlock()
+			return
+		}
+		pending = len(c.calls)
+		err := c.connect(timeout)
+		c.mutex.Unlock()
+		if err == nil {
+			console.Display("CTM048I", c.addr, pending)
+			return
+		}
+		if err == errMuxUnsupported {
+			break
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	err := fmt.Errorf("Could not reconnect to servant[%s]: %s", c.addr, reason)
+	for _, call := range c.calls {
+		select {
+		case call.ch <- &response{err: err}:
+		default:
+		}
+	}
+}
+
+// 再接続時に送信するフレームを返す。
+// servantが受付済みのジョブ実行要求は二重に実行されないよう、再接続要求に切り替える。
+// 受付の通知を受け取れなかった要求は、servantが実行済みかを判断できるよう、同じIDで再送する。
+// 再送として拒否されないよう、認証コードは付与し直す。
+func (call *muxCall) resendFrame() *message.Frame {
+	msg := call.req
+	if call.acked && call.msgType == "request" {
+		a := message.Attach{NID: call.frame.NID, JID: call.frame.JID}
+		attachMsg, err := a.GenerateJSON()
+		if err != nil {
+			return call.frame
+		}
+		msg = attachMsg
+	}
+
+	signed, err := message.Sign(msg, config.Sec.AuthSecret)
+	if err != nil {
+		return call.frame
+	}
+	f := message.NewRequestFrame(call.frame.Seq, signed)
+	f.ID = call.frame.ID
+	f.Resend = true
+	return f
+}
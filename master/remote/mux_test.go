@@ -0,0 +1,263 @@
+package remote
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/unirita/cuto/master/config"
+	"github.com/unirita/cuto/message"
+)
+
+// 多重化接続に対応したテスト用servantの接続を受け付け、開始要求に応答する。
+func acceptTestMux(t *testing.T, listener net.Listener) (net.Conn, *bufio.Scanner) {
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Log(err)
+		return nil, nil
+	}
+	scanner := bufio.NewScanner(conn)
+	if !scanner.Scan() || !strings.Contains(scanner.Text(), `"type":"mux"`) {
+		t.Errorf("多重化接続の開始要求[%s]が想定と違っている。", scanner.Text())
+		conn.Close()
+		return nil, nil
+	}
+	conn.Write([]byte(`{"type":"mux","version":"2.3.4"}` + "\n"))
+	return conn, scanner
+}
+
+// 要求フレームを受信するまで読み込む。
+func readTestRequestFrame(t *testing.T, scanner *bufio.Scanner) *message.Frame {
+	for scanner.Scan() {
+		f := new(message.Frame)
+		if err := f.ParseJSON(scanner.Text()); err != nil {
+			t.Errorf("受信したフレーム[%s]をパースできない: %s", scanner.Text(), err)
+			return nil
+		}
+		if f.Kind == message.FRAME_REQUEST {
+			return f
+		}
+	}
+	return nil
+}
+
+func writeTestFrame(conn net.Conn, seq int64, kind string, body string) {
+	f := message.Frame{Seq: seq, Kind: kind, Body: body}
+	msg, _ := f.GenerateJSON()
+	conn.Write([]byte(msg + "\n"))
+}
+
+func listenTestPort(t *testing.T, port int) net.Listener {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		t.Fatalf("テスト用のlistenに失敗しました: %s", err)
+	}
+	return listener
+}
+
+func TestSendRequest_多重化接続で複数の要求を送信できる(t *testing.T) {
+	config.Job.Multiplex = 1
+	defer func() { config.Job.Multiplex = 0 }()
+	const port = 12350
+	listener := listenTestPort(t, port)
+	defer listener.Close()
+
+	go func() {
+		conn, scanner := acceptTestMux(t, listener)
+		if conn == nil {
+			return
+		}
+		defer conn.Close()
+		for i := 0; i < 2; i++ {
+			f := readTestRequestFrame(t, scanner)
+			if f == nil {
+				return
+			}
+			writeTestFrame(conn, f.Seq, message.FRAME_ACK, "")
+			writeTestFrame(conn, f.Seq, message.FRAME_MESSAGE, message.ST_HEADER+"2015-04-01 12:34:56.789")
+			writeTestFrame(conn, f.Seq, message.FRAME_MESSAGE, "response-"+f.JID)
+		}
+		scanner.Scan()
+	}()
+
+	var wg sync.WaitGroup
+	results := make([]string, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			stCh := make(chan string, 1)
+			req := fmt.Sprintf(`{"type":"request","nid":1,"jid":"job%d"}`, i)
+			res, err := SendRequest(testHost, port, req, stCh, nil)
+			if err != nil {
+				t.Errorf("エラーが発生しました: %s", err)
+			}
+			if st := <-stCh; st != "2015-04-01 12:34:56.789" {
+				t.Errorf("スタート時刻[%s]が想定と違っている。", st)
+			}
+			results[i] = res
+		}(i)
+	}
+	wg.Wait()
+
+	for i, res := range results {
+		if res != fmt.Sprintf("response-job%d", i) {
+			t.Errorf("要求[%d]への返信[%s]が想定と違っている。", i, res)
+		}
+	}
+}
+
+func TestSendRequest_多重化接続に対応していないservantには要求毎に接続する(t *testing.T) {
+	config.Job.Multiplex = 1
+	defer func() { config.Job.Multiplex = 0 }()
+	const port = 12351
+	listener := listenTestPort(t, port)
+	defer listener.Close()
+
+	msq := make(chan string, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		// 従来のservantは開始要求を解析できずに接続を閉じる
+		bufio.NewScanner(conn).Scan()
+		conn.Close()
+		runTestReceiver(t, listener, msq, 0)
+	}()
+
+	stCh := make(chan string, 1)
+	defer close(stCh)
+	res, err := SendRequest(testHost, port, `testrequest`, stCh, nil)
+	if err != nil {
+		t.Fatalf("エラーが発生しました: %s", err)
+	}
+	if msg := <-msq; msg != "testrequest\n" {
+		t.Errorf("リスナに届いたメッセージが間違っています: %s", msg)
+	}
+	if res != `testresponse` {
+		t.Errorf("返信[%s]が想定と違っている。", res)
+	}
+}
+
+func TestSendRequest_切断後に再接続して受付済みのジョブに再接続要求を送信する(t *testing.T) {
+	config.Job.Multiplex = 1
+	config.Job.ConnectionTimeoutSec = 3
+	defer func() {
+		config.Job.Multiplex = 0
+		config.Job.ConnectionTimeoutSec = 1
+	}()
+	const port = 12352
+	listener := listenTestPort(t, port)
+	defer listener.Close()
+
+	resumed := make(chan string, 1)
+	go func() {
+		conn, scanner := acceptTestMux(t, listener)
+		if conn == nil {
+			return
+		}
+		f := readTestRequestFrame(t, scanner)
+		if f == nil {
+			return
+		}
+		writeTestFrame(conn, f.Seq, message.FRAME_ACK, "")
+		conn.Close()
+
+		conn, scanner = acceptTestMux(t, listener)
+		if conn == nil {
+			return
+		}
+		defer conn.Close()
+		f = readTestRequestFrame(t, scanner)
+		if f == nil {
+			return
+		}
+		resumed <- f.Body
+		writeTestFrame(conn, f.Seq, message.FRAME_MESSAGE, "attached")
+		scanner.Scan()
+	}()
+
+	stCh := make(chan string, 1)
+	defer close(stCh)
+	res, err := SendRequest(testHost, port, `{"type":"request","nid":1,"jid":"job1"}`, stCh, nil)
+	if err != nil {
+		t.Fatalf("エラーが発生しました: %s", err)
+	}
+	if res != "attached" {
+		t.Errorf("返信[%s]が想定と違っている。", res)
+	}
+	body := <-resumed
+	if !strings.Contains(body, `"type":"attach"`) || !strings.Contains(body, `"nid":1`) || !strings.Contains(body, `"jid":"job1"`) {
+		t.Errorf("再接続後に送信された要求[%s]が想定と違っている。", body)
+	}
+}
+
+func TestResendFrame_再送する要求には認証コードを付与し直す(t *testing.T) {
+	config.Sec.AuthSecret = "secret"
+	defer func() { config.Sec.AuthSecret = "" }()
+
+	req := `{"type":"jobcheck","nid":1,"jid":"job1"}`
+	signed, _ := message.Sign(req, config.Sec.AuthSecret)
+	c := &muxClient{calls: make(map[int64]*muxCall)}
+	call := c.addCall(req, signed)
+
+	if err := message.Verify(call.frame.Body, "secret"); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	f := call.resendFrame()
+	if f.Seq != call.frame.Seq {
+		t.Errorf("再送フレームの番号[%d]が想定と違っている。", f.Seq)
+	}
+	if err := message.Verify(f.Body, "secret"); err != nil {
+		t.Errorf("再送フレームの認証に失敗した: %s", err)
+	}
+	if f.ID == "" || f.ID != call.frame.ID {
+		t.Errorf("再送フレームのID[%s]が想定と違っている。", f.ID)
+	}
+	if !f.Resend {
+		t.Error("再送フレームに再送フラグがセットされていない。")
+	}
+}
+
+func TestSendRequest_多重化接続で最大サイズを超える要求は送信しない(t *testing.T) {
+	config.Job.Multiplex = 1
+	defer func() { config.Job.Multiplex = 0 }()
+	const port = 12357
+	listener := listenTestPort(t, port)
+	defer listener.Close()
+
+	received := make(chan string, 2)
+	go func() {
+		conn, scanner := acceptTestMux(t, listener)
+		if conn == nil {
+			return
+		}
+		defer conn.Close()
+		f := readTestRequestFrame(t, scanner)
+		if f == nil {
+			return
+		}
+		received <- f.JID
+		writeTestFrame(conn, f.Seq, message.FRAME_MESSAGE, "testresponse")
+		scanner.Scan()
+	}()
+
+	stCh := make(chan string, 1)
+	defer close(stCh)
+	large := `{"type":"request","nid":1,"jid":"large","param":"` + strings.Repeat("a", message.MaxFrameSize) + `"}`
+	_, err := SendRequest(testHost, port, large, stCh, nil)
+	if err == nil || !strings.Contains(err.Error(), "exceeds limit") {
+		t.Errorf("エラー[%v]が想定と違っている。", err)
+	}
+
+	if _, err := SendRequest(testHost, port, `{"type":"jobcheck","nid":1,"jid":"small"}`, stCh, nil); err != nil {
+		t.Fatalf("エラーが発生しました: %s", err)
+	}
+	if jid := <-received; jid != "small" {
+		t.Errorf("servantが受信した要求[%s]が想定と違っている。", jid)
+	}
+}
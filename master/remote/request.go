@@ -5,6 +5,7 @@ package remote
 
 import (
 	"bufio"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"strings"
@@ -23,6 +24,11 @@ type response struct {
 // 送受信メッセージの終端文字
 const MsgEnd = "\n"
 
+const bufSize = 1024
+
+// ジョブログ取得結果など、1行が大きいメッセージも受信できるようにする。
+const maxMsgSize = 8 * 1024 * 1024
+
 // ホスト名がhost、ポート番号がportのservantへ接続し、ジョブ実行要求を送信する。
 // servantから返信されたジョブ実行結果を関数外へ返す。
 //
@@ -32,31 +38,70 @@ const MsgEnd = "\n"
 //
 // param : req リクエストメッセージ。
 //
+// param : stCh ジョブの開始時刻を受け取るチャネル。
+//
+// param : outCh ジョブの出力を行単位で受け取るチャネル。受け取らない場合はnil。
+//
 // return : 返信メッセージ。
 //
 // return : エラー情報。
-func SendRequest(host string, port int, req string, stCh chan<- string) (string, error) {
-	const bufSize = 1024
+func SendRequest(host string, port int, req string, stCh chan<- string, outCh chan<- string) (string, error) {
 	timeout := time.Duration(config.Job.ConnectionTimeoutSec) * time.Second
 
+	log.Debug(req)
 	addr := fmt.Sprintf("%s:%d", host, port)
-	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if config.Job.Multiplex != 0 {
+		// 多重化接続に対応していないservantへは、要求毎に接続する。
+		c := getMuxClient(addr)
+		if resMsg, err := c.send(req, stCh, outCh, timeout); err != errMuxUnsupported {
+			return resMsg, err
+		}
+	}
+
+	signed, err := message.Sign(req, config.Sec.AuthSecret)
+	if err != nil {
+		return ``, err
+	}
+	if config.Job.FramedProtocol != 0 {
+		// 長さ付きフレーム形式に対応していないservantへは、従来の形式で送信する。
+		if resMsg, err := sendFramed(addr, signed, stCh, outCh, timeout); err != errFramedUnsupported {
+			return resMsg, err
+		}
+	}
+
+	conn, err := dial(addr, timeout)
 	if err != nil {
 		return ``, err
 	}
 	defer conn.Close()
 
-	log.Debug(req)
-	_, err = conn.Write([]byte(req + MsgEnd))
+	_, err = conn.Write([]byte(signed + MsgEnd))
 	if err != nil {
 		return ``, err
 	}
 
 	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, bufSize), maxMsgSize)
+	return receiveResponse(func() <-chan *response { return readResponse(scanner) }, stCh, outCh, timeout)
+}
 
+// servantからの返信を、ハートビートやジョブの開始時刻・出力以外のメッセージを受信するまで処理する。
+//
+// param : recv 次の返信を受け取るチャネルを返す関数。
+//
+// param : stCh ジョブの開始時刻を受け取るチャネル。
+//
+// param : outCh ジョブの出力を行単位で受け取るチャネル。受け取らない場合はnil。
+//
+// param : timeout 返信を待つ時間。
+//
+// return : 返信メッセージ。
+//
+// return : エラー情報。
+func receiveResponse(recv func() <-chan *response, stCh chan<- string, outCh chan<- string, timeout time.Duration) (string, error) {
 	for {
 		select {
-		case res := <-readResponse(scanner):
+		case res := <-recv():
 			if res.err != nil {
 				return ``, res.err
 			}
@@ -68,6 +113,11 @@ func SendRequest(host string, port int, req string, stCh chan<- string) (string,
 				st := res.msg[len(message.ST_HEADER):]
 				stCh <- st
 				continue
+			} else if strings.HasPrefix(res.msg, message.OUT_HEADER) {
+				if outCh != nil {
+					outCh <- res.msg[len(message.OUT_HEADER):]
+				}
+				continue
 			}
 
 			return res.msg, nil
@@ -77,6 +127,21 @@ func SendRequest(host string, port int, req string, stCh chan<- string) (string,
 	}
 }
 
+// servantへ接続する。
+// TLSの利用が設定されている場合は、TLSで接続する。
+func dial(addr string, timeout time.Duration) (net.Conn, error) {
+	tlsConf, err := config.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConf == nil {
+		return net.DialTimeout("tcp", addr, timeout)
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	return tls.DialWithDialer(dialer, "tcp", addr, tlsConf)
+}
+
 func readResponse(scanner *bufio.Scanner) <-chan *response {
 	ch := make(chan *response, 10)
 	go func() {
@@ -1,13 +1,18 @@
 package remote
 
 import (
+	"crypto/tls"
 	"fmt"
+	"io/ioutil"
 	"net"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/unirita/cuto/master/config"
 	"github.com/unirita/cuto/message"
+	"github.com/unirita/cuto/testutil"
 )
 
 const (
@@ -123,7 +128,7 @@ func TestSendMessage_メッセージを送信できる(t *testing.T) {
 
 	stCh := make(chan string, 1)
 	defer close(stCh)
-	resMsg, err := SendRequest(testHost, testPort, `testrequest`, stCh)
+	resMsg, err := SendRequest(testHost, testPort, `testrequest`, stCh, nil)
 	if err != nil {
 		t.Fatalf("エラーが発生しました: %s", err)
 	}
@@ -152,7 +157,7 @@ func TestSendMessage_一定時間応答がない場合はタイムアウトす
 
 	stCh := make(chan string, 1)
 	defer close(stCh)
-	_, err := SendRequest(testHost, testPort, `testrequest`, stCh)
+	_, err := SendRequest(testHost, testPort, `testrequest`, stCh, nil)
 	if err == nil {
 		t.Fatalf("タイムアウトが発生しない。")
 	}
@@ -173,7 +178,7 @@ func TestSendMessage_ハートビートが返される場合はタイムアウ
 
 	stCh := make(chan string, 1)
 	defer close(stCh)
-	resMsg, err := SendRequest(testHost, testPort, `testrequest`, stCh)
+	resMsg, err := SendRequest(testHost, testPort, `testrequest`, stCh, nil)
 	if err != nil {
 		t.Fatalf("エラーが発生しました: %s", err)
 	}
@@ -202,7 +207,7 @@ func TestSendMessage_スタート時刻をチャンネルから取得できる(t
 
 	stCh := make(chan string, 1)
 	defer close(stCh)
-	resMsg, err := SendRequest(testHost, testPort, `testrequest`, stCh)
+	resMsg, err := SendRequest(testHost, testPort, `testrequest`, stCh, nil)
 	if err != nil {
 		t.Fatalf("エラーが発生しました: %s", err)
 	}
@@ -224,3 +229,118 @@ func TestSendMessage_スタート時刻をチャンネルから取得できる(t
 		t.Errorf("リスナからのレスポンスメッセージが間違っています: %s", resMsg)
 	}
 }
+
+func TestSendMessage_認証コードを付与して送信できる(t *testing.T) {
+	config.Sec.AuthSecret = "secret"
+	defer func() { config.Sec.AuthSecret = "" }()
+
+	addr := fmt.Sprintf(":%d", testPort)
+	listener, listenErr := net.Listen("tcp", addr)
+	if listenErr != nil {
+		t.Fatalf("テスト用のlistenに失敗しました: %s", listenErr)
+	}
+	defer listener.Close()
+
+	msq := make(chan string, 10)
+	go runTestReceiver(t, listener, msq, 0)
+
+	stCh := make(chan string, 1)
+	defer close(stCh)
+	if _, err := SendRequest(testHost, testPort, `{"type":"jobcheck","nid":1,"jid":"job1"}`, stCh, nil); err != nil {
+		t.Fatalf("エラーが発生しました: %s", err)
+	}
+
+	received := strings.TrimSuffix(<-msq, "\n")
+	if err := message.Verify(received, "secret"); err != nil {
+		t.Errorf("リスナに届いたメッセージの認証に失敗した: %s", err)
+	}
+}
+
+func TestSendMessage_TLSで送信できる(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cuto_tls")
+	if err != nil {
+		t.Fatalf("テスト用のディレクトリ作成に失敗しました: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile, err := testutil.GenerateTestCert(dir)
+	if err != nil {
+		t.Fatalf("テスト用の証明書作成に失敗しました: %s", err)
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("テスト用の証明書読み込みに失敗しました: %s", err)
+	}
+
+	config.Sec.UseTLS = 1
+	config.Sec.TLSCAFile = certFile
+	defer func() {
+		config.Sec.UseTLS = 0
+		config.Sec.TLSCAFile = ""
+	}()
+
+	addr := fmt.Sprintf(":%d", testPort)
+	listener, listenErr := tls.Listen("tcp", addr, &tls.Config{Certificates: []tls.Certificate{cert}})
+	if listenErr != nil {
+		t.Fatalf("テスト用のlistenに失敗しました: %s", listenErr)
+	}
+	defer listener.Close()
+
+	msq := make(chan string, 10)
+	go runTestReceiver(t, listener, msq, 0)
+
+	stCh := make(chan string, 1)
+	defer close(stCh)
+	resMsg, err := SendRequest(testHost, testPort, `testrequest`, stCh, nil)
+	if err != nil {
+		t.Fatalf("エラーが発生しました: %s", err)
+	}
+	if message := <-msq; message != "testrequest\n" {
+		t.Errorf("リスナに届いたメッセージが間違っています: %s", message)
+	}
+	if resMsg != `testresponse` {
+		t.Errorf("リスナからのレスポンスメッセージが間違っています: %s", resMsg)
+	}
+}
+
+func TestSendMessage_ジョブの出力をチャンネルから取得できる(t *testing.T) {
+	addr := fmt.Sprintf(":%d", testPort)
+	listener, listenErr := net.Listen("tcp", addr)
+	if listenErr != nil {
+		t.Fatalf("テスト用のlistenに失敗しました: %s", listenErr)
+	}
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			t.Log(err)
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 1024)
+		conn.Read(buf)
+		conn.Write([]byte(message.OUT_HEADER + "output1\n"))
+		conn.Write([]byte(message.OUT_HEADER + "output2\n"))
+		conn.Write([]byte("testresponse\n"))
+	}()
+
+	stCh := make(chan string, 1)
+	defer close(stCh)
+	outCh := make(chan string, 10)
+	resMsg, err := SendRequest(testHost, testPort, `testrequest`, stCh, outCh)
+	if err != nil {
+		t.Fatalf("エラーが発生しました: %s", err)
+	}
+	if resMsg != `testresponse` {
+		t.Errorf("リスナからのレスポンスメッセージが間違っています: %s", resMsg)
+	}
+	if len(outCh) != 2 {
+		t.Fatalf("取得したジョブの出力の行数[%d]が間違っています。", len(outCh))
+	}
+	if line := <-outCh; line != "output1" {
+		t.Errorf("取得したジョブの出力が間違っています: %s", line)
+	}
+	if line := <-outCh; line != "output2" {
+		t.Errorf("取得したジョブの出力が間違っています: %s", line)
+	}
+}
@@ -0,0 +1,46 @@
+package message
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// 実行中ジョブへの再接続要求メッセージ。
+// servantは対象ジョブの終了を待ち、ジョブ実行要求と同じresponseメッセージを返信する。
+type Attach struct {
+	Type    string `json:"type"`
+	Version string `json:"version"`
+	NID     int    `json:"nid"`
+	JID     string `json:"jid"`
+	Auth    string `json:"auth,omitempty"`
+}
+
+const attachMessageType = "attach"
+
+// 実行中ジョブへの再接続要求JSONメッセージをパースし、Attachオブジェクトのメンバをセットする。
+//
+// param : message 受信メッセージ文字列
+func (a *Attach) ParseJSON(message string) error {
+	byteMessage := []byte(message)
+	err := json.Unmarshal(byteMessage, a)
+	if err != nil {
+		return err
+	}
+	if a.Type != attachMessageType {
+		return fmt.Errorf("Invalid message type.")
+	}
+	return nil
+}
+
+// Attachオブジェクトの値を元に、実行中ジョブへの再接続要求JSONメッセージを生成する
+//
+// return : JSONメッセージフォーマットの文字列。
+func (a Attach) GenerateJSON() (string, error) {
+	a.Type = attachMessageType
+	a.Version = MasterVersion
+	byteMessage, err := json.Marshal(a)
+	if err != nil {
+		return ``, err
+	}
+	return string(byteMessage), nil
+}
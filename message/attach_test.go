@@ -0,0 +1,56 @@
+package message
+
+import (
+	"testing"
+)
+
+func TestAttach_実行中ジョブへの再接続要求メッセージをパースできる(t *testing.T) {
+	message := `{
+    "type":"attach",
+    "version":"1.2.3",
+    "nid":1234,
+    "jid":"job1"
+}`
+
+	var a Attach
+	err := a.ParseJSON(message)
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+	if a.Version != "1.2.3" {
+		t.Errorf("取得したversionの値が違います： %s", a.Version)
+	}
+	if a.NID != 1234 {
+		t.Errorf("取得したnidの値が違います： %d", a.NID)
+	}
+	if a.JID != `job1` {
+		t.Errorf("取得したjidの値が違います： %s", a.JID)
+	}
+}
+
+func TestAttach_typeが間違っている場合はエラーが発生する(t *testing.T) {
+	message := `{
+    "type":"cancel",
+    "version":"1.2.3",
+    "nid":1234,
+    "jid":"job1"
+}`
+
+	var a Attach
+	if err := a.ParseJSON(message); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestAttach_実行中ジョブへの再接続要求メッセージを生成できる(t *testing.T) {
+	MasterVersion = "1.2.3"
+	a := Attach{NID: 1234, JID: "job1"}
+	msg, err := a.GenerateJSON()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+	expect := `{"type":"attach","version":"1.2.3","nid":1234,"jid":"job1"}`
+	if msg != expect {
+		t.Errorf("生成されたメッセージ[%s]が想定と違います。", msg)
+	}
+}
@@ -0,0 +1,173 @@
+package message
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 認証コードを格納するJSONメッセージのキー
+const authKey = "auth"
+
+// 認証コードの有効期間。送信日時との差がこれを超えるメッセージは拒否する。
+const authWindow = 5 * time.Minute
+
+// 有効期間内に受信したメッセージのnonce。同じnonceのメッセージを再度受信した場合は拒否する。
+var usedNonces = struct {
+	sync.Mutex
+	expires map[string]time.Time
+}{expires: make(map[string]time.Time)}
+
+// 共有秘密鍵を用いて、JSONメッセージに認証コードを付与する。
+// 認証コードは"送信日時.nonce.HMAC"の形式で、HMACはauthキーを除いたメッセージ内容と送信日時、nonceのHMAC-SHA256で算出する。
+//
+// param : msg JSONメッセージ文字列。
+//
+// param : secret 共有秘密鍵。空文字列の場合は認証コードを付与しない。
+//
+// return : 認証コード付きのJSONメッセージ文字列。
+//
+// return : エラー情報。
+func Sign(msg string, secret string) (string, error) {
+	return signAt(msg, secret, time.Now())
+}
+
+func signAt(msg string, secret string, now time.Time) (string, error) {
+	if secret == "" {
+		return msg, nil
+	}
+
+	fields, err := parseFields(msg)
+	if err != nil {
+		return ``, err
+	}
+	ts := strconv.FormatInt(now.Unix(), 10)
+	nonce, err := generateNonce()
+	if err != nil {
+		return ``, err
+	}
+	mac, err := computeMAC(fields, ts, nonce, secret)
+	if err != nil {
+		return ``, err
+	}
+	fields[authKey] = strings.Join([]string{ts, nonce, mac}, ".")
+
+	signed, err := json.Marshal(fields)
+	if err != nil {
+		return ``, err
+	}
+	return string(signed), nil
+}
+
+// 共有秘密鍵を用いて、JSONメッセージに付与された認証コードを検証する。
+// 送信日時が有効期間外のメッセージや、既に受信したメッセージの再送も拒否する。
+//
+// param : msg JSONメッセージ文字列。
+//
+// param : secret 共有秘密鍵。空文字列の場合は検証を行わない。
+//
+// return : 検証に失敗した場合はエラー情報。
+func Verify(msg string, secret string) error {
+	return verifyAt(msg, secret, time.Now())
+}
+
+func verifyAt(msg string, secret string, now time.Time) error {
+	if secret == "" {
+		return nil
+	}
+
+	fields, err := parseFields(msg)
+	if err != nil {
+		return err
+	}
+	auth, ok := fields[authKey].(string)
+	if !ok || auth == "" {
+		return fmt.Errorf("Message has no authentication code.")
+	}
+	parts := strings.Split(auth, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("Authentication code is invalid.")
+	}
+	ts, nonce := parts[0], parts[1]
+	mac, err := computeMAC(fields, ts, nonce, secret)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(mac)) {
+		return fmt.Errorf("Authentication code is invalid.")
+	}
+
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("Authentication code is invalid.")
+	}
+	sent := time.Unix(sec, 0)
+	if diff := now.Sub(sent); diff > authWindow || diff < -authWindow {
+		return fmt.Errorf("Message sent at [%s] is out of the valid period.", sent.Format(time.RFC3339))
+	}
+	if !useNonce(nonce, sent.Add(authWindow), now) {
+		return fmt.Errorf("Message is replayed.")
+	}
+	return nil
+}
+
+// 送信毎に異なるnonceを生成する。
+func generateNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ``, err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// nonceを受信済みとして記録する。有効期間内に既に受信している場合はfalseを返す。
+// 有効期間を過ぎたnonceは記録から削除する。
+func useNonce(nonce string, expire time.Time, now time.Time) bool {
+	usedNonces.Lock()
+	defer usedNonces.Unlock()
+	for n, e := range usedNonces.expires {
+		if now.After(e) {
+			delete(usedNonces.expires, n)
+		}
+	}
+	if _, ok := usedNonces.expires[nonce]; ok {
+		return false
+	}
+	usedNonces.expires[nonce] = expire
+	return true
+}
+
+func parseFields(msg string) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(msg), &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// authキーを除いたメッセージ内容と送信日時、nonceから認証コードを算出する。
+// キーの順序に依存しないよう、map形式で再生成したJSONを算出対象とする。
+func computeMAC(fields map[string]interface{}, ts string, nonce string, secret string) (string, error) {
+	content := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		if k != authKey {
+			content[k] = v
+		}
+	}
+	b, err := json.Marshal(content)
+	if err != nil {
+		return ``, err
+	}
+
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write(b)
+	h.Write([]byte("\n" + ts + "\n" + nonce))
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,134 @@
+package message
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSign_認証コードが付与され検証に成功する(t *testing.T) {
+	req := Request{NID: 1, JID: "job1", Path: "test.sh", Param: "a b"}
+	msg, err := req.GenerateJSON()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+
+	signed, err := Sign(msg, "secret")
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if !strings.Contains(signed, `"auth":"`) {
+		t.Fatalf("認証コードが付与されていない: %s", signed)
+	}
+	if err := Verify(signed, "secret"); err != nil {
+		t.Errorf("想定外のエラーが発生した: %s", err)
+	}
+
+	var parsed Request
+	if err := parsed.ParseJSON(signed); err != nil {
+		t.Fatalf("認証コード付きのメッセージをパースできない: %s", err)
+	}
+	if parsed.Auth == "" {
+		t.Error("パースしたメッセージに認証コードがセットされていない。")
+	}
+	if parsed.JID != "job1" || parsed.Param != "a b" {
+		t.Errorf("パースしたメッセージの内容が想定と違っている: %v", parsed)
+	}
+}
+
+func TestSign_秘密鍵が空の場合はメッセージを変更しない(t *testing.T) {
+	msg := `{"type":"jobcheck","nid":1,"jid":"job1"}`
+	signed, err := Sign(msg, "")
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if signed != msg {
+		t.Errorf("メッセージが変更された: %s", signed)
+	}
+}
+
+func TestSign_JSONとしてパースできない場合はエラー(t *testing.T) {
+	if _, err := Sign(`{"type":`, "secret"); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestVerify_秘密鍵が空の場合は検証しない(t *testing.T) {
+	if err := Verify(`{"type":"jobcheck"}`, ""); err != nil {
+		t.Errorf("想定外のエラーが発生した: %s", err)
+	}
+}
+
+func TestVerify_認証コードが無い場合はエラー(t *testing.T) {
+	if err := Verify(`{"type":"jobcheck","nid":1,"jid":"job1"}`, "secret"); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestVerify_秘密鍵が異なる場合はエラー(t *testing.T) {
+	signed, _ := Sign(`{"type":"jobcheck","nid":1,"jid":"job1"}`, "secret")
+	if err := Verify(signed, "other"); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestVerify_メッセージが改ざんされた場合はエラー(t *testing.T) {
+	signed, _ := Sign(`{"type":"request","nid":1,"jid":"job1","path":"test.sh"}`, "secret")
+	tampered := strings.Replace(signed, "test.sh", "evil.sh", 1)
+	if err := Verify(tampered, "secret"); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestVerify_同じメッセージを再度受信した場合はエラー(t *testing.T) {
+	signed, _ := Sign(`{"type":"cancel","nid":1,"jid":"job1"}`, "secret")
+	if err := Verify(signed, "secret"); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if err := Verify(signed, "secret"); err == nil {
+		t.Error("再送されたメッセージでエラーが発生しなかった。")
+	}
+
+	// 同じ内容でも、署名し直したメッセージは受け付ける
+	resigned, _ := Sign(`{"type":"cancel","nid":1,"jid":"job1"}`, "secret")
+	if err := Verify(resigned, "secret"); err != nil {
+		t.Errorf("想定外のエラーが発生した: %s", err)
+	}
+}
+
+func TestVerify_送信日時が有効期間外の場合はエラー(t *testing.T) {
+	now := time.Now()
+	for _, sent := range []time.Time{now.Add(-authWindow - time.Minute), now.Add(authWindow + time.Minute)} {
+		signed, _ := signAt(`{"type":"request","nid":1,"jid":"job1"}`, "secret", sent)
+		if err := verifyAt(signed, "secret", now); err == nil {
+			t.Errorf("送信日時[%s]でエラーが発生しなかった。", sent)
+		}
+	}
+
+	signed, _ := signAt(`{"type":"request","nid":1,"jid":"job1"}`, "secret", now.Add(-time.Minute))
+	if err := verifyAt(signed, "secret", now); err != nil {
+		t.Errorf("想定外のエラーが発生した: %s", err)
+	}
+}
+
+func TestVerify_送信日時が改ざんされた場合はエラー(t *testing.T) {
+	signed, _ := signAt(`{"type":"request","nid":1,"jid":"job1"}`, "secret", time.Unix(1000, 0))
+	tampered := strings.Replace(signed, `"auth":"1000.`, `"auth":"`+strconv.FormatInt(time.Now().Unix(), 10)+`.`, 1)
+	if err := Verify(tampered, "secret"); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestUseNonce_有効期間を過ぎたnonceは記録から削除する(t *testing.T) {
+	now := time.Now()
+	if !useNonce("expired", now.Add(time.Second), now) {
+		t.Fatal("初回の記録に失敗した。")
+	}
+	if useNonce("expired", now.Add(time.Second), now) {
+		t.Error("有効期間内の同じnonceが受け付けられた。")
+	}
+	if !useNonce("expired", now.Add(time.Minute), now.Add(2*time.Second)) {
+		t.Error("有効期間を過ぎたnonceが削除されていない。")
+	}
+}
@@ -0,0 +1,84 @@
+package message
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ジョブ実行中止要求メッセージ。
+type Cancel struct {
+	Type    string `json:"type"`
+	Version string `json:"version"`
+	NID     int    `json:"nid"`
+	JID     string `json:"jid"`
+	Auth    string `json:"auth,omitempty"`
+}
+
+// ジョブ実行中止結果メッセージ。
+type CancelResult struct {
+	Type      string `json:"type"`
+	Version   string `json:"version"`
+	NID       int    `json:"nid"`
+	JID       string `json:"jid"`
+	Cancelled bool   `json:"cancelled"`
+	Detail    string `json:"detail"`
+}
+
+const cancelMessageType = "cancel"
+const cancelResultMessageType = "cancelresult"
+
+// ジョブ実行中止要求JSONメッセージをパースし、Cancelオブジェクトのメンバをセットする。
+//
+// param : message 受信メッセージ文字列
+func (c *Cancel) ParseJSON(message string) error {
+	byteMessage := []byte(message)
+	err := json.Unmarshal(byteMessage, c)
+	if err != nil {
+		return err
+	}
+	if c.Type != cancelMessageType {
+		return fmt.Errorf("Invalid message type.")
+	}
+	return nil
+}
+
+// Cancelオブジェクトの値を元に、ジョブ実行中止要求JSONメッセージを生成する
+//
+// return : JSONメッセージフォーマットの文字列。
+func (c Cancel) GenerateJSON() (string, error) {
+	c.Type = cancelMessageType
+	c.Version = MasterVersion
+	byteMessage, err := json.Marshal(c)
+	if err != nil {
+		return ``, err
+	}
+	return string(byteMessage), nil
+}
+
+// ジョブ実行中止結果JSONメッセージをパースし、CancelResultオブジェクトのメンバをセットする。
+//
+// param : message 受信メッセージ文字列
+func (c *CancelResult) ParseJSON(message string) error {
+	byteMessage := []byte(message)
+	err := json.Unmarshal(byteMessage, c)
+	if err != nil {
+		return err
+	}
+	if c.Type != cancelResultMessageType {
+		return fmt.Errorf("Invalid message type.")
+	}
+	return nil
+}
+
+// CancelResultオブジェクトの値を元に、ジョブ実行中止結果JSONメッセージを生成する
+//
+// return : JSONメッセージフォーマットの文字列。
+func (c CancelResult) GenerateJSON() (string, error) {
+	c.Type = cancelResultMessageType
+	c.Version = ServantVersion
+	byteMessage, err := json.Marshal(c)
+	if err != nil {
+		return ``, err
+	}
+	return string(byteMessage), nil
+}
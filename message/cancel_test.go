@@ -0,0 +1,83 @@
+package message
+
+import (
+	"testing"
+)
+
+func TestCancel_ジョブ実行中止要求メッセージをパースできる(t *testing.T) {
+	message := `{
+    "type":"cancel",
+    "version":"1.2.3",
+    "nid":1234,
+    "jid":"job1"
+}`
+
+	var c Cancel
+	err := c.ParseJSON(message)
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+	if c.Version != "1.2.3" {
+		t.Errorf("取得したversionの値が違います： %s", c.Version)
+	}
+	if c.NID != 1234 {
+		t.Errorf("取得したnidの値が違います： %d", c.NID)
+	}
+	if c.JID != `job1` {
+		t.Errorf("取得したjidの値が違います： %s", c.JID)
+	}
+}
+
+func TestCancel_typeが間違っている場合はエラーが発生する(t *testing.T) {
+	message := `{
+    "type":"jobcheck",
+    "version":"1.2.3",
+    "nid":1234,
+    "jid":"job1"
+}`
+
+	var c Cancel
+	if err := c.ParseJSON(message); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestCancel_ジョブ実行中止要求メッセージを生成できる(t *testing.T) {
+	MasterVersion = "1.2.3"
+	c := Cancel{NID: 1234, JID: "job1"}
+	msg, err := c.GenerateJSON()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+
+	expect := `{"type":"cancel","version":"1.2.3","nid":1234,"jid":"job1"}`
+	if msg != expect {
+		t.Errorf("生成されたメッセージ[%s]が想定と違います。", msg)
+	}
+}
+
+func TestCancelResult_ジョブ実行中止結果メッセージを生成しパースできる(t *testing.T) {
+	ServantVersion = "1.2.3"
+	c := CancelResult{NID: 1234, JID: "job1", Cancelled: true, Detail: "detail"}
+	msg, err := c.GenerateJSON()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+
+	var parsed CancelResult
+	if err := parsed.ParseJSON(msg); err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+	if parsed.NID != 1234 || parsed.JID != "job1" || !parsed.Cancelled || parsed.Detail != "detail" {
+		t.Errorf("パース結果[%v]が想定と違います。", parsed)
+	}
+}
+
+func TestCancelResult_typeが間違っている場合はエラーが発生する(t *testing.T) {
+	message := `{"type":"cancel","version":"1.2.3","nid":1234,"jid":"job1"}`
+
+	var c CancelResult
+	if err := c.ParseJSON(message); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
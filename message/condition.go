@@ -0,0 +1,284 @@
+package message
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 分岐条件式で使用可能な比較演算子
+const (
+	opEQ = `==`
+	opNE = `!=`
+	opLT = `<`
+	opLE = `<=`
+	opGT = `>`
+	opGE = `>=`
+)
+
+// 単一の変数のみで構成されるオペランドにマッチする正規表現
+var varOperandExp = regexp.MustCompile(`^\$M[SEJ][^$]+\$$`)
+
+// 値が常に整数となる変数（ジョブの戻り値、インスタンスID、カレンダー変数）
+var intOperandExp = regexp.MustCompile(`^\$(MJ[^$:]+:RC|MSJOBNET:ID|MSCAL:[A-Z]+)\$$`)
+
+// 排他ゲートウェイの分岐条件式を表す構造体
+type Condition struct {
+	expr  string
+	Left  string // 左辺オペランド
+	Op    string // 比較演算子
+	Right string // 右辺オペランド
+}
+
+// 分岐条件式を解析してCondition構造体を生成する。
+// 条件式は「オペランド 比較演算子 オペランド」の形式で記述する。
+// オペランドにはマスタで利用可能な変数（$MS～$、$ME～$、$MJ～$）、ダブルクォーテーションで囲んだ文字列、または数値を指定できる。
+//
+// param : expr 分岐条件式。
+//
+// return : 分岐条件式構造体。
+//
+// return : エラー情報。
+func ParseCondition(expr string) (*Condition, error) {
+	c := new(Condition)
+	c.expr = strings.TrimSpace(expr)
+	if c.expr == `` {
+		return nil, fmt.Errorf("Condition expression is empty.")
+	}
+
+	pos, op := findOperator(c.expr)
+	if pos < 0 {
+		return nil, fmt.Errorf("Condition[%s] has no comparison operator.", c.expr)
+	}
+	if op == `=` {
+		return nil, fmt.Errorf("Condition[%s] uses undefined operator[=]. Use [==] instead.", c.expr)
+	}
+	c.Op = op
+	c.Left = strings.TrimSpace(c.expr[:pos])
+	c.Right = strings.TrimSpace(c.expr[pos+len(op):])
+
+	if p, _ := findOperator(c.Right); p >= 0 {
+		return nil, fmt.Errorf("Condition[%s] has more than one comparison operator.", c.expr)
+	}
+	for _, operand := range []string{c.Left, c.Right} {
+		if err := validateOperand(operand); err != nil {
+			return nil, fmt.Errorf("Condition[%s] is invalid: %s", c.expr, err)
+		}
+	}
+
+	return c, nil
+}
+
+// ダブルクォーテーションの外側にある最初の比較演算子を探し、その位置と演算子を返す。
+// 見つからない場合は-1を返す。
+func findOperator(s string) (int, string) {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '"' {
+			quoted = !quoted
+			continue
+		}
+		if quoted {
+			continue
+		}
+
+		hasEq := i+1 < len(s) && s[i+1] == '='
+		switch c {
+		case '=':
+			if hasEq {
+				return i, opEQ
+			}
+			return i, `=`
+		case '!':
+			if hasEq {
+				return i, opNE
+			}
+		case '<':
+			if hasEq {
+				return i, opLE
+			}
+			return i, opLT
+		case '>':
+			if hasEq {
+				return i, opGE
+			}
+			return i, opGT
+		}
+	}
+	return -1, ``
+}
+
+func validateOperand(operand string) error {
+	if operand == `` {
+		return fmt.Errorf("Operand is empty.")
+	}
+	if strings.HasPrefix(operand, `"`) {
+		if len(operand) < 2 || !strings.HasSuffix(operand, `"`) {
+			return fmt.Errorf("Quotation of operand[%s] is not closed.", operand)
+		}
+		return nil
+	}
+	if strings.ContainsAny(operand, "\" \t") {
+		return fmt.Errorf("Operand[%s] must be quoted.", operand)
+	}
+	return nil
+}
+
+// 分岐条件式の文字列表現を返す。
+func (c *Condition) String() string {
+	return c.expr
+}
+
+// 変数を展開して分岐条件式を評価する。
+//
+// return : 評価結果。
+//
+// return : エラー情報。
+func (c *Condition) Evaluate() (bool, error) {
+	left, err := expandOperand(c.Left)
+	if err != nil {
+		return false, err
+	}
+	right, err := expandOperand(c.Right)
+	if err != nil {
+		return false, err
+	}
+
+	return compare(left, c.Op, right)
+}
+
+func expandOperand(operand string) (string, error) {
+	if strings.HasPrefix(operand, `"`) {
+		operand = operand[1 : len(operand)-1]
+	}
+	return ExpandStringVars(operand, plcMaster, kndSys, kndEnv, kndJob)
+}
+
+// 2つの値を比較する。
+// 両辺とも整数として解釈できる場合は数値として、それ以外の場合は文字列として比較する。
+// 文字列の大小比較はエラーとする。
+func compare(left, op, right string) (bool, error) {
+	l, lerr := strconv.Atoi(left)
+	r, rerr := strconv.Atoi(right)
+	if lerr == nil && rerr == nil {
+		switch op {
+		case opEQ:
+			return l == r, nil
+		case opNE:
+			return l != r, nil
+		case opLT:
+			return l < r, nil
+		case opLE:
+			return l <= r, nil
+		case opGT:
+			return l > r, nil
+		case opGE:
+			return l >= r, nil
+		}
+	} else {
+		switch op {
+		case opEQ:
+			return left == right, nil
+		case opNE:
+			return left != right, nil
+		case opLT, opLE, opGT, opGE:
+			return false, fmt.Errorf("Cannot compare non-numeric value[%s %s %s].", left, op, right)
+		}
+	}
+
+	return false, fmt.Errorf("Undefined operator[%s].", op)
+}
+
+// 分岐条件式の集合が、とりうるすべての値を網羅しているかを判定する。
+// 全ての条件式が同一の変数と定数の比較である場合のみ判定を行い、それ以外の場合は網羅していないものとみなす。
+// 大小比較による数値の範囲の網羅は、ジョブの戻り値等、値が常に整数となる変数の場合のみ判定する。
+//
+// param : conds 分岐条件式のリスト。
+//
+// return : 網羅している場合はtrueを返す。
+func IsExhaustive(conds []*Condition) bool {
+	if len(conds) == 0 {
+		return false
+	}
+
+	type term struct {
+		op      string
+		literal string
+	}
+	terms := make([]term, 0, len(conds))
+	varName := ``
+	for _, c := range conds {
+		var v, lit, op string
+		switch {
+		case varOperandExp.MatchString(c.Left) && !strings.Contains(c.Right, `$`):
+			v, lit, op = c.Left, c.Right, c.Op
+		case varOperandExp.MatchString(c.Right) && !strings.Contains(c.Left, `$`):
+			v, lit, op = c.Right, c.Left, reverseOperator(c.Op)
+		default:
+			return false
+		}
+		if varName == `` {
+			varName = v
+		} else if varName != v {
+			return false
+		}
+		terms = append(terms, term{op: op, literal: strings.Trim(lit, `"`)})
+	}
+
+	// 値が整数の変数で定数がすべて整数であれば境界値の前後を、それ以外は各定数と、どの定数とも一致しない値を代表値とする。
+	numeric := intOperandExp.MatchString(varName)
+	for _, t := range terms {
+		if _, err := strconv.Atoi(t.literal); err != nil {
+			numeric = false
+			break
+		}
+	}
+
+	var samples []string
+	if numeric {
+		for _, t := range terms {
+			n, _ := strconv.Atoi(t.literal)
+			samples = append(samples, strconv.Itoa(n-1), strconv.Itoa(n), strconv.Itoa(n+1))
+		}
+	} else {
+		other := ``
+		for _, t := range terms {
+			if t.op != opEQ && t.op != opNE {
+				return false
+			}
+			samples = append(samples, t.literal)
+			other += t.literal
+		}
+		samples = append(samples, other+`_`)
+	}
+
+	for _, s := range samples {
+		covered := false
+		for _, t := range terms {
+			if ok, err := compare(s, t.op, t.literal); err == nil && ok {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+func reverseOperator(op string) string {
+	switch op {
+	case opLT:
+		return opGT
+	case opLE:
+		return opGE
+	case opGT:
+		return opLT
+	case opGE:
+		return opLE
+	}
+	return op
+}
@@ -0,0 +1,138 @@
+package message
+
+import "testing"
+
+func mustParseCondition(t *testing.T, expr string) *Condition {
+	c, err := ParseCondition(expr)
+	if err != nil {
+		t.Fatalf("条件式[%s]のパースで想定外のエラーが発生した: %s", expr, err)
+	}
+	return c
+}
+
+func TestParseCondition_条件式を解析できる(t *testing.T) {
+	c := mustParseCondition(t, ` $MJjob1:RC$ >= 4 `)
+	if c.Left != `$MJjob1:RC$` {
+		t.Errorf("左辺[%s]が想定と違っている。", c.Left)
+	}
+	if c.Op != `>=` {
+		t.Errorf("演算子[%s]が想定と違っている。", c.Op)
+	}
+	if c.Right != `4` {
+		t.Errorf("右辺[%s]が想定と違っている。", c.Right)
+	}
+	if c.String() != `$MJjob1:RC$ >= 4` {
+		t.Errorf("文字列表現[%s]が想定と違っている。", c.String())
+	}
+}
+
+func TestParseCondition_クォートされた文字列中の演算子は無視する(t *testing.T) {
+	c := mustParseCondition(t, `$MJjob1:OUT$ != "a==b"`)
+	if c.Op != `!=` {
+		t.Errorf("演算子[%s]が想定と違っている。", c.Op)
+	}
+	if c.Right != `"a==b"` {
+		t.Errorf("右辺[%s]が想定と違っている。", c.Right)
+	}
+}
+
+func TestParseCondition_不正な条件式はエラー(t *testing.T) {
+	exprs := []string{
+		``,
+		`$MJjob1:RC$`,
+		`$MJjob1:RC$ = 0`,
+		`$MJjob1:RC$ ==`,
+		`== 0`,
+		`$MJjob1:RC$ == 0 == 1`,
+		`$MJjob1:OUT$ == "SKIP`,
+		`$MJjob1:OUT$ == SKIP NOW`,
+	}
+	for _, expr := range exprs {
+		if _, err := ParseCondition(expr); err == nil {
+			t.Errorf("条件式[%s]でエラーが発生しなかった。", expr)
+		}
+	}
+}
+
+func TestEvaluate_ジョブネットワーク変数を使用した条件式を評価できる(t *testing.T) {
+	res := new(Response)
+	res.RC = 4
+	res.Var = "SKIP"
+	AddJobValue("condjob", res)
+
+	cases := []struct {
+		expr   string
+		expect bool
+	}{
+		{`$MJcondjob:RC$ == 4`, true},
+		{`$MJcondjob:RC$ != 4`, false},
+		{`$MJcondjob:RC$ < 10`, true},
+		{`$MJcondjob:RC$ <= 3`, false},
+		{`$MJcondjob:RC$ > 3`, true},
+		{`0 >= $MJcondjob:RC$`, false},
+		{`$MJcondjob:RC$ == 04`, true},
+		{`$MJcondjob:OUT$ == "SKIP"`, true},
+		{`$MJcondjob:OUT$ == SKIP`, true},
+		{`"$MJcondjob:OUT$" != "RUN"`, true},
+	}
+	for _, c := range cases {
+		cond := mustParseCondition(t, c.expr)
+		actual, err := cond.Evaluate()
+		if err != nil {
+			t.Errorf("条件式[%s]の評価で想定外のエラーが発生した: %s", c.expr, err)
+			continue
+		}
+		if actual != c.expect {
+			t.Errorf("条件式[%s]の評価結果[%v]が想定と違っている。", c.expr, actual)
+		}
+	}
+}
+
+func TestEvaluate_未実行のジョブを参照した場合はエラー(t *testing.T) {
+	cond := mustParseCondition(t, `$MJnoexecjob:RC$ == 0`)
+	if _, err := cond.Evaluate(); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestEvaluate_数値以外の大小比較はエラー(t *testing.T) {
+	res := new(Response)
+	res.Var = "SKIP"
+	AddJobValue("condjob", res)
+
+	cond := mustParseCondition(t, `$MJcondjob:OUT$ > 0`)
+	if _, err := cond.Evaluate(); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestIsExhaustive_網羅性を判定できる(t *testing.T) {
+	cases := []struct {
+		exprs  []string
+		expect bool
+	}{
+		{[]string{`$MJjob1:RC$ == 0`, `$MJjob1:RC$ != 0`}, true},
+		{[]string{`$MJjob1:RC$ < 4`, `$MJjob1:RC$ >= 4`}, true},
+		{[]string{`$MJjob1:RC$ <= 0`, `0 < $MJjob1:RC$`}, true},
+		{[]string{`$MJjob1:RC$ < 4`, `$MJjob1:RC$ > 4`}, false},
+		{[]string{`$MJjob1:RC$ == 0`, `$MJjob1:RC$ == 1`}, false},
+		{[]string{`$MJjob1:OUT$ == "SKIP"`, `$MJjob1:OUT$ != "SKIP"`}, true},
+		{[]string{`$MJjob1:OUT$ == "SKIP"`, `$MJjob1:OUT$ == "RUN"`}, false},
+		{[]string{`$MJjob1:RC$ == 0`, `$MJjob2:RC$ != 0`}, false},
+		{[]string{`$MJjob1:RC$ == $MJjob2:RC$`, `$MJjob1:RC$ != $MJjob2:RC$`}, false},
+		{[]string{`$MJjob1:OUT$ < 4`, `$MJjob1:OUT$ >= 4`}, false},
+		{[]string{`$MSJOBNET:SD$ <= 0`, `0 < $MSJOBNET:SD$`}, false},
+		{[]string{`$MSJOBNET:ID$ < 100`, `$MSJOBNET:ID$ >= 100`}, true},
+		{[]string{`$MSCAL:ISBIZDAY$ == 1`, `$MSCAL:ISBIZDAY$ < 1`, `$MSCAL:ISBIZDAY$ > 1`}, true},
+		{[]string{`$MJjob1:OUT$ == 0`, `$MJjob1:OUT$ != 0`}, true},
+	}
+	for _, c := range cases {
+		conds := make([]*Condition, len(c.exprs))
+		for i, expr := range c.exprs {
+			conds[i] = mustParseCondition(t, expr)
+		}
+		if actual := IsExhaustive(conds); actual != c.expect {
+			t.Errorf("条件式%vの網羅性判定結果[%v]が想定と違っている。", c.exprs, actual)
+		}
+	}
+}
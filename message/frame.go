@@ -0,0 +1,116 @@
+package message
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// 多重化接続の開始要求・応答メッセージ。
+// masterは接続直後にこのメッセージを送信し、servantが同じtypeのメッセージを返信した場合に多重化接続を利用する。
+type Mux struct {
+	Type    string `json:"type"`
+	Version string `json:"version"`
+}
+
+// 多重化接続上でやり取りするフレーム。
+// Seqはmasterが要求毎に採番する番号で、servantは要求に対する全てのフレームに同じSeqをセットする。
+// IDはmasterが要求フレーム毎に生成する一意な値で、再接続後に再送する場合も変わらない。
+type Frame struct {
+	Seq    int64  `json:"seq"`
+	NID    int    `json:"nid"`
+	JID    string `json:"jid"`
+	Kind   string `json:"kind"`
+	ID     string `json:"id,omitempty"`
+	Resend bool   `json:"resend,omitempty"`
+	Body   string `json:"body,omitempty"`
+}
+
+const muxMessageType = "mux"
+
+// 多重化接続で送受信できるフレームの最大サイズ。
+// 送信側はこのサイズを超えるフレームを送信せず、受信側はこのサイズまで受信する。
+const MaxFrameSize = 16 * 1024 * 1024
+
+// フレームの種別
+const (
+	FRAME_REQUEST = "request" // masterからの要求。Bodyは従来の接続で送信する要求メッセージ。
+	FRAME_ACK     = "ack"     // servantが要求を受け付けたことの通知
+	FRAME_MESSAGE = "message" // servantからの返信。Bodyは従来の接続で返信する1行分のメッセージ。
+	FRAME_PING    = "ping"    // masterからの接続確認
+	FRAME_PONG    = "pong"    // 接続確認への応答
+)
+
+// 多重化接続の開始要求・応答JSONメッセージをパースし、Muxオブジェクトのメンバをセットする。
+//
+// param : message 受信メッセージ文字列
+func (m *Mux) ParseJSON(message string) error {
+	byteMessage := []byte(message)
+	err := json.Unmarshal(byteMessage, m)
+	if err != nil {
+		return err
+	}
+	if m.Type != muxMessageType {
+		return fmt.Errorf("Invalid message type.")
+	}
+	return nil
+}
+
+// Muxオブジェクトの値を元に、多重化接続の開始要求・応答JSONメッセージを生成する
+// バージョンには、送信元に応じてMasterVersionまたはServantVersionをセットしておく。
+//
+// return : JSONメッセージフォーマットの文字列。
+func (m Mux) GenerateJSON() (string, error) {
+	m.Type = muxMessageType
+	byteMessage, err := json.Marshal(m)
+	if err != nil {
+		return ``, err
+	}
+	return string(byteMessage), nil
+}
+
+// 要求メッセージbodyを格納したフレームを生成する。
+// フレームのNID、JIDには、bodyに含まれるnid、jidの値をセットする。
+//
+// param : seq 要求の番号。
+//
+// param : body 要求メッセージ文字列。
+//
+// return : 生成したフレーム。
+func NewRequestFrame(seq int64, body string) *Frame {
+	var header struct {
+		NID int    `json:"nid"`
+		JID string `json:"jid"`
+	}
+	json.Unmarshal([]byte(body), &header)
+	// IDを生成できない場合は、再送時の重複実行の検出を行わない
+	id, _ := generateNonce()
+	return &Frame{Seq: seq, NID: header.NID, JID: header.JID, Kind: FRAME_REQUEST, ID: id, Body: body}
+}
+
+// フレームのJSON文字列をパースし、Frameオブジェクトのメンバをセットする。
+//
+// param : message 受信フレーム文字列
+func (f *Frame) ParseJSON(message string) error {
+	if err := json.Unmarshal([]byte(message), f); err != nil {
+		return err
+	}
+	if f.Kind == "" {
+		return fmt.Errorf("Frame kind is empty.")
+	}
+	return nil
+}
+
+// Frameオブジェクトの値を元に、フレームのJSON文字列を生成する
+// 生成した文字列がMaxFrameSizeを超える場合はエラーを返す。
+//
+// return : JSONフォーマットの文字列。
+func (f Frame) GenerateJSON() (string, error) {
+	byteMessage, err := json.Marshal(f)
+	if err != nil {
+		return ``, err
+	}
+	if len(byteMessage) > MaxFrameSize {
+		return ``, fmt.Errorf("Frame size [%d] exceeds limit [%d].", len(byteMessage), MaxFrameSize)
+	}
+	return string(byteMessage), nil
+}
@@ -0,0 +1,70 @@
+package message
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMux_多重化接続の開始要求メッセージを生成しパースできる(t *testing.T) {
+	m := Mux{Version: "1.2.3"}
+	msg, err := m.GenerateJSON()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+	if msg != `{"type":"mux","version":"1.2.3"}` {
+		t.Errorf("生成されたメッセージ[%s]が想定と違います。", msg)
+	}
+
+	var parsed Mux
+	if err := parsed.ParseJSON(msg); err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+	if parsed.Version != "1.2.3" {
+		t.Errorf("取得したversionの値が違います： %s", parsed.Version)
+	}
+}
+
+func TestMux_typeが間違っている場合はエラーが発生する(t *testing.T) {
+	var m Mux
+	if err := m.ParseJSON(`{"type":"request","version":"1.2.3"}`); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestNewRequestFrame_要求メッセージのnidとjidをセットできる(t *testing.T) {
+	body := `{"type":"request","version":"1.2.3","nid":1234,"jid":"job1"}`
+	f := NewRequestFrame(5, body)
+	if f.Seq != 5 || f.NID != 1234 || f.JID != "job1" || f.Kind != FRAME_REQUEST || f.Body != body {
+		t.Errorf("生成されたフレーム%vが想定と違います。", f)
+	}
+}
+
+func TestFrame_フレームを生成しパースできる(t *testing.T) {
+	f := Frame{Seq: 5, NID: 1234, JID: "job1", Kind: FRAME_MESSAGE, Body: HEARTBEAT}
+	msg, err := f.GenerateJSON()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+
+	var parsed Frame
+	if err := parsed.ParseJSON(msg); err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+	if parsed != f {
+		t.Errorf("パースしたフレーム%vが想定と違います。", parsed)
+	}
+}
+
+func TestFrame_種別が無い場合はエラーが発生する(t *testing.T) {
+	var f Frame
+	if err := f.ParseJSON(`{"type":"request","nid":1234,"jid":"job1"}`); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestFrame_最大サイズを超えるフレームは生成できない(t *testing.T) {
+	f := NewRequestFrame(1, strings.Repeat("a", MaxFrameSize))
+	if _, err := f.GenerateJSON(); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
@@ -11,6 +11,7 @@ type JobCheck struct {
 	Version string `json:"version"`
 	NID     int    `json:"nid"`
 	JID     string `json:"jid"`
+	Auth    string `json:"auth,omitempty"`
 }
 
 const jobCheckMessageType = "jobcheck"
@@ -0,0 +1,90 @@
+package message
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ジョブログ取得要求メッセージ。
+// From、Toで取得する行の範囲（1始まり、0は無指定）を、Tailで末尾から取得する行数を指定できる。
+type Joblog struct {
+	Type    string `json:"type"`
+	Version string `json:"version"`
+	NID     int    `json:"nid"`
+	JID     string `json:"jid"`
+	From    int    `json:"from,omitempty"`
+	To      int    `json:"to,omitempty"`
+	Tail    int    `json:"tail,omitempty"`
+	Auth    string `json:"auth,omitempty"`
+}
+
+// ジョブログ取得結果メッセージ。
+type JoblogResult struct {
+	Type      string `json:"type"`
+	Version   string `json:"version"`
+	NID       int    `json:"nid"`
+	JID       string `json:"jid"`
+	File      string `json:"file"`
+	Content   string `json:"content"`
+	Truncated bool   `json:"truncated"`
+	Detail    string `json:"detail"`
+}
+
+const joblogMessageType = "joblog"
+const joblogResultMessageType = "joblogresult"
+
+// ジョブログ取得要求JSONメッセージをパースし、Joblogオブジェクトのメンバをセットする。
+//
+// param : message 受信メッセージ文字列
+func (j *Joblog) ParseJSON(message string) error {
+	byteMessage := []byte(message)
+	err := json.Unmarshal(byteMessage, j)
+	if err != nil {
+		return err
+	}
+	if j.Type != joblogMessageType {
+		return fmt.Errorf("Invalid message type.")
+	}
+	return nil
+}
+
+// Joblogオブジェクトの値を元に、ジョブログ取得要求JSONメッセージを生成する
+//
+// return : JSONメッセージフォーマットの文字列。
+func (j Joblog) GenerateJSON() (string, error) {
+	j.Type = joblogMessageType
+	j.Version = MasterVersion
+	byteMessage, err := json.Marshal(j)
+	if err != nil {
+		return ``, err
+	}
+	return string(byteMessage), nil
+}
+
+// ジョブログ取得結果JSONメッセージをパースし、JoblogResultオブジェクトのメンバをセットする。
+//
+// param : message 受信メッセージ文字列
+func (j *JoblogResult) ParseJSON(message string) error {
+	byteMessage := []byte(message)
+	err := json.Unmarshal(byteMessage, j)
+	if err != nil {
+		return err
+	}
+	if j.Type != joblogResultMessageType {
+		return fmt.Errorf("Invalid message type.")
+	}
+	return nil
+}
+
+// JoblogResultオブジェクトの値を元に、ジョブログ取得結果JSONメッセージを生成する
+//
+// return : JSONメッセージフォーマットの文字列。
+func (j JoblogResult) GenerateJSON() (string, error) {
+	j.Type = joblogResultMessageType
+	j.Version = ServantVersion
+	byteMessage, err := json.Marshal(j)
+	if err != nil {
+		return ``, err
+	}
+	return string(byteMessage), nil
+}
@@ -0,0 +1,84 @@
+package message
+
+import (
+	"testing"
+)
+
+func TestJoblog_ジョブログ取得要求メッセージをパースできる(t *testing.T) {
+	message := `{
+    "type":"joblog",
+    "version":"1.2.3",
+    "nid":1234,
+    "jid":"job1",
+    "tail":20
+}`
+
+	var j Joblog
+	if err := j.ParseJSON(message); err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+	if j.NID != 1234 {
+		t.Errorf("取得したnidの値が違います： %d", j.NID)
+	}
+	if j.JID != `job1` {
+		t.Errorf("取得したjidの値が違います： %s", j.JID)
+	}
+	if j.Tail != 20 {
+		t.Errorf("取得したtailの値が違います： %d", j.Tail)
+	}
+}
+
+func TestJoblog_typeが間違っている場合はエラーが発生する(t *testing.T) {
+	message := `{"type":"cancel","version":"1.2.3","nid":1234,"jid":"job1"}`
+
+	var j Joblog
+	if err := j.ParseJSON(message); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestJoblog_ジョブログ取得要求メッセージを生成できる(t *testing.T) {
+	MasterVersion = "1.2.3"
+	j := Joblog{NID: 1234, JID: "job1", From: 2, To: 5}
+	msg, err := j.GenerateJSON()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+
+	expected := `{"type":"joblog","version":"1.2.3","nid":1234,"jid":"job1","from":2,"to":5}`
+	if msg != expected {
+		t.Errorf("生成されたメッセージが違います： %s", msg)
+	}
+}
+
+func TestJoblogResult_ジョブログ取得結果メッセージを生成しパースできる(t *testing.T) {
+	ServantVersion = "2.3.4"
+	r := JoblogResult{NID: 1234, JID: "job1", File: "1234.job.job1.20150401.log", Content: "line1\nline2\n"}
+	msg, err := r.GenerateJSON()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+
+	var parsed JoblogResult
+	if err := parsed.ParseJSON(msg); err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+	if parsed.Version != "2.3.4" {
+		t.Errorf("取得したversionの値が違います： %s", parsed.Version)
+	}
+	if parsed.File != r.File {
+		t.Errorf("取得したfileの値が違います： %s", parsed.File)
+	}
+	if parsed.Content != r.Content {
+		t.Errorf("取得したcontentの値が違います： %s", parsed.Content)
+	}
+}
+
+func TestJoblogResult_typeが間違っている場合はエラーが発生する(t *testing.T) {
+	message := `{"type":"joblog","version":"1.2.3","nid":1234,"jid":"job1"}`
+
+	var r JoblogResult
+	if err := r.ParseJSON(message); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
@@ -6,15 +6,16 @@ import (
 )
 
 type JobResult struct {
-	Type    string `json:"type"`
-	Version string `json:"version"`
-	NID     int    `json:"nid"`
-	JID     string `json:"jid"`
-	RC      int    `json:"rc"`
-	Stat    int    `json:"stat"`
-	Var     string `json:"var"`
-	St      string `json:"st"`
-	Et      string `json:"et"`
+	Type    string            `json:"type"`
+	Version string            `json:"version"`
+	NID     int               `json:"nid"`
+	JID     string            `json:"jid"`
+	RC      int               `json:"rc"`
+	Stat    int               `json:"stat"`
+	Var     string            `json:"var"`
+	Outputs map[string]string `json:"outputs,omitempty"`
+	St      string            `json:"st"`
+	Et      string            `json:"et"`
 }
 
 const jobResultMessageType = "jobresult"
@@ -11,3 +11,4 @@ type Message interface {
 
 const HEARTBEAT = "heartbeat"
 const ST_HEADER = "ST:"
+const OUT_HEADER = "OUT:"
@@ -26,6 +26,11 @@ type Request struct {
 	ErrRC     int    `json:"errrc"`
 	ErrStr    string `json:"errstr"`
 	Timeout   int    `json:"timeout"`
+	Stream    bool   `json:"stream,omitempty"`
+	Auth      string `json:"auth,omitempty"`
+
+	ReqID  string `json:"-"` // 多重化接続で要求を識別するID。servantが受信したフレームからセットする。
+	Resent bool   `json:"-"` // 再接続後に再送された要求であればtrue
 }
 
 const requestMessageType = "request"
@@ -91,6 +96,21 @@ func (r *Request) ExpandMasterVars() error {
 	return nil
 }
 
+// masterで利用可能な変数を展開できる範囲で展開する。
+// 展開できなかった変数はそのまま残し、その変数名を返す。
+func (r *Request) ExpandMasterVarsPartially() []string {
+	var unresolved, vars []string
+	r.Path, vars = ExpandStringVarsPartially(r.Path, plcMaster, kndEnv)
+	unresolved = append(unresolved, vars...)
+	r.Param, vars = ExpandStringVarsPartially(r.Param, plcMaster, kndSys, kndEnv, kndJob)
+	unresolved = append(unresolved, vars...)
+	r.Env, vars = ExpandStringVarsPartially(r.Env, plcMaster, kndSys, kndEnv, kndJob)
+	unresolved = append(unresolved, vars...)
+	r.Workspace, vars = ExpandStringVarsPartially(r.Workspace, plcMaster, kndEnv)
+	unresolved = append(unresolved, vars...)
+	return unresolved
+}
+
 // servantで利用可能な変数を展開する。
 func (r *Request) ExpandServantVars() error {
 	newPath, err := ExpandStringVars(r.Path, plcServant, kndSys, kndEnv)
@@ -349,3 +349,32 @@ func TestExpandServantVars_エラー発生時は元の値を維持する(t *test
 		t.Logf("実績値：%s", req.Env)
 	}
 }
+
+func TestExpandMasterVarsPartially_展開できない変数を残して展開する(t *testing.T) {
+	AddSysValue("JOBNET", "ID", "123456")
+	os.Setenv("TEST", "testenv")
+
+	req := new(Request)
+	req.Path = `$METEST$ $SETEST$`
+	req.Param = `$MSJOBNET:ID$ $MJnotexec:RC$`
+	req.Env = `A=$METEST$+B=$MJnotexec:OUT$`
+	req.Workspace = `$METEST$`
+
+	unresolved := req.ExpandMasterVarsPartially()
+
+	if req.Path != `testenv $SETEST$` {
+		t.Errorf("変数展開後のPathの値[%s]が想定と違っている。", req.Path)
+	}
+	if req.Param != `123456 $MJnotexec:RC$` {
+		t.Errorf("変数展開後のParamの値[%s]が想定と違っている。", req.Param)
+	}
+	if req.Env != `A=testenv+B=$MJnotexec:OUT$` {
+		t.Errorf("変数展開後のEnvの値[%s]が想定と違っている。", req.Env)
+	}
+	if req.Workspace != `testenv` {
+		t.Errorf("変数展開後のWorkspaceの値[%s]が想定と違っている。", req.Workspace)
+	}
+	if len(unresolved) != 2 {
+		t.Fatalf("展開できなかった変数の数[%d]が想定と違っている。", len(unresolved))
+	}
+}
@@ -12,17 +12,20 @@ import (
 var ServantVersion string
 
 type Response struct {
-	Type       string `json:"type"`
-	Version    string `json:"version"`
-	NID        int    `json:"nid"`
-	JID        string `json:"jid"`
-	RC         int    `json:"rc"`
-	Stat       int    `json:"stat"`
-	Detail     string `json:"detail"`
-	Var        string `json:"var"`
-	St         string `json:"st"`
-	Et         string `json:"et"`
-	JoblogFile string `json:"joblogfile"`
+	Type       string            `json:"type"`
+	Version    string            `json:"version"`
+	NID        int               `json:"nid"`
+	JID        string            `json:"jid"`
+	RC         int               `json:"rc"`
+	Stat       int               `json:"stat"`
+	Detail     string            `json:"detail"`
+	Var        string            `json:"var"`
+	Outputs    map[string]string `json:"outputs,omitempty"`
+	St         string            `json:"st"`
+	Et         string            `json:"et"`
+	JoblogFile string            `json:"joblogfile"`
+	QueuePos   int               `json:"queuepos,omitempty"`
+	QueueSec   int               `json:"queuesec,omitempty"`
 }
 
 const responseMessageType = "response"
@@ -0,0 +1,93 @@
+package message
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// servantの状態確認要求メッセージ。
+type Status struct {
+	Type    string `json:"type"`
+	Version string `json:"version"`
+	Auth    string `json:"auth,omitempty"`
+}
+
+// servantの状態確認結果メッセージ。
+// Loadには1分間の平均負荷をセットする。取得できない環境では負の値となる。
+type StatusResult struct {
+	Type      string      `json:"type"`
+	Version   string      `json:"version"`
+	Uptime    int         `json:"uptime"`
+	MultiProc int         `json:"multiproc"`
+	Queued    int         `json:"queued"`
+	Load      float64     `json:"load"`
+	Jobs      []StatusJob `json:"jobs"`
+}
+
+// servantで実行中のジョブの情報。
+type StatusJob struct {
+	NID  int    `json:"nid"`
+	JID  string `json:"jid"`
+	Path string `json:"path"`
+	PID  int    `json:"pid"`
+	St   string `json:"st"`
+}
+
+const statusMessageType = "status"
+const statusResultMessageType = "statusresult"
+
+// 状態確認要求JSONメッセージをパースし、Statusオブジェクトのメンバをセットする。
+//
+// param : message 受信メッセージ文字列
+func (s *Status) ParseJSON(message string) error {
+	byteMessage := []byte(message)
+	err := json.Unmarshal(byteMessage, s)
+	if err != nil {
+		return err
+	}
+	if s.Type != statusMessageType {
+		return fmt.Errorf("Invalid message type.")
+	}
+	return nil
+}
+
+// Statusオブジェクトの値を元に、状態確認要求JSONメッセージを生成する
+//
+// return : JSONメッセージフォーマットの文字列。
+func (s Status) GenerateJSON() (string, error) {
+	s.Type = statusMessageType
+	s.Version = MasterVersion
+	byteMessage, err := json.Marshal(s)
+	if err != nil {
+		return ``, err
+	}
+	return string(byteMessage), nil
+}
+
+// 状態確認結果JSONメッセージをパースし、StatusResultオブジェクトのメンバをセットする。
+//
+// param : message 受信メッセージ文字列
+func (s *StatusResult) ParseJSON(message string) error {
+	byteMessage := []byte(message)
+	err := json.Unmarshal(byteMessage, s)
+	if err != nil {
+		return err
+	}
+	if s.Type != statusResultMessageType {
+		return fmt.Errorf("Invalid message type.")
+	}
+	return nil
+}
+
+// StatusResultオブジェクトの値を元に、状態確認結果JSONメッセージを生成する
+//
+// return : JSONメッセージフォーマットの文字列。
+func (s StatusResult) GenerateJSON() (string, error) {
+	s.Type = statusResultMessageType
+	s.Version = ServantVersion
+	byteMessage, err := json.Marshal(s)
+	if err != nil {
+		return ``, err
+	}
+	return string(byteMessage), nil
+}
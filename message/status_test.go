@@ -0,0 +1,62 @@
+package message
+
+import (
+	"testing"
+)
+
+func TestStatus_状態確認要求メッセージを生成しパースできる(t *testing.T) {
+	MasterVersion = "1.2.3"
+	msg, err := Status{}.GenerateJSON()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+	if msg != `{"type":"status","version":"1.2.3"}` {
+		t.Errorf("生成されたメッセージ[%s]が想定と違います。", msg)
+	}
+
+	var s Status
+	if err := s.ParseJSON(msg); err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+	if s.Version != "1.2.3" {
+		t.Errorf("取得したversionの値が違います： %s", s.Version)
+	}
+}
+
+func TestStatus_typeが間違っている場合はエラーが発生する(t *testing.T) {
+	var s Status
+	if err := s.ParseJSON(`{"type":"statusresult","version":"1.2.3"}`); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestStatusResult_状態確認結果メッセージを生成しパースできる(t *testing.T) {
+	ServantVersion = "2.3.4"
+	r := StatusResult{Uptime: 60, MultiProc: 20, Queued: 1, Load: 0.5}
+	r.Jobs = []StatusJob{{NID: 1234, JID: "job1", Path: "test.sh", PID: 5678, St: "2015-04-01 12:34:56.789"}}
+	msg, err := r.GenerateJSON()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+
+	var parsed StatusResult
+	if err := parsed.ParseJSON(msg); err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+	if parsed.Version != "2.3.4" {
+		t.Errorf("取得したversionの値が違います： %s", parsed.Version)
+	}
+	if parsed.Uptime != 60 || parsed.MultiProc != 20 || parsed.Queued != 1 || parsed.Load != 0.5 {
+		t.Errorf("取得した状態%vが想定と違います。", parsed)
+	}
+	if len(parsed.Jobs) != 1 || parsed.Jobs[0] != r.Jobs[0] {
+		t.Errorf("取得したjobsの値が違います： %v", parsed.Jobs)
+	}
+}
+
+func TestStatusResult_typeが間違っている場合はエラーが発生する(t *testing.T) {
+	var r StatusResult
+	if err := r.ParseJSON(`{"type":"status","version":"1.2.3"}`); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
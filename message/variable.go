@@ -32,15 +32,17 @@ type variable struct {
 	Kind  byte
 	Name  string
 	Tag   string
+	Key   string // 名前付き出力のキー（$MJjob:OUT:key$形式の場合のみ）
 }
 
 // ジョブネットワーク変数の値を格納する構造体
 type jobValue struct {
-	ID  string
-	RC  string
-	SD  string
-	ED  string
-	OUT string
+	ID      string
+	RC      string
+	SD      string
+	ED      string
+	OUT     string
+	Outputs map[string]string
 }
 
 var sysValues map[string]string
@@ -65,6 +67,7 @@ func AddJobValue(name string, res *Response) {
 	j.SD = res.St
 	j.ED = res.Et
 	j.OUT = res.Var
+	j.Outputs = res.Outputs
 
 	jobValues[name] = j
 }
@@ -99,6 +102,37 @@ func ExpandStringVars(src string, place byte, kinds ...byte) (string, error) {
 	return result, nil
 }
 
+// 文字列src内の変数を展開できる範囲で展開する。
+// 展開できなかった変数は文字列中にそのまま残し、その変数名を第2戻り値として返す。
+func ExpandStringVarsPartially(src string, place byte, kinds ...byte) (string, []string) {
+	if len(kinds) == 0 {
+		return src, nil
+	}
+
+	kindptn := string(kinds)
+	pattern := fmt.Sprintf(`\$%c[%s](.+?)\$`, place, kindptn)
+	exp := regexp.MustCompile(pattern)
+	matches := exp.FindAllString(src, -1)
+
+	result := src
+	var unresolved []string
+	for _, m := range matches {
+		v := NewVariable(m)
+		if v == nil {
+			continue
+		}
+
+		val, err := v.Expand()
+		if err != nil {
+			unresolved = append(unresolved, m)
+			continue
+		}
+		result = strings.Replace(result, m, val, -1)
+	}
+
+	return result, unresolved
+}
+
 // 変数名を解析してvariable構造体を生成する。
 func NewVariable(key string) *variable {
 	if len(key) < minKeyLength {
@@ -126,6 +160,10 @@ func NewVariable(key string) *variable {
 	case 2:
 		v.Name = nameAndTag[0]
 		v.Tag = nameAndTag[1]
+	case 3:
+		v.Name = nameAndTag[0]
+		v.Tag = nameAndTag[1]
+		v.Key = nameAndTag[2]
 	default:
 		return nil
 	}
@@ -140,6 +178,10 @@ func (v *variable) String() string {
 
 // 変数を値に展開する。
 func (v *variable) Expand() (string, error) {
+	if v.Key != `` && v.Kind != kndJob {
+		return ``, fmt.Errorf("Undefined variable[%s].", v)
+	}
+
 	switch v.Kind {
 	case kndSys:
 		return v.expandSys()
@@ -177,6 +219,17 @@ func (v *variable) expandJob() (string, error) {
 		return ``, fmt.Errorf("Job[%s] is not executed yet.", v.Name)
 	}
 
+	if v.Key != `` {
+		if v.Tag != `OUT` {
+			return ``, fmt.Errorf("Undefined variable[%s].", v)
+		}
+		val, ok := j.Outputs[v.Key]
+		if !ok {
+			return ``, fmt.Errorf("Job[%s] did not output [%s].", v.Name, v.Key)
+		}
+		return val, nil
+	}
+
 	switch v.Tag {
 	case `ID`:
 		return j.ID, nil
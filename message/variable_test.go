@@ -169,6 +169,43 @@ func TestExpand_ジョブネットワーク変数の値を取得できる(t *tes
 	}
 }
 
+func TestNewVariable_変数オブジェクトを生成できる_名前付き出力(t *testing.T) {
+	v := NewVariable(`$MJTEST:OUT:file$`)
+	if v == nil {
+		t.Fatal("オブジェクト生成に失敗した。")
+	}
+	if v.Name != `TEST` || v.Tag != `OUT` || v.Key != `file` {
+		t.Errorf("解析結果[%s, %s, %s]が想定と違っている。", v.Name, v.Tag, v.Key)
+	}
+}
+
+func TestExpand_名前付き出力の値を取得できる(t *testing.T) {
+	res := new(Response)
+	res.Var = "testout"
+	res.Outputs = map[string]string{"file": "a.csv", "count": "10"}
+	AddJobValue("outtest", res)
+
+	after, err := ExpandStringVars(`$MJouttest:OUT:file$ $MJouttest:OUT:count$ $MJouttest:OUT$`, plcMaster, kndJob)
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生[%s]", err)
+	}
+	if after != "a.csv 10 testout" {
+		t.Errorf("変数展開後の文字列[%s]が想定と違っている。", after)
+	}
+}
+
+func TestExpand_出力されていない名前付き出力を参照したらエラー(t *testing.T) {
+	res := new(Response)
+	res.Outputs = map[string]string{"file": "a.csv"}
+	AddJobValue("outtest", res)
+
+	for _, key := range []string{`$MJouttest:OUT:none$`, `$MJouttest:RC:file$`, `$MSJOBNET:ID:file$`} {
+		if _, err := NewVariable(key).Expand(); err == nil {
+			t.Errorf("変数[%s]でエラーが発生しなかった。", key)
+		}
+	}
+}
+
 func TestExpand_未定義のジョブネットワーク変数を参照したらエラー(t *testing.T) {
 	res := new(Response)
 	res.RC = 1
@@ -261,6 +298,27 @@ func TestExpandStringVars_場所識別子が異なる変数を無視する(t *te
 	}
 }
 
+func TestExpandStringVarsPartially_展開できない変数を残して展開する(t *testing.T) {
+	AddSysValue("JOBNET", "ID", "123456")
+	os.Setenv("TEST", "testenv")
+
+	before := `NETWORK[$MSJOBNET:ID$] JOBRC[$MJnotexec:RC$] TESTENV[$METEST$] UNDEF[$MSUNDEF:X$]`
+	after, unresolved := ExpandStringVarsPartially(before, plcMaster, kndSys, kndEnv, kndJob)
+
+	expect := `NETWORK[123456] JOBRC[$MJnotexec:RC$] TESTENV[testenv] UNDEF[$MSUNDEF:X$]`
+	if after != expect {
+		t.Errorf("変数展開後の文字列が想定と一致しない。")
+		t.Logf("想定値：%s", expect)
+		t.Logf("実績値：%s", after)
+	}
+	if len(unresolved) != 2 {
+		t.Fatalf("展開できなかった変数の数[%d]が想定と違っている。", len(unresolved))
+	}
+	if unresolved[0] != `$MJnotexec:RC$` || unresolved[1] != `$MSUNDEF:X$` {
+		t.Errorf("展開できなかった変数%vが想定と違っている。", unresolved)
+	}
+}
+
 func TestExpandTime(t *testing.T) {
 	v := NewVariable("$ST20150730123456.789$")
 	result, err := v.expandTime()
@@ -0,0 +1,234 @@
+package message
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// 長さ付きフレーム形式の通信プロトコルのバージョン
+const ProtocolVersion = 1
+
+// 接続を受け付ける通信プロトコルの最小バージョン
+const MinProtocolVersion = 1
+
+// 長さ付きフレーム形式で送受信できるメッセージの最大サイズ
+const MaxMessageSize = 64 * 1024 * 1024
+
+// フレームの先頭に付与するメッセージ長のバイト数
+const frameHeaderSize = 4
+
+// メッセージ種別以外の機能
+const (
+	CAP_STREAM = "stream" // ジョブ出力の逐次送信
+)
+
+// servantが受信できるメッセージ種別と機能
+var ServantCapabilities = []string{requestMessageType, jobCheckMessageType, cancelMessageType, joblogMessageType, attachMessageType, statusMessageType, CAP_STREAM}
+
+// masterが受信できるメッセージ種別と機能
+var MasterCapabilities = []string{responseMessageType, jobResultMessageType, cancelResultMessageType, joblogResultMessageType, statusResultMessageType, rejectMessageType, CAP_STREAM}
+
+// servantが受信するメッセージの種別毎に、メッセージオブジェクトを生成する関数
+var servantMessages = map[string]func() Message{
+	requestMessageType:  func() Message { return new(Request) },
+	jobCheckMessageType: func() Message { return new(JobCheck) },
+	cancelMessageType:   func() Message { return new(Cancel) },
+	joblogMessageType:   func() Message { return new(Joblog) },
+	attachMessageType:   func() Message { return new(Attach) },
+	statusMessageType:   func() Message { return new(Status) },
+}
+
+// 接続開始時に交換するメッセージ。
+// masterは接続直後にこのメッセージを1行で送信し、servantが同じtypeのメッセージを返信した場合に、
+// 以降のメッセージを長さ付きフレーム形式で送受信する。
+// 相手のCapabilitiesに含まれないメッセージ種別や機能の項目は送信しない。
+type Hello struct {
+	Type         string   `json:"type"`
+	Version      string   `json:"version"`
+	Protocol     int      `json:"protocol"`
+	Capabilities []string `json:"capabilities"`
+	MaxSize      int      `json:"maxsize"`
+}
+
+// 受信したメッセージを拒否したことを通知するメッセージ。
+type Reject struct {
+	Type    string `json:"type"`
+	Version string `json:"version"`
+	Detail  string `json:"detail"`
+}
+
+const helloMessageType = "hello"
+const rejectMessageType = "reject"
+
+// 自身のバージョンと受信できるメッセージ種別・機能をセットしたHelloオブジェクトを生成する。
+//
+// param : version 送信元のバージョン。
+//
+// param : capabilities 送信元が受信できるメッセージ種別と機能。
+//
+// return : 生成したHelloオブジェクト。
+func NewHello(version string, capabilities []string) *Hello {
+	return &Hello{Version: version, Protocol: ProtocolVersion, Capabilities: capabilities, MaxSize: MaxMessageSize}
+}
+
+// 接続開始JSONメッセージをパースし、Helloオブジェクトのメンバをセットする。
+//
+// param : message 受信メッセージ文字列
+func (h *Hello) ParseJSON(message string) error {
+	byteMessage := []byte(message)
+	err := json.Unmarshal(byteMessage, h)
+	if err != nil {
+		return err
+	}
+	if h.Type != helloMessageType {
+		return fmt.Errorf("Invalid message type.")
+	}
+	return nil
+}
+
+// Helloオブジェクトの値を元に、接続開始JSONメッセージを生成する
+//
+// return : JSONメッセージフォーマットの文字列。
+func (h Hello) GenerateJSON() (string, error) {
+	h.Type = helloMessageType
+	byteMessage, err := json.Marshal(h)
+	if err != nil {
+		return ``, err
+	}
+	return string(byteMessage), nil
+}
+
+// 相手がメッセージ種別または機能capabilityに対応しているかを返す。
+func (h *Hello) Supports(capability string) bool {
+	for _, c := range h.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// 相手から受信したHelloを元に、使用するプロトコルのバージョンを決定する。
+//
+// param : peer 相手から受信したHelloオブジェクト。
+//
+// return : 使用するプロトコルのバージョン。
+//
+// return : 相手のプロトコルに対応していない場合はエラー情報。
+func NegotiateProtocol(peer *Hello) (int, error) {
+	if peer.Protocol < MinProtocolVersion {
+		return 0, fmt.Errorf("Protocol version [%d] of version [%s] is not supported. It must be [%d] or later.", peer.Protocol, peer.Version, MinProtocolVersion)
+	}
+	if peer.Protocol < ProtocolVersion {
+		return peer.Protocol, nil
+	}
+	return ProtocolVersion, nil
+}
+
+// 拒否通知JSONメッセージをパースし、Rejectオブジェクトのメンバをセットする。
+//
+// param : message 受信メッセージ文字列
+func (r *Reject) ParseJSON(message string) error {
+	byteMessage := []byte(message)
+	err := json.Unmarshal(byteMessage, r)
+	if err != nil {
+		return err
+	}
+	if r.Type != rejectMessageType {
+		return fmt.Errorf("Invalid message type.")
+	}
+	return nil
+}
+
+// Rejectオブジェクトの値を元に、拒否通知JSONメッセージを生成する
+//
+// return : JSONメッセージフォーマットの文字列。
+func (r Reject) GenerateJSON() (string, error) {
+	r.Type = rejectMessageType
+	r.Version = ServantVersion
+	byteMessage, err := json.Marshal(r)
+	if err != nil {
+		return ``, err
+	}
+	return string(byteMessage), nil
+}
+
+// メッセージの種別を返す。JSONとして解析できない場合はエラーを返す。
+func MessageType(message string) (string, error) {
+	var header struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal([]byte(message), &header); err != nil {
+		return ``, err
+	}
+	return header.Type, nil
+}
+
+// servantが受信したメッセージに、未知の種別や項目が含まれていないかを検査する。
+//
+// param : message 受信メッセージ文字列。
+//
+// return : 未知の種別や項目が含まれている場合はエラー情報。
+func CheckServantMessage(message string) error {
+	msgType, err := MessageType(message)
+	if err != nil {
+		return err
+	}
+	newMessage, ok := servantMessages[msgType]
+	if !ok {
+		return fmt.Errorf("Unknown message type [%s].", msgType)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader([]byte(message)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(newMessage()); err != nil {
+		return fmt.Errorf("Invalid [%s] message: %s", msgType, err)
+	}
+	return nil
+}
+
+// メッセージの前にメッセージ長を付与した1フレームを書き込む。
+//
+// param : w 書き込み先。
+//
+// param : message メッセージ文字列。
+//
+// return : エラー情報。
+func WriteFramed(w io.Writer, message string) error {
+	if len(message) > MaxMessageSize {
+		return fmt.Errorf("Message size [%d] exceeds limit [%d].", len(message), MaxMessageSize)
+	}
+	buf := make([]byte, frameHeaderSize+len(message))
+	binary.BigEndian.PutUint32(buf, uint32(len(message)))
+	copy(buf[frameHeaderSize:], message)
+	_, err := w.Write(buf)
+	return err
+}
+
+// メッセージ長が付与された1フレームを読み込み、メッセージを返す。
+//
+// param : r 読み込み元。
+//
+// param : maxSize 受信できるメッセージの最大サイズ。
+//
+// return : メッセージ文字列。
+//
+// return : エラー情報。
+func ReadFramed(r io.Reader, maxSize int) (string, error) {
+	header := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return ``, err
+	}
+	size := binary.BigEndian.Uint32(header)
+	if int64(size) > int64(maxSize) {
+		return ``, fmt.Errorf("Message size [%d] exceeds limit [%d].", size, maxSize)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return ``, err
+	}
+	return string(body), nil
+}
@@ -0,0 +1,142 @@
+package message
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHello_接続開始メッセージを生成しパースできる(t *testing.T) {
+	h := NewHello("1.2.3", ServantCapabilities)
+	msg, err := h.GenerateJSON()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+
+	var parsed Hello
+	if err := parsed.ParseJSON(msg); err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+	if parsed.Version != "1.2.3" {
+		t.Errorf("取得したversionの値が違います： %s", parsed.Version)
+	}
+	if parsed.Protocol != ProtocolVersion {
+		t.Errorf("取得したprotocolの値が違います： %d", parsed.Protocol)
+	}
+	if parsed.MaxSize != MaxMessageSize {
+		t.Errorf("取得したmaxsizeの値が違います： %d", parsed.MaxSize)
+	}
+	if !parsed.Supports("attach") || !parsed.Supports(CAP_STREAM) {
+		t.Errorf("取得したcapabilitiesの値が違います： %v", parsed.Capabilities)
+	}
+	if parsed.Supports("unknown") {
+		t.Error("未知の機能に対応していると判定された。")
+	}
+}
+
+func TestHello_typeが間違っている場合はエラーが発生する(t *testing.T) {
+	var h Hello
+	if err := h.ParseJSON(`{"type":"request","version":"1.2.3"}`); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestNegotiateProtocol_双方が対応しているバージョンを選択する(t *testing.T) {
+	v, err := NegotiateProtocol(&Hello{Protocol: ProtocolVersion + 1})
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+	if v != ProtocolVersion {
+		t.Errorf("選択されたバージョン[%d]が想定と違います。", v)
+	}
+
+	if _, err := NegotiateProtocol(&Hello{Protocol: MinProtocolVersion - 1, Version: "0.9"}); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestReject_拒否通知メッセージを生成しパースできる(t *testing.T) {
+	msg, err := Reject{Detail: "testerror"}.GenerateJSON()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+
+	var parsed Reject
+	if err := parsed.ParseJSON(msg); err != nil {
+		t.Fatalf("想定外のエラーが発生しました: %s", err)
+	}
+	if parsed.Detail != "testerror" {
+		t.Errorf("取得したdetailの値が違います： %s", parsed.Detail)
+	}
+}
+
+func TestCheckServantMessage_未知の種別や項目を含むメッセージはエラーになる(t *testing.T) {
+	cases := []struct {
+		msg    string
+		expect string
+	}{
+		{`{"type":"request","version":"1.2.3","nid":1,"jid":"job1","path":"test.sh","stream":true,"auth":"abc"}`, ""},
+		{`{"type":"attach","version":"1.2.3","nid":1,"jid":"job1"}`, ""},
+		{`{"type":"request","version":"1.2.3","nid":1,"jid":"job1","unknown":1}`, `unknown field "unknown"`},
+		{`{"type":"unknown","version":"1.2.3"}`, "Unknown message type [unknown]"},
+		{`{"type":"response","version":"1.2.3"}`, "Unknown message type [response]"},
+		{`request`, "invalid character"},
+	}
+	for i, c := range cases {
+		err := CheckServantMessage(c.msg)
+		if c.expect == "" {
+			if err != nil {
+				t.Errorf("ケース[%d]で想定外のエラーが発生した: %s", i, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("ケース[%d]でエラーが発生しなかった。", i)
+		} else if !strings.Contains(err.Error(), c.expect) {
+			t.Errorf("ケース[%d]のエラーメッセージ[%s]が想定と違っている。", i, err)
+		}
+	}
+}
+
+func TestWriteFramed_書き込んだフレームを読み込める(t *testing.T) {
+	large := strings.Repeat("a", 100*1024)
+	var buf bytes.Buffer
+	for _, msg := range []string{"first", large, ""} {
+		if err := WriteFramed(&buf, msg); err != nil {
+			t.Fatalf("想定外のエラーが発生しました: %s", err)
+		}
+	}
+
+	for _, expect := range []string{"first", large, ""} {
+		msg, err := ReadFramed(&buf, MaxMessageSize)
+		if err != nil {
+			t.Fatalf("想定外のエラーが発生しました: %s", err)
+		}
+		if msg != expect {
+			t.Errorf("読み込んだメッセージ長[%d]が想定[%d]と違います。", len(msg), len(expect))
+		}
+	}
+}
+
+func TestReadFramed_最大サイズを超えるフレームはエラーになる(t *testing.T) {
+	var buf bytes.Buffer
+	WriteFramed(&buf, "0123456789")
+
+	_, err := ReadFramed(&buf, 9)
+	if err == nil {
+		t.Fatal("エラーが発生しなかった。")
+	}
+	if !strings.Contains(err.Error(), "exceeds limit") {
+		t.Errorf("エラーメッセージ[%s]が想定と違っている。", err)
+	}
+}
+
+func TestReadFramed_途中で切断された場合はエラーになる(t *testing.T) {
+	var buf bytes.Buffer
+	WriteFramed(&buf, "0123456789")
+	buf.Truncate(8)
+
+	if _, err := ReadFramed(&buf, MaxMessageSize); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
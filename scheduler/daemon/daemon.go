@@ -0,0 +1,221 @@
+package daemon
+
+import (
+	"sync"
+	"time"
+
+	"github.com/unirita/cuto/console"
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/db/tx"
+	"github.com/unirita/cuto/scheduler/schedule"
+	"github.com/unirita/cuto/utctime"
+)
+
+// ジョブネットを起動し、インスタンスIDを返す関数の型
+// 戻り値のチャネルは、ジョブネットの終了時にクローズされる。
+type Launcher func(jobnet string) (instanceID int, done <-chan struct{}, err error)
+
+// スケジュールによる起動を記録する関数の型
+type Recorder func(l *db.ScheduleLog) error
+
+// スケジュールを確認する間隔の上限
+const maxSleep = time.Minute
+
+// コンソールメッセージに出力する起動日時の書式
+const displayLayout = "2006-01-02 15:04 MST"
+
+// スケジュールに従ってジョブネットを起動する常駐処理
+type Daemon struct {
+	schedules []*schedule.Schedule
+	launcher  Launcher
+	recorder  Recorder
+	now       func() time.Time // 現在日時を返す関数
+	maxSleep  time.Duration    // スケジュールを確認する間隔の上限
+
+	mutex   sync.Mutex
+	running map[string]int        // ジョブネット名毎の実行中の数
+	queued  map[string][]*trigger // ジョブネット名毎の実行待ちの起動
+}
+
+// スケジュールによる起動
+type trigger struct {
+	schedule  *schedule.Schedule
+	scheduled time.Time // 予定された起動日時
+}
+
+// Daemonオブジェクトを生成する。
+//
+// param : schedules スケジュール定義のリスト。
+//
+// param : launcher ジョブネットを起動する関数。
+//
+// param : recorder 起動を記録する関数。
+//
+// return : Daemonオブジェクト。
+func New(schedules []*schedule.Schedule, launcher Launcher, recorder Recorder) *Daemon {
+	d := new(Daemon)
+	d.schedules = schedules
+	d.launcher = launcher
+	d.recorder = recorder
+	d.now = time.Now
+	d.maxSleep = maxSleep
+	d.running = make(map[string]int)
+	d.queued = make(map[string][]*trigger)
+	return d
+}
+
+// stopがクローズされるまで、スケジュールに従ってジョブネットを起動する。
+// 終了時に実行中のジョブネットは停止せず、実行待ちの起動は破棄する。
+//
+// param : stop 終了を通知するチャネル。
+func (d *Daemon) Run(stop <-chan struct{}) {
+	nexts := make([]time.Time, len(d.schedules))
+	now := d.now()
+	for i, s := range d.schedules {
+		nexts[i] = s.Next(now)
+		displayNext(s, nexts[i])
+	}
+
+	for {
+		now = d.now()
+		wait := d.maxSleep
+		for i, s := range d.schedules {
+			if nexts[i].IsZero() {
+				continue
+			}
+			if !nexts[i].After(now) {
+				d.fire(s, nexts[i])
+				nexts[i] = s.Next(now)
+				displayNext(s, nexts[i])
+				if nexts[i].IsZero() {
+					continue
+				}
+			}
+			if w := nexts[i].Sub(now); w < wait {
+				wait = w
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func displayNext(s *schedule.Schedule, next time.Time) {
+	if next.IsZero() {
+		console.Display("CTC010W", s.Name, s.Jobnet)
+		return
+	}
+	console.Display("CTC009I", s.Name, s.Jobnet, next.Format(displayLayout))
+}
+
+// スケジュールの重複実行の方針に従い、起動を処理する。
+func (d *Daemon) fire(s *schedule.Schedule, scheduled time.Time) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	t := &trigger{schedule: s, scheduled: scheduled}
+	if d.running[s.Jobnet] > 0 {
+		switch s.Overlap {
+		case schedule.OverlapSkip:
+			console.Display("CTC012W", s.Name, s.Jobnet)
+			d.record(t, db.SC_SKIPPED, 0, "Previous run is not ended.")
+			return
+		case schedule.OverlapQueue:
+			for _, q := range d.queued[s.Jobnet] {
+				if q.schedule == s {
+					console.Display("CTC012W", s.Name, s.Jobnet)
+					d.record(t, db.SC_SKIPPED, 0, "Previous trigger is already queued.")
+					return
+				}
+			}
+			d.queued[s.Jobnet] = append(d.queued[s.Jobnet], t)
+			console.Display("CTC013I", s.Name, s.Jobnet)
+			d.record(t, db.SC_QUEUED, 0, "")
+			return
+		}
+	}
+	d.launch(t)
+}
+
+// 起動対象のジョブネットを起動する。
+// 呼び出し元でd.mutexをロックしておくこと。
+func (d *Daemon) launch(t *trigger) {
+	jobnet := t.schedule.Jobnet
+	d.running[jobnet]++
+	go func() {
+		id, done, err := d.launcher(jobnet)
+		if err != nil {
+			console.Display("CTC014E", t.schedule.Name, jobnet, err)
+			d.mutex.Lock()
+			d.record(t, db.SC_FAILED, 0, err.Error())
+			d.finish(jobnet)
+			d.mutex.Unlock()
+			return
+		}
+
+		console.Display("CTC011I", t.schedule.Name, jobnet, id)
+		d.mutex.Lock()
+		d.record(t, db.SC_LAUNCHED, id, "")
+		d.mutex.Unlock()
+
+		<-done
+		d.mutex.Lock()
+		d.finish(jobnet)
+		d.mutex.Unlock()
+	}()
+}
+
+// ジョブネットの実行中の数を減らし、実行中のものが無くなれば実行待ちの起動を行う。
+// 呼び出し元でd.mutexをロックしておくこと。
+func (d *Daemon) finish(jobnet string) {
+	d.running[jobnet]--
+	if d.running[jobnet] > 0 {
+		return
+	}
+	delete(d.running, jobnet)
+
+	queue := d.queued[jobnet]
+	if len(queue) == 0 {
+		return
+	}
+	t := queue[0]
+	if len(queue) == 1 {
+		delete(d.queued, jobnet)
+	} else {
+		d.queued[jobnet] = queue[1:]
+	}
+	d.launch(t)
+}
+
+func (d *Daemon) record(t *trigger, action string, instanceID int, detail string) {
+	l := db.NewScheduleLog(t.schedule.Name, t.schedule.Jobnet, t.scheduled.UTC().Format(utctime.Default))
+	l.TriggerDate = utctime.Now().String()
+	l.Action = action
+	l.InstanceID = instanceID
+	l.Detail = detail
+	if err := d.recorder(l); err != nil {
+		console.Display("CTC015E", t.schedule.Name, err)
+	}
+}
+
+// DBファイルのSCHEDULELOGテーブルへ起動を記録する関数を返す。
+//
+// param : dbFile DBファイルのパス。
+//
+// return : 起動を記録する関数。
+func DBRecorder(dbFile string) Recorder {
+	return func(l *db.ScheduleLog) error {
+		conn, err := db.Open(dbFile)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		return tx.InsertScheduleLog(conn, l)
+	}
+}
@@ -0,0 +1,236 @@
+package daemon
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/scheduler/schedule"
+)
+
+type testRecorder struct {
+	mutex sync.Mutex
+	logs  []*db.ScheduleLog
+	ch    chan *db.ScheduleLog
+}
+
+func newTestRecorder() *testRecorder {
+	return &testRecorder{ch: make(chan *db.ScheduleLog, 10)}
+}
+
+func (r *testRecorder) record(l *db.ScheduleLog) error {
+	r.mutex.Lock()
+	r.logs = append(r.logs, l)
+	r.mutex.Unlock()
+	r.ch <- l
+	return nil
+}
+
+func (r *testRecorder) wait(t *testing.T) *db.ScheduleLog {
+	select {
+	case l := <-r.ch:
+		return l
+	case <-time.After(3 * time.Second):
+		t.Fatal("Test timeout.")
+	}
+	return nil
+}
+
+type testLauncher struct {
+	mutex sync.Mutex
+	count int
+	done  []chan struct{}
+}
+
+func (l *testLauncher) launch(jobnet string) (int, <-chan struct{}, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.count++
+	done := make(chan struct{})
+	l.done = append(l.done, done)
+	return l.count, done, nil
+}
+
+func (l *testLauncher) end(i int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	close(l.done[i])
+}
+
+func loadTestSchedule(t *testing.T, overlap string) *schedule.Schedule {
+	def := `
+[[schedule]]
+name='test'
+jobnet='testnet'
+cron='* * * * *'
+timezone='UTC'
+overlap='` + overlap + `'
+`
+	schedules, err := schedule.LoadReader(strings.NewReader(def), "")
+	if err != nil {
+		t.Fatalf("Unexpected error occured: %s", err)
+	}
+	return schedules[0]
+}
+
+var testScheduled = time.Date(2015, 12, 31, 0, 0, 0, 0, time.UTC)
+
+func TestFire_Launch(t *testing.T) {
+	s := loadTestSchedule(t, schedule.OverlapSkip)
+	l := new(testLauncher)
+	r := newTestRecorder()
+	d := New([]*schedule.Schedule{s}, l.launch, r.record)
+
+	d.fire(s, testScheduled)
+	log := r.wait(t)
+	if log.Action != db.SC_LAUNCHED {
+		t.Errorf("Action => %s, want %s", log.Action, db.SC_LAUNCHED)
+	}
+	if log.InstanceID != 1 {
+		t.Errorf("InstanceID => %d, want %d", log.InstanceID, 1)
+	}
+	if log.Schedule != "test" || log.JobnetWork != "testnet" {
+		t.Errorf("Unexpected schedule[%s] or jobnet[%s].", log.Schedule, log.JobnetWork)
+	}
+	if log.ScheduledDate != "2015-12-31 00:00:00.000" {
+		t.Errorf("ScheduledDate => %s, want %s", log.ScheduledDate, "2015-12-31 00:00:00.000")
+	}
+}
+
+func TestFire_Skip(t *testing.T) {
+	s := loadTestSchedule(t, schedule.OverlapSkip)
+	l := new(testLauncher)
+	r := newTestRecorder()
+	d := New([]*schedule.Schedule{s}, l.launch, r.record)
+
+	d.fire(s, testScheduled)
+	r.wait(t)
+	d.fire(s, testScheduled.Add(time.Minute))
+	if log := r.wait(t); log.Action != db.SC_SKIPPED {
+		t.Errorf("Action => %s, want %s", log.Action, db.SC_SKIPPED)
+	}
+
+	l.end(0)
+	time.Sleep(10 * time.Millisecond)
+	d.fire(s, testScheduled.Add(2*time.Minute))
+	if log := r.wait(t); log.Action != db.SC_LAUNCHED {
+		t.Errorf("Action => %s, want %s", log.Action, db.SC_LAUNCHED)
+	}
+}
+
+func TestFire_Queue(t *testing.T) {
+	s := loadTestSchedule(t, schedule.OverlapQueue)
+	l := new(testLauncher)
+	r := newTestRecorder()
+	d := New([]*schedule.Schedule{s}, l.launch, r.record)
+
+	d.fire(s, testScheduled)
+	r.wait(t)
+	d.fire(s, testScheduled.Add(time.Minute))
+	if log := r.wait(t); log.Action != db.SC_QUEUED {
+		t.Errorf("Action => %s, want %s", log.Action, db.SC_QUEUED)
+	}
+	d.fire(s, testScheduled.Add(2*time.Minute))
+	if log := r.wait(t); log.Action != db.SC_SKIPPED {
+		t.Errorf("Action => %s, want %s", log.Action, db.SC_SKIPPED)
+	}
+
+	l.end(0)
+	log := r.wait(t)
+	if log.Action != db.SC_LAUNCHED {
+		t.Errorf("Action => %s, want %s", log.Action, db.SC_LAUNCHED)
+	}
+	if log.ScheduledDate != "2015-12-31 00:01:00.000" {
+		t.Errorf("ScheduledDate => %s, want %s", log.ScheduledDate, "2015-12-31 00:01:00.000")
+	}
+}
+
+func TestFire_Allow(t *testing.T) {
+	s := loadTestSchedule(t, schedule.OverlapAllow)
+	l := new(testLauncher)
+	r := newTestRecorder()
+	d := New([]*schedule.Schedule{s}, l.launch, r.record)
+
+	d.fire(s, testScheduled)
+	r.wait(t)
+	d.fire(s, testScheduled.Add(time.Minute))
+	log := r.wait(t)
+	if log.Action != db.SC_LAUNCHED {
+		t.Errorf("Action => %s, want %s", log.Action, db.SC_LAUNCHED)
+	}
+	if log.InstanceID != 2 {
+		t.Errorf("InstanceID => %d, want %d", log.InstanceID, 2)
+	}
+}
+
+func TestFire_LaunchError(t *testing.T) {
+	s := loadTestSchedule(t, schedule.OverlapSkip)
+	launcher := func(jobnet string) (int, <-chan struct{}, error) {
+		return 0, nil, errors.New("testerror")
+	}
+	r := newTestRecorder()
+	d := New([]*schedule.Schedule{s}, launcher, r.record)
+
+	d.fire(s, testScheduled)
+	log := r.wait(t)
+	if log.Action != db.SC_FAILED {
+		t.Errorf("Action => %s, want %s", log.Action, db.SC_FAILED)
+	}
+	if log.Detail != "testerror" {
+		t.Errorf("Detail => %s, want %s", log.Detail, "testerror")
+	}
+
+	// Failed launch must not block next trigger.
+	time.Sleep(10 * time.Millisecond)
+	d.fire(s, testScheduled.Add(time.Minute))
+	if log := r.wait(t); log.Action != db.SC_FAILED {
+		t.Errorf("Action => %s, want %s", log.Action, db.SC_FAILED)
+	}
+}
+
+func TestRun(t *testing.T) {
+	s := loadTestSchedule(t, schedule.OverlapSkip)
+	l := new(testLauncher)
+	r := newTestRecorder()
+	d := New([]*schedule.Schedule{s}, l.launch, r.record)
+	d.maxSleep = 10 * time.Millisecond
+
+	var mutex sync.Mutex
+	times := []time.Time{
+		time.Date(2015, 12, 31, 10, 0, 30, 0, time.UTC),
+		time.Date(2015, 12, 31, 10, 0, 45, 0, time.UTC),
+		time.Date(2015, 12, 31, 10, 1, 0, 0, time.UTC),
+	}
+	d.now = func() time.Time {
+		mutex.Lock()
+		defer mutex.Unlock()
+		now := times[0]
+		if len(times) > 1 {
+			times = times[1:]
+		}
+		return now
+	}
+
+	stop := make(chan struct{})
+	end := make(chan struct{})
+	go func() {
+		d.Run(stop)
+		close(end)
+	}()
+
+	log := r.wait(t)
+	close(stop)
+	<-end
+	if log.Action != db.SC_LAUNCHED {
+		t.Errorf("Action => %s, want %s", log.Action, db.SC_LAUNCHED)
+	}
+	if log.ScheduledDate != "2015-12-31 10:01:00.000" {
+		t.Errorf("ScheduledDate => %s, want %s", log.ScheduledDate, "2015-12-31 10:01:00.000")
+	}
+	if len(r.logs) != 1 {
+		t.Errorf("Number of records => %d, want %d", len(r.logs), 1)
+	}
+}
@@ -0,0 +1,5 @@
+/*
+daemon is a package which triggers jobnets according to schedules,
+and controls overlapped runs of the same jobnet.
+*/
+package daemon
@@ -0,0 +1,21 @@
+package daemon
+
+import (
+	"os/exec"
+
+	"github.com/unirita/cuto/master/launcher"
+)
+
+// masterコマンドでジョブネットを起動する関数を返す。
+//
+// param : masterPath masterコマンドのパス。
+//
+// param : configPath masterコマンドに指定する設定ファイルのパス。
+//
+// return : ジョブネットを起動する関数。
+func MasterLauncher(masterPath, configPath string) Launcher {
+	return func(jobnet string) (int, <-chan struct{}, error) {
+		cmd := exec.Command(masterPath, "-n", jobnet, "-s", "-c", configPath)
+		return launcher.Launch(cmd)
+	}
+}
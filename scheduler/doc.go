@@ -0,0 +1,2 @@
+// schedule.iniに定義された日時にジョブネットを起動する常駐コマンド
+package main
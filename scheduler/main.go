@@ -0,0 +1,176 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/unirita/cuto/console"
+	"github.com/unirita/cuto/log"
+	"github.com/unirita/cuto/master/config"
+	"github.com/unirita/cuto/master/jobnet"
+	"github.com/unirita/cuto/scheduler/daemon"
+	"github.com/unirita/cuto/scheduler/schedule"
+	"github.com/unirita/cuto/util"
+)
+
+// 実行時引数のオプション
+type arguments struct {
+	versionFlag bool   // バージョン情報表示フラグ
+	checkFlag   bool   // スケジュール定義の検査フラグ
+	configPath  string // 設定ファイルのパス
+}
+
+// schedulerの戻り値
+const (
+	rc_OK    = 0
+	rc_ERROR = 1
+)
+
+const usage = `Usage :
+    scheduler [-v] [-t] [-c ConfigFile]
+
+Option :
+    -v            : Print scheduler version.
+    -t            : Check schedule definitions and print next trigger times, then exit.
+    -c ConfigFile : Designate master config file path.
+                    If it is omitted, '<CUTOROOT>/bin/master.ini' will be used.
+
+Copyright 2015 unirita Inc.
+`
+
+func main() {
+	args := fetchArgs()
+	if args == nil {
+		showUsage()
+		os.Exit(rc_ERROR)
+	}
+	os.Exit(realMain(args))
+}
+
+func realMain(args *arguments) int {
+	if args.versionFlag {
+		fmt.Println(Version)
+		return rc_OK
+	}
+
+	if args.configPath == "" {
+		args.configPath = filepath.Join(util.GetRootPath(), "bin", "master.ini")
+	}
+	if err := config.Load(args.configPath); err != nil {
+		console.Display("CTC004E", args.configPath, err)
+		return rc_ERROR
+	}
+	if err := config.DetectError(); err != nil {
+		console.Display("CTC005E", err)
+		return rc_ERROR
+	}
+
+	schedulePath := filepath.Join(config.Dir.JobnetDir, schedule.FileName)
+	schedules, err := schedule.LoadFile(schedulePath)
+	if err != nil {
+		console.Display("CTC007E", schedulePath, err)
+		return rc_ERROR
+	}
+
+	if args.checkFlag {
+		return check(schedules)
+	}
+
+	if err := log.Init(config.Dir.LogDir,
+		"scheduler",
+		"",
+		config.Log.OutputLevel,
+		config.Log.MaxSizeKB,
+		config.Log.MaxGeneration,
+		config.Log.TimeoutSec); err != nil {
+		console.Display("CTC006E", err)
+		return rc_ERROR
+	}
+	defer log.Term()
+
+	rc := rc_OK
+	console.Display("CTC001I", os.Getpid(), Version)
+	defer func() {
+		console.Display("CTC002I", rc)
+	}()
+
+	if !validateJobnets(schedules) {
+		rc = rc_ERROR
+		return rc
+	}
+
+	stop := make(chan struct{})
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		close(stop)
+	}()
+
+	masterPath := filepath.Join(util.GetRootPath(), "bin", "master")
+	d := daemon.New(schedules,
+		daemon.MasterLauncher(masterPath, args.configPath),
+		daemon.DBRecorder(config.DB.DBFile))
+	d.Run(stop)
+	return rc
+}
+
+// スケジュールのジョブネットを検証し、次回の起動日時を出力する。
+func check(schedules []*schedule.Schedule) int {
+	if !validateJobnets(schedules) {
+		return rc_ERROR
+	}
+	now := time.Now()
+	for _, s := range schedules {
+		next := s.Next(now)
+		if next.IsZero() {
+			fmt.Printf("%s\t%s\t-\n", s.Name, s.Jobnet)
+		} else {
+			fmt.Printf("%s\t%s\t%s\n", s.Name, s.Jobnet, next.Format("2006-01-02 15:04 MST"))
+		}
+	}
+	return rc_OK
+}
+
+// スケジュールの全てのジョブネットが読み込み可能で、フローに誤りが無いかを検証する。
+func validateJobnets(schedules []*schedule.Schedule) bool {
+	valid := true
+	for _, s := range schedules {
+		nwk := jobnet.LoadNetwork(s.Jobnet)
+		if nwk == nil {
+			console.Display("CTC008E", s.Jobnet, s.Name)
+			valid = false
+			continue
+		}
+		if err := nwk.DetectFlowError(); err != nil {
+			console.Display("CTM011E", nwk.MasterPath, err)
+			console.Display("CTC008E", s.Jobnet, s.Name)
+			valid = false
+		}
+		nwk.Terminate()
+	}
+	return valid
+}
+
+func fetchArgs() *arguments {
+	args := new(arguments)
+	flag.Usage = showUsage
+	flag.BoolVar(&args.versionFlag, "v", false, "version option")
+	flag.BoolVar(&args.checkFlag, "t", false, "check option")
+	flag.StringVar(&args.configPath, "c", "", "config file option")
+	flag.Parse()
+	if flag.NArg() != 0 {
+		return nil
+	}
+	return args
+}
+
+func showUsage() {
+	console.Display("CTC003E")
+	fmt.Fprint(os.Stderr, usage)
+}
@@ -0,0 +1,72 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func mustParseCron(t *testing.T, expr string) *CronSpec {
+	c, err := ParseCron(expr)
+	if err != nil {
+		t.Fatalf("Unexpected error occured for cron[%s]: %s", expr, err)
+	}
+	return c
+}
+
+func TestParseCron_InvalidExpression(t *testing.T) {
+	exprs := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"* * * foo *",
+	}
+	for _, expr := range exprs {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("Error was not detected for cron[%s].", expr)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	base := time.Date(2015, 12, 31, 23, 58, 30, 0, time.UTC) // Thursday
+	cases := []struct {
+		expr   string
+		expect time.Time
+	}{
+		{"* * * * *", time.Date(2015, 12, 31, 23, 59, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"30 9 * * *", time.Date(2016, 1, 1, 9, 30, 0, 0, time.UTC)},
+		{"0 0 * * mon-fri", time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * sat,sun", time.Date(2016, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2016, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 12 29 feb *", time.Date(2016, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{"0 0 15 * 1", time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC)},
+		{"10-20/5 3 * * *", time.Date(2016, 1, 1, 3, 10, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Time{}},
+	}
+	for _, c := range cases {
+		actual := mustParseCron(t, c.expr).Next(base)
+		if !actual.Equal(c.expect) {
+			t.Errorf("Next time of cron[%s] is %v, expected %v.", c.expr, actual, c.expect)
+		}
+	}
+}
+
+func TestCronNext_KeepLocation(t *testing.T) {
+	loc := time.FixedZone("JST", 9*60*60)
+	base := time.Date(2015, 12, 31, 23, 0, 0, 0, loc)
+	actual := mustParseCron(t, "0 8 * * *").Next(base)
+	expect := time.Date(2016, 1, 1, 8, 0, 0, 0, loc)
+	if !actual.Equal(expect) {
+		t.Errorf("Next time is %v, expected %v.", actual, expect)
+	}
+}
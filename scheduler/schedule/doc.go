@@ -0,0 +1,5 @@
+/*
+schedule is a package which loads schedule definitions for scheduler command,
+and calculates trigger times from cron expressions.
+*/
+package schedule
@@ -0,0 +1,173 @@
+package schedule
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/unirita/cuto/calendar"
+)
+
+// 同じジョブネットの前回の実行中に起動日時となった場合の方針
+const (
+	OverlapSkip  = "skip"  // 起動しない
+	OverlapQueue = "queue" // 前回の実行の終了後に起動する
+	OverlapAllow = "allow" // 同時に起動する
+)
+
+// 営業日カレンダーによる起動日の規則
+const (
+	DaysAll         = "all"          // cron式に一致する全ての日
+	DaysBizday      = "bizday"       // 営業日のみ
+	DaysHoliday     = "holiday"      // 休業日のみ
+	DaysFirstBizday = "first_bizday" // 月初の営業日のみ
+	DaysLastBizday  = "last_bizday"  // 月末の営業日のみ
+)
+
+// ジョブネットディレクトリに配置するスケジュール定義ファイルの名前
+const FileName = "schedule.ini"
+
+type definitions struct {
+	Schedule []*Schedule `toml:"schedule"`
+}
+
+// ジョブネットを日時指定で起動するスケジュール定義
+type Schedule struct {
+	Name     string `toml:"name"`     // スケジュール名（一意）
+	Jobnet   string `toml:"jobnet"`   // 起動するジョブネット名
+	Cron     string `toml:"cron"`     // cron式
+	Timezone string `toml:"timezone"` // cron式を評価するタイムゾーン（空の場合はローカル）
+	Overlap  string `toml:"overlap"`  // 重複実行の方針（空の場合はskip）
+	Calendar string `toml:"calendar"` // 営業日カレンダー名（空の場合はジョブネットのカレンダー）
+	Days     string `toml:"days"`     // 営業日カレンダーによる起動日の規則（空の場合はall）
+
+	spec     *CronSpec
+	location *time.Location
+	calendar *calendar.Calendar
+}
+
+// スケジュール定義ファイルを読み込む。
+//
+// param : path スケジュール定義ファイルのパス。
+//
+// return : スケジュール定義のリスト。
+//
+// return : エラー情報。
+func LoadFile(path string) ([]*Schedule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadReader(f, filepath.Dir(path))
+}
+
+// スケジュール定義を読み込み、検証する。
+// 営業日カレンダーはcalendarDirから読み込む。
+//
+// param : reader スケジュール定義の読み込み元。
+//
+// param : calendarDir カレンダー定義ファイルのディレクトリ。
+//
+// return : スケジュール定義のリスト。
+//
+// return : エラー情報。
+func LoadReader(reader io.Reader, calendarDir string) ([]*Schedule, error) {
+	defs := new(definitions)
+	if _, err := toml.DecodeReader(reader, defs); err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	for _, s := range defs.Schedule {
+		if err := s.init(calendarDir); err != nil {
+			return nil, err
+		}
+		if names[s.Name] {
+			return nil, fmt.Errorf("Schedule name[%s] duplicated.", s.Name)
+		}
+		names[s.Name] = true
+	}
+	return defs.Schedule, nil
+}
+
+func (s *Schedule) init(calendarDir string) error {
+	if s.Name == "" {
+		return fmt.Errorf("Schedule must have a name.")
+	}
+	if s.Jobnet == "" {
+		return fmt.Errorf("Schedule[%s] must have a jobnet.", s.Name)
+	}
+
+	var err error
+	if s.spec, err = ParseCron(s.Cron); err != nil {
+		return fmt.Errorf("Schedule[%s] has invalid cron: %s", s.Name, err)
+	}
+
+	if s.Timezone == "" {
+		s.location = time.Local
+	} else if s.location, err = time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("Schedule[%s] has invalid timezone: %s", s.Name, err)
+	}
+
+	switch s.Overlap {
+	case "":
+		s.Overlap = OverlapSkip
+	case OverlapSkip, OverlapQueue, OverlapAllow:
+	default:
+		return fmt.Errorf("Schedule[%s] has invalid overlap policy[%s].", s.Name, s.Overlap)
+	}
+
+	switch s.Days {
+	case "":
+		s.Days = DaysAll
+	case DaysAll, DaysBizday, DaysHoliday, DaysFirstBizday, DaysLastBizday:
+	default:
+		return fmt.Errorf("Schedule[%s] has invalid days[%s].", s.Name, s.Days)
+	}
+
+	if s.Calendar == "" {
+		s.calendar, err = calendar.LoadFor(calendarDir, s.Jobnet)
+	} else {
+		s.calendar, err = calendar.Load(calendarDir, s.Calendar)
+	}
+	if err != nil {
+		return fmt.Errorf("Schedule[%s] could not load calendar: %s", s.Name, err)
+	}
+	return nil
+}
+
+// tより後で次に起動する日時を返す。
+// 起動する日時が無い場合はゼロ値を返す。
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.In(s.location)
+	limit := t.AddDate(searchYears, 0, 0)
+	for {
+		t = s.spec.Next(t)
+		if t.IsZero() || t.After(limit) {
+			return time.Time{}
+		}
+		if s.matchDays(t) {
+			return t
+		}
+	}
+}
+
+func (s *Schedule) matchDays(t time.Time) bool {
+	switch s.Days {
+	case DaysBizday:
+		return s.calendar.IsBusinessDay(t)
+	case DaysHoliday:
+		return !s.calendar.IsBusinessDay(t)
+	case DaysFirstBizday:
+		return s.calendar.IsFirstBusinessDay(t)
+	case DaysLastBizday:
+		return s.calendar.IsLastBusinessDay(t)
+	}
+	return true
+}
@@ -0,0 +1,145 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadReader(t *testing.T) {
+	def := `
+[[schedule]]
+name='daily'
+jobnet='dailybatch'
+cron='0 2 * * *'
+timezone='UTC'
+
+[[schedule]]
+name='hourly'
+jobnet='poll'
+cron='@hourly'
+overlap='queue'
+`
+	schedules, err := LoadReader(strings.NewReader(def), "_testdata")
+	if err != nil {
+		t.Fatalf("Unexpected error occured: %s", err)
+	}
+	if len(schedules) != 2 {
+		t.Fatalf("Number of schedules[%d] is not expected value[%d].", len(schedules), 2)
+	}
+	if schedules[0].Name != "daily" || schedules[0].Jobnet != "dailybatch" {
+		t.Errorf("Unexpected schedule[%v].", schedules[0])
+	}
+	if schedules[0].Overlap != OverlapSkip {
+		t.Errorf("Default overlap policy[%s] is not %s.", schedules[0].Overlap, OverlapSkip)
+	}
+	if schedules[1].Overlap != OverlapQueue {
+		t.Errorf("Overlap policy[%s] is not %s.", schedules[1].Overlap, OverlapQueue)
+	}
+
+	base := time.Date(2015, 12, 31, 23, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	next := schedules[0].Next(base)
+	expect := time.Date(2016, 1, 1, 2, 0, 0, 0, time.UTC)
+	if !next.Equal(expect) {
+		t.Errorf("Next time[%v] is not expected value[%v].", next, expect)
+	}
+}
+
+func TestLoadReader_InvalidDefinition(t *testing.T) {
+	defs := []string{
+		"[[schedule]]\njobnet='a'\ncron='* * * * *'",
+		"[[schedule]]\nname='a'\ncron='* * * * *'",
+		"[[schedule]]\nname='a'\njobnet='a'\ncron='* * *'",
+		"[[schedule]]\nname='a'\njobnet='a'\ncron='* * * * *'\ntimezone='No/Where'",
+		"[[schedule]]\nname='a'\njobnet='a'\ncron='* * * * *'\noverlap='wait'",
+		"[[schedule]]\nname='a'\njobnet='a'\ncron='* * * * *'\n[[schedule]]\nname='a'\njobnet='b'\ncron='* * * * *'",
+		"[[schedule]]\nname='a'\njobnet='a'\ncron='* * * * *'\ndays='weekday'",
+		"[[schedule]]\nname='a'\njobnet='a'\ncron='* * * * *'\ncalendar='noexists'",
+		"[[schedule]\n",
+	}
+	for _, def := range defs {
+		if _, err := LoadReader(strings.NewReader(def), "_testdata"); err == nil {
+			t.Errorf("Error was not detected for definition[%s].", def)
+		}
+	}
+}
+
+func TestNext_WithBusinessCalendar(t *testing.T) {
+	def := `
+[[schedule]]
+name='bizday'
+jobnet='a'
+cron='0 9 * * *'
+timezone='UTC'
+calendar='biz'
+days='bizday'
+
+[[schedule]]
+name='holiday'
+jobnet='a'
+cron='0 9 * * *'
+timezone='UTC'
+calendar='biz'
+days='holiday'
+
+[[schedule]]
+name='first'
+jobnet='a'
+cron='0 9 * * *'
+timezone='UTC'
+calendar='biz'
+days='first_bizday'
+
+[[schedule]]
+name='last'
+jobnet='a'
+cron='0 9 * * *'
+timezone='UTC'
+calendar='biz'
+days='last_bizday'
+`
+	schedules, err := LoadReader(strings.NewReader(def), "_testdata")
+	if err != nil {
+		t.Fatalf("Unexpected error occured: %s", err)
+	}
+
+	base := time.Date(2015, 12, 31, 12, 0, 0, 0, time.UTC)
+	expects := []time.Time{
+		time.Date(2016, 1, 4, 9, 0, 0, 0, time.UTC),
+		time.Date(2016, 1, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2016, 1, 4, 9, 0, 0, 0, time.UTC),
+		time.Date(2016, 1, 28, 9, 0, 0, 0, time.UTC),
+	}
+	for i, s := range schedules {
+		if next := s.Next(base); !next.Equal(expects[i]) {
+			t.Errorf("Next time[%v] of schedule[%s] is not expected value[%v].", next, s.Name, expects[i])
+		}
+	}
+}
+
+func TestNext_DefaultCalendar(t *testing.T) {
+	def := `
+[[schedule]]
+name='bizday'
+jobnet='noexists'
+cron='0 9 * * *'
+timezone='UTC'
+days='bizday'
+`
+	schedules, err := LoadReader(strings.NewReader(def), "_testdata")
+	if err != nil {
+		t.Fatalf("Unexpected error occured: %s", err)
+	}
+
+	base := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+	expect := time.Date(2016, 1, 4, 9, 0, 0, 0, time.UTC)
+	if next := schedules[0].Next(base); !next.Equal(expect) {
+		t.Errorf("Next time[%v] is not expected value[%v].", next, expect)
+	}
+}
+
+func TestLoadFile_NotExists(t *testing.T) {
+	if _, err := LoadFile("noexistfilepath"); err == nil {
+		t.Error("Error was not detected.")
+	}
+}
@@ -0,0 +1,4 @@
+package main
+
+// schedulerのバージョン情報
+const Version = "0.9.7.1"
@@ -4,8 +4,11 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -63,6 +66,7 @@ type ServantConfig struct {
 	Job jobSection
 	Dir dirSection
 	Log logSection
+	Sec securitySection `toml:"security"`
 }
 
 // サーバント設定のsysセクション
@@ -94,6 +98,17 @@ type logSection struct {
 	TimeoutSec    int    `toml:"timeout_sec"`
 }
 
+// サーバント設定のsecurityセクション
+type securitySection struct {
+	TLSCertFile     string   `toml:"tls_cert_file"`
+	TLSKeyFile      string   `toml:"tls_key_file"`
+	TLSClientCAFile string   `toml:"tls_client_ca_file"`
+	AuthSecret      string   `toml:"auth_secret"`
+	RestrictJobPath int      `toml:"restrict_job_path"`
+	AllowedJobPaths []string `toml:"allowed_job_paths"`
+	DenyDocker      int      `toml:"deny_docker"`
+}
+
 var Servant *ServantConfig
 var FilePath string
 var RootPath string
@@ -143,6 +158,20 @@ func (c *ServantConfig) DetectError() error {
 	if c.Job.MultiProc <= 0 {
 		return fmt.Errorf("job.multi_proc(%d) must not be 0 or less.", c.Job.MultiProc)
 	}
+	if (c.Sec.TLSCertFile == "") != (c.Sec.TLSKeyFile == "") {
+		return fmt.Errorf("security.tls_cert_file and security.tls_key_file must be set together.")
+	}
+	if c.Sec.TLSClientCAFile != "" && c.Sec.TLSCertFile == "" {
+		return fmt.Errorf("security.tls_client_ca_file requires security.tls_cert_file.")
+	}
+	for _, p := range c.Sec.AllowedJobPaths {
+		if !filepath.IsAbs(p) {
+			return fmt.Errorf("security.allowed_job_paths(%s) must be absolute path.", p)
+		}
+		if _, err := filepath.Match(p, ""); err != nil {
+			return fmt.Errorf("security.allowed_job_paths(%s) is invalid pattern.", p)
+		}
+	}
 	if c.Log.MaxSizeKB <= 0 {
 		return fmt.Errorf("log.max_size_kb(%d) must not be 0 or less.", c.Log.MaxSizeKB)
 	}
@@ -177,6 +206,12 @@ func (s *ServantConfig) replaceCutoroot() {
 	s.Dir.JoblogDir = strings.Replace(s.Dir.JoblogDir, tag_CUTOROOT, util.GetRootPath(), -1)
 	s.Dir.JobDir = strings.Replace(s.Dir.JobDir, tag_CUTOROOT, util.GetRootPath(), -1)
 	s.Dir.LogDir = strings.Replace(s.Dir.LogDir, tag_CUTOROOT, util.GetRootPath(), -1)
+	s.Sec.TLSCertFile = strings.Replace(s.Sec.TLSCertFile, tag_CUTOROOT, util.GetRootPath(), -1)
+	s.Sec.TLSKeyFile = strings.Replace(s.Sec.TLSKeyFile, tag_CUTOROOT, util.GetRootPath(), -1)
+	s.Sec.TLSClientCAFile = strings.Replace(s.Sec.TLSClientCAFile, tag_CUTOROOT, util.GetRootPath(), -1)
+	for i, p := range s.Sec.AllowedJobPaths {
+		s.Sec.AllowedJobPaths[i] = strings.Replace(p, tag_CUTOROOT, util.GetRootPath(), -1)
+	}
 }
 
 // 設定値の相対パスを絶対パスへ変換する。
@@ -191,3 +226,36 @@ func (s *ServantConfig) convertFullpath() {
 		s.Dir.LogDir = filepath.Join(RootPath, s.Dir.LogDir)
 	}
 }
+
+// securityセクションの設定値を元に、masterからの接続を受け付ける際のTLS設定を生成する。
+// サーバ証明書が設定されていない場合はnilを返す。
+// クライアントCA証明書が設定されている場合は、masterにクライアント証明書の提示を要求する。
+//
+// 戻り値: TLS設定
+//
+// 戻り値: エラー情報
+func (s *ServantConfig) TLSConfig() (*tls.Config, error) {
+	if s.Sec.TLSCertFile == "" {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(s.Sec.TLSCertFile, s.Sec.TLSKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	tlsConf := new(tls.Config)
+	tlsConf.Certificates = []tls.Certificate{cert}
+
+	if s.Sec.TLSClientCAFile != "" {
+		pem, err := ioutil.ReadFile(s.Sec.TLSClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConf.ClientCAs = x509.NewCertPool()
+		if !tlsConf.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("No certificate found in tls_client_ca_file[%s].", s.Sec.TLSClientCAFile)
+		}
+		tlsConf.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConf, nil
+}
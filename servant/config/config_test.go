@@ -1,8 +1,13 @@
 package config
 
 import (
+	"crypto/tls"
+	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
+
+	"github.com/unirita/cuto/testutil"
 )
 
 func generateTestConfig() *ServantConfig {
@@ -264,3 +269,93 @@ func TestDetectError_ログファイル最大世代数が0以下の場合はエ
 		t.Error("エラーが発生しなかった。")
 	}
 }
+
+func TestDetectError_サーバ証明書と秘密鍵の片方だけが指定された場合はエラー(t *testing.T) {
+	c := generateTestConfig()
+	c.Sec.TLSKeyFile = `servant.key`
+	if err := c.DetectError(); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestDetectError_サーバ証明書なしでクライアントCA証明書が指定された場合はエラー(t *testing.T) {
+	c := generateTestConfig()
+	c.Sec.TLSClientCAFile = `ca.crt`
+	if err := c.DetectError(); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestTLSConfig_サーバ証明書が指定されない場合はnilを返す(t *testing.T) {
+	c := generateTestConfig()
+	tlsConf, err := c.TLSConfig()
+	if err != nil {
+		t.Fatalf("想定以外のエラーが発生した: %s", err)
+	}
+	if tlsConf != nil {
+		t.Error("TLS設定が生成された。")
+	}
+}
+
+func TestTLSConfig_クライアント証明書を要求するTLS設定を生成できる(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cuto_tls")
+	if err != nil {
+		t.Fatalf("テスト用のディレクトリ作成に失敗した: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	certFile, keyFile, err := testutil.GenerateTestCert(dir)
+	if err != nil {
+		t.Fatalf("テスト用の証明書作成に失敗した: %s", err)
+	}
+
+	c := generateTestConfig()
+	c.Sec.TLSCertFile = certFile
+	c.Sec.TLSKeyFile = keyFile
+	c.Sec.TLSClientCAFile = certFile
+	tlsConf, err := c.TLSConfig()
+	if err != nil {
+		t.Fatalf("想定以外のエラーが発生した: %s", err)
+	}
+	if len(tlsConf.Certificates) != 1 {
+		t.Errorf("サーバ証明書の数[%d]が想定と違っている。", len(tlsConf.Certificates))
+	}
+	if tlsConf.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Errorf("クライアント認証の設定[%v]が想定と違っている。", tlsConf.ClientAuth)
+	}
+}
+
+func TestDetectError_許可リストに相対パスが指定された場合はエラー(t *testing.T) {
+	c := generateTestConfig()
+	c.Sec.AllowedJobPaths = []string{`jobs`}
+	if err := c.DetectError(); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+}
+
+func TestLoadReader_許可リストを取得できる(t *testing.T) {
+	conf := `
+[security]
+restrict_job_path=1
+allowed_job_paths=['<CUTOROOT>/bin', '/opt/jobs/*.sh']
+deny_docker=1
+`
+	c, err := loadReader(strings.NewReader(conf))
+	if err != nil {
+		t.Fatalf("想定以外のエラーが発生した: %s", err)
+	}
+	if c.Sec.RestrictJobPath != 1 {
+		t.Errorf("restrict_job_pathの値[%d]が想定と違っている。", c.Sec.RestrictJobPath)
+	}
+	if c.Sec.DenyDocker != 1 {
+		t.Errorf("deny_dockerの値[%d]が想定と違っている。", c.Sec.DenyDocker)
+	}
+	if len(c.Sec.AllowedJobPaths) != 2 {
+		t.Fatalf("allowed_job_pathsの要素数[%d]が想定と違っている。", len(c.Sec.AllowedJobPaths))
+	}
+	if strings.Contains(c.Sec.AllowedJobPaths[0], "<CUTOROOT>") {
+		t.Errorf("allowed_job_pathsの<CUTOROOT>タグ[%s]が展開されていない。", c.Sec.AllowedJobPaths[0])
+	}
+	if c.Sec.AllowedJobPaths[1] != "/opt/jobs/*.sh" {
+		t.Errorf("allowed_job_pathsの値[%s]が想定と違っている。", c.Sec.AllowedJobPaths[1])
+	}
+}
@@ -0,0 +1,56 @@
+package job
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/unirita/cuto/message"
+)
+
+// servant.iniのsecurityセクションの設定に従い、ジョブの実行が許可されているかを検査する。
+//
+// restrict_job_pathが有効な場合、実行できるのはjob_dir配下のファイルと、allowed_job_pathsに合致するファイルのみとなる。
+// 相対パスで指定されたジョブファイルが..でjob_dirの外を指す場合も拒否する。
+//
+// return : 実行が許可されない場合はエラー情報。
+func (j *jobInstance) checkAllowed() error {
+	sec := j.config.Sec
+	if j.path == message.DockerTag {
+		if sec.DenyDocker != 0 {
+			return fmt.Errorf("Docker job is forbidden by servant.ini.")
+		}
+		return nil
+	}
+	if sec.RestrictJobPath == 0 {
+		return nil
+	}
+
+	path := j.path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(j.config.Dir.JobDir, path)
+	}
+	path = filepath.Clean(path)
+
+	if isUnderDir(j.config.Dir.JobDir, path) {
+		return nil
+	}
+	for _, allowed := range sec.AllowedJobPaths {
+		if isUnderDir(allowed, path) {
+			return nil
+		}
+		if ok, _ := filepath.Match(filepath.Clean(allowed), path); ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("Job path[%s] is not allowed by servant.ini.", j.path)
+}
+
+// pathがdir自身、またはdir配下のパスであるかを調べる。
+func isUnderDir(dir string, path string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
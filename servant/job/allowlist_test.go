@@ -0,0 +1,110 @@
+package job
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/message"
+)
+
+func createRestrictedJobInstance(path string) *jobInstance {
+	j := createTestJobInstance()
+	j.config.Dir.JobDir = filepath.Join(testJobPath, "jobscript")
+	j.config.Sec.RestrictJobPath = 1
+	j.config.Sec.AllowedJobPaths = []string{
+		filepath.Join(testJobPath, "allowed"),
+		filepath.Join(testJobPath, "bin", "*.sh"),
+	}
+	j.path = path
+	return j
+}
+
+func TestCheckAllowed_制限が無効な場合は全て許可する(t *testing.T) {
+	j := createTestJobInstance()
+	j.path = filepath.Join(testJobPath, "other", "job.sh")
+	if err := j.checkAllowed(); err != nil {
+		t.Errorf("想定外のエラーが発生した: %s", err)
+	}
+}
+
+func TestCheckAllowed_job_dir配下のジョブは許可する(t *testing.T) {
+	paths := []string{
+		"job.sh",
+		filepath.Join("sub", "job.sh"),
+		filepath.Join(testJobPath, "jobscript", "job.sh"),
+	}
+	for _, path := range paths {
+		j := createRestrictedJobInstance(path)
+		if err := j.checkAllowed(); err != nil {
+			t.Errorf("ジョブ[%s]で想定外のエラーが発生した: %s", path, err)
+		}
+	}
+}
+
+func TestCheckAllowed_許可リストに合致するジョブは許可する(t *testing.T) {
+	paths := []string{
+		filepath.Join(testJobPath, "allowed", "job.sh"),
+		filepath.Join(testJobPath, "allowed", "sub", "job.bat"),
+		filepath.Join(testJobPath, "bin", "job.sh"),
+	}
+	for _, path := range paths {
+		j := createRestrictedJobInstance(path)
+		if err := j.checkAllowed(); err != nil {
+			t.Errorf("ジョブ[%s]で想定外のエラーが発生した: %s", path, err)
+		}
+	}
+}
+
+func TestCheckAllowed_許可されないジョブはエラー(t *testing.T) {
+	paths := []string{
+		filepath.Join("..", "other", "job.sh"),
+		filepath.Join("sub", "..", "..", "job.sh"),
+		filepath.Join(testJobPath, "other", "job.sh"),
+		filepath.Join(testJobPath, "allowed_not", "job.sh"),
+		filepath.Join(testJobPath, "bin", "job.bat"),
+		filepath.Join(testJobPath, "bin", "sub", "job.sh"),
+	}
+	for _, path := range paths {
+		j := createRestrictedJobInstance(path)
+		if err := j.checkAllowed(); err == nil {
+			t.Errorf("ジョブ[%s]でエラーが発生しなかった。", path)
+		}
+	}
+}
+
+func TestCheckAllowed_dockerタグの禁止(t *testing.T) {
+	j := createRestrictedJobInstance(message.DockerTag)
+	if err := j.checkAllowed(); err != nil {
+		t.Errorf("禁止されていないのにエラーが発生した: %s", err)
+	}
+
+	j.config.Sec.DenyDocker = 1
+	if err := j.checkAllowed(); err == nil {
+		t.Error("禁止されているのにエラーが発生しなかった。")
+	}
+}
+
+func TestDoJobRequest_許可されないジョブは実行せず異常終了を返す(t *testing.T) {
+	c := *conf
+	c.Sec.RestrictJobPath = 1
+	req := &message.Request{
+		Type: "request",
+		NID:  204,
+		JID:  "serviceTask_004",
+		Path: filepath.Join("..", "job"),
+	}
+
+	stCh := make(chan string, 1)
+	res := DoJobRequest(req, &c, stCh, nil)
+	close(stCh)
+	if res.Stat != db.ABNORMAL {
+		t.Errorf("ステータス[%d]が想定と違っている。", res.Stat)
+	}
+	if len(res.Detail) == 0 {
+		t.Error("異常終了メッセージが存在しない。")
+	}
+	if len(res.St) != 0 {
+		t.Error("ジョブが実行された。")
+	}
+}
@@ -0,0 +1,135 @@
+package job
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/unirita/cuto/console"
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/message"
+	"github.com/unirita/cuto/servant/config"
+)
+
+// 要求の受付から返信までの間にあるジョブの管理情報
+// 実行枠の待機中や、実行終了後の結果作成中のジョブも含む。
+var active = struct {
+	sync.Mutex
+	jobs map[string]*jobInstance
+}{jobs: make(map[string]*jobInstance)}
+
+// 要求を受け付けたジョブを登録する。
+// 同じジョブの要求を受け付け済みの場合は登録せず、受け付け済みのジョブを返す。
+func (j *jobInstance) activate() *jobInstance {
+	active.Lock()
+	defer active.Unlock()
+	key := runningKey(j.nID, j.jID)
+	if prev, ok := active.jobs[key]; ok {
+		return prev
+	}
+	j.doneCh = make(chan struct{})
+	active.jobs[key] = j
+	return nil
+}
+
+// 返信するメッセージを確定させ、ジョブの登録を解除する。
+// 再接続中のマスタへは、同じメッセージが返信される。
+func (j *jobInstance) deactivate(res *message.Response) {
+	active.Lock()
+	defer active.Unlock()
+	j.response = res
+	close(j.doneCh)
+	key := runningKey(j.nID, j.jID)
+	if active.jobs[key] == j {
+		delete(active.jobs, key)
+	}
+}
+
+// 実行中ジョブへの再接続要求を受け付け、ジョブの終了を待って実行結果を返す。
+// ジョブが既に終了している場合はジャーナルに記録された実行結果を返し、
+// このサーバントで実行されていない場合は異常終了のメッセージを返す。
+//
+// param : a マスタからの再接続要求メッセージ。
+//
+// param : conf サーバントの設定情報。
+//
+// return : マスタへ返信するメッセージ。
+func DoJobAttach(a *message.Attach, conf *config.ServantConfig) *message.Response {
+	if res, ok := waitActiveJob(a.NID, a.JID); ok {
+		return res
+	}
+
+	entry, err := searchJournal(journalPath(conf.Dir.JoblogDir), a.NID, a.JID)
+	if err == nil && entry != nil && entry.State == journalEnded {
+		return createResponseFromJournal(entry)
+	}
+
+	console.Display("CTS036W", a.NID, a.JID)
+	res := new(message.Response)
+	res.NID = a.NID
+	res.JID = a.JID
+	res.Stat = db.ABNORMAL
+	res.Detail = detailNotRunning
+	return res
+}
+
+// 要求を受け付け済みのジョブがあるかを返す。
+func isActive(nid int, jid string) bool {
+	active.Lock()
+	defer active.Unlock()
+	_, ok := active.jobs[runningKey(nid, jid)]
+	return ok
+}
+
+// 要求を受け付け済みのジョブがあれば、その終了を待って返信するメッセージを返す。
+//
+// return : 返信するメッセージ。
+//
+// return : 要求を受け付け済みのジョブが無い場合はfalse。
+func waitActiveJob(nid int, jid string) (*message.Response, bool) {
+	active.Lock()
+	j, ok := active.jobs[runningKey(nid, jid)]
+	active.Unlock()
+	if !ok {
+		return nil, false
+	}
+	return j.waitResponse(), true
+}
+
+// ジョブの終了を待ち、返信するメッセージを返す。
+func (j *jobInstance) waitResponse() *message.Response {
+	console.Display("CTS035I", j.path, j.nID, j.jID)
+	<-j.doneCh
+	return j.response
+}
+
+// 再送された要求と同じIDの要求で実行済みのジョブがジャーナルに記録されていれば、その実行結果を返す。
+// 再送された要求でない場合や、実行済みでない場合はnilを返す。
+func (j *jobInstance) searchEndedResponse() *message.Response {
+	if !j.resent || j.reqID == "" {
+		return nil
+	}
+	entry, err := searchJournal(journalPath(j.config.Dir.JoblogDir), j.nID, j.jID)
+	if err != nil || entry == nil || entry.ReqID != j.reqID || entry.State != journalEnded {
+		return nil
+	}
+	console.Display("CTS041I", j.nID, j.jID)
+	return createResponseFromJournal(entry)
+}
+
+// ジャーナルに記録された終了済みジョブのレコードから、レスポンスメッセージを作成する。
+func createResponseFromJournal(entry *journalEntry) *message.Response {
+	res := new(message.Response)
+	res.NID = entry.NID
+	res.JID = entry.JID
+	res.RC = entry.RC
+	res.Stat = entry.Stat
+	res.Detail = entry.Detail
+	res.Var = entry.Var
+	res.Outputs = entry.Outputs
+	res.St = entry.St
+	res.Et = entry.Et
+	if entry.Joblog != "" {
+		res.JoblogFile = filepath.Base(entry.Joblog)
+	}
+	return res
+}
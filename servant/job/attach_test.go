@@ -0,0 +1,143 @@
+package job
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/message"
+)
+
+func TestDoJobAttach_WaitForActiveJob(t *testing.T) {
+	conf := makeJournalTestConfig(t)
+	defer os.RemoveAll(conf.Dir.JoblogDir)
+
+	j := &jobInstance{config: conf, nID: 1, jID: "job1", path: "test.sh"}
+	j.activate()
+
+	resCh := make(chan *message.Response, 1)
+	go func() {
+		resCh <- DoJobAttach(&message.Attach{NID: 1, JID: "job1"}, conf)
+	}()
+
+	select {
+	case <-resCh:
+		t.Fatal("DoJobAttach() returned before job ended.")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	expected := &message.Response{NID: 1, JID: "job1", RC: 4, Stat: db.WARN, Var: "testvar"}
+	j.deactivate(expected)
+
+	select {
+	case res := <-resCh:
+		if res != expected {
+			t.Errorf("DoJobAttach() => %v, wants %v", res, expected)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("DoJobAttach() did not return after job ended.")
+	}
+}
+
+func TestDoJobAttach_EndedJob(t *testing.T) {
+	conf := makeJournalTestConfig(t)
+	defer os.RemoveAll(conf.Dir.JoblogDir)
+
+	appendJournal(journalPath(conf.Dir.JoblogDir), &journalEntry{NID: 1, JID: "job1", State: journalEnded,
+		RC: 4, Stat: db.WARN, Var: "testvar", St: "2015-08-01 03:05:25.123", Et: "2015-08-01 03:34:56.789",
+		Joblog: "/path/to/1.test.job1.20150801120525.123.log"})
+
+	res := DoJobAttach(&message.Attach{NID: 1, JID: "job1"}, conf)
+	if res.RC != 4 {
+		t.Errorf("res.RC => %d, wants %d", res.RC, 4)
+	}
+	if res.Stat != db.WARN {
+		t.Errorf("res.Stat => %d, wants %d", res.Stat, db.WARN)
+	}
+	if res.Var != "testvar" {
+		t.Errorf("res.Var => %s, wants %s", res.Var, "testvar")
+	}
+	if res.Et != "2015-08-01 03:34:56.789" {
+		t.Errorf("res.Et => %s, wants %s", res.Et, "2015-08-01 03:34:56.789")
+	}
+	if res.JoblogFile != "1.test.job1.20150801120525.123.log" {
+		t.Errorf("res.JoblogFile => %s, wants %s", res.JoblogFile, "1.test.job1.20150801120525.123.log")
+	}
+}
+
+func TestDoJobAttach_NotRunningJob(t *testing.T) {
+	conf := makeJournalTestConfig(t)
+	defer os.RemoveAll(conf.Dir.JoblogDir)
+
+	appendJournal(journalPath(conf.Dir.JoblogDir), &journalEntry{NID: 1, JID: "job1", State: journalRunning,
+		ServantPID: os.Getpid() + 1})
+
+	res := DoJobAttach(&message.Attach{NID: 1, JID: "job1"}, conf)
+	if res.NID != 1 || res.JID != "job1" {
+		t.Errorf("Unexpected response: %v", res)
+	}
+	if res.Stat != db.ABNORMAL {
+		t.Errorf("res.Stat => %d, wants %d", res.Stat, db.ABNORMAL)
+	}
+	if res.Detail != detailNotRunning {
+		t.Errorf("res.Detail => %s, wants %s", res.Detail, detailNotRunning)
+	}
+}
+
+func TestActivate_ReturnsAlreadyActiveJob(t *testing.T) {
+	conf := makeJournalTestConfig(t)
+	defer os.RemoveAll(conf.Dir.JoblogDir)
+
+	j1 := &jobInstance{config: conf, nID: 2, jID: "job1", path: "test.sh"}
+	if prev := j1.activate(); prev != nil {
+		t.Fatalf("activate() => %v, wants nil", prev)
+	}
+	j2 := &jobInstance{config: conf, nID: 2, jID: "job1", path: "test.sh"}
+	if prev := j2.activate(); prev != j1 {
+		t.Errorf("activate() => %v, wants %v", prev, j1)
+	}
+
+	j1.deactivate(&message.Response{NID: 2, JID: "job1"})
+	j3 := &jobInstance{config: conf, nID: 2, jID: "job1", path: "test.sh"}
+	if prev := j3.activate(); prev != nil {
+		t.Errorf("activate() => %v, wants nil", prev)
+	}
+	j3.deactivate(&message.Response{NID: 2, JID: "job1"})
+}
+
+func TestSearchEndedResponse_ResentRequestAlreadyEnded(t *testing.T) {
+	conf := makeJournalTestConfig(t)
+	defer os.RemoveAll(conf.Dir.JoblogDir)
+
+	appendJournal(journalPath(conf.Dir.JoblogDir), &journalEntry{NID: 3, JID: "job1", ReqID: "req1", State: journalEnded,
+		RC: 4, Stat: db.WARN, Var: "testvar", Et: "2015-08-01 03:34:56.789"})
+
+	j := &jobInstance{config: conf, nID: 3, jID: "job1", reqID: "req1", resent: true}
+	res := j.searchEndedResponse()
+	if res == nil {
+		t.Fatal("searchEndedResponse() => nil, wants response")
+	}
+	if res.RC != 4 || res.Stat != db.WARN || res.Var != "testvar" {
+		t.Errorf("Unexpected response: %v", res)
+	}
+}
+
+func TestSearchEndedResponse_NotMatchedRequest(t *testing.T) {
+	conf := makeJournalTestConfig(t)
+	defer os.RemoveAll(conf.Dir.JoblogDir)
+
+	appendJournal(journalPath(conf.Dir.JoblogDir), &journalEntry{NID: 3, JID: "job1", ReqID: "req1", State: journalEnded})
+	appendJournal(journalPath(conf.Dir.JoblogDir), &journalEntry{NID: 3, JID: "job2", ReqID: "req2", State: journalRunning})
+
+	cases := []*jobInstance{
+		{config: conf, nID: 3, jID: "job1", reqID: "req1", resent: false},
+		{config: conf, nID: 3, jID: "job1", reqID: "other", resent: true},
+		{config: conf, nID: 3, jID: "job2", reqID: "req2", resent: true},
+	}
+	for _, j := range cases {
+		if res := j.searchEndedResponse(); res != nil {
+			t.Errorf("searchEndedResponse() => %v, wants nil [jid=%s reqid=%s resent=%v]", res, j.jID, j.reqID, j.resent)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package job
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/unirita/cuto/console"
+	"github.com/unirita/cuto/message"
+	"github.com/unirita/cuto/servant/config"
+)
+
+// 実行中ジョブの管理情報
+var running = struct {
+	sync.Mutex
+	jobs map[string]*jobInstance
+}{jobs: make(map[string]*jobInstance)}
+
+// 実行中ジョブを識別するキーを生成する。
+func runningKey(nID int, jID string) string {
+	return fmt.Sprintf("%d:%s", nID, jID)
+}
+
+// 受け付けたジョブを登録する。
+// 実行枠の空きを待っているジョブやプロセスの起動前のジョブは、processにnilを指定して登録する。
+func (j *jobInstance) register(process *os.Process) {
+	running.Lock()
+	defer running.Unlock()
+	j.process = process
+	j.cancelCh = make(chan struct{})
+	running.jobs[runningKey(j.nID, j.jID)] = j
+}
+
+// 登録済みのジョブに、起動したジョブプロセスとその開始日時をセットする。
+// 既に実行中止要求を受けている場合はセットせず、falseを返す。
+func (j *jobInstance) setProcess(process *os.Process, st string) bool {
+	running.Lock()
+	defer running.Unlock()
+	if j.cancelled {
+		return false
+	}
+	j.process = process
+	j.st = st
+	return true
+}
+
+// 実行を終了したジョブの登録を解除する。
+func (j *jobInstance) unregister() {
+	running.Lock()
+	defer running.Unlock()
+	key := runningKey(j.nID, j.jID)
+	if running.jobs[key] == j {
+		delete(running.jobs, key)
+	}
+}
+
+// ジョブが実行中止されたかを調べる。
+func (j *jobInstance) isCancelled() bool {
+	running.Lock()
+	defer running.Unlock()
+	return j.cancelled
+}
+
+// ジョブの実行中止要求を受け付け、実行中のジョブプロセスを強制終了する。
+//
+// param : c マスタからの実行中止要求メッセージ。
+//
+// param : conf サーバントの設定情報。
+//
+// return : マスタへ返信するメッセージ。
+func DoJobCancel(c *message.Cancel, conf *config.ServantConfig) *message.CancelResult {
+	result := new(message.CancelResult)
+	result.NID = c.NID
+	result.JID = c.JID
+
+	running.Lock()
+	defer running.Unlock()
+
+	j, ok := running.jobs[runningKey(c.NID, c.JID)]
+	if !ok {
+		console.Display("CTS025W", c.NID, c.JID)
+		result.Detail = "Job is not running."
+		return result
+	}
+
+	if j.cancelled {
+		result.Detail = "Job is already cancelled."
+		return result
+	}
+	if j.process == nil {
+		// 実行枠の空きを待っているジョブやプロセスの起動前のジョブは、起動させずに打ち切らせる
+		j.cancelled = true
+		close(j.cancelCh)
+		console.Display("CTS031I", j.path, c.NID, c.JID)
+		result.Cancelled = true
+		return result
+	}
+	if err := killProcessGroup(j.process); err != nil {
+		console.DisplayError("CTS019E", err)
+		result.Detail = err.Error()
+		return result
+	}
+	j.cancelled = true
+	close(j.cancelCh)
+	console.Display("CTS024I", j.path, c.NID, c.JID, j.process.Pid)
+	result.Cancelled = true
+	return result
+}
@@ -0,0 +1,50 @@
+package job
+
+import (
+	"os"
+	"testing"
+
+	"github.com/unirita/cuto/message"
+)
+
+func TestDoJobCancel_実行中でないジョブは中止できない(t *testing.T) {
+	cnl := &message.Cancel{NID: 999, JID: "notrunning"}
+	result := DoJobCancel(cnl, conf)
+	if result.Cancelled {
+		t.Error("実行中でないジョブが中止された。")
+	}
+	if result.NID != 999 || result.JID != "notrunning" {
+		t.Errorf("返信メッセージのID[%d, %s]が想定と違っている。", result.NID, result.JID)
+	}
+	if len(result.Detail) == 0 {
+		t.Error("詳細メッセージがセットされていない。")
+	}
+}
+
+func TestSetProcess_起動前に実行中止されたジョブにはプロセスをセットしない(t *testing.T) {
+	j := createTestJobInstance()
+	j.nID = 4321
+	j.register(nil)
+	defer j.unregister()
+
+	cnl := &message.Cancel{NID: 4321, JID: j.jID}
+	if result := DoJobCancel(cnl, conf); !result.Cancelled {
+		t.Fatalf("起動前のジョブを実行中止できなかった: %s", result.Detail)
+	}
+
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("予期しないエラーが発生した: %s", err)
+	}
+	if j.setProcess(process, "2015-04-01 12:34:56.789") {
+		t.Error("実行中止されたにも関わらず、プロセスがセットされた。")
+	}
+	if j.process != nil {
+		t.Error("実行中止されたジョブにプロセスがセットされている。")
+	}
+	select {
+	case <-j.cancelCh:
+	default:
+		t.Error("実行中止要求がチャネルへ通知されていない。")
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -38,24 +39,42 @@ type jobInstance struct {
 	errPtn          string                // 異常終了の文字列パターン
 	timeout         int                   // 実行タイムアウトまでの時間（秒）
 	jID             string                // ジョブID
+	reqID           string                // 多重化接続で要求を識別するID
+	resent          bool                  // 多重化接続の再接続後に再送された要求であればtrue
 	rc              int                   // ジョブの戻り値
 	stat            int                   // ジョブステータス
 	detail          string                // 異常終了時のメッセージ
 	variable        string                // 変数情報
+	outputs         map[string]string     // 名前付き出力
+	outputFile      string                // 名前付き出力JSONファイルのパス
 	st              string                // ジョブ開始日時
 	et              string                // ジョブ終了日時
 	joblog          string                // ジョブログ内容
 	joblogFile      string                // ジョブログファイル名
 	joblogTimestamp string                // ジョブログファイル名に使用するタイムスタンプ文字列
+	process         *os.Process           // 実行中のジョブプロセス
+	cancelled       bool                  // 実行中止要求を受けたかどうか
+	cancelCh        chan struct{}         // 実行中止要求を通知するチャネル
+	queue           *jobQueue             // 実行枠を獲得したキュー
+	queuePos        int                   // 実行枠の待ち順位（待機しなかった場合は0）
+	queueSec        int                   // 実行枠の待ち時間（秒）
+	outCh           chan<- string         // ジョブの出力を行単位で送信するチャネル。送信しない場合はnil。
+	doneCh          chan struct{}         // 返信するメッセージの確定を通知するチャネル
+	response        *message.Response     // マスタへ返信するメッセージ
 }
 
 var (
-	detailWarnRC  = "JOB-RC exceeded MAX-WarnRC."
-	detailErrRC   = "JOB-RC exceeded MAX-ErrRC."
-	detailWarnPtn = "JOB-OUTPUT matched Warning Pattern."
-	detailErrPtn  = "JOB-OUTPUT matched Error Pattern."
+	detailWarnRC     = "JOB-RC exceeded MAX-WarnRC."
+	detailErrRC      = "JOB-RC exceeded MAX-ErrRC."
+	detailWarnPtn    = "JOB-OUTPUT matched Warning Pattern."
+	detailErrPtn     = "JOB-OUTPUT matched Error Pattern."
+	detailCancel     = "JOB was cancelled."
+	detailNotRunning = "JOB is not running on servant."
 )
 
+// 強制終了したジョブプロセスの出力の読み込みが終わるまで待つ時間
+var killWaitTime = 5 * time.Second
+
 // 実行ジョブ情報のコンストラクタ
 func newJobInstance(req *message.Request, conf *config.ServantConfig) *jobInstance {
 	job := new(jobInstance)
@@ -71,6 +90,9 @@ func newJobInstance(req *message.Request, conf *config.ServantConfig) *jobInstan
 	job.errPtn = req.ErrStr
 	job.timeout = req.Timeout
 	job.jID = req.JID
+	job.reqID = req.ReqID
+	job.resent = req.Resent
+	job.outputFile = outputFilePath(conf.Dir.JoblogDir, job.nID, job.jID)
 
 	return job
 }
@@ -83,19 +105,58 @@ func newJobInstance(req *message.Request, conf *config.ServantConfig) *jobInstan
 //
 // param : stCh スタート時刻送信用チャンネル
 //
+// param : outCh ジョブの出力を行単位で送信するチャンネル。送信しない場合はnil。
+//
 // return : マスタへ返信するメッセージ。
-func DoJobRequest(req *message.Request, conf *config.ServantConfig, stCh chan<- string) *message.Response {
+func DoJobRequest(req *message.Request, conf *config.ServantConfig, stCh chan<- string, outCh chan<- string) *message.Response {
 	job := newJobInstance(req, conf)
-	if err := job.do(stCh); err != nil {
+	job.outCh = outCh
+	// 再接続したmasterから同じジョブの要求を再送された場合は、二重に実行せず終了を待つ
+	if prev := job.activate(); prev != nil {
+		return prev.waitResponse()
+	}
+	// 受付の通知が届かずに再送された要求は、実行済みであれば二重に実行せず実行結果を返す
+	if res := job.searchEndedResponse(); res != nil {
+		job.deactivate(res)
+		return res
+	}
+	job.writeJournal(journalAccepted, 0)
+	res := job.doRequest(stCh)
+	job.writeJournal(journalEnded, 0)
+	job.deactivate(res)
+	return res
+}
+
+// ジョブの実行可否を確認し、実行枠を獲得してから実行して、マスタへ返信するメッセージを作成する。
+func (j *jobInstance) doRequest(stCh chan<- string) *message.Response {
+	if err := j.checkAllowed(); err != nil {
+		console.Display("CTS028E", j.path, j.nID, j.jID, err)
+		j.stat = db.ABNORMAL
+		j.detail = err.Error()
+		return j.createResponse()
+	}
+	// 実行枠の待機中からプロセスの起動まで、実行中止要求を受け付けられるよう登録しておく
+	j.register(nil)
+	defer j.unregister()
+
+	acquired := j.acquireSlot()
+	defer j.releaseSlot()
+	if !acquired || j.isCancelled() {
+		j.stat = db.CANCELLED
+		j.detail = detailCancel
+		return j.createResponse()
+	}
+
+	if err := j.do(stCh); err != nil {
 		console.DisplayError("CTS019E", err)
-		job.stat = db.ABNORMAL
-		job.detail = err.Error()
-		return job.createResponse()
+		j.stat = db.ABNORMAL
+		j.detail = err.Error()
+		return j.createResponse()
 	}
 
-	console.Display("CTS011I", job.path, job.nID, job.jID, job.stat, job.rc)
-	job.setVariableValue()
-	return job.createResponse()
+	console.Display("CTS011I", j.path, j.nID, j.jID, j.stat, j.rc)
+	j.setVariableValue()
+	return j.createResponse()
 }
 
 func (j *jobInstance) do(stCh chan<- string) error {
@@ -105,6 +166,9 @@ func (j *jobInstance) do(stCh chan<- string) error {
 		return errors.New("Cannot execute job on Docker, because docker_command_path is lacked in servant.ini")
 	}
 
+	if err := prepareOutputFile(j.outputFile); err != nil {
+		log.Warn(fmt.Sprintf("Could not prepare output file[%s]: %s", j.outputFile, err))
+	}
 	if err := j.run(cmd, stCh); err != nil {
 		return err
 	}
@@ -115,6 +179,12 @@ func (j *jobInstance) do(stCh chan<- string) error {
 		}
 	}
 
+	if j.isCancelled() {
+		j.stat = db.CANCELLED
+		j.detail = detailCancel
+		return nil
+	}
+
 	// RCからの結果と、出力MSGの結果を比較し、大きい方（異常の方）を採用する
 	rcSt, rcMsg := j.judgeRC()
 	ptnSt, ptnMsg := j.judgeJoblog()
@@ -133,6 +203,7 @@ func (j *jobInstance) do(stCh chan<- string) error {
 func (j *jobInstance) createShell() *exec.Cmd {
 	shell, params := j.organizePathAndParam()
 	cmd := exec.Command(shell, params...)
+	setProcessGroup(cmd)
 
 	// 環境変数指定がない場合は、既存の物のみを追加する。
 	if len(j.env) > 0 {
@@ -141,6 +212,7 @@ func (j *jobInstance) createShell() *exec.Cmd {
 	} else {
 		cmd.Env = os.Environ()
 	}
+	cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", outputFileEnv, j.outputFile))
 	if len(j.workDir) > 0 {
 		cmd.Dir = j.workDir
 	} else {
@@ -203,26 +275,35 @@ func (j *jobInstance) organizePathAndParam() (string, []string) {
 // ジョブ実行を行い、そのリターンコードを返す。
 func (j *jobInstance) run(cmd *exec.Cmd, stCh chan<- string) error {
 	isJoblogDisabled := j.config.Job.DisuseJoblog != 0
-	outputBuffer := new(bytes.Buffer)
+	outputBuffer := new(lockedBuffer)
 
+	var outputWriter io.Writer = outputBuffer
 	if isJoblogDisabled {
-		outputWriter := io.MultiWriter(os.Stdout, outputBuffer)
-		cmd.Stdout = outputWriter
-		cmd.Stderr = outputWriter
-	} else {
-		cmd.Stdout = outputBuffer
-		cmd.Stderr = outputBuffer
+		outputWriter = io.MultiWriter(os.Stdout, outputBuffer)
+	}
+	if j.outCh != nil {
+		// 出力をリアルタイムにマスタへ送信する
+		lw := newLineWriter(j.outCh)
+		defer lw.Close()
+		outputWriter = io.MultiWriter(outputWriter, lw)
 	}
+	cmd.Stdout = outputWriter
+	cmd.Stderr = outputWriter
 
 	if err := cmd.Start(); err != nil {
 		return err
 	}
 	startTime := utctime.Now()
-	j.st = startTime.String() // ジョブ開始日時の取得
 	j.joblogTimestamp = startTime.FormatLocaltime(utctime.NoDelimiter)
+	// 開始時刻を受け取った直後の実行中止要求も受け付けられるよう、先にプロセスをセットする
+	if !j.setProcess(cmd.Process, startTime.String()) {
+		// 起動までの間に実行中止要求を受けた場合は、起動したプロセスを強制終了する
+		killProcessGroup(cmd.Process)
+	}
 	stCh <- j.st
 
 	console.Display("CTS010I", j.path, j.nID, j.jID, cmd.Process.Pid)
+	j.writeJournal(journalRunning, cmd.Process.Pid)
 
 	err := j.waitCmdTimeout(cmd)
 	j.et = utctime.Now().String() // ジョブ終了日時の取得
@@ -244,27 +325,57 @@ func (j *jobInstance) run(cmd *exec.Cmd, stCh chan<- string) error {
 }
 
 func (j *jobInstance) waitCmdTimeout(cmd *exec.Cmd) error {
-	if j.timeout == 0 {
-		// timeoutが0の場合はタイムアウトなしでジョブ終了を待つ
-		return cmd.Wait()
-	}
-
 	ch := make(chan error, 1)
 	go func() {
 		defer close(ch)
 		ch <- cmd.Wait()
 	}()
 
-	t := time.Duration(j.timeout) * time.Second
+	// timeoutが0の場合はタイムアウトなしでジョブ終了を待つ
+	var timeoutCh <-chan time.Time
+	if j.timeout > 0 {
+		timeoutCh = time.After(time.Duration(j.timeout) * time.Second)
+	}
+
 	select {
 	case err := <-ch:
 		return err
-	case <-time.After(t):
-		cmd.Process.Kill()
+	case <-timeoutCh:
+		killProcessGroup(cmd.Process)
+		waitKilled(ch)
 		return errors.New("Process timeout.")
+	case <-j.cancelCh:
+		waitKilled(ch)
+		return nil
 	}
+}
 
-	return nil
+// 強制終了したジョブプロセスの終了を待つ。
+// 別のプロセスグループへ移った子孫プロセスが出力を保持している場合に備え、一定時間で待機を打ち切る。
+func waitKilled(ch <-chan error) {
+	select {
+	case <-ch:
+	case <-time.After(killWaitTime):
+	}
+}
+
+// 排他制御を行うバッファ。
+// 待機を打ち切った後も、ジョブの出力を読み込むgoroutineが書き込みを続ける場合があるため使用する。
+type lockedBuffer struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.String()
 }
 
 // ジョブのRCを確認し、statを返す。
@@ -351,14 +462,19 @@ func (j *jobInstance) createResponse() *message.Response {
 	res.Stat = j.stat
 	res.Detail = j.detail
 	res.Var = j.variable
+	res.Outputs = j.outputs
 	res.St = j.st
 	res.Et = j.et
 	res.JoblogFile = filepath.Base(j.joblogFile)
+	res.QueuePos = j.queuePos
+	res.QueueSec = j.queueSec
 	return &res
 }
 
-// ジョブログファイルから変数情報を取得する。
+// ジョブログファイルから変数情報および名前付き出力を取得する。
 func (j *jobInstance) setVariableValue() {
+	j.outputs = collectOutputs(j.joblog, j.outputFile)
+
 	reader := strings.NewReader(j.joblog)
 	scanner := bufio.NewScanner(reader)
 	var line string
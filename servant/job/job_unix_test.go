@@ -10,6 +10,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/unirita/cuto/db"
 	"github.com/unirita/cuto/message"
@@ -32,7 +33,7 @@ func TestDoJobRequest_拡張子無しSHジョブが正常に実行できる(t *t
 		ErrStr:    "ERR",
 	}
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	if len(<-stCh) == 0 {
 		t.Error("ジョブ開始時間が送信されていない.")
 	}
@@ -100,7 +101,7 @@ func TestDoJobRequest_パス指定ありCSHジョブが正常に実行できる(
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.RC != 0 {
 		t.Errorf("ジョブが正常終了するはずなのに異常終了した. - %v", res.RC)
@@ -142,7 +143,7 @@ func TestDoJobRequest_存在しないジョブ(t *testing.T) {
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.RC != 0 {
 		t.Error("実行失敗の場合、RCは0のはず.")
@@ -176,7 +177,7 @@ func TestDoJobRequest_RCで警告終了するジョブ_閾値と同じ(t *testin
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.RC != 4 {
 		t.Errorf("RCは4のはず. - %v", res.RC)
@@ -203,6 +204,17 @@ func TestDoJobRequest_RCで警告終了するジョブ_閾値と同じ(t *testin
 		t.Error("ジョブログが存在しない.")
 	}
 	defer file.Close()
+
+	entry, err := searchJournal(journalPath(conf.Dir.JoblogDir), res.NID, res.JID)
+	if err != nil || entry == nil {
+		t.Fatalf("ジョブの実行状況がジャーナルに記録されていない。%v", err)
+	}
+	if entry.State != journalEnded || entry.Stat != res.Stat || entry.RC != res.RC || entry.Var != res.Var {
+		t.Errorf("ジャーナルに記録された実行結果%vが想定と違っている。", entry)
+	}
+	if entry.St != res.St || entry.Et != res.Et {
+		t.Errorf("ジャーナルに記録された開始・終了日時[%s, %s]が想定と違っている。", entry.St, entry.Et)
+	}
 }
 func TestDoJobRequest_RCで異常終了するジョブ_閾値と同じ(t *testing.T) {
 	req := &message.Request{
@@ -220,7 +232,7 @@ func TestDoJobRequest_RCで異常終了するジョブ_閾値と同じ(t *testin
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.RC != 12 {
 		t.Errorf("RCは12のはず. - %v", res.RC)
@@ -265,7 +277,7 @@ func TestDoJobRequest_標準出力で警告終了するジョブ_RC確認なし(
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.RC != 0 {
 		t.Error("RCは0のはず.")
@@ -309,7 +321,7 @@ func TestDoJobRequest_標準エラー出力で異常終了するzshジョブ_RC
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.RC != 0 {
 		t.Error("RCは0のはず.")
@@ -355,7 +367,7 @@ func TestDoJobRequest_タイムアウト時間を超えたら異常終了する(
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.RC != 0 {
 		t.Errorf("戻り値が想定外 - %v", res.RC)
@@ -367,3 +379,99 @@ func TestDoJobRequest_タイムアウト時間を超えたら異常終了する(
 		t.Error("異常メッセージがセットされていない。")
 	}
 }
+
+func TestDoJobCancel_実行中のジョブを中止できる(t *testing.T) {
+	req := &message.Request{
+		Type:      "request",
+		NID:       209,
+		JID:       "serviceTask_001",
+		Path:      "twosec.sh",
+		Workspace: testJobPath,
+	}
+
+	stCh := make(chan string, 1)
+	resCh := make(chan *message.Response, 1)
+	go func() {
+		resCh <- DoJobRequest(req, conf, stCh, nil)
+	}()
+	<-stCh
+
+	cnl := &message.Cancel{NID: 209, JID: "serviceTask_001"}
+	result := DoJobCancel(cnl, conf)
+	if !result.Cancelled {
+		t.Errorf("ジョブが中止されなかった。 - %s", result.Detail)
+	}
+
+	res := <-resCh
+	close(stCh)
+	if res.Stat != db.CANCELLED {
+		t.Errorf("ステータス[%d]が想定と違っている。", res.Stat)
+	}
+}
+
+func TestDoJobCancel_ジョブが起動した子孫プロセスも強制終了できる(t *testing.T) {
+	req := &message.Request{
+		Type:      "request",
+		NID:       214,
+		JID:       "serviceTask_001",
+		Path:      "twosec.sh",
+		Workspace: testJobPath,
+	}
+
+	stCh := make(chan string, 1)
+	outCh := make(chan string, 10)
+	resCh := make(chan *message.Response, 1)
+	go func() {
+		resCh <- DoJobRequest(req, conf, stCh, outCh)
+	}()
+	<-stCh
+	// シェルがsleepコマンドを起動するまで待つ
+	<-outCh
+	time.Sleep(100 * time.Millisecond)
+
+	start := time.Now()
+	cnl := &message.Cancel{NID: 214, JID: "serviceTask_001"}
+	if result := DoJobCancel(cnl, conf); !result.Cancelled {
+		t.Fatalf("ジョブが中止されなかった。 - %s", result.Detail)
+	}
+
+	res := <-resCh
+	close(stCh)
+	close(outCh)
+	if res.Stat != db.CANCELLED {
+		t.Errorf("ステータス[%d]が想定と違っている。", res.Stat)
+	}
+	// 子孫プロセスが残って出力を保持している場合は、待機の打ち切りまで終了しない
+	if elapsed := time.Since(start); elapsed >= killWaitTime {
+		t.Errorf("子孫プロセスの終了を待って[%v]経過した。", elapsed)
+	}
+}
+
+func TestDoJobRequest_ジョブの出力を行単位で送信できる(t *testing.T) {
+	req := &message.Request{
+		Type:  "request",
+		NID:   210,
+		JID:   "serviceTask_001",
+		Path:  "job",
+		Param: "XX 0",
+		Env:   "TESTENV1=ENVENV+ENV0=AAAAA+ENV2=BBBB",
+	}
+
+	stCh := make(chan string, 1)
+	outCh := make(chan string, 10)
+	res := DoJobRequest(req, conf, stCh, outCh)
+	close(stCh)
+	close(outCh)
+	if res.Stat != db.NORMAL {
+		t.Fatalf("ステータス[%d]が想定と違っている。 - %s", res.Stat, res.Detail)
+	}
+
+	var lines []string
+	for line := range outCh {
+		lines = append(lines, line)
+	}
+	expected := []string{"AAAAA", "BBBB", "ENVENV XX"}
+	if len(lines) < len(expected) || strings.Join(lines[:len(expected)], ",") != strings.Join(expected, ",") {
+		t.Errorf("送信された出力%vが想定と違っている。", lines)
+	}
+}
@@ -31,7 +31,7 @@ func TestDoJobRequest_ジョブが正常に実行できる(t *testing.T) {
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	if len(<-stCh) == 0 {
 		t.Error("ジョブ開始時間が送信されていない.")
 	}
@@ -99,7 +99,7 @@ func TestDoJobRequest_パス指定あり引数なしジョブが正常に実行
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.RC != 0 {
 		t.Error("ジョブが正常終了するはずなのに異常終了した.")
@@ -141,7 +141,7 @@ func TestDoJobRequest_存在しないジョブ(t *testing.T) {
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.RC != 0 {
 		t.Error("実行失敗の場合、RCは0のはず.")
@@ -176,7 +176,7 @@ func TestDoJobRequest_RCで警告終了するジョブ_閾値と同じ(t *testin
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.RC != 4 {
 		t.Error("RCは4のはず.")
@@ -223,7 +223,7 @@ func TestDoJobRequest_RCで警告終了するがチェックしないジョブ(t
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.RC != 4 {
 		t.Error("RCは4のはず.")
@@ -266,7 +266,7 @@ func TestDoJobRequest_RCで警告終了しないジョブ_閾値未満(t *testin
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.RC != 3 {
 		t.Error("RCは3のはず.")
@@ -311,7 +311,7 @@ func TestDoJobRequest_標準出力で警告終了するジョブ_RC確認なし(
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.RC != 0 {
 		t.Errorf("RCは0のはず. RC(%v)", res.RC)
@@ -358,7 +358,7 @@ func TestDoJobRequest_標準出力で警告終了するがチェックしない
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.RC != 0 {
 		t.Error("RCは0のはず.")
@@ -403,7 +403,7 @@ func TestDoJobRequest_JSジョブが正常に実行できる(t *testing.T) {
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.RC != 0 {
 		t.Errorf("ジョブが正常終了するはずなのに異常終了した. RC(%v)", res.RC)
@@ -448,7 +448,7 @@ func TestDoJobRequest_標準エラー出力で警告終了するVBSジョブ_RC
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.RC != 0 {
 		t.Error("RCは0のはず.")
@@ -495,7 +495,7 @@ func TestDoJobRequest_RCで異常終了するジョブ_閾値と同じ(t *testin
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.RC != 12 {
 		t.Error("RCは12のはず.")
@@ -540,7 +540,7 @@ func TestDoJobRequest_RCで異常終了するがチェックしないジョブ(t
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.RC != 12 {
 		t.Error("RCは12のはず.")
@@ -585,7 +585,7 @@ func TestDoJobRequest_RCで異常終了して標準出力で警告終了する
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.RC != 12 {
 		t.Error("RCは12のはず.")
@@ -630,7 +630,7 @@ func TestDoJobRequest_RCで警告終了して標準出力で異常終了する
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.RC != 11 {
 		t.Error("RCは11のはず.")
@@ -675,7 +675,7 @@ func TestDoJobRequest_日本語ジョブ(t *testing.T) {
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.RC != 100 {
 		t.Error("RCは100のはず.なのに", res.RC)
@@ -720,7 +720,7 @@ func TestDoJobRequest_powershellジョブを実行(t *testing.T) {
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if req.NID != res.NID {
 		t.Error("NIDがリクエストとレスポンスで異なる.")
@@ -770,7 +770,7 @@ func TestDoJobRequest_タイムアウト時間を超えたら異常終了する(
 	}
 
 	stCh := make(chan string, 1)
-	res := DoJobRequest(req, conf, stCh)
+	res := DoJobRequest(req, conf, stCh, nil)
 	close(stCh)
 	if res.Stat != db.ABNORMAL {
 		t.Error("異常終了するはずが、正常終了している。")
@@ -11,12 +11,60 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/unirita/cuto/db"
 	"github.com/unirita/cuto/message"
 	"github.com/unirita/cuto/servant/config"
 	"github.com/unirita/cuto/utctime"
 )
 
+// ジョブの実行結果確認要求を受け付け、ジャーナルに記録された最新の実行状況を返す。
+// ジャーナルに記録が無い場合は、ジャーナル導入前に実行されたジョブとみなし、サーバントのログから実行結果を取得する。
+//
+// param : chk マスタからの実行結果確認要求メッセージ。
+//
+// param : conf サーバントの設定情報。
+//
+// return : マスタへ返信するメッセージ。
 func DoJobResultCheck(chk *message.JobCheck, conf *config.ServantConfig) *message.JobResult {
+	entry, err := searchJournal(journalPath(conf.Dir.JoblogDir), chk.NID, chk.JID)
+	if err != nil {
+		return createErrorResult(chk.NID, chk.JID)
+	}
+	if entry == nil {
+		return checkJobResultFromLog(chk, conf)
+	}
+	return createResultFromJournal(entry)
+}
+
+// ジャーナルのレコードから実行結果を作成する。
+// 終了していないジョブは、このサーバントが要求を受け付けて実行中である場合に実行中とし、
+// サーバントの再起動等で実行が中断された場合は異常終了とする。
+// 以前のサーバントが起動したジョブのプロセスが残っていても、実行結果を返せないため、再接続要求と同様に実行中とはしない。
+func createResultFromJournal(entry *journalEntry) *message.JobResult {
+	result := new(message.JobResult)
+	result.NID = entry.NID
+	result.JID = entry.JID
+	result.St = entry.St
+
+	if entry.State == journalEnded {
+		result.Stat = entry.Stat
+		result.RC = entry.RC
+		result.Var = entry.Var
+		result.Outputs = entry.Outputs
+		result.Et = entry.Et
+		return result
+	}
+
+	if entry.ServantPID == os.Getpid() && isActive(entry.NID, entry.JID) {
+		result.Stat = db.RUNNING
+	} else {
+		result.Stat = db.ABNORMAL
+	}
+	return result
+}
+
+// サーバントのログに出力されたジョブ終了メッセージから実行結果を作成する。
+func checkJobResultFromLog(chk *message.JobCheck, conf *config.ServantConfig) *message.JobResult {
 	result := new(message.JobResult)
 	result.NID = chk.NID
 	result.JID = chk.JID
@@ -61,6 +109,7 @@ func DoJobResultCheck(chk *message.JobCheck, conf *config.ServantConfig) *messag
 		return createErrorResult(chk.NID, chk.JID)
 	}
 	result.Var = variable
+	result.Outputs = extractOutputsFromJoblog(joblog, outputFilePath(conf.Dir.JoblogDir, chk.NID, chk.JID))
 
 	return result
 }
@@ -176,6 +225,14 @@ func extractVariableFromJoblog(joblog string) (string, error) {
 	return variable, nil
 }
 
+func extractOutputsFromJoblog(joblog string, outputFile string) map[string]string {
+	content, err := ioutil.ReadFile(joblog)
+	if err != nil {
+		return nil
+	}
+	return collectOutputs(string(content), outputFile)
+}
+
 func createUnexecutedResult(nid int, jid string) *message.JobResult {
 	result := new(message.JobResult)
 	result.NID = nid
@@ -0,0 +1,137 @@
+package job
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/unirita/cuto/message"
+	"github.com/unirita/cuto/servant/config"
+)
+
+// ジョブログ取得結果として返信する内容の最大サイズ。超過した場合は末尾を優先して返信する。
+const maxJoblogContentSize = 1024 * 1024
+
+// ジョブログ取得要求を受け付け、該当するジョブログの内容を返す。
+// 同一インスタンスID、ジョブIDのジョブログが複数存在する場合は、最新のものを対象とする。
+//
+// param : j マスタからのジョブログ取得要求メッセージ。
+//
+// param : conf サーバントの設定情報。
+//
+// return : マスタへ返信するメッセージ。
+func DoJoblogFetch(j *message.Joblog, conf *config.ServantConfig) *message.JoblogResult {
+	result := new(message.JoblogResult)
+	result.NID = j.NID
+	result.JID = j.JID
+
+	if j.Tail < 0 || j.From < 0 || j.To < 0 {
+		result.Detail = "Negative line number is specified."
+		return result
+	}
+	if j.Tail > 0 && (j.From > 0 || j.To > 0) {
+		result.Detail = "Tail and range cannot be specified at the same time."
+		return result
+	}
+	if j.To > 0 && j.From > j.To {
+		result.Detail = fmt.Sprintf("Invalid line range [%d-%d].", j.From, j.To)
+		return result
+	}
+
+	path, err := searchJoblogAllDays(conf.Dir.JoblogDir, j.NID, j.JID)
+	if err != nil {
+		result.Detail = err.Error()
+		return result
+	}
+	result.File = filepath.Base(path)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		result.Detail = err.Error()
+		return result
+	}
+	lines := splitLines(data)
+	if j.Tail > 0 {
+		if j.Tail < len(lines) {
+			lines = lines[len(lines)-j.Tail:]
+		}
+	} else {
+		lines = selectLines(lines, j.From, j.To)
+	}
+
+	content := bytes.Join(lines, nil)
+	if len(content) > maxJoblogContentSize {
+		content = content[len(content)-maxJoblogContentSize:]
+		result.Truncated = true
+	}
+	result.Content = string(content)
+	return result
+}
+
+// joblogDir配下の全ての日付ディレクトリから、最新のジョブログファイルを探す。
+func searchJoblogAllDays(joblogDir string, nid int, jid string) (string, error) {
+	dirInfos, err := ioutil.ReadDir(joblogDir)
+	if err != nil {
+		return "", err
+	}
+	dirNames := make([]string, 0, len(dirInfos))
+	for _, info := range dirInfos {
+		if info.IsDir() {
+			dirNames = append(dirNames, info.Name())
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(dirNames)))
+
+	matcher := regexp.MustCompile(fmt.Sprintf(`^%d\.[^.]+\.%s\.`, nid, regexp.QuoteMeta(jid)))
+	for _, dirName := range dirNames {
+		dir := filepath.Join(joblogDir, dirName)
+		fileInfos, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		var path string
+		for _, fileInfo := range fileInfos {
+			if matcher.MatchString(fileInfo.Name()) {
+				path = fileInfo.Name()
+			}
+		}
+		if path != "" {
+			return filepath.Join(dir, path), nil
+		}
+	}
+
+	return "", fmt.Errorf("Joblog file not found.")
+}
+
+// 改行コードを残したまま、データを行単位に分割する。
+func splitLines(data []byte) [][]byte {
+	lines := make([][]byte, 0)
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			lines = append(lines, data)
+			break
+		}
+		lines = append(lines, data[:i+1])
+		data = data[i+1:]
+	}
+	return lines
+}
+
+// from行目からto行目までを取り出す。0の場合は範囲を制限しない。
+func selectLines(lines [][]byte, from int, to int) [][]byte {
+	if to > 0 && to < len(lines) {
+		lines = lines[:to]
+	}
+	if from > 1 {
+		if from > len(lines) {
+			return nil
+		}
+		lines = lines[from-1:]
+	}
+	return lines
+}
@@ -0,0 +1,146 @@
+package job
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unirita/cuto/message"
+	"github.com/unirita/cuto/servant/config"
+)
+
+func createJoblogTestConfig(t *testing.T) (*config.ServantConfig, func()) {
+	dir, err := ioutil.TempDir("", "cuto_joblog")
+	if err != nil {
+		t.Fatalf("一時ディレクトリの作成に失敗した: %s", err)
+	}
+	files := map[string]string{
+		filepath.Join("20150801", "1.testjob.job1.20150801090525.123.log"): "old\n",
+		filepath.Join("20150802", "1.testjob.job1.20150802120525.123.log"): "line1\nline2\nline3\nline4\nline5\n",
+		filepath.Join("20150802", "2.testjob.job1.20150802130000.000.log"): "other\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("ジョブログの作成に失敗した: %s", err)
+		}
+	}
+
+	conf := new(config.ServantConfig)
+	conf.Dir.JoblogDir = dir
+	return conf, func() { os.RemoveAll(dir) }
+}
+
+func TestDoJoblogFetch_最新のジョブログ全体を取得できる(t *testing.T) {
+	conf, cleanup := createJoblogTestConfig(t)
+	defer cleanup()
+
+	result := DoJoblogFetch(&message.Joblog{NID: 1, JID: "job1"}, conf)
+	if result.Detail != "" {
+		t.Fatalf("想定外のエラーが発生した: %s", result.Detail)
+	}
+	if result.File != "1.testjob.job1.20150802120525.123.log" {
+		t.Errorf("ファイル名[%s]が想定と違っている。", result.File)
+	}
+	if result.Content != "line1\nline2\nline3\nline4\nline5\n" {
+		t.Errorf("取得内容[%s]が想定と違っている。", result.Content)
+	}
+	if result.Truncated {
+		t.Error("切り詰めていないのに切り詰めフラグが立っている。")
+	}
+}
+
+func TestDoJoblogFetch_末尾から指定行数を取得できる(t *testing.T) {
+	conf, cleanup := createJoblogTestConfig(t)
+	defer cleanup()
+
+	result := DoJoblogFetch(&message.Joblog{NID: 1, JID: "job1", Tail: 2}, conf)
+	if result.Content != "line4\nline5\n" {
+		t.Errorf("取得内容[%s]が想定と違っている。", result.Content)
+	}
+
+	result = DoJoblogFetch(&message.Joblog{NID: 1, JID: "job1", Tail: 10}, conf)
+	if result.Content != "line1\nline2\nline3\nline4\nline5\n" {
+		t.Errorf("取得内容[%s]が想定と違っている。", result.Content)
+	}
+}
+
+func TestDoJoblogFetch_行範囲を指定して取得できる(t *testing.T) {
+	conf, cleanup := createJoblogTestConfig(t)
+	defer cleanup()
+
+	cases := []struct {
+		from     int
+		to       int
+		expected string
+	}{
+		{2, 3, "line2\nline3\n"},
+		{4, 0, "line4\nline5\n"},
+		{0, 1, "line1\n"},
+		{5, 9, "line5\n"},
+		{6, 0, ""},
+	}
+	for _, c := range cases {
+		result := DoJoblogFetch(&message.Joblog{NID: 1, JID: "job1", From: c.from, To: c.to}, conf)
+		if result.Detail != "" {
+			t.Errorf("範囲[%d-%d]で想定外のエラーが発生した: %s", c.from, c.to, result.Detail)
+		}
+		if result.Content != c.expected {
+			t.Errorf("範囲[%d-%d]の取得内容[%s]が想定と違っている。", c.from, c.to, result.Content)
+		}
+	}
+}
+
+func TestDoJoblogFetch_不正な指定はエラー(t *testing.T) {
+	conf, cleanup := createJoblogTestConfig(t)
+	defer cleanup()
+
+	reqs := []*message.Joblog{
+		{NID: 1, JID: "job1", Tail: 1, From: 1},
+		{NID: 1, JID: "job1", From: 3, To: 2},
+		{NID: 1, JID: "job1", Tail: -1},
+	}
+	for _, req := range reqs {
+		result := DoJoblogFetch(req, conf)
+		if result.Detail == "" {
+			t.Errorf("要求[%+v]でエラーが発生しなかった。", req)
+		}
+		if result.Content != "" {
+			t.Errorf("要求[%+v]で内容が返された。", req)
+		}
+	}
+}
+
+func TestDoJoblogFetch_ジョブログが存在しない場合はエラー(t *testing.T) {
+	conf, cleanup := createJoblogTestConfig(t)
+	defer cleanup()
+
+	result := DoJoblogFetch(&message.Joblog{NID: 1, JID: "job9"}, conf)
+	if result.Detail != "Joblog file not found." {
+		t.Errorf("エラー内容[%s]が想定と違っている。", result.Detail)
+	}
+}
+
+func TestDoJoblogFetch_サイズ上限を超える場合は末尾を返す(t *testing.T) {
+	conf, cleanup := createJoblogTestConfig(t)
+	defer cleanup()
+
+	big := strings.Repeat("x", maxJoblogContentSize) + "\nlast\n"
+	path := filepath.Join(conf.Dir.JoblogDir, "20150803", "3.testjob.job1.20150803000000.000.log")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	ioutil.WriteFile(path, []byte(big), 0644)
+
+	result := DoJoblogFetch(&message.Joblog{NID: 3, JID: "job1"}, conf)
+	if !result.Truncated {
+		t.Error("切り詰めフラグが立っていない。")
+	}
+	if len(result.Content) != maxJoblogContentSize {
+		t.Errorf("取得内容のサイズ[%d]が想定と違っている。", len(result.Content))
+	}
+	if !strings.HasSuffix(result.Content, "\nlast\n") {
+		t.Error("末尾が返されていない。")
+	}
+}
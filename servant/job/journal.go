@@ -0,0 +1,237 @@
+package job
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/unirita/cuto/console"
+	"github.com/unirita/cuto/utctime"
+)
+
+// ジョブの実行状況
+const (
+	journalAccepted = "accepted" // 要求を受け付け、実行を開始していない
+	journalRunning  = "running"  // 実行中
+	journalEnded    = "ended"    // 終了済み
+)
+
+// ジャーナルファイル名
+const journalFileName = "servant.journal"
+
+// 終了済みジョブのジャーナルを保持する日数
+const journalKeepDays = 31
+
+// ジャーナルファイルへの書き込みと、journalIndexへのアクセスを排他する
+var journalLock sync.Mutex
+
+// ジャーナルファイルのパス毎に、ジョブ毎の最新のレコードを保持する索引。
+// 初回の検索時にジャーナルファイルから作成し、以降はレコードの追記に合わせて更新する。
+var journalIndex = make(map[string]map[string]*journalEntry)
+
+// サーバントが受け付けたジョブの実行状況を表す、ジャーナルの1レコード
+type journalEntry struct {
+	NID        int               `json:"nid"`
+	JID        string            `json:"jid"`
+	ReqID      string            `json:"reqid,omitempty"`
+	State      string            `json:"state"`
+	ServantPID int               `json:"servant_pid"`
+	PID        int               `json:"pid,omitempty"`
+	Path       string            `json:"path"`
+	RC         int               `json:"rc"`
+	Stat       int               `json:"stat"`
+	Detail     string            `json:"detail,omitempty"`
+	Var        string            `json:"var,omitempty"`
+	Outputs    map[string]string `json:"outputs,omitempty"`
+	St         string            `json:"st,omitempty"`
+	Et         string            `json:"et,omitempty"`
+	Joblog     string            `json:"joblog,omitempty"`
+	Time       string            `json:"time"`
+}
+
+// ジャーナルファイルのパスを返す。
+func journalPath(joblogDir string) string {
+	return filepath.Join(joblogDir, journalFileName)
+}
+
+// ジョブの実行状況をジャーナルへ記録する。
+// 記録に失敗してもジョブの実行は継続する。
+//
+// param : state ジョブの実行状況。
+//
+// param : pid ジョブのプロセスID。実行中でない場合は0。
+func (j *jobInstance) writeJournal(state string, pid int) {
+	e := &journalEntry{
+		NID:        j.nID,
+		JID:        j.jID,
+		ReqID:      j.reqID,
+		State:      state,
+		ServantPID: os.Getpid(),
+		PID:        pid,
+		Path:       j.path,
+		St:         j.st,
+		Time:       utctime.Now().String(),
+	}
+	if state == journalEnded {
+		e.RC = j.rc
+		e.Stat = j.stat
+		e.Detail = j.detail
+		e.Var = j.variable
+		e.Outputs = j.outputs
+		e.Et = j.et
+		e.Joblog = j.joblogFile
+	}
+
+	if err := appendJournal(journalPath(j.config.Dir.JoblogDir), e); err != nil {
+		console.Display("CTS033W", j.nID, j.jID, err)
+	}
+}
+
+// ジャーナルファイルの末尾へレコードを追記する。
+// サーバントが異常終了しても記録が失われないよう、追記の都度ディスクへ書き出す。
+func appendJournal(path string, e *journalEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	journalLock.Lock()
+	defer journalLock.Unlock()
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+
+	if index, ok := journalIndex[path]; ok {
+		index[runningKey(e.NID, e.JID)] = e
+	}
+	return nil
+}
+
+// ジャーナルファイルの全レコードを読み込む。ファイルが存在しない場合はnilを返す。
+// 書き込み途中で中断した行など、解析できない行は読み飛ばす。
+// journalLockを取得した状態で呼び出すこと。
+func readJournal(path string) ([]*journalEntry, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []*journalEntry
+	s := bufio.NewScanner(file)
+	s.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for s.Scan() {
+		e := new(journalEntry)
+		if err := json.Unmarshal(s.Bytes(), e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, s.Err()
+}
+
+// ジャーナルから、指定したジョブの最新のレコードを取得する。該当するレコードが無い場合はnilを返す。
+// ジャーナルファイルを読み込むのは索引の作成時のみで、以降は索引から取得する。
+// 取得したレコードは索引と共有しているため、呼び出し元で変更しないこと。
+func searchJournal(path string, nid int, jid string) (*journalEntry, error) {
+	journalLock.Lock()
+	defer journalLock.Unlock()
+
+	index, ok := journalIndex[path]
+	if !ok {
+		entries, err := readJournal(path)
+		if err != nil {
+			return nil, err
+		}
+		index = makeJournalIndex(entries)
+		journalIndex[path] = index
+	}
+	return index[runningKey(nid, jid)], nil
+}
+
+// レコードの一覧から、ジョブ毎の最新のレコードの索引を作成する。
+func makeJournalIndex(entries []*journalEntry) map[string]*journalEntry {
+	index := make(map[string]*journalEntry)
+	for _, e := range entries {
+		index[runningKey(e.NID, e.JID)] = e
+	}
+	return index
+}
+
+// ジャーナルファイルを、ジョブ毎の最新のレコードのみに縮約する。
+// 保持日数を過ぎた終了済みジョブのレコードは削除する。
+// サーバント起動時、ジョブを受け付ける前に呼び出す。
+//
+// param : joblogDir ジョブログ出力先フォルダ。
+//
+// return : エラー情報。
+func CompactJournal(joblogDir string) error {
+	journalLock.Lock()
+	defer journalLock.Unlock()
+
+	path := journalPath(joblogDir)
+	entries, err := readJournal(path)
+	if err != nil || entries == nil {
+		return err
+	}
+
+	limit := utctime.Now().AddDays(-journalKeepDays).String()
+	latest := make(map[string]int)
+	for i, e := range entries {
+		latest[runningKey(e.NID, e.JID)] = i
+	}
+
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	var kept []*journalEntry
+	w := bufio.NewWriter(file)
+	for i, e := range entries {
+		if latest[runningKey(e.NID, e.JID)] != i {
+			continue
+		}
+		if e.State == journalEnded && e.Time < limit {
+			continue
+		}
+		kept = append(kept, e)
+		b, err := json.Marshal(e)
+		if err != nil {
+			file.Close()
+			return err
+		}
+		w.Write(append(b, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("Could not replace journal file [%s]: %s", path, err)
+	}
+	journalIndex[path] = makeJournalIndex(kept)
+	return nil
+}
@@ -0,0 +1,272 @@
+package job
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/message"
+	"github.com/unirita/cuto/servant/config"
+	"github.com/unirita/cuto/utctime"
+)
+
+func makeJournalTestConfig(t *testing.T) *config.ServantConfig {
+	dir, err := ioutil.TempDir("", "cuto_journal")
+	if err != nil {
+		t.Fatalf("Could not create temp dir: %s", err)
+	}
+	conf := config.DefaultServantConfig()
+	conf.Dir.JoblogDir = dir
+	conf.Dir.LogDir = dir
+	return conf
+}
+
+func TestWriteJournal_SearchLatestEntry(t *testing.T) {
+	conf := makeJournalTestConfig(t)
+	defer os.RemoveAll(conf.Dir.JoblogDir)
+
+	j := &jobInstance{config: conf, nID: 1, jID: "job1", path: "test.sh"}
+	j.writeJournal(journalAccepted, 0)
+	j.st = "2015-08-01 03:05:25.123"
+	j.writeJournal(journalRunning, 1234)
+	other := &jobInstance{config: conf, nID: 1, jID: "job2", path: "test.sh"}
+	other.writeJournal(journalAccepted, 0)
+	j.rc = 5
+	j.stat = db.WARN
+	j.variable = "testvar"
+	j.outputs = map[string]string{"file": "a.csv"}
+	j.et = "2015-08-01 03:34:56.789"
+	j.joblogFile = "test.log"
+	j.writeJournal(journalEnded, 0)
+
+	entry, err := searchJournal(journalPath(conf.Dir.JoblogDir), 1, "job1")
+	if err != nil {
+		t.Fatalf("Unexpected error occured: %s", err)
+	}
+	if entry == nil {
+		t.Fatalf("searchJournal() returned nil.")
+	}
+	if entry.State != journalEnded {
+		t.Errorf("entry.State => %s, wants %s", entry.State, journalEnded)
+	}
+	if entry.RC != 5 || entry.Stat != db.WARN || entry.Var != "testvar" || entry.Outputs["file"] != "a.csv" {
+		t.Errorf("Unexpected entry: %v", entry)
+	}
+	if entry.St != j.st || entry.Et != j.et || entry.Joblog != "test.log" {
+		t.Errorf("Unexpected entry: %v", entry)
+	}
+
+	entry, err = searchJournal(journalPath(conf.Dir.JoblogDir), 2, "job1")
+	if err != nil {
+		t.Fatalf("Unexpected error occured: %s", err)
+	}
+	if entry != nil {
+		t.Errorf("searchJournal() returned unexpected entry: %v", entry)
+	}
+}
+
+func TestSearchJournal_IgnoreBrokenLine(t *testing.T) {
+	conf := makeJournalTestConfig(t)
+	defer os.RemoveAll(conf.Dir.JoblogDir)
+
+	path := journalPath(conf.Dir.JoblogDir)
+	appendJournal(path, &journalEntry{NID: 1, JID: "job1", State: journalEnded, Stat: db.NORMAL})
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0666)
+	file.WriteString(`{"nid":1,"jid":"job1","sta`)
+	file.Close()
+
+	entry, err := searchJournal(path, 1, "job1")
+	if err != nil {
+		t.Fatalf("Unexpected error occured: %s", err)
+	}
+	if entry == nil || entry.State != journalEnded {
+		t.Errorf("Unexpected entry: %v", entry)
+	}
+}
+
+func TestSearchJournal_NotExist(t *testing.T) {
+	conf := makeJournalTestConfig(t)
+	defer os.RemoveAll(conf.Dir.JoblogDir)
+
+	entry, err := searchJournal(journalPath(conf.Dir.JoblogDir), 1, "job1")
+	if err != nil {
+		t.Errorf("Unexpected error occured: %s", err)
+	}
+	if entry != nil {
+		t.Errorf("searchJournal() returned unexpected entry: %v", entry)
+	}
+}
+
+func TestDoJobResultCheck_FromJournal(t *testing.T) {
+	conf := makeJournalTestConfig(t)
+	defer os.RemoveAll(conf.Dir.JoblogDir)
+
+	path := journalPath(conf.Dir.JoblogDir)
+	appendJournal(path, &journalEntry{NID: 1, JID: "job1", State: journalEnded, RC: 5, Stat: db.WARN,
+		Var: "testvar", Outputs: map[string]string{"file": "a.csv"},
+		St: "2015-08-01 03:05:25.123", Et: "2015-08-01 03:34:56.789"})
+
+	result := DoJobResultCheck(&message.JobCheck{NID: 1, JID: "job1"}, conf)
+	if result.RC != 5 {
+		t.Errorf("result.RC => %d, wants %d", result.RC, 5)
+	}
+	if result.Stat != db.WARN {
+		t.Errorf("result.Stat => %d, wants %d", result.Stat, db.WARN)
+	}
+	if result.Var != "testvar" {
+		t.Errorf("result.Var => %s, wants %s", result.Var, "testvar")
+	}
+	if result.Outputs["file"] != "a.csv" {
+		t.Errorf("result.Outputs[file] => %s, wants %s", result.Outputs["file"], "a.csv")
+	}
+	if result.St != "2015-08-01 03:05:25.123" {
+		t.Errorf("result.St => %s, wants %s", result.St, "2015-08-01 03:05:25.123")
+	}
+	if result.Et != "2015-08-01 03:34:56.789" {
+		t.Errorf("result.Et => %s, wants %s", result.Et, "2015-08-01 03:34:56.789")
+	}
+}
+
+func TestDoJobResultCheck_RunningJob(t *testing.T) {
+	conf := makeJournalTestConfig(t)
+	defer os.RemoveAll(conf.Dir.JoblogDir)
+
+	j := &jobInstance{config: conf, nID: 1, jID: "job1", path: "test.sh"}
+	j.activate()
+	defer j.deactivate(&message.Response{NID: 1, JID: "job1"})
+
+	path := journalPath(conf.Dir.JoblogDir)
+	appendJournal(path, &journalEntry{NID: 1, JID: "job1", State: journalRunning, ServantPID: os.Getpid(),
+		St: "2015-08-01 03:05:25.123"})
+
+	result := DoJobResultCheck(&message.JobCheck{NID: 1, JID: "job1"}, conf)
+	if result.Stat != db.RUNNING {
+		t.Errorf("result.Stat => %d, wants %d", result.Stat, db.RUNNING)
+	}
+	if result.St != "2015-08-01 03:05:25.123" {
+		t.Errorf("result.St => %s, wants %s", result.St, "2015-08-01 03:05:25.123")
+	}
+}
+
+func TestDoJobResultCheck_InterruptedJob(t *testing.T) {
+	conf := makeJournalTestConfig(t)
+	defer os.RemoveAll(conf.Dir.JoblogDir)
+
+	path := journalPath(conf.Dir.JoblogDir)
+	appendJournal(path, &journalEntry{NID: 1, JID: "job1", State: journalAccepted, ServantPID: os.Getpid() + 1})
+
+	result := DoJobResultCheck(&message.JobCheck{NID: 1, JID: "job1"}, conf)
+	if result.Stat != db.ABNORMAL {
+		t.Errorf("result.Stat => %d, wants %d", result.Stat, db.ABNORMAL)
+	}
+}
+
+func TestDoJobResultCheck_JobOfPreviousServant(t *testing.T) {
+	conf := makeJournalTestConfig(t)
+	defer os.RemoveAll(conf.Dir.JoblogDir)
+
+	// 以前のサーバントが起動したジョブのプロセスが残っている状態
+	path := journalPath(conf.Dir.JoblogDir)
+	appendJournal(path, &journalEntry{NID: 1, JID: "job1", State: journalRunning, ServantPID: os.Getpid() + 1,
+		PID: os.Getpid()})
+
+	result := DoJobResultCheck(&message.JobCheck{NID: 1, JID: "job1"}, conf)
+	if result.Stat != db.ABNORMAL {
+		t.Errorf("result.Stat => %d, wants %d", result.Stat, db.ABNORMAL)
+	}
+	res := DoJobAttach(&message.Attach{NID: 1, JID: "job1"}, conf)
+	if res.Stat != db.ABNORMAL {
+		t.Errorf("res.Stat => %d, wants %d", res.Stat, db.ABNORMAL)
+	}
+}
+
+func TestDoJobResultCheck_NotActiveJob(t *testing.T) {
+	conf := makeJournalTestConfig(t)
+	defer os.RemoveAll(conf.Dir.JoblogDir)
+
+	path := journalPath(conf.Dir.JoblogDir)
+	appendJournal(path, &journalEntry{NID: 1, JID: "job1", State: journalRunning, ServantPID: os.Getpid()})
+
+	result := DoJobResultCheck(&message.JobCheck{NID: 1, JID: "job1"}, conf)
+	if result.Stat != db.ABNORMAL {
+		t.Errorf("result.Stat => %d, wants %d", result.Stat, db.ABNORMAL)
+	}
+}
+
+func TestSearchJournal_UseIndexAfterFirstSearch(t *testing.T) {
+	conf := makeJournalTestConfig(t)
+	defer os.RemoveAll(conf.Dir.JoblogDir)
+
+	path := journalPath(conf.Dir.JoblogDir)
+	appendJournal(path, &journalEntry{NID: 1, JID: "job1", State: journalAccepted})
+	if entry, _ := searchJournal(path, 1, "job1"); entry == nil || entry.State != journalAccepted {
+		t.Fatalf("Unexpected entry: %v", entry)
+	}
+
+	appendJournal(path, &journalEntry{NID: 1, JID: "job1", State: journalRunning})
+	// 索引の作成後は、ジャーナルファイルを読み込まずに検索する
+	if err := os.Rename(path, path+".bak"); err != nil {
+		t.Fatalf("Could not rename journal: %s", err)
+	}
+	entry, err := searchJournal(path, 1, "job1")
+	if err != nil {
+		t.Fatalf("Unexpected error occured: %s", err)
+	}
+	if entry == nil || entry.State != journalRunning {
+		t.Errorf("Unexpected entry: %v", entry)
+	}
+}
+
+func TestCompactJournal(t *testing.T) {
+	conf := makeJournalTestConfig(t)
+	defer os.RemoveAll(conf.Dir.JoblogDir)
+
+	old := utctime.Now().AddDays(-journalKeepDays - 1).String()
+	now := utctime.Now().String()
+	path := journalPath(conf.Dir.JoblogDir)
+	appendJournal(path, &journalEntry{NID: 1, JID: "job1", State: journalAccepted, Time: old})
+	appendJournal(path, &journalEntry{NID: 1, JID: "job1", State: journalEnded, Time: old})
+	appendJournal(path, &journalEntry{NID: 2, JID: "job1", State: journalAccepted, Time: now})
+	appendJournal(path, &journalEntry{NID: 2, JID: "job1", State: journalRunning, Time: now})
+	appendJournal(path, &journalEntry{NID: 3, JID: "job1", State: journalRunning, Time: old})
+
+	if err := CompactJournal(conf.Dir.JoblogDir); err != nil {
+		t.Fatalf("Unexpected error occured: %s", err)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Could not read journal: %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("len(lines) => %d, wants %d", len(lines), 2)
+	}
+	if !strings.Contains(lines[0], `"nid":2`) || !strings.Contains(lines[0], `"state":"running"`) {
+		t.Errorf("lines[0] => %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"nid":3`) {
+		t.Errorf("lines[1] => %s", lines[1])
+	}
+
+	if entry, _ := searchJournal(path, 1, "job1"); entry != nil {
+		t.Errorf("searchJournal() returned removed entry: %v", entry)
+	}
+	if entry, _ := searchJournal(path, 2, "job1"); entry == nil || entry.State != journalRunning {
+		t.Errorf("Unexpected entry: %v", entry)
+	}
+}
+
+func TestCompactJournal_NotExist(t *testing.T) {
+	conf := makeJournalTestConfig(t)
+	defer os.RemoveAll(conf.Dir.JoblogDir)
+
+	if err := CompactJournal(conf.Dir.JoblogDir); err != nil {
+		t.Errorf("Unexpected error occured: %s", err)
+	}
+	if _, err := os.Stat(journalPath(conf.Dir.JoblogDir)); !os.IsNotExist(err) {
+		t.Error("CompactJournal() created journal file.")
+	}
+}
@@ -0,0 +1,111 @@
+package job
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/unirita/cuto/log"
+)
+
+// ジョブが名前付き出力を標準出力へ書き出す行の接頭辞
+const outputLinePrefix = "##CUTO:"
+
+// ジョブが名前付き出力を書き込むJSONファイルのパスを渡す環境変数名
+const outputFileEnv = "CUTO_OUTPUT_FILE"
+
+// 名前付き出力のキーとして利用可能な文字列
+var outputKeyExp = regexp.MustCompile(`^[0-9A-Za-z_.\-]+$`)
+
+// 名前付き出力JSONファイルのパスを返す。
+// ジョブの実行結果確認でも参照できるよう、インスタンスIDとジョブIDから一意に決まるパスとする。
+func outputFilePath(joblogDir string, nid int, jid string) string {
+	return filepath.Join(joblogDir, "outputs", fmt.Sprintf("%d.%s.json", nid, jid))
+}
+
+// 名前付き出力JSONファイルの出力先フォルダを作成し、前回実行時のファイルを削除する。
+func prepareOutputFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// ジョブの出力内容joblogと名前付き出力JSONファイルから、名前付き出力を取得する。
+// 同じキーが複数回出力された場合は最後の値を、両方に出力された場合はJSONファイルの値を採用する。
+// 出力が無い場合はnilを返す。
+func collectOutputs(joblog string, path string) map[string]string {
+	outputs := parseOutputLines(joblog)
+	fileOutputs, err := readOutputFile(path)
+	if err != nil {
+		log.Warn(fmt.Sprintf("Could not read output file[%s]: %s", path, err))
+	}
+	for k, v := range fileOutputs {
+		if outputs == nil {
+			outputs = make(map[string]string)
+		}
+		outputs[k] = v
+	}
+	return outputs
+}
+
+// 「##CUTO:キー=値」形式の行から名前付き出力を取得する。
+func parseOutputLines(joblog string) map[string]string {
+	var outputs map[string]string
+	s := bufio.NewScanner(strings.NewReader(joblog))
+	for s.Scan() {
+		line := strings.TrimRight(s.Text(), "\r")
+		if !strings.HasPrefix(line, outputLinePrefix) {
+			continue
+		}
+		kv := strings.SplitN(line[len(outputLinePrefix):], "=", 2)
+		if len(kv) != 2 || !outputKeyExp.MatchString(kv[0]) {
+			log.Warn(fmt.Sprintf("Invalid output line[%s] was ignored.", line))
+			continue
+		}
+		if outputs == nil {
+			outputs = make(map[string]string)
+		}
+		outputs[kv[0]] = kv[1]
+	}
+	return outputs
+}
+
+// 名前付き出力JSONファイルを読み込む。ファイルが存在しない場合はnilを返す。
+// JSONの値が文字列以外の場合は、JSON表現をそのまま値とする。
+func readOutputFile(path string) (map[string]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+
+	outputs := make(map[string]string)
+	for k, v := range raw {
+		if !outputKeyExp.MatchString(k) {
+			log.Warn(fmt.Sprintf("Invalid output key[%s] in file[%s] was ignored.", k, path))
+			continue
+		}
+		var str string
+		if err := json.Unmarshal(v, &str); err == nil {
+			outputs[k] = str
+		} else {
+			outputs[k] = string(v)
+		}
+	}
+	return outputs, nil
+}
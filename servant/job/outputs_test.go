@@ -0,0 +1,97 @@
+package job
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseOutputLines(t *testing.T) {
+	joblog := "hello\r\n##CUTO:file=a.csv\r\n##CUTO:count=1\n##CUTO:count=2\n##CUTO:bad key=x\n##CUTO:novalue\nbye"
+
+	outputs := parseOutputLines(joblog)
+	if len(outputs) != 2 {
+		t.Fatalf("len(outputs) => %d, wants %d", len(outputs), 2)
+	}
+	if outputs["file"] != "a.csv" {
+		t.Errorf("outputs[file] => %s, wants %s", outputs["file"], "a.csv")
+	}
+	if outputs["count"] != "2" {
+		t.Errorf("outputs[count] => %s, wants %s", outputs["count"], "2")
+	}
+}
+
+func TestParseOutputLines_NoOutput(t *testing.T) {
+	if outputs := parseOutputLines("hello\nbye"); outputs != nil {
+		t.Errorf("parseOutputLines() => %v, wants nil", outputs)
+	}
+}
+
+func TestReadOutputFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cuto_outputs")
+	if err != nil {
+		t.Fatalf("Could not create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "outputs.json")
+	if err := ioutil.WriteFile(path, []byte(`{"file":"a.csv","count":10,"ok":true,"bad key":"x"}`), 0666); err != nil {
+		t.Fatalf("Could not write output file: %s", err)
+	}
+
+	outputs, err := readOutputFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error occured: %s", err)
+	}
+	expects := map[string]string{"file": "a.csv", "count": "10", "ok": "true"}
+	if len(outputs) != len(expects) {
+		t.Fatalf("len(outputs) => %d, wants %d", len(outputs), len(expects))
+	}
+	for k, v := range expects {
+		if outputs[k] != v {
+			t.Errorf("outputs[%s] => %s, wants %s", k, outputs[k], v)
+		}
+	}
+}
+
+func TestReadOutputFile_NotExist(t *testing.T) {
+	outputs, err := readOutputFile(filepath.Join(os.TempDir(), "cuto_noexist_outputs.json"))
+	if err != nil {
+		t.Errorf("Unexpected error occured: %s", err)
+	}
+	if outputs != nil {
+		t.Errorf("readOutputFile() => %v, wants nil", outputs)
+	}
+}
+
+func TestCollectOutputs_FileOverridesLines(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cuto_outputs")
+	if err != nil {
+		t.Fatalf("Could not create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := outputFilePath(dir, 1, "job1")
+	if err := prepareOutputFile(path); err != nil {
+		t.Fatalf("Unexpected error occured: %s", err)
+	}
+	if err := ioutil.WriteFile(path, []byte(`{"file":"b.csv"}`), 0666); err != nil {
+		t.Fatalf("Could not write output file: %s", err)
+	}
+
+	outputs := collectOutputs("##CUTO:file=a.csv\n##CUTO:count=1", path)
+	if outputs["file"] != "b.csv" {
+		t.Errorf("outputs[file] => %s, wants %s", outputs["file"], "b.csv")
+	}
+	if outputs["count"] != "1" {
+		t.Errorf("outputs[count] => %s, wants %s", outputs["count"], "1")
+	}
+
+	if err := prepareOutputFile(path); err != nil {
+		t.Fatalf("Unexpected error occured: %s", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("prepareOutputFile() did not remove old output file.")
+	}
+}
@@ -0,0 +1,22 @@
+// +build darwin linux
+
+package job
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// ジョブが起動した子孫プロセスもまとめて強制終了できるよう、ジョブプロセスを新しいプロセスグループで起動する。
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// ジョブプロセスのプロセスグループを強制終了する。
+func killProcessGroup(p *os.Process) error {
+	if err := syscall.Kill(-p.Pid, syscall.SIGKILL); err != nil {
+		return p.Kill()
+	}
+	return nil
+}
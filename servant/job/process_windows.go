@@ -0,0 +1,21 @@
+package job
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// ジョブが起動した子孫プロセスもまとめて強制終了できるよう、ジョブプロセスを新しいプロセスグループで起動する。
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// ジョブプロセスと、その子孫プロセスを強制終了する。
+func killProcessGroup(p *os.Process) error {
+	if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(p.Pid)).Run(); err != nil {
+		return p.Kill()
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package job
+
+import (
+	"sync"
+	"time"
+
+	"github.com/unirita/cuto/console"
+)
+
+// ジョブの同時実行数を制限するキュー
+type jobQueue struct {
+	slots   chan struct{} // 実行枠。容量が同時実行数の上限となる。
+	mutex   sync.Mutex
+	waiting int // 実行枠の空きを待っているジョブの数
+}
+
+// 同時実行数の制限に使用するキュー。nilの場合は制限しない。
+var queue *jobQueue
+
+// ジョブの同時実行数の上限を設定する。
+// 実行枠に空きが無い場合、後から受け付けたジョブは空きが出るまで受付順に待機する。
+//
+// param : multi 同時実行数の上限。
+func InitQueue(multi int) {
+	queue = newJobQueue(multi)
+}
+
+func newJobQueue(multi int) *jobQueue {
+	q := new(jobQueue)
+	q.slots = make(chan struct{}, multi)
+	return q
+}
+
+// 待機中のジョブの数を1増やし、待ち順位を返す。
+func (q *jobQueue) enter() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.waiting++
+	return q.waiting
+}
+
+// 待機中のジョブの数を1減らす。
+func (q *jobQueue) leave() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.waiting--
+}
+
+// 実行枠を解放する。
+func (q *jobQueue) release() {
+	<-q.slots
+}
+
+// 実行枠を獲得する。空きが無い場合は、空きが出るか実行中止要求を受けるまで待機する。
+// 待機中に実行中止要求を受け付けられるよう、事前にジョブを登録しておくこと。
+//
+// return : 実行枠を獲得できた場合はtrue、実行中止された場合はfalse。
+func (j *jobInstance) acquireSlot() bool {
+	q := queue
+	if q == nil {
+		return true
+	}
+	select {
+	case q.slots <- struct{}{}:
+		j.queue = q
+		return true
+	default:
+	}
+
+	pos := q.enter()
+	defer q.leave()
+	console.Display("CTS029I", j.path, j.nID, j.jID, pos)
+
+	start := time.Now()
+	select {
+	case q.slots <- struct{}{}:
+		j.queue = q
+		j.queuePos = pos
+		j.queueSec = int(time.Since(start) / time.Second)
+		console.Display("CTS030I", j.path, j.nID, j.jID, j.queueSec)
+		return true
+	case <-j.cancelCh:
+		return false
+	}
+}
+
+// 獲得した実行枠を解放する。
+func (j *jobInstance) releaseSlot() {
+	if j.queue == nil {
+		return
+	}
+	j.queue.release()
+	j.queue = nil
+}
@@ -0,0 +1,103 @@
+package job
+
+import (
+	"testing"
+	"time"
+
+	"github.com/unirita/cuto/message"
+)
+
+func TestAcquireSlot_キューが無い場合は待機しない(t *testing.T) {
+	j := createTestJobInstance()
+	if !j.acquireSlot() {
+		t.Fatal("実行枠を獲得できなかった。")
+	}
+	j.releaseSlot()
+	if j.queuePos != 0 {
+		t.Errorf("待ち順位[%d]が想定と違っている。", j.queuePos)
+	}
+}
+
+func TestAcquireSlot_実行枠に空きが無い場合は解放まで待機する(t *testing.T) {
+	InitQueue(1)
+	defer func() { queue = nil }()
+
+	j1 := createTestJobInstance()
+	if !j1.acquireSlot() {
+		t.Fatal("1つ目のジョブが実行枠を獲得できなかった。")
+	}
+
+	j2 := createTestJobInstance()
+	j2.jID = "JID2"
+	acquired := make(chan bool, 1)
+	go func() {
+		acquired <- j2.acquireSlot()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("実行枠に空きが無いにも関わらず、2つ目のジョブが待機しなかった。")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	j1.releaseSlot()
+	select {
+	case ok := <-acquired:
+		if !ok {
+			t.Fatal("2つ目のジョブが実行枠を獲得できなかった。")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("実行枠が解放されたにも関わらず、2つ目のジョブが待機し続けた。")
+	}
+	j2.releaseSlot()
+
+	if j1.queuePos != 0 {
+		t.Errorf("1つ目のジョブの待ち順位[%d]が想定と違っている。", j1.queuePos)
+	}
+	if j2.queuePos != 1 {
+		t.Errorf("2つ目のジョブの待ち順位[%d]が想定と違っている。", j2.queuePos)
+	}
+	res := j2.createResponse()
+	if res.QueuePos != 1 {
+		t.Errorf("レスポンスの待ち順位[%d]が想定と違っている。", res.QueuePos)
+	}
+}
+
+func TestAcquireSlot_待機中に実行中止要求を受けた場合はfalseを返す(t *testing.T) {
+	InitQueue(1)
+	defer func() { queue = nil }()
+
+	j1 := createTestJobInstance()
+	j1.acquireSlot()
+	defer j1.releaseSlot()
+
+	j2 := createTestJobInstance()
+	j2.nID = 5678
+	j2.register(nil)
+	defer j2.unregister()
+	acquired := make(chan bool, 1)
+	go func() {
+		acquired <- j2.acquireSlot()
+	}()
+
+	cnl := &message.Cancel{NID: 5678, JID: j2.jID}
+	var result *message.CancelResult
+	for i := 0; i < 50; i++ {
+		time.Sleep(10 * time.Millisecond)
+		if result = DoJobCancel(cnl, j2.config); result.Cancelled {
+			break
+		}
+	}
+	if !result.Cancelled {
+		t.Fatalf("待機中のジョブを実行中止できなかった: %s", result.Detail)
+	}
+
+	select {
+	case ok := <-acquired:
+		if ok {
+			t.Error("実行中止されたにも関わらず、実行枠を獲得した。")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("実行中止されたにも関わらず、待機し続けた。")
+	}
+}
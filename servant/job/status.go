@@ -0,0 +1,63 @@
+package job
+
+import (
+	"sort"
+	"time"
+
+	"github.com/unirita/cuto/message"
+	"github.com/unirita/cuto/servant/config"
+	"github.com/unirita/cuto/util"
+)
+
+// サーバントの起動日時
+var startTime = time.Now()
+
+// サーバントの状態確認要求を受け付け、稼働時間、同時実行数の上限、実行中のジョブおよび負荷を返す。
+//
+// param : s マスタからの状態確認要求メッセージ。
+//
+// param : conf サーバントの設定情報。
+//
+// return : マスタへ返信するメッセージ。
+func DoServantStatus(s *message.Status, conf *config.ServantConfig) *message.StatusResult {
+	result := new(message.StatusResult)
+	result.Uptime = int(time.Since(startTime) / time.Second)
+	result.MultiProc = conf.Job.MultiProc
+	result.Queued = countQueuedJobs()
+	result.Load = util.LoadAverage()
+	result.Jobs = listRunningJobs()
+	return result
+}
+
+// 実行枠の空きを待っているジョブの数を返す。
+func countQueuedJobs() int {
+	q := queue
+	if q == nil {
+		return 0
+	}
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.waiting
+}
+
+// 実行中のジョブの一覧を、インスタンスID、ジョブIDの順に並べて返す。
+// 実行枠の空きを待っているジョブは含まない。
+func listRunningJobs() []message.StatusJob {
+	running.Lock()
+	defer running.Unlock()
+
+	jobs := make([]message.StatusJob, 0, len(running.jobs))
+	for _, j := range running.jobs {
+		if j.process == nil {
+			continue
+		}
+		jobs = append(jobs, message.StatusJob{NID: j.nID, JID: j.jID, Path: j.path, PID: j.process.Pid, St: j.st})
+	}
+	sort.Slice(jobs, func(a, b int) bool {
+		if jobs[a].NID != jobs[b].NID {
+			return jobs[a].NID < jobs[b].NID
+		}
+		return jobs[a].JID < jobs[b].JID
+	})
+	return jobs
+}
@@ -0,0 +1,58 @@
+package job
+
+import (
+	"os"
+	"testing"
+
+	"github.com/unirita/cuto/message"
+	"github.com/unirita/cuto/servant/config"
+)
+
+func TestDoServantStatus_Base(t *testing.T) {
+	conf := config.DefaultServantConfig()
+	conf.Job.MultiProc = 3
+
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	j2 := &jobInstance{nID: 2, jID: "job1", path: "test2.sh", st: "2015-04-01 12:34:56.789"}
+	j2.register(process)
+	defer j2.unregister()
+	j1 := &jobInstance{nID: 1, jID: "job2", path: "test1.sh", st: "2015-04-01 12:00:00.000"}
+	j1.register(process)
+	defer j1.unregister()
+	waiting := &jobInstance{nID: 1, jID: "job3", path: "test3.sh"}
+	waiting.register(nil)
+	defer waiting.unregister()
+
+	result := DoServantStatus(new(message.Status), conf)
+	if result.MultiProc != 3 {
+		t.Errorf("result.MultiProc => %d, wants %d", result.MultiProc, 3)
+	}
+	if result.Uptime < 0 {
+		t.Errorf("result.Uptime => %d, wants 0 or more", result.Uptime)
+	}
+	if len(result.Jobs) != 2 {
+		t.Fatalf("len(result.Jobs) => %d, wants %d", len(result.Jobs), 2)
+	}
+	expected := message.StatusJob{NID: 1, JID: "job2", Path: "test1.sh", PID: os.Getpid(), St: "2015-04-01 12:00:00.000"}
+	if result.Jobs[0] != expected {
+		t.Errorf("result.Jobs[0] => %v, wants %v", result.Jobs[0], expected)
+	}
+	if result.Jobs[1].NID != 2 {
+		t.Errorf("result.Jobs[1].NID => %d, wants %d", result.Jobs[1].NID, 2)
+	}
+}
+
+func TestDoServantStatus_CountQueuedJobs(t *testing.T) {
+	defer func(q *jobQueue) { queue = q }(queue)
+	queue = newJobQueue(1)
+	queue.enter()
+	queue.enter()
+
+	result := DoServantStatus(new(message.Status), config.DefaultServantConfig())
+	if result.Queued != 2 {
+		t.Errorf("result.Queued => %d, wants %d", result.Queued, 2)
+	}
+}
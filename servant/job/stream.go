@@ -0,0 +1,67 @@
+package job
+
+import (
+	"bytes"
+	"sync"
+)
+
+// 1行として送信する出力の最大バイト数。これを超える行は分割して送信する。
+const maxStreamLineSize = 4096
+
+// ジョブの出力を行単位でチャネルへ送信するio.Writer
+type lineWriter struct {
+	mutex  sync.Mutex
+	ch     chan<- string
+	buf    []byte
+	closed bool
+}
+
+func newLineWriter(ch chan<- string) *lineWriter {
+	w := new(lineWriter)
+	w.ch = ch
+	return w
+}
+
+// 出力内容を改行で区切り、完成した行をチャネルへ送信する。
+// Close後に書き込まれた内容は破棄する。
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.closed {
+		return len(p), nil
+	}
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.send(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	for len(w.buf) > maxStreamLineSize {
+		w.send(w.buf[:maxStreamLineSize])
+		w.buf = w.buf[maxStreamLineSize:]
+	}
+	return len(p), nil
+}
+
+// 改行で終わっていない残りの出力を送信し、以降の書き込みを破棄する。
+func (w *lineWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.closed {
+		return nil
+	}
+	if len(w.buf) > 0 {
+		w.send(w.buf)
+		w.buf = nil
+	}
+	w.closed = true
+	return nil
+}
+
+func (w *lineWriter) send(line []byte) {
+	w.ch <- string(bytes.TrimSuffix(line, []byte("\r")))
+}
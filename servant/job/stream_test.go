@@ -0,0 +1,48 @@
+package job
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineWriter_改行単位で送信する(t *testing.T) {
+	ch := make(chan string, 10)
+	w := newLineWriter(ch)
+	w.Write([]byte("line1\r\nli"))
+	w.Write([]byte("ne2\nline3"))
+	if len(ch) != 2 {
+		t.Fatalf("送信された行数[%d]が想定と違っている。", len(ch))
+	}
+	if line := <-ch; line != "line1" {
+		t.Errorf("1行目[%s]が想定と違っている。", line)
+	}
+	if line := <-ch; line != "line2" {
+		t.Errorf("2行目[%s]が想定と違っている。", line)
+	}
+
+	w.Close()
+	if line := <-ch; line != "line3" {
+		t.Errorf("改行の無い最終行[%s]が想定と違っている。", line)
+	}
+
+	w.Write([]byte("after close\n"))
+	if len(ch) != 0 {
+		t.Error("Close後に書き込まれた内容が送信された。")
+	}
+}
+
+func TestLineWriter_長すぎる行は分割して送信する(t *testing.T) {
+	ch := make(chan string, 10)
+	w := newLineWriter(ch)
+	w.Write([]byte(strings.Repeat("a", maxStreamLineSize+10)))
+	if len(ch) != 1 {
+		t.Fatalf("送信された行数[%d]が想定と違っている。", len(ch))
+	}
+	if line := <-ch; len(line) != maxStreamLineSize {
+		t.Errorf("送信された行の長さ[%d]が想定と違っている。", len(line))
+	}
+	w.Close()
+	if line := <-ch; len(line) != 10 {
+		t.Errorf("残りの行の長さ[%d]が想定と違っている。", len(line))
+	}
+}
@@ -0,0 +1,94 @@
+package remote
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/unirita/cuto/console"
+	"github.com/unirita/cuto/log"
+	"github.com/unirita/cuto/message"
+)
+
+// 受信したメッセージが接続開始メッセージであれば、パースしたHelloオブジェクトを返す。
+// 接続開始メッセージでない場合はnilを返す。
+func parseHello(msg string) *message.Hello {
+	h := new(message.Hello)
+	if err := h.ParseJSON(msg); err != nil {
+		return nil
+	}
+	return h
+}
+
+// 接続開始メッセージに応答し、長さ付きフレーム形式で要求メッセージを受信してセッションを生成する。
+// masterのプロトコルに対応していない場合や、要求メッセージを受信できない場合は、拒否通知を返信して切断する。
+//
+// param : conn masterとの接続。
+//
+// param : r 接続からの受信に使用するReader。
+//
+// param : peer masterから受信したHelloオブジェクト。
+//
+// param : sq 生成したセッションを送信するチャネル。
+func serveFramed(conn net.Conn, r *bufio.Reader, peer *message.Hello, sq chan<- *Session) {
+	if _, err := message.NegotiateProtocol(peer); err != nil {
+		rejectConn(conn, err)
+		return
+	}
+
+	hello, err := message.NewHello(message.ServantVersion, message.ServantCapabilities).GenerateJSON()
+	if err != nil {
+		log.Error(err)
+		conn.Close()
+		return
+	}
+	if _, err := conn.Write([]byte(hello + MsgEnd)); err != nil {
+		log.Error(err)
+		conn.Close()
+		return
+	}
+
+	fc := &framedConn{Conn: conn}
+	body, err := message.ReadFramed(r, message.MaxMessageSize)
+	if err != nil {
+		rejectConn(fc, err)
+		return
+	}
+
+	s := NewSession(fc, body)
+	s.strict = true
+	sq <- s
+}
+
+// 拒否通知を返信して切断する。
+// connがframedConnの場合は、拒否通知を1フレームとして送信する。
+func rejectConn(conn net.Conn, reason error) {
+	defer conn.Close()
+	console.Display("CTS039W", conn.RemoteAddr(), reason)
+	msg, err := message.Reject{Detail: reason.Error()}.GenerateJSON()
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	conn.Write([]byte(msg + MsgEnd))
+}
+
+// 長さ付きフレーム形式でメッセージを送信する接続。
+// セッションが書き込んだメッセージを、1行毎に1フレームとして送信する。
+type framedConn struct {
+	net.Conn
+	writeLock sync.Mutex
+}
+
+func (c *framedConn) Write(b []byte) (int, error) {
+	// ハートビートとジョブの出力などが並行して書き込まれるため、フレームが混ざらないよう排他する
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	for _, line := range strings.Split(strings.TrimSuffix(string(b), MsgEnd), MsgEnd) {
+		if err := message.WriteFramed(c.Conn, line); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
@@ -0,0 +1,115 @@
+package remote
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/unirita/cuto/message"
+	"github.com/unirita/cuto/testutil"
+)
+
+// サーバ側の接続でメッセージの受信を開始する。
+// 戻り値の関数は、接続を閉じて受信処理の終了を待つ。
+func startTestReceiveMessage(client, server net.Conn, sq chan<- *Session) func() {
+	done := make(chan struct{})
+	go func() {
+		receiveMessage(server, sq)
+		close(done)
+	}()
+	return func() {
+		client.Close()
+		server.Close()
+		<-done
+	}
+}
+
+func TestReceiveMessage_接続開始メッセージに応答し長さ付きフレームで要求を受信できる(t *testing.T) {
+	message.ServantVersion = "2.3.4"
+	client, server := net.Pipe()
+	sq := make(chan *Session, 1)
+	defer startTestReceiveMessage(client, server, sq)()
+
+	hello, _ := message.NewHello("1.2.3", message.MasterCapabilities).GenerateJSON()
+	client.Write([]byte(hello + "\n"))
+	r := bufio.NewReader(client)
+	line, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatalf("接続開始メッセージへの応答を受信できなかった: %s", err)
+	}
+	peer := new(message.Hello)
+	if err := peer.ParseJSON(line); err != nil {
+		t.Fatalf("接続開始メッセージへの応答[%s]をパースできなかった: %s", line, err)
+	}
+	if peer.Version != "2.3.4" || peer.Protocol != message.ProtocolVersion || !peer.Supports("request") {
+		t.Errorf("接続開始メッセージへの応答%vが想定と違っている。", peer)
+	}
+
+	reqMsg := `{"type":"request","version":"1.2.3","nid":1234,"jid":"job1","param":"` + strings.Repeat("a", 100*1024) + `"}`
+	go message.WriteFramed(client, reqMsg)
+
+	session := <-sq
+	defer session.endHeartbeat()
+	if session.Body != reqMsg {
+		t.Errorf("セッションの要求メッセージ長[%d]が想定[%d]と違っている。", len(session.Body), len(reqMsg))
+	}
+	if !session.strict {
+		t.Error("セッションが未知の項目を拒否するよう設定されていない。")
+	}
+
+	go session.Conn.Write([]byte(message.ST_HEADER + "20150401123456.789" + MsgEnd))
+	msg, err := message.ReadFramed(r, message.MaxMessageSize)
+	if err != nil {
+		t.Fatalf("フレームを受信できなかった: %s", err)
+	}
+	if msg != message.ST_HEADER+"20150401123456.789" {
+		t.Errorf("受信したフレーム[%s]が想定と違っている。", msg)
+	}
+}
+
+func TestReceiveMessage_対応していないプロトコルの接続は拒否する(t *testing.T) {
+	client, server := net.Pipe()
+	sq := make(chan *Session, 1)
+	defer startTestReceiveMessage(client, server, sq)()
+
+	client.Write([]byte(`{"type":"hello","version":"0.1","protocol":0}` + "\n"))
+	line, err := bufio.NewReader(client).ReadString('\n')
+	if err != nil {
+		t.Fatalf("拒否通知を受信できなかった: %s", err)
+	}
+	rj := new(message.Reject)
+	if err := rj.ParseJSON(line); err != nil {
+		t.Fatalf("拒否通知[%s]をパースできなかった: %s", line, err)
+	}
+	if !strings.Contains(rj.Detail, "Protocol version [0]") {
+		t.Errorf("拒否の理由[%s]が想定と違っている。", rj.Detail)
+	}
+	if len(sq) != 0 {
+		t.Error("拒否した接続のセッションが生成された。")
+	}
+}
+
+func TestDo_未知の項目を含むメッセージは拒否する(t *testing.T) {
+	conf := readTestConfig()
+
+	conn := testutil.NewConnStub()
+	reqMsg := `{"type":"request","version":"1.2.3","nid":1234,"jid":"job1","unknown":"value"}`
+	session := Session{Conn: &framedConn{Conn: conn}, Body: reqMsg, doJobRequest: doTestRequest, strict: true}
+	session.startHeartbeat()
+	if err := session.Do(conf); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+
+	msg, err := message.ReadFramed(strings.NewReader(conn.WriteStr), message.MaxMessageSize)
+	if err != nil {
+		t.Fatalf("拒否通知を読み込めなかった: %s", err)
+	}
+	rj := new(message.Reject)
+	if err := rj.ParseJSON(msg); err != nil {
+		t.Fatalf("拒否通知[%s]をパースできなかった: %s", msg, err)
+	}
+	if !strings.Contains(rj.Detail, `unknown field "unknown"`) {
+		t.Errorf("拒否の理由[%s]が想定と違っている。", rj.Detail)
+	}
+}
@@ -0,0 +1,143 @@
+package remote
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/unirita/cuto/console"
+	"github.com/unirita/cuto/log"
+	"github.com/unirita/cuto/message"
+)
+
+// masterとの多重化接続
+type muxConn struct {
+	conn      net.Conn
+	writeLock sync.Mutex
+}
+
+// 受信したメッセージが多重化接続の開始要求であるかを調べる。
+func isMuxRequest(msg string) bool {
+	m := new(message.Mux)
+	return m.ParseJSON(msg) == nil
+}
+
+// 多重化接続でmasterからのフレームを受信し、要求毎にセッションを生成する。
+// 接続が切断されても実行中のジョブは継続し、masterは再接続後に再接続要求で実行結果を受け取る。
+//
+// param : conn masterとの接続。
+//
+// param : scanner 接続からの受信に使用するScanner。
+//
+// param : sq 生成したセッションを送信するチャネル。
+func serveMux(conn net.Conn, scanner *bufio.Scanner, sq chan<- *Session) {
+	defer conn.Close()
+
+	// 多重化接続は長時間維持するため、受信のタイムアウトを解除する
+	conn.SetReadDeadline(time.Time{})
+
+	m := &muxConn{conn: conn}
+	hello, err := message.Mux{Version: message.ServantVersion}.GenerateJSON()
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	if err := m.writeLine(hello); err != nil {
+		log.Error(err)
+		return
+	}
+	console.Display("CTS037I", conn.RemoteAddr())
+
+	for scanner.Scan() {
+		f := new(message.Frame)
+		if err := f.ParseJSON(scanner.Text()); err != nil {
+			console.Display("CTS015E", err.Error())
+			continue
+		}
+
+		switch f.Kind {
+		case message.FRAME_PING:
+			m.writeFrame(&message.Frame{Seq: f.Seq, Kind: message.FRAME_PONG})
+		case message.FRAME_REQUEST:
+			m.writeFrame(&message.Frame{Seq: f.Seq, NID: f.NID, JID: f.JID, Kind: message.FRAME_ACK})
+			s := NewSession(&frameConn{mux: m, seq: f.Seq, nid: f.NID, jid: f.JID}, f.Body)
+			s.reqID = f.ID
+			s.resent = f.Resend
+			sq <- s
+		default:
+			log.Warn(fmt.Sprintf("Unknown frame kind[%s] was ignored.", f.Kind))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Warn(fmt.Sprintf("[%v]: %v", conn.RemoteAddr(), err))
+	}
+	console.Display("CTS038I", conn.RemoteAddr())
+}
+
+// フレームを送信する。
+func (m *muxConn) writeFrame(f *message.Frame) error {
+	msg, err := f.GenerateJSON()
+	if err != nil {
+		return err
+	}
+	return m.writeLine(msg)
+}
+
+// 1行分のメッセージを送信する。複数のセッションからの送信が混ざらないよう排他する。
+func (m *muxConn) writeLine(msg string) error {
+	m.writeLock.Lock()
+	defer m.writeLock.Unlock()
+	_, err := m.conn.Write([]byte(msg + MsgEnd))
+	return err
+}
+
+// 多重化接続上の1つの要求に対応する仮想的な接続。
+// セッションが書き込んだメッセージを1行毎にフレームへ格納して送信する。
+type frameConn struct {
+	mux *muxConn
+	seq int64
+	nid int
+	jid string
+}
+
+func (c *frameConn) Read(b []byte) (int, error) {
+	return 0, fmt.Errorf("Read is not supported on multiplexed connection.")
+}
+
+func (c *frameConn) Write(b []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimSuffix(string(b), MsgEnd), MsgEnd) {
+		f := &message.Frame{Seq: c.seq, NID: c.nid, JID: c.jid, Kind: message.FRAME_MESSAGE, Body: line}
+		if err := c.mux.writeFrame(f); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// 多重化接続自体は他のセッションが利用するため、閉じない。
+func (c *frameConn) Close() error {
+	return nil
+}
+
+func (c *frameConn) LocalAddr() net.Addr {
+	return c.mux.conn.LocalAddr()
+}
+
+func (c *frameConn) RemoteAddr() net.Addr {
+	return c.mux.conn.RemoteAddr()
+}
+
+func (c *frameConn) SetDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *frameConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *frameConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
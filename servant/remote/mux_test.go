@@ -0,0 +1,107 @@
+package remote
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/unirita/cuto/message"
+)
+
+func readTestFrame(t *testing.T, scanner *bufio.Scanner) *message.Frame {
+	if !scanner.Scan() {
+		t.Fatalf("フレームを受信できなかった: %v", scanner.Err())
+	}
+	f := new(message.Frame)
+	if err := f.ParseJSON(scanner.Text()); err != nil {
+		t.Fatalf("受信したフレーム[%s]をパースできなかった: %s", scanner.Text(), err)
+	}
+	return f
+}
+
+func TestReceiveMessage_多重化接続で要求毎にセッションを生成できる(t *testing.T) {
+	message.ServantVersion = "2.3.4"
+	client, server := net.Pipe()
+	defer client.Close()
+
+	sq := make(chan *Session, 1)
+	endCh := make(chan struct{})
+	go func() {
+		receiveMessage(server, sq)
+		close(endCh)
+	}()
+
+	client.Write([]byte(`{"type":"mux","version":"1.2.3"}` + "\n"))
+	scanner := bufio.NewScanner(client)
+	if !scanner.Scan() {
+		t.Fatalf("開始要求への応答を受信できなかった: %v", scanner.Err())
+	}
+	if scanner.Text() != `{"type":"mux","version":"2.3.4"}` {
+		t.Errorf("開始要求への応答[%s]が想定と違っている。", scanner.Text())
+	}
+
+	client.Write([]byte(`{"seq":1,"nid":0,"jid":"","kind":"ping"}` + "\n"))
+	if f := readTestFrame(t, scanner); f.Kind != message.FRAME_PONG || f.Seq != 1 {
+		t.Errorf("接続確認への応答%vが想定と違っている。", f)
+	}
+
+	reqMsg := `{"type":"request","version":"1.2.3","nid":1234,"jid":"job1"}`
+	frame, _ := message.NewRequestFrame(2, reqMsg).GenerateJSON()
+	client.Write([]byte(frame + "\n"))
+	if f := readTestFrame(t, scanner); f.Kind != message.FRAME_ACK || f.Seq != 2 || f.NID != 1234 || f.JID != "job1" {
+		t.Errorf("要求の受付通知%vが想定と違っている。", f)
+	}
+
+	session := <-sq
+	defer session.endHeartbeat()
+	if session.Body != reqMsg {
+		t.Errorf("セッションの要求メッセージ[%s]が想定と違っている。", session.Body)
+	}
+
+	go session.Conn.Write([]byte("testresponse" + MsgEnd))
+	f := readTestFrame(t, scanner)
+	if f.Kind != message.FRAME_MESSAGE || f.Seq != 2 || f.NID != 1234 || f.JID != "job1" || f.Body != "testresponse" {
+		t.Errorf("返信フレーム%vが想定と違っている。", f)
+	}
+
+	client.Close()
+	<-endCh
+}
+
+func TestReceiveMessage_多重化接続で1MBを超える要求を受信できる(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	sq := make(chan *Session, 1)
+	endCh := make(chan struct{})
+	go func() {
+		receiveMessage(server, sq)
+		close(endCh)
+	}()
+
+	scanner := bufio.NewScanner(client)
+	go client.Write([]byte(`{"type":"mux","version":"1.2.3"}` + "\n"))
+	if !scanner.Scan() {
+		t.Fatalf("開始要求への応答を受信できなかった: %v", scanner.Err())
+	}
+
+	reqMsg := `{"type":"request","version":"1.2.3","nid":1234,"jid":"job1","param":"` + strings.Repeat("a", 2*1024*1024) + `"}`
+	frame, err := message.NewRequestFrame(1, reqMsg).GenerateJSON()
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	go client.Write([]byte(frame + "\n"))
+	if f := readTestFrame(t, scanner); f.Kind != message.FRAME_ACK || f.Seq != 1 {
+		t.Errorf("要求の受付通知%vが想定と違っている。", f)
+	}
+
+	session := <-sq
+	session.endHeartbeat()
+	if session.Body != reqMsg {
+		t.Errorf("セッションの要求メッセージ長[%d]が想定[%d]と違っている。", len(session.Body), len(reqMsg))
+	}
+
+	client.Close()
+	<-endCh
+}
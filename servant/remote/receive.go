@@ -6,12 +6,16 @@ package remote
 
 import (
 	"bufio"
+	"crypto/tls"
 	"fmt"
+	"io"
 	"net"
+	"strings"
 	"time"
 
 	"github.com/unirita/cuto/console"
 	"github.com/unirita/cuto/log"
+	"github.com/unirita/cuto/message"
 )
 
 // 送受信メッセージの終端文字
@@ -29,16 +33,22 @@ const protocol = "tcp"
 //
 // 引数：multi メッセージ受信の多重度
 //
+// 引数：tlsConf TLS設定。nilの場合はTLSを利用しない。
+//
 // 戻り値：メッセージ受信を通知する、受信チャネル
 //
 // 戻り値：エラー情報
-func StartReceive(bindAddr string, port int, multi int) (<-chan *Session, error) {
+func StartReceive(bindAddr string, port int, multi int, tlsConf *tls.Config) (<-chan *Session, error) {
 	addr := fmt.Sprintf("%s:%d", bindAddr, port)
 
 	listener, err := net.Listen(protocol, addr)
 	if err != nil {
 		return nil, err
 	}
+	if tlsConf != nil {
+		listener = tls.NewListener(listener, tlsConf)
+		console.Display("CTS027I")
+	}
 	console.Display("CTS007I", bindAddr, port)
 
 	sq := make(chan *Session, multi)
@@ -74,11 +84,54 @@ func receiveMessage(conn net.Conn, sq chan<- *Session) error {
 		return err
 	}
 
-	scanner := bufio.NewScanner(conn)
-	if !scanner.Scan() {
-		log.Error(fmt.Sprintf("[%v]: %v\n", conn.RemoteAddr(), scanner.Err()))
-		return scanner.Err()
+	r := bufio.NewReader(conn)
+	line, err := readLine(r, message.MaxFrameSize)
+	if err != nil {
+		log.Error(fmt.Sprintf("[%v]: %v\n", conn.RemoteAddr(), err))
+		return err
+	}
+	if hello := parseHello(line); hello != nil {
+		serveFramed(conn, r, hello, sq)
+		return nil
 	}
-	sq <- NewSession(conn, scanner.Text())
+	if isMuxRequest(line) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 1024), message.MaxFrameSize)
+		serveMux(conn, scanner, sq)
+		return nil
+	}
+	sq <- NewSession(conn, line)
 	return nil
 }
+
+// 1行分のメッセージを読み込む。行末の改行文字は取り除く。
+// 最後の行に改行文字が無い場合も、1行として扱う。
+//
+// param : r 読み込み元。
+//
+// param : maxSize 1行の最大サイズ。
+//
+// return : 読み込んだ行。
+//
+// return : エラー情報。
+func readLine(r *bufio.Reader, maxSize int) (string, error) {
+	var line []byte
+	for {
+		b, err := r.ReadSlice('\n')
+		line = append(line, b...)
+		if len(line) > maxSize {
+			return ``, bufio.ErrTooLong
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == io.EOF && len(line) > 0 {
+			break
+		}
+		if err != nil {
+			return ``, err
+		}
+		break
+	}
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
@@ -13,7 +13,7 @@ func init() {
 }
 
 func TestStartReceive_ポート番号に定義外の値を渡すとエラーが発生する(t *testing.T) {
-	_, err := StartReceive(config.Servant.Sys.BindAddress, 65536, config.Servant.Job.MultiProc)
+	_, err := StartReceive(config.Servant.Sys.BindAddress, 65536, config.Servant.Job.MultiProc, nil)
 
 	if err == nil {
 		t.Error("エラーが発生していない。")
@@ -5,6 +5,7 @@
 package remote
 
 import (
+	"fmt"
 	"net"
 	"time"
 
@@ -23,8 +24,13 @@ type Session struct {
 	Conn net.Conn
 	Body string
 
+	strict         bool   // 未知の種別や項目を含むメッセージを拒否する場合はtrue
+	reqID          string // 多重化接続で要求を識別するID
+	resent         bool   // 多重化接続の再接続後に再送された要求であればtrue
 	endHeartbeatCh chan endSig
-	doJobRequest   func(req *message.Request, conf *config.ServantConfig, stCh chan<- string) *message.Response
+	doJobRequest   func(req *message.Request, conf *config.ServantConfig, stCh chan<- string, outCh chan<- string) *message.Response
+	doCancel       func(cnl *message.Cancel, conf *config.ServantConfig) *message.CancelResult
+	doAttach       func(a *message.Attach, conf *config.ServantConfig) *message.Response
 }
 
 // Sessionオブジェクトのコンストラクタ
@@ -33,6 +39,8 @@ func NewSession(conn net.Conn, body string) *Session {
 	s.Conn = conn
 	s.Body = body
 	s.doJobRequest = job.DoJobRequest
+	s.doCancel = job.DoJobCancel
+	s.doAttach = job.DoJobAttach
 	s.startHeartbeat()
 	return s
 }
@@ -44,22 +52,64 @@ func (s *Session) Do(conf *config.ServantConfig) error {
 	defer s.Conn.Close()
 	defer s.endHeartbeat()
 
+	if err := message.Verify(s.Body, conf.Sec.AuthSecret); err != nil {
+		console.Display("CTS026E", s.Conn.RemoteAddr(), err)
+		return s.reject(err)
+	}
+	if s.strict {
+		if err := message.CheckServantMessage(s.Body); err != nil {
+			rejectConn(s.Conn, err)
+			return err
+		}
+	}
+
 	var msg string
 	req := new(message.Request)
 	if err := req.ParseJSON(s.Body); err != nil {
 		chk := new(message.JobCheck)
-		if err := chk.ParseJSON(s.Body); err != nil {
+		cnl := new(message.Cancel)
+		jl := new(message.Joblog)
+		at := new(message.Attach)
+		st := new(message.Status)
+		if err := chk.ParseJSON(s.Body); err == nil {
+			resultMsg, err := s.doJobCheck(chk, conf)
+			if err != nil {
+				log.Error(err)
+				return err
+			}
+			msg = resultMsg
+		} else if err := cnl.ParseJSON(s.Body); err == nil {
+			resultMsg, err := s.doJobCancel(cnl, conf)
+			if err != nil {
+				log.Error(err)
+				return err
+			}
+			msg = resultMsg
+		} else if err := jl.ParseJSON(s.Body); err == nil {
+			resultMsg, err := s.doJoblog(jl, conf)
+			if err != nil {
+				log.Error(err)
+				return err
+			}
+			msg = resultMsg
+		} else if err := at.ParseJSON(s.Body); err == nil {
+			resMsg, err := s.doJobAttach(at, conf)
+			if err != nil {
+				log.Error(err)
+				return err
+			}
+			msg = resMsg
+		} else if err := st.ParseJSON(s.Body); err == nil {
+			resultMsg, err := s.doServantStatus(st, conf)
+			if err != nil {
+				log.Error(err)
+				return err
+			}
+			msg = resultMsg
+		} else {
 			console.Display("CTS015E", err.Error())
 			return err
 		}
-
-		resultMsg, err := s.doJobCheck(chk, conf)
-		if err != nil {
-			log.Error(err)
-			return err
-		}
-
-		msg = resultMsg
 	} else {
 		resMsg, err := s.doRequest(req, conf)
 		if err != nil {
@@ -89,7 +139,21 @@ func (s *Session) doRequest(req *message.Request, conf *config.ServantConfig) (s
 	go s.waitAndSendStartTime(stCh)
 	defer close(stCh)
 
-	res := s.doJobRequest(req, conf, stCh)
+	var outCh chan string
+	if req.Stream {
+		outCh = make(chan string, 10)
+		outEndCh := make(chan struct{})
+		go s.sendOutput(outCh, outEndCh)
+		// 出力の送信がレスポンスより後にならないよう、送信完了を待つ
+		defer func() {
+			close(outCh)
+			<-outEndCh
+		}()
+	}
+
+	req.ReqID = s.reqID
+	req.Resent = s.resent
+	res := s.doJobRequest(req, conf, stCh, outCh)
 	return res.GenerateJSON()
 }
 
@@ -98,11 +162,55 @@ func (s *Session) doJobCheck(chk *message.JobCheck, conf *config.ServantConfig)
 	return result.GenerateJSON()
 }
 
+func (s *Session) doJobCancel(cnl *message.Cancel, conf *config.ServantConfig) (string, error) {
+	result := s.doCancel(cnl, conf)
+	return result.GenerateJSON()
+}
+
+func (s *Session) doJobAttach(a *message.Attach, conf *config.ServantConfig) (string, error) {
+	res := s.doAttach(a, conf)
+	return res.GenerateJSON()
+}
+
+func (s *Session) doJoblog(jl *message.Joblog, conf *config.ServantConfig) (string, error) {
+	console.Display("CTS032I", jl.NID, jl.JID)
+	result := job.DoJoblogFetch(jl, conf)
+	return result.GenerateJSON()
+}
+
+func (s *Session) doServantStatus(st *message.Status, conf *config.ServantConfig) (string, error) {
+	console.Display("CTS040I", s.Conn.RemoteAddr())
+	result := job.DoServantStatus(st, conf)
+	return result.GenerateJSON()
+}
+
+// 認証に失敗したメッセージを拒否する。
+// ジョブ実行要求の場合は、masterで異常終了として扱えるようにエラーレスポンスを返信する。
+func (s *Session) reject(authErr error) error {
+	req := new(message.Request)
+	if err := req.ParseJSON(s.Body); err != nil {
+		return authErr
+	}
+
+	res := s.createErrorResponse(req, fmt.Errorf("Servant rejected request: %s", authErr))
+	msg, err := res.GenerateJSON()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	if _, err := s.Conn.Write([]byte(msg + MsgEnd)); err != nil {
+		log.Error(err)
+		return err
+	}
+	return authErr
+}
+
 // ハートビートを開始する。
+// 送信間隔は開始時点の設定値を使用する。
 func (s *Session) startHeartbeat() {
 	s.endHeartbeatCh = make(chan endSig, 1)
+	t := time.Duration(config.Servant.Job.HeartbeatSpanSec) * time.Second
 	go func() {
-		t := time.Duration(config.Servant.Job.HeartbeatSpanSec) * time.Second
 		for {
 			select {
 			case <-s.endHeartbeatCh:
@@ -132,6 +240,15 @@ func (s *Session) waitAndSendStartTime(stCh <-chan string) {
 	s.Conn.Write([]byte(message.ST_HEADER + st + MsgEnd))
 }
 
+// ジョブの出力をチャネルから受け取り、1行ずつmasterへ送信する。
+// チャネルがクローズされたらoutEndChをクローズして終了する。
+func (s *Session) sendOutput(outCh <-chan string, outEndCh chan<- struct{}) {
+	defer close(outEndCh)
+	for line := range outCh {
+		s.Conn.Write([]byte(message.OUT_HEADER + line + MsgEnd))
+	}
+}
+
 // ジョブ実行結果が得られないようなエラーが発生した場合のレスポンスメッセージを生成する。
 func (s *Session) createErrorResponse(req *message.Request, err error) *message.Response {
 	res := new(message.Response)
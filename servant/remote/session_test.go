@@ -6,6 +6,7 @@ package remote
 import (
 	"errors"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/unirita/cuto/db"
@@ -19,7 +20,7 @@ func readTestConfig() *config.ServantConfig {
 	return config.Servant
 }
 
-func doTestRequest(req *message.Request, conf *config.ServantConfig, stCh chan<- string) *message.Response {
+func doTestRequest(req *message.Request, conf *config.ServantConfig, stCh chan<- string, outCh chan<- string) *message.Response {
 	res := new(message.Response)
 	res.NID = req.NID
 	res.RC = 1
@@ -149,3 +150,242 @@ func TestDo_コネクションオブジェクトへのWriteに失敗したらエ
 		t.Errorf("想定外のメッセージが書き込まれた: %s", conn.WriteStr)
 	}
 }
+
+func doTestCancel(cnl *message.Cancel, conf *config.ServantConfig) *message.CancelResult {
+	result := new(message.CancelResult)
+	result.NID = cnl.NID
+	result.JID = cnl.JID
+	result.Cancelled = true
+	return result
+}
+
+func TestDo_ジョブの実行中止要求を処理し結果を送信できる(t *testing.T) {
+	cnlMsg := `{"type":"cancel","version":"1.2.3","nid":1234,"jid":"001"}`
+
+	conf := readTestConfig()
+	message.ServantVersion = "2.3.4"
+
+	conn := testutil.NewConnStub()
+	session := Session{Conn: conn, Body: cnlMsg, doJobRequest: doTestRequest, doCancel: doTestCancel}
+	session.startHeartbeat()
+	err := session.Do(conf)
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+
+	expected := `{"type":"cancelresult","version":"2.3.4","nid":1234,"jid":"001","cancelled":true,"detail":""}`
+	expected += "\n"
+	if conn.WriteStr != expected {
+		t.Errorf("送信されたジョブ実行中止結果が間違っています。")
+		t.Logf("想定値: %s", expected)
+		t.Logf("実績値: %s", conn.WriteStr)
+	}
+}
+
+func doTestAttach(a *message.Attach, conf *config.ServantConfig) *message.Response {
+	res := new(message.Response)
+	res.NID = a.NID
+	res.JID = a.JID
+	res.RC = 1
+	res.Stat = 1
+	res.Var = "attached"
+	return res
+}
+
+func TestDo_実行中ジョブへの再接続要求を処理し結果を送信できる(t *testing.T) {
+	atMsg := `{"type":"attach","version":"1.2.3","nid":1234,"jid":"001"}`
+
+	conf := readTestConfig()
+	message.ServantVersion = "2.3.4"
+
+	conn := testutil.NewConnStub()
+	session := Session{Conn: conn, Body: atMsg, doJobRequest: doTestRequest, doAttach: doTestAttach}
+	session.startHeartbeat()
+	err := session.Do(conf)
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+
+	res := new(message.Response)
+	if err := res.ParseJSON(strings.TrimSuffix(conn.WriteStr, "\n")); err != nil {
+		t.Fatalf("送信されたメッセージ[%s]をパースできない: %s", conn.WriteStr, err)
+	}
+	if res.NID != 1234 || res.JID != "001" || res.RC != 1 || res.Var != "attached" {
+		t.Errorf("送信されたジョブ実行結果[%s]が間違っています。", conn.WriteStr)
+	}
+}
+
+func TestDo_ジョブログ取得要求を処理し結果を送信できる(t *testing.T) {
+	jlMsg := `{"type":"joblog","version":"1.2.3","nid":1234,"jid":"001"}`
+
+	conf := readTestConfig()
+	conf.Dir.JoblogDir = "notexist"
+	message.ServantVersion = "2.3.4"
+
+	conn := testutil.NewConnStub()
+	session := Session{Conn: conn, Body: jlMsg, doJobRequest: doTestRequest, doCancel: doTestCancel}
+	session.startHeartbeat()
+	err := session.Do(conf)
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+
+	result := new(message.JoblogResult)
+	if err := result.ParseJSON(strings.TrimSuffix(conn.WriteStr, "\n")); err != nil {
+		t.Fatalf("送信されたジョブログ取得結果をパースできない: %s", err)
+	}
+	if result.NID != 1234 || result.JID != "001" {
+		t.Errorf("送信されたジョブログ取得結果のID[%d/%s]が間違っています。", result.NID, result.JID)
+	}
+	if len(result.Detail) == 0 {
+		t.Error("ジョブログが存在しないにも関わらず、エラー内容が設定されていない。")
+	}
+}
+
+func TestDo_認証コードが正しい場合はジョブを実行できる(t *testing.T) {
+	reqMsg, _ := message.Sign(`{"type":"request","version":"1.2.3","nid":1234,"jid":"001","path":"test.sh"}`, "secret")
+
+	conf := readTestConfig()
+	conf.Sec.AuthSecret = "secret"
+
+	conn := testutil.NewConnStub()
+	session := Session{Conn: conn, Body: reqMsg, doJobRequest: doTestRequest}
+	session.startHeartbeat()
+	err := session.Do(conf)
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if !strings.Contains(conn.WriteStr, `"stat":1`) {
+		t.Errorf("ジョブ実行結果が送信されていない: %s", conn.WriteStr)
+	}
+}
+
+func TestDo_認証コードが無いジョブ実行要求は拒否する(t *testing.T) {
+	reqMsg := `{"type":"request","version":"1.2.3","nid":1234,"jid":"001","path":"test.sh"}`
+
+	conf := readTestConfig()
+	conf.Sec.AuthSecret = "secret"
+
+	conn := testutil.NewConnStub()
+	session := Session{Conn: conn, Body: reqMsg, doJobRequest: doTestRequest}
+	session.startHeartbeat()
+	err := session.Do(conf)
+	if err == nil {
+		t.Fatal("エラーが発生していない。")
+	}
+
+	res := new(message.Response)
+	if err := res.ParseJSON(strings.TrimSuffix(conn.WriteStr, "\n")); err != nil {
+		t.Fatalf("エラーレスポンスが送信されていない: %s", conn.WriteStr)
+	}
+	if res.Stat != db.ABNORMAL {
+		t.Errorf("ステータス[%d]が想定と違っている。", res.Stat)
+	}
+	if res.NID != 1234 || res.JID != "001" {
+		t.Errorf("インスタンスID[%d]、ジョブID[%s]が想定と違っている。", res.NID, res.JID)
+	}
+}
+
+func TestDo_認証コードが不正な中止要求は拒否する(t *testing.T) {
+	cnlMsg, _ := message.Sign(`{"type":"cancel","version":"1.2.3","nid":1234,"jid":"001"}`, "other")
+
+	conf := readTestConfig()
+	conf.Sec.AuthSecret = "secret"
+
+	conn := testutil.NewConnStub()
+	session := Session{Conn: conn, Body: cnlMsg, doJobRequest: doTestRequest, doCancel: doTestCancel}
+	session.startHeartbeat()
+	err := session.Do(conf)
+	if err == nil {
+		t.Fatal("エラーが発生していない。")
+	}
+	if conn.WriteStr != "" {
+		t.Errorf("想定外のメッセージが書き込まれた: %s", conn.WriteStr)
+	}
+}
+
+// 書き込まれた内容を全て記録するnet.Connのスタブ
+type recordConnStub struct {
+	*testutil.ConnStub
+	mutex  sync.Mutex
+	writes []string
+}
+
+func (c *recordConnStub) Write(b []byte) (int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.writes = append(c.writes, string(b))
+	return len(b), nil
+}
+
+func doTestStreamRequest(req *message.Request, conf *config.ServantConfig, stCh chan<- string, outCh chan<- string) *message.Response {
+	if outCh != nil {
+		outCh <- "line1"
+		outCh <- "line2"
+	}
+	return doTestRequest(req, conf, stCh, outCh)
+}
+
+func TestDo_ストリーミング指定時はジョブの出力をレスポンスより先に送信する(t *testing.T) {
+	reqMsg := `{"type":"request","version":"1.2.3","nid":1234,"jid":"001","path":"test.sh","stream":true}`
+
+	conf := readTestConfig()
+	message.ServantVersion = "2.3.4"
+
+	conn := &recordConnStub{ConnStub: testutil.NewConnStub()}
+	session := Session{Conn: conn, Body: reqMsg, doJobRequest: doTestStreamRequest}
+	session.startHeartbeat()
+	if err := session.Do(conf); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(strings.Join(conn.writes, ""), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("送信されたメッセージ数[%d]が想定と違っています: %v", len(lines), conn.writes)
+	}
+	if lines[0] != message.OUT_HEADER+"line1" || lines[1] != message.OUT_HEADER+"line2" {
+		t.Errorf("送信されたジョブの出力が間違っています: %v", lines[:2])
+	}
+	if !strings.HasPrefix(lines[2], `{"type":"response"`) {
+		t.Errorf("最後にレスポンスが送信されていません: %s", lines[2])
+	}
+}
+
+func TestDo_ストリーミング指定が無い場合は出力を送信しない(t *testing.T) {
+	reqMsg := `{"type":"request","version":"1.2.3","nid":1234,"jid":"001","path":"test.sh"}`
+
+	conf := readTestConfig()
+	conn := &recordConnStub{ConnStub: testutil.NewConnStub()}
+	session := Session{Conn: conn, Body: reqMsg, doJobRequest: doTestStreamRequest}
+	session.startHeartbeat()
+	if err := session.Do(conf); err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if strings.Contains(strings.Join(conn.writes, ""), message.OUT_HEADER) {
+		t.Errorf("ジョブの出力が送信された: %v", conn.writes)
+	}
+}
+
+func TestDo_状態確認要求を処理し結果を送信できる(t *testing.T) {
+	stMsg := `{"type":"status","version":"1.2.3"}`
+
+	conf := readTestConfig()
+	conf.Job.MultiProc = 5
+	message.ServantVersion = "2.3.4"
+
+	conn := testutil.NewConnStub()
+	session := Session{Conn: conn, Body: stMsg, doJobRequest: doTestRequest}
+	session.startHeartbeat()
+	err := session.Do(conf)
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+
+	result := new(message.StatusResult)
+	if err := result.ParseJSON(strings.TrimSuffix(conn.WriteStr, "\n")); err != nil {
+		t.Fatalf("送信されたメッセージ[%s]をパースできない: %s", conn.WriteStr, err)
+	}
+	if result.Version != "2.3.4" || result.MultiProc != 5 {
+		t.Errorf("送信された状態確認結果[%s]が間違っています。", conn.WriteStr)
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/unirita/cuto/console"
 	"github.com/unirita/cuto/log"
 	"github.com/unirita/cuto/servant/config"
+	"github.com/unirita/cuto/servant/job"
 	"github.com/unirita/cuto/servant/remote"
 )
 
@@ -18,8 +20,18 @@ var eventLoopFunc = eventLoop
 
 // サーバントメインルーチン
 func Run() (int, error) {
+	tlsConf, err := config.Servant.TLSConfig()
+	if err != nil {
+		return -1, err
+	}
+
+	job.InitQueue(config.Servant.Job.MultiProc)
+	if err := job.CompactJournal(config.Servant.Dir.JoblogDir); err != nil {
+		console.Display("CTS034W", err)
+	}
+
 	// セッションの用意
-	sq, err := remote.StartReceive(config.Servant.Sys.BindAddress, config.Servant.Sys.BindPort, config.Servant.Job.MultiProc)
+	sq, err := remote.StartReceive(config.Servant.Sys.BindAddress, config.Servant.Sys.BindPort, config.Servant.Job.MultiProc, tlsConf)
 	if err != nil {
 		return -1, err
 	}
@@ -0,0 +1,77 @@
+package gen
+
+import (
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/utctime"
+)
+
+// DBから取得したジョブネットワーク、ジョブ、試行結果の情報を、表示用の構造体へ格納する。
+// show、apiのいずれも、この関数で生成した構造体を出力する。
+//
+// param : jobnet ジョブネットワーク情報。
+//
+// param : jobs ジョブネットワークに所属するジョブ情報一覧。
+//
+// param : attempts ジョブネットワークに所属するジョブの試行結果一覧。
+//
+// param : isOutputUTC 日時を標準時のまま出力するかどうか。
+//
+// return : 表示用のジョブネットワーク構造体。
+func NewOutputJobNet(jobnet *db.JobNetworkResult, jobs []*db.JobResult, attempts []*db.JobAttempt, isOutputUTC bool) *OutputJobNet {
+	jobNet := &OutputJobNet{
+		Id:         jobnet.ID,
+		Jobnetwork: jobnet.JobnetWork,
+		StartDate:  correctTimezone(jobnet.StartDate, isOutputUTC),
+		EndDate:    correctTimezone(jobnet.EndDate, isOutputUTC),
+		Status:     jobnet.Status,
+		Detail:     jobnet.Detail,
+		CreateDate: correctTimezone(jobnet.CreateDate, isOutputUTC),
+		UpdateDate: correctTimezone(jobnet.UpdateDate, isOutputUTC),
+	}
+	for _, job := range jobs {
+		j := &OutputJob{
+			JobId:      job.JobId,
+			Jobname:    job.JobName,
+			StartDate:  correctTimezone(job.StartDate, isOutputUTC),
+			EndDate:    correctTimezone(job.EndDate, isOutputUTC),
+			Status:     job.Status,
+			Detail:     job.Detail,
+			Rc:         job.Rc,
+			Node:       job.Node,
+			Port:       job.Port,
+			Variable:   job.Variable,
+			Outputs:    job.GetOutputs(),
+			CreateDate: correctTimezone(job.CreateDate, isOutputUTC),
+			UpdateDate: correctTimezone(job.UpdateDate, isOutputUTC),
+		}
+		for _, attempt := range attempts {
+			if attempt.JobId != job.JobId {
+				continue
+			}
+			j.Attempts = append(j.Attempts, &OutputAttempt{
+				Attempt:    attempt.Attempt,
+				StartDate:  correctTimezone(attempt.StartDate, isOutputUTC),
+				EndDate:    correctTimezone(attempt.EndDate, isOutputUTC),
+				Status:     attempt.Status,
+				Detail:     attempt.Detail,
+				Rc:         attempt.Rc,
+				Node:       attempt.Node,
+				Port:       attempt.Port,
+				CreateDate: correctTimezone(attempt.CreateDate, isOutputUTC),
+			})
+		}
+		jobNet.Jobs = append(jobNet.Jobs, j)
+	}
+	return jobNet
+}
+
+func correctTimezone(utcStr string, isOutputUTC bool) string {
+	if isOutputUTC {
+		return utcStr
+	}
+	t, err := utctime.Parse(utctime.Default, utcStr)
+	if err != nil {
+		return utcStr
+	}
+	return t.FormatLocaltime(utctime.Default)
+}
@@ -14,7 +14,7 @@ type CsvGenerator struct {
 }
 
 func (s CsvGenerator) Generate(out *OutputRoot) (string, error) {
-	var jnBuf, jobBuf bytes.Buffer
+	var jnBuf, jobBuf, attemptBuf bytes.Buffer
 
 	jnWriter := csv.NewWriter(&jnBuf)
 	jnWriter.Write([]string{"#Type", "JobNetwork ID", "JobNetwork Name", "Start Date", "End Date",
@@ -22,6 +22,8 @@ func (s CsvGenerator) Generate(out *OutputRoot) (string, error) {
 	jobWriter := csv.NewWriter(&jobBuf)
 	jobWriter.Write([]string{"#Type", "JobNework ID", "Job ID", "Job Name", "Start Date", "End Date",
 		"Status", "Detail Message", "Return Code", "Node", "Port", "Variable", "CreateDate", "Update Date"})
+	attemptWriter := csv.NewWriter(&attemptBuf)
+	hasAttempt := false
 	for _, jn := range out.Jobnetworks {
 		if err := jnWriter.Write([]string{"JOBNET", fmt.Sprintf("%d", jn.Id),
 			jn.Jobnetwork, jn.StartDate, jn.EndDate, fmt.Sprintf("%d", jn.Status),
@@ -37,9 +39,23 @@ func (s CsvGenerator) Generate(out *OutputRoot) (string, error) {
 
 				panic(err)
 			}
+			for _, a := range job.Attempts {
+				if !hasAttempt {
+					attemptWriter.Write([]string{"#Type", "JobNework ID", "Job ID", "Attempt", "Start Date", "End Date",
+						"Status", "Detail Message", "Return Code", "Node", "Port", "CreateDate"})
+					hasAttempt = true
+				}
+				if err := attemptWriter.Write([]string{"ATTEMPT", fmt.Sprintf("%d", jn.Id), job.JobId,
+					fmt.Sprintf("%d", a.Attempt), a.StartDate, a.EndDate, fmt.Sprintf("%d", a.Status), a.Detail,
+					fmt.Sprintf("%d", a.Rc), a.Node, fmt.Sprintf("%d", a.Port), a.CreateDate}); err != nil {
+
+					panic(err)
+				}
+			}
 		}
 	}
 	jnWriter.Flush()
 	jobWriter.Flush()
-	return jnBuf.String() + jobBuf.String(), nil
+	attemptWriter.Flush()
+	return jnBuf.String() + jobBuf.String() + attemptBuf.String(), nil
 }
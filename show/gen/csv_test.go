@@ -6,6 +6,7 @@ package gen
 import (
 	"bufio"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -113,3 +114,28 @@ func TestGenerate_CSV形式にジェネレート(t *testing.T) {
 		i++
 	}
 }
+
+func TestGenerate_CSV形式にジョブの試行結果をジェネレート(t *testing.T) {
+	d := CreateTestData()
+	d.Jobnetworks[0].Jobs[1].Attempts = []*OutputAttempt{
+		{Attempt: 1, StartDate: "2015-04-27 14:05:24.999", EndDate: "2015-04-27 14:10:24.999", Status: 9,
+			Detail: "ABNORMAL", Rc: 12, Node: "localhost", Port: 2015, CreateDate: "2015-04-27 14:10:24.999"},
+	}
+
+	var gen CsvGenerator
+	msg, err := gen.Generate(d)
+	if err != nil {
+		t.Fatalf("エラーが返りました。 - %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(msg, "\n"), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("出力行数[%v]が想定と違います。", len(lines))
+	}
+	if lines[5] != "#Type,JobNework ID,Job ID,Attempt,Start Date,End Date,Status,Detail Message,Return Code,Node,Port,CreateDate" {
+		t.Errorf("不正な行です。[%v]", lines[5])
+	}
+	if lines[6] != "ATTEMPT,101,job2,1,2015-04-27 14:05:24.999,2015-04-27 14:10:24.999,9,ABNORMAL,12,localhost,2015,2015-04-27 14:10:24.999" {
+		t.Errorf("不正な行です。[%v]", lines[6])
+	}
+}
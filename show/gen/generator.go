@@ -28,16 +28,31 @@ type OutputJobNet struct {
 
 // 表示用のジョブ構造体
 type OutputJob struct {
-	JobId      string `json:"jobid"`
-	Jobname    string `json:"jobname"`
+	JobId      string            `json:"jobid"`
+	Jobname    string            `json:"jobname"`
+	StartDate  string            `json:"startdate"`
+	EndDate    string            `json:"enddate"`
+	Status     int               `json:"status"`
+	Detail     string            `json:"detail"`
+	Rc         int               `json:"rc"`
+	Node       string            `json:"node`
+	Port       int               `json:"port"`
+	Variable   string            `json:"variable"`
+	Outputs    map[string]string `json:"outputs,omitempty"`
+	CreateDate string            `json:"createdate"`
+	UpdateDate string            `json:"updatedate"`
+	Attempts   []*OutputAttempt  `json:"attempts,omitempty"`
+}
+
+// 表示用のジョブ試行結果構造体
+type OutputAttempt struct {
+	Attempt    int    `json:"attempt"`
 	StartDate  string `json:"startdate"`
 	EndDate    string `json:"enddate"`
 	Status     int    `json:"status"`
 	Detail     string `json:"detail"`
 	Rc         int    `json:"rc"`
-	Node       string `json:"node`
+	Node       string `json:"node"`
 	Port       int    `json:"port"`
-	Variable   string `json:"variable"`
 	CreateDate string `json:"createdate"`
-	UpdateDate string `json:"updatedate"`
 }
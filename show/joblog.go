@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/unirita/cuto/db"
+	"github.com/unirita/cuto/db/query"
+	"github.com/unirita/cuto/message"
+)
+
+// servantへメッセージを送信する関数の型。
+type sendFunc func(string, int, string, chan<- string, chan<- string) (string, error)
+
+// ジョブログの取得に使用する構造体。
+type JoblogParam struct {
+	nid  int      // インスタンスID
+	jid  string   // ジョブID
+	from int      // 取得開始行
+	to   int      // 取得終了行
+	tail int      // 末尾から取得する行数
+	send sendFunc // servantへの送信関数
+}
+
+// JoblogParam構造体のコンストラクタ。
+func NewJoblogParam(nid int, jid string, from int, to int, tail int, send sendFunc) *JoblogParam {
+	return &JoblogParam{
+		nid:  nid,
+		jid:  jid,
+		from: from,
+		to:   to,
+		tail: tail,
+		send: send,
+	}
+}
+
+// ジョブを実行したservantからジョブログを取得し、wへ出力する。
+//
+// param : db_name DBファイル名。
+//
+// param : w 出力先。
+//
+// return : 取得結果。
+//
+// return : エラー情報。
+func (p *JoblogParam) Run(db_name string, w io.Writer) (*message.JoblogResult, error) {
+	conn, err := db.Open(db_name)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	jobs, err := query.GetJobMapOfTargetNetwork(conn, p.nid)
+	if err != nil {
+		return nil, err
+	}
+	job, ok := jobs[p.jid]
+	if !ok {
+		return nil, fmt.Errorf("Job [%s] not found in instance [%d].", p.jid, p.nid)
+	}
+
+	jl := &message.Joblog{NID: p.nid, JID: p.jid, From: p.from, To: p.to, Tail: p.tail}
+	jlMsg, err := jl.GenerateJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	stCh := make(chan string, 1)
+	defer close(stCh)
+
+	resultMsg, err := p.send(nodeHost(job.Node), job.Port, jlMsg, stCh, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(message.JoblogResult)
+	if err := result.ParseJSON(resultMsg); err != nil {
+		return nil, err
+	}
+	if len(result.Detail) > 0 {
+		return nil, fmt.Errorf("%s", result.Detail)
+	}
+	fmt.Fprint(w, result.Content)
+	return result, nil
+}
+
+// ノード名からホスト名を取り出す。コンテナ指定（host>container）の場合はホスト部分を返す。
+func nodeHost(node string) string {
+	return strings.SplitN(node, ">", 2)[0]
+}
+
+// FROM-TO形式の行範囲指定をパースする。省略された側は0を返す。
+func parseLines(lines string) (int, int, error) {
+	if len(lines) == 0 {
+		return 0, 0, nil
+	}
+	parts := strings.SplitN(lines, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("Invalid [lines] format. [%s]", lines)
+	}
+	var err error
+	nums := make([]int, 2)
+	for i, part := range parts {
+		if len(part) == 0 {
+			continue
+		}
+		if nums[i], err = strconv.Atoi(part); err != nil || nums[i] < 1 {
+			return 0, 0, fmt.Errorf("Invalid [lines] format. [%s]", lines)
+		}
+	}
+	if nums[1] > 0 && nums[0] > nums[1] {
+		return 0, 0, fmt.Errorf("Invalid [lines] format. [%s]", lines)
+	}
+	return nums[0], nums[1], nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/unirita/cuto/message"
+)
+
+const joblogTestDB = "show_test.sqlite"
+
+func TestJoblogParamRun_servantから取得したジョブログを出力する(t *testing.T) {
+	var sentHost, sentMsg string
+	var sentPort int
+	send := func(host string, port int, msg string, stCh chan<- string, outCh chan<- string) (string, error) {
+		sentHost, sentPort, sentMsg = host, port, msg
+		return `{"type":"joblogresult","version":"1.2.3","nid":1,"jid":"JOB03","file":"1.job.JOB03.log","content":"line1\nline2\n","truncated":false,"detail":""}`, nil
+	}
+
+	var buf bytes.Buffer
+	param := NewJoblogParam(1, "JOB03", 0, 0, 2, send)
+	result, err := param.Run(joblogTestDB, &buf)
+	if err != nil {
+		t.Fatalf("想定外のエラーが発生した: %s", err)
+	}
+	if sentHost != "dcserver" || sentPort != 2015 {
+		t.Errorf("送信先[%s:%d]が想定と違っている。", sentHost, sentPort)
+	}
+	jl := new(message.Joblog)
+	if err := jl.ParseJSON(sentMsg); err != nil {
+		t.Fatalf("送信したメッセージをパースできない: %s", err)
+	}
+	if jl.NID != 1 || jl.JID != "JOB03" || jl.Tail != 2 {
+		t.Errorf("送信したメッセージ[%s]が想定と違っている。", sentMsg)
+	}
+	if buf.String() != "line1\nline2\n" {
+		t.Errorf("出力内容[%s]が想定と違っている。", buf.String())
+	}
+	if result.File != "1.job.JOB03.log" {
+		t.Errorf("ファイル名[%s]が想定と違っている。", result.File)
+	}
+}
+
+func TestJoblogParamRun_servantがエラーを返した場合はエラー(t *testing.T) {
+	send := func(host string, port int, msg string, stCh chan<- string, outCh chan<- string) (string, error) {
+		return `{"type":"joblogresult","version":"1.2.3","nid":1,"jid":"JOB01","file":"","content":"","truncated":false,"detail":"Joblog file not found."}`, nil
+	}
+
+	var buf bytes.Buffer
+	param := NewJoblogParam(1, "JOB01", 0, 0, 0, send)
+	if _, err := param.Run(joblogTestDB, &buf); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("想定外の出力[%s]があった。", buf.String())
+	}
+}
+
+func TestJoblogParamRun_存在しないジョブはエラー(t *testing.T) {
+	called := false
+	send := func(host string, port int, msg string, stCh chan<- string, outCh chan<- string) (string, error) {
+		called = true
+		return "", errors.New("not expected")
+	}
+
+	var buf bytes.Buffer
+	param := NewJoblogParam(1, "NOJOB", 0, 0, 0, send)
+	if _, err := param.Run(joblogTestDB, &buf); err == nil {
+		t.Error("エラーが発生しなかった。")
+	}
+	if called {
+		t.Error("servantへ送信された。")
+	}
+}
+
+func TestParseLines_行範囲をパースできる(t *testing.T) {
+	cases := []struct {
+		lines string
+		from  int
+		to    int
+	}{
+		{"", 0, 0},
+		{"2-5", 2, 5},
+		{"3-", 3, 0},
+		{"-4", 0, 4},
+	}
+	for _, c := range cases {
+		from, to, err := parseLines(c.lines)
+		if err != nil {
+			t.Errorf("[%s]で想定外のエラーが発生した: %s", c.lines, err)
+			continue
+		}
+		if from != c.from || to != c.to {
+			t.Errorf("[%s]のパース結果[%d-%d]が想定と違っている。", c.lines, from, to)
+		}
+	}
+}
+
+func TestParseLines_不正な行範囲はエラー(t *testing.T) {
+	for _, lines := range []string{"5", "a-3", "0-3", "5-2"} {
+		if _, _, err := parseLines(lines); err == nil {
+			t.Errorf("[%s]でエラーが発生しなかった。", lines)
+		}
+	}
+}
+
+func TestNodeHost_コンテナ指定の場合はホスト部分を返す(t *testing.T) {
+	if h := nodeHost("host1>container1"); h != "host1" {
+		t.Errorf("ホスト名[%s]が想定と違っている。", h)
+	}
+	if h := nodeHost("host1"); h != "host1" {
+		t.Errorf("ホスト名[%s]が想定と違っている。", h)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/unirita/cuto/console"
 	"github.com/unirita/cuto/db"
 	"github.com/unirita/cuto/master/config"
+	"github.com/unirita/cuto/master/remote"
 	"github.com/unirita/cuto/show/gen"
 	"github.com/unirita/cuto/utctime"
 )
@@ -29,6 +30,10 @@ type arguments struct {
 	format string // 表示フォーマット
 	config string // 設定ファイルのパス
 	isUTC  bool   // 時刻を標準時として扱うかどうか
+	joblog bool   // ジョブログを表示
+	jid    string // ジョブID
+	lines  string // ジョブログの表示行範囲
+	tail   int    // ジョブログの末尾から表示する行数
 }
 
 // 戻り値
@@ -54,6 +59,7 @@ const status_normal = "normal"
 const status_abnormal = "abnormal"
 const status_running = "running"
 const status_warn = "warn"
+const status_cancelled = "cancelled"
 
 func main() {
 	console.DisplayError("CTU001I", Version)
@@ -82,6 +88,9 @@ func realMain(args *arguments) int {
 		console.DisplayError("CTU006E", args.config)
 		return rc_PARMERR
 	}
+	if args.joblog {
+		return showJoblog(args)
+	}
 	if len(args.from) == 0 && len(args.to) == 0 { // From-to指定無しの場合は、現在のCPU日付のみを対象とする
 		now := utctime.Now()
 		if args.isUTC {
@@ -121,6 +130,37 @@ func realMain(args *arguments) int {
 	return rc_OK
 }
 
+// ジョブログの表示
+func showJoblog(args *arguments) int {
+	if args.nid <= 0 || len(args.jid) == 0 {
+		console.DisplayError("CTU003E", "[-joblog] requires [-nid] and [-jid].")
+		showUsage()
+		return rc_PARMERR
+	}
+	from, to, err := parseLines(args.lines)
+	if err != nil {
+		console.DisplayError("CTU003E", err)
+		showUsage()
+		return rc_PARMERR
+	}
+	if args.tail < 0 || (args.tail > 0 && len(args.lines) > 0) {
+		console.DisplayError("CTU003E", "Invalid [tail] option.")
+		showUsage()
+		return rc_PARMERR
+	}
+
+	param := NewJoblogParam(args.nid, args.jid, from, to, args.tail, remote.SendRequest)
+	result, err := param.Run(config.DB.DBFile, os.Stdout)
+	if err != nil {
+		console.DisplayError("CTU007E", args.nid, args.jid, err)
+		return rc_ERROR
+	}
+	if result.Truncated {
+		console.DisplayError("CTU008W", result.File)
+	}
+	return rc_OK
+}
+
 // 引数情報の取得
 func fetchArgs() *arguments {
 	args := new(arguments)
@@ -135,6 +175,10 @@ func fetchArgs() *arguments {
 	flag.StringVar(&args.format, "format", "", "Output format.")
 	flag.StringVar(&args.config, "c", "", "Input config-file.")
 	flag.BoolVar(&args.isUTC, "utc", false, "UTC option.")
+	flag.BoolVar(&args.joblog, "joblog", false, "Joblog option.")
+	flag.StringVar(&args.jid, "jid", "", "Job ID.")
+	flag.StringVar(&args.lines, "lines", "", "Joblog line range.")
+	flag.IntVar(&args.tail, "tail", 0, "Joblog tail lines.")
 	flag.Parse()
 	return args
 }
@@ -171,6 +215,8 @@ func getStatusType(status string) (int, error) {
 		return db.RUNNING, nil
 	} else if status == status_warn {
 		return db.WARN, nil
+	} else if status == status_cancelled {
+		return db.CANCELLED, nil
 	}
 	return 0, fmt.Errorf("Unknown status type [%v].", status)
 }
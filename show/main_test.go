@@ -426,6 +426,13 @@ func TestGetStatusType_指定毎に返るステータスを確認(t *testing.T)
 		t.Errorf("ステータス[%v]が返る予定が、[%v]が返った。", db.WARN, s)
 	}
 
+	s, err = getStatusType("cancelled")
+	if err != nil {
+		t.Errorf("成功する予定が、エラーになった。 - %v", err)
+	} else if s != db.CANCELLED {
+		t.Errorf("ステータス[%v]が返る予定が、[%v]が返った。", db.CANCELLED, s)
+	}
+
 	s, err = getStatusType("")
 	if err != nil {
 		t.Errorf("成功する予定が、エラーになった。 - %v", err)
@@ -11,7 +11,6 @@ import (
 	"github.com/unirita/cuto/db"
 	"github.com/unirita/cuto/db/query"
 	"github.com/unirita/cuto/show/gen"
-	"github.com/unirita/cuto/utctime"
 )
 
 // 表示に使用する構造体。
@@ -27,8 +26,9 @@ type ShowParam struct {
 
 // ジョブネットワークのインスタンス毎の表示用構造体
 type oneJobnetwork struct {
-	jobnet *db.JobNetworkResult // ジョブネットワーク情報
-	jobs   []*db.JobResult      // ジョブネットワークに所属するジョブ情報一覧
+	jobnet   *db.JobNetworkResult // ジョブネットワーク情報
+	jobs     []*db.JobResult      // ジョブネットワークに所属するジョブ情報一覧
+	attempts []*db.JobAttempt     // ジョブネットワークに所属するジョブの試行結果一覧
 }
 
 // ShowParam構造体のコンストラクタ。
@@ -112,48 +112,14 @@ func (o *oneJobnetwork) getJobList(conn db.IConnection) error {
 	if err != nil {
 		return err
 	}
+	o.attempts, err = query.GetJobAttemptsOfTargetNetwork(conn, o.jobnet.ID)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
 // 出力ジェネレータ構造体への格納
 func (o *oneJobnetwork) setOutputStructure(isOutputUTC bool) *gen.OutputJobNet {
-	jobNet := &gen.OutputJobNet{
-		Id:         o.jobnet.ID,
-		Jobnetwork: o.jobnet.JobnetWork,
-		StartDate:  correctTimezone(o.jobnet.StartDate, isOutputUTC),
-		EndDate:    correctTimezone(o.jobnet.EndDate, isOutputUTC),
-		Status:     o.jobnet.Status,
-		Detail:     o.jobnet.Detail,
-		CreateDate: correctTimezone(o.jobnet.CreateDate, isOutputUTC),
-		UpdateDate: correctTimezone(o.jobnet.UpdateDate, isOutputUTC),
-	}
-	for _, job := range o.jobs {
-		j := &gen.OutputJob{
-			JobId:      job.JobId,
-			Jobname:    job.JobName,
-			StartDate:  correctTimezone(job.StartDate, isOutputUTC),
-			EndDate:    correctTimezone(job.EndDate, isOutputUTC),
-			Status:     job.Status,
-			Detail:     job.Detail,
-			Rc:         job.Rc,
-			Node:       job.Node,
-			Port:       job.Port,
-			Variable:   job.Variable,
-			CreateDate: correctTimezone(job.CreateDate, isOutputUTC),
-			UpdateDate: correctTimezone(job.UpdateDate, isOutputUTC),
-		}
-		jobNet.Jobs = append(jobNet.Jobs, j)
-	}
-	return jobNet
-}
-
-func correctTimezone(utcStr string, isOutputUTC bool) string {
-	if isOutputUTC {
-		return utcStr
-	}
-	t, err := utctime.Parse(utctime.Default, utcStr)
-	if err != nil {
-		return utcStr
-	}
-	return t.FormatLocaltime(utctime.Default)
+	return gen.NewOutputJobNet(o.jobnet, o.jobs, o.attempts, isOutputUTC)
 }
@@ -245,3 +245,34 @@ func TestSetOutputStructure_ローカルタイムゾーンでの出力(t *testin
 	}
 
 }
+
+func TestSetOutputStructure_ジョブの試行結果をセットする(t *testing.T) {
+	jobnet := &oneJobnetwork{}
+	jobnet.jobnet = &db.JobNetworkResult{ID: 123, JobnetWork: "Jobnet123"}
+	jobnet.jobs = []*db.JobResult{
+		{ID: 123, JobId: "Job1", Status: db.NORMAL},
+		{ID: 123, JobId: "Job2", Status: db.NORMAL},
+	}
+	jobnet.attempts = []*db.JobAttempt{
+		{ID: 123, JobId: "Job1", Attempt: 1, Status: db.ABNORMAL, Rc: 12, StartDate: "2015-04-27 14:15:24.999"},
+		{ID: 123, JobId: "Job1", Attempt: 2, Status: db.NORMAL, Rc: 0, StartDate: "2015-04-27 14:25:24.999"},
+	}
+
+	out := jobnet.setOutputStructure(true)
+	if len(out.Jobs) != 2 {
+		t.Fatalf("ジョブ件数[%v]が想定と違います。", len(out.Jobs))
+	}
+	attempts := out.Jobs[0].Attempts
+	if len(attempts) != 2 {
+		t.Fatalf("Job1の試行結果件数[%v]が想定と違います。", len(attempts))
+	}
+	if attempts[0].Attempt != 1 || attempts[0].Status != db.ABNORMAL || attempts[0].Rc != 12 {
+		t.Errorf("1回目の試行結果[%v]が想定と違います。", attempts[0])
+	}
+	if attempts[1].Attempt != 2 || attempts[1].StartDate != "2015-04-27 14:25:24.999" {
+		t.Errorf("2回目の試行結果[%v]が想定と違います。", attempts[1])
+	}
+	if out.Jobs[1].Attempts != nil {
+		t.Errorf("試行結果の無いJob2に試行結果[%v]がセットされました。", out.Jobs[1].Attempts)
+	}
+}
@@ -79,6 +79,14 @@ if %errorlevel% neq 0 (
 )
 popd
 popd
+pushd launcher
+echo github.com/unirita/cuto/master/launcher package tested...
+go test -coverprofile cover.out>> %LOGFILE%
+if %errorlevel% neq 0 (
+  echo NG.
+  set RETCODE=1
+)
+popd
 pushd remote
 echo github.com/unirita/cuto/master/remote package tested...
 go test -coverprofile cover.out>> %LOGFILE%
@@ -179,6 +187,28 @@ if %errorlevel% neq 0 (
 popd
 popd
 
+pushd scheduler
+pushd daemon
+echo github.com/unirita/cuto/scheduler/daemon package tested...
+go test -coverprofile cover.out>> %LOGFILE%
+if %errorlevel% neq 0 (
+  echo NG.
+  set RETCODE=1
+)
+popd
+popd
+
+pushd scheduler
+pushd schedule
+echo github.com/unirita/cuto/scheduler/schedule package tested...
+go test -coverprofile cover.out>> %LOGFILE%
+if %errorlevel% neq 0 (
+  echo NG.
+  set RETCODE=1
+)
+popd
+popd
+
 pushd utctime
 echo github.com/unirita/cuto/utctime package tested...
 go test -coverprofile cover.out>> %LOGFILE%
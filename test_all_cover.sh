@@ -78,6 +78,13 @@ if [ "$?" -ne "0" ] ; then
   echo "NG."
   RETCODE=1
 fi
+cd $TESTROOT/master/launcher
+echo "github.com/unirita/cuto/master/launcher package tested..."
+go test -coverprofile cover.out>> $LOGFILE
+if [ "$?" -ne "0" ] ; then
+  echo "NG."
+  RETCODE=1
+fi
 
 
 cd $TESTROOT/master/remote
@@ -179,6 +186,23 @@ fi
 
 
 
+cd $TESTROOT/scheduler/daemon
+echo "github.com/unirita/cuto/scheduler/daemon package tested..."
+go test -coverprofile cover.out>> $LOGFILE
+if [ "$?" -ne "0" ] ; then
+  echo "NG."
+  RETCODE=1
+fi
+cd $TESTROOT/scheduler/schedule
+echo "github.com/unirita/cuto/scheduler/schedule package tested..."
+go test -coverprofile cover.out>> $LOGFILE
+if [ "$?" -ne "0" ] ; then
+  echo "NG."
+  RETCODE=1
+fi
+
+
+
 cd $TESTROOT/utctime
 echo "github.com/unirita/cuto/utctime package tested..."
 go test -coverprofile cover.out>> $LOGFILE
@@ -0,0 +1,7 @@
+package util
+
+// LoadAverage returns system load average over the last 1 minute.
+// It returns negative value if load average is not available.
+func LoadAverage() float64 {
+	return loadAverage()
+}
@@ -0,0 +1,11 @@
+package util
+
+import (
+	"testing"
+)
+
+func TestLoadAverage_ReturnLoadAverageOfSystem(t *testing.T) {
+	if load := LoadAverage(); load < 0 {
+		t.Errorf("LoadAverage() returns %f, wants 0 or more.", load)
+	}
+}
@@ -0,0 +1,27 @@
+// +build darwin linux
+
+package util
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+const loadavgFile = "/proc/loadavg"
+
+func loadAverage() float64 {
+	b, err := ioutil.ReadFile(loadavgFile)
+	if err != nil {
+		return -1
+	}
+	fields := strings.Fields(string(b))
+	if len(fields) == 0 {
+		return -1
+	}
+	load, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return -1
+	}
+	return load
+}
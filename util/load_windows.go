@@ -0,0 +1,6 @@
+package util
+
+func loadAverage() float64 {
+	// Windows does not provide load average.
+	return -1
+}